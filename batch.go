@@ -0,0 +1,233 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/isaacphi/mcp-language-server/internal/tools"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	// defaultBatchConcurrency is how many batched tool calls run at once by default
+	defaultBatchConcurrency = 4
+	// maxBatchConcurrency caps the concurrency a batch can ask for
+	maxBatchConcurrency = 16
+)
+
+// batchCall is one tool call of a batch
+type batchCall struct {
+	Tool      string
+	Arguments map[string]any
+	// edits is set when the tool isn't read-only
+	edits bool
+}
+
+// batchResult is the outcome of a batched tool call. A call that wasn't run
+// because an earlier one failed is skipped.
+type batchResult struct {
+	text string
+	// structured is the call's structured content, if any
+	structured any
+	failed     bool
+	skipped    bool
+}
+
+// runBatch runs calls with at most concurrency in flight and returns their
+// results in order. A call that edits files waits for the calls before it and
+// runs alone, so edits never race each other or the calls around them. With
+// stopOnError, calls not yet started when one fails are skipped.
+func runBatch(ctx context.Context, calls []batchCall, concurrency int, stopOnError bool,
+	call func(context.Context, batchCall) batchResult,
+) []batchResult {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	results := make([]batchResult, len(calls))
+
+	var (
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+		failed bool
+	)
+	sem := make(chan struct{}, concurrency)
+	for i, c := range calls {
+		if c.edits {
+			wg.Wait()
+		}
+		sem <- struct{}{}
+		mu.Lock()
+		stop := failed && stopOnError
+		mu.Unlock()
+		if stop || ctx.Err() != nil {
+			<-sem
+			results[i] = batchResult{skipped: true}
+			continue
+		}
+
+		if c.edits {
+			results[i] = call(ctx, c)
+			if results[i].failed {
+				mu.Lock()
+				failed = true
+				mu.Unlock()
+			}
+			<-sem
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, c batchCall) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			result := call(ctx, c)
+			results[i] = result
+			if result.failed {
+				mu.Lock()
+				failed = true
+				mu.Unlock()
+			}
+		}(i, c)
+	}
+	wg.Wait()
+	return results
+}
+
+// parseBatchCalls reads the requests argument of the batch tool
+func parseBatchCalls(value any) ([]batchCall, error) {
+	items, ok := value.([]any)
+	if !ok {
+		return nil, fmt.Errorf("requests must be an array")
+	}
+	if len(items) == 0 {
+		return nil, fmt.Errorf("requests must not be empty")
+	}
+
+	calls := make([]batchCall, 0, len(items))
+	for i, item := range items {
+		request, ok := item.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("request %d must be an object", i+1)
+		}
+		tool, ok := request["tool"].(string)
+		if !ok || tool == "" {
+			return nil, fmt.Errorf("request %d must name a tool", i+1)
+		}
+		if tool == "batch" {
+			return nil, fmt.Errorf("request %d: batches can't be nested", i+1)
+		}
+		arguments := map[string]any{}
+		if v, ok := request["arguments"]; ok && v != nil {
+			if arguments, ok = v.(map[string]any); !ok {
+				return nil, fmt.Errorf("request %d: arguments must be an object", i+1)
+			}
+		}
+		calls = append(calls, batchCall{Tool: tool, Arguments: arguments})
+	}
+	return calls, nil
+}
+
+// BatchCallResult is the outcome of one call of a batch. Status is "ok",
+// "error" or "skipped"; Structured is the call's structured content.
+type BatchCallResult struct {
+	Tool       string `json:"tool"`
+	Status     string `json:"status"`
+	Text       string `json:"text,omitempty"`
+	Structured any    `json:"structured,omitempty"`
+}
+
+// BatchResult is the outcome of a batch, one result per call in order
+type BatchResult struct {
+	Succeeded int               `json:"succeeded"`
+	Failed    int               `json:"failed"`
+	Skipped   int               `json:"skipped"`
+	Results   []BatchCallResult `json:"results"`
+
+	calls   []batchCall
+	results []batchResult
+}
+
+func newBatchResult(calls []batchCall, results []batchResult) *BatchResult {
+	batch := &BatchResult{Results: []BatchCallResult{}, calls: calls, results: results}
+	for i, result := range results {
+		call := BatchCallResult{Tool: calls[i].Tool, Status: batchStatus(result), Text: result.text, Structured: result.structured}
+		switch call.Status {
+		case "skipped":
+			batch.Skipped++
+		case "error":
+			batch.Failed++
+		default:
+			batch.Succeeded++
+		}
+		batch.Results = append(batch.Results, call)
+	}
+	return batch
+}
+
+func (b *BatchResult) Format() string {
+	return formatBatchResults(b.calls, b.results)
+}
+
+func batchStatus(result batchResult) string {
+	switch {
+	case result.skipped:
+		return "skipped"
+	case result.failed:
+		return "error"
+	default:
+		return "ok"
+	}
+}
+
+// formatBatchResults renders the results of a batch, one section per call
+func formatBatchResults(calls []batchCall, results []batchResult) string {
+	var succeeded, failed, skipped int
+	var sections []string
+	for i, result := range results {
+		status := batchStatus(result)
+		switch status {
+		case "skipped":
+			skipped++
+		case "error":
+			failed++
+		default:
+			succeeded++
+		}
+		section := fmt.Sprintf("[%d] %s: %s\n", i+1, calls[i].Tool, status)
+		if result.skipped {
+			section += "Not run after an earlier request failed\n"
+		} else if text := strings.TrimRight(result.text, "\n"); text != "" {
+			section += text + "\n"
+		}
+		sections = append(sections, section)
+	}
+
+	summary := fmt.Sprintf("Batch of %d requests: %d succeeded, %d failed", len(calls), succeeded, failed)
+	if skipped > 0 {
+		summary += fmt.Sprintf(", %d skipped", skipped)
+	}
+	return summary + "\n\n" + strings.Join(sections, "\n")
+}
+
+// toolResult returns a tool's result as structured content, along with its
+// text rendering for clients and models that read text
+func toolResult(result tools.Result) *mcp.CallToolResult {
+	return mcp.NewToolResultStructured(result, result.Format())
+}
+
+// toolResultText joins the text content of a tool result
+func toolResultText(result *mcp.CallToolResult) string {
+	if result == nil {
+		return ""
+	}
+	var parts []string
+	for _, content := range result.Content {
+		if text, ok := mcp.AsTextContent(content); ok {
+			parts = append(parts, text.Text)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
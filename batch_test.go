@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunBatch(t *testing.T) {
+	calls := []batchCall{{Tool: "hover"}, {Tool: "fail"}, {Tool: "definition"}, {Tool: "hover"}}
+	call := func(ctx context.Context, c batchCall) batchResult {
+		return batchResult{text: c.Tool + " result", failed: c.Tool == "fail"}
+	}
+
+	t.Run("runs every call in order", func(t *testing.T) {
+		results := runBatch(context.Background(), calls, 4, false, call)
+		require.Len(t, results, 4)
+		assert.Equal(t, batchResult{text: "hover result"}, results[0])
+		assert.Equal(t, batchResult{text: "fail result", failed: true}, results[1])
+		assert.Equal(t, batchResult{text: "definition result"}, results[2])
+		assert.Equal(t, batchResult{text: "hover result"}, results[3])
+	})
+
+	t.Run("stops at the first failure", func(t *testing.T) {
+		results := runBatch(context.Background(), calls, 1, true, call)
+		assert.False(t, results[0].failed)
+		assert.True(t, results[1].failed)
+		assert.True(t, results[2].skipped)
+		assert.True(t, results[3].skipped)
+	})
+
+	t.Run("bounds concurrency", func(t *testing.T) {
+		var running, peak int32
+		slow := func(ctx context.Context, c batchCall) batchResult {
+			n := atomic.AddInt32(&running, 1)
+			for {
+				p := atomic.LoadInt32(&peak)
+				if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			atomic.AddInt32(&running, -1)
+			return batchResult{}
+		}
+		runBatch(context.Background(), make([]batchCall, 8), 2, false, slow)
+		assert.LessOrEqual(t, atomic.LoadInt32(&peak), int32(2))
+	})
+
+	t.Run("runs edits alone", func(t *testing.T) {
+		var running, finished int32
+		var edits []int32
+		track := func(ctx context.Context, c batchCall) batchResult {
+			n := atomic.AddInt32(&running, 1)
+			if c.edits {
+				edits = append(edits, n, atomic.LoadInt32(&finished))
+			}
+			time.Sleep(10 * time.Millisecond)
+			atomic.AddInt32(&running, -1)
+			atomic.AddInt32(&finished, 1)
+			return batchResult{}
+		}
+		calls := []batchCall{{Tool: "hover"}, {Tool: "hover"}, {Tool: "edit_file", edits: true}, {Tool: "hover"}, {Tool: "edit_file", edits: true}}
+		runBatch(context.Background(), calls, 4, false, track)
+		assert.Equal(t, []int32{1, 2, 1, 4}, edits, "each edit runs alone after the calls before it")
+	})
+}
+
+func TestParseBatchCalls(t *testing.T) {
+	calls, err := parseBatchCalls([]any{
+		map[string]any{"tool": "hover", "arguments": map[string]any{"filePath": "main.go", "line": float64(3)}},
+		map[string]any{"tool": "document_symbols"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []batchCall{
+		{Tool: "hover", Arguments: map[string]any{"filePath": "main.go", "line": float64(3)}},
+		{Tool: "document_symbols", Arguments: map[string]any{}},
+	}, calls)
+
+	for _, value := range []any{
+		"hover",
+		[]any{},
+		[]any{map[string]any{"arguments": map[string]any{}}},
+		[]any{map[string]any{"tool": "batch"}},
+		[]any{map[string]any{"tool": "hover", "arguments": "main.go"}},
+	} {
+		_, err := parseBatchCalls(value)
+		assert.Error(t, err, "%v", value)
+	}
+}
+
+func TestFormatBatchResults(t *testing.T) {
+	calls := []batchCall{{Tool: "hover"}, {Tool: "definition"}, {Tool: "references"}}
+	results := []batchResult{{text: "func Run()\n"}, {text: "symbol not found", failed: true}, {skipped: true}}
+
+	assert.Equal(t, `Batch of 3 requests: 1 succeeded, 1 failed, 1 skipped
+
+[1] hover: ok
+func Run()
+
+[2] definition: error
+symbol not found
+
+[3] references: skipped
+Not run after an earlier request failed
+`, formatBatchResults(calls, results))
+}
+
+func TestToolResultText(t *testing.T) {
+	assert.Equal(t, "done", toolResultText(mcp.NewToolResultText("done")))
+	assert.Equal(t, "", toolResultText(nil))
+}
+
+func TestToolResult(t *testing.T) {
+	batch := newBatchResult([]batchCall{{Tool: "hover"}}, []batchResult{{text: "func Run()"}})
+	result := toolResult(batch)
+	assert.Same(t, batch, result.StructuredContent)
+	assert.Equal(t, batch.Format(), toolResultText(result))
+}
+
+func TestNewBatchResult(t *testing.T) {
+	calls := []batchCall{{Tool: "hover"}, {Tool: "definition"}, {Tool: "references"}}
+	results := []batchResult{{text: "func Run()\n", structured: map[string]any{"path": "main.go"}}, {text: "symbol not found", failed: true}, {skipped: true}}
+
+	batch := newBatchResult(calls, results)
+	assert.Equal(t, 1, batch.Succeeded)
+	assert.Equal(t, 1, batch.Failed)
+	assert.Equal(t, 1, batch.Skipped)
+	assert.Equal(t, []BatchCallResult{
+		{Tool: "hover", Status: "ok", Text: "func Run()\n", Structured: map[string]any{"path": "main.go"}},
+		{Tool: "definition", Status: "error", Text: "symbol not found"},
+		{Tool: "references", Status: "skipped"},
+	}, batch.Results)
+	assert.Equal(t, formatBatchResults(calls, results), batch.Format())
+}
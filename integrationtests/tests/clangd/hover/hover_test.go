@@ -117,7 +117,7 @@ func TestHover(t *testing.T) {
 			filePath := filepath.Join(suite.WorkspaceDir, tt.file)
 
 			// Get hover info
-			result, err := tools.GetHoverInfo(ctx, suite.Client, filePath, tt.line, tt.column)
+			result, err := common.Text(tools.GetHoverInfo(ctx, suite.Client, filePath, tt.line, tt.column))
 			if err != nil {
 				// For the "OutsideFile" test or "NoHoverInfo" we might expect an error or empty result
 				if tt.name == "OutsideFile" || strings.HasPrefix(tt.name, "NoHoverInfo") {
@@ -7,6 +7,8 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/tools"
 )
 
 // Logger is an interface for logging in tests
@@ -14,6 +16,17 @@ type Logger interface {
 	Printf(format string, v ...any)
 }
 
+// Text returns the text rendering of a tool result, so that tests can be
+// written against what language models see:
+//
+//	result, err := common.Text(tools.GetHoverInfo(ctx, client, path, line, column))
+func Text[R tools.Result](result R, err error) (string, error) {
+	if err != nil {
+		return "", err
+	}
+	return result.Format(), nil
+}
+
 // Helper to copy directories recursively
 func CopyDir(src, dst string) error {
 	srcInfo, err := os.Stat(src)
@@ -30,7 +30,7 @@ func TestCodeLens(t *testing.T) {
 
 		// Test GetCodeLens
 		filePath := filepath.Join(suite.WorkspaceDir, "go.mod")
-		result, err := tools.GetCodeLens(ctx, suite.Client, filePath)
+		result, err := common.Text(tools.GetCodeLens(ctx, suite.Client, filePath))
 		if err != nil {
 			t.Fatalf("GetCodeLens failed: %v", err)
 		}
@@ -61,7 +61,7 @@ func TestCodeLens(t *testing.T) {
 
 		// First get the code lenses to find the right index
 		filePath := filepath.Join(suite.WorkspaceDir, "go.mod")
-		result, err := tools.GetCodeLens(ctx, suite.Client, filePath)
+		result, err := common.Text(tools.GetCodeLens(ctx, suite.Client, filePath))
 		if err != nil {
 			t.Fatalf("GetCodeLens failed: %v", err)
 		}
@@ -75,7 +75,7 @@ func TestCodeLens(t *testing.T) {
 		t.Logf("Code lenses: %s", result)
 
 		// Execute the code lens (use index 3 which should be the tidy lens)
-		execResult, err := tools.ExecuteCodeLens(ctx, suite.Client, filePath, 3)
+		execResult, err := common.Text(tools.ExecuteCodeLens(ctx, suite.Client, filePath, 3))
 		if err != nil {
 			t.Fatalf("ExecuteCodeLens failed: %v", err)
 		}
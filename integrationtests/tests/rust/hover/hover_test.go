@@ -132,7 +132,7 @@ func TestHover(t *testing.T) {
 			}
 
 			// Get hover info
-			result, err := tools.GetHoverInfo(ctx, suite.Client, filePath, tt.line, tt.column)
+			result, err := common.Text(tools.GetHoverInfo(ctx, suite.Client, filePath, tt.line, tt.column))
 			if err != nil {
 				// For the "OutsideFile" test, we expect an error
 				if tt.name == "OutsideFile" {
@@ -1,6 +1,10 @@
 package lsp
 
-import "github.com/isaacphi/mcp-language-server/internal/protocol"
+import (
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
 
 // HasDefinitionSupport checks if the server supports textDocument/definition
 // AND workspace/symbol (both required by our definition tool implementation).
@@ -103,6 +107,73 @@ func HasCodeActionSupport(caps *protocol.ServerCapabilities) bool {
 	return caps.CodeActionProvider != nil
 }
 
+// HasCodeActionResolveSupport checks if the server supports codeAction/resolve.
+//
+// CodeActionProvider is interface{} type - can be bool or CodeActionOptions.
+// After JSON decoding the options arrive as map[string]interface{}, so both the
+// typed and the map form are checked for ResolveProvider = true.
+func HasCodeActionResolveSupport(caps *protocol.ServerCapabilities) bool {
+	if caps == nil {
+		return false
+	}
+	switch opts := caps.CodeActionProvider.(type) {
+	case protocol.CodeActionOptions:
+		return opts.ResolveProvider
+	case *protocol.CodeActionOptions:
+		return opts != nil && opts.ResolveProvider
+	case map[string]interface{}:
+		resolve, _ := opts["resolveProvider"].(bool)
+		return resolve
+	}
+	return false
+}
+
+// HasCodeActionKindSupport checks if the server may return code actions of the given kind.
+//
+// CodeActionProvider is interface{} type - can be bool or CodeActionOptions.
+// Servers that answer with a bool, or with options that don't list codeActionKinds,
+// may return any kind. Otherwise the kind, or a parent kind such as "source" for
+// "source.organizeImports", must be listed.
+func HasCodeActionKindSupport(caps *protocol.ServerCapabilities, kind protocol.CodeActionKind) bool {
+	if !HasCodeActionSupport(caps) {
+		return false
+	}
+
+	var kinds []string
+	switch opts := caps.CodeActionProvider.(type) {
+	case bool:
+		return opts
+	case protocol.CodeActionOptions:
+		for _, k := range opts.CodeActionKinds {
+			kinds = append(kinds, string(k))
+		}
+	case *protocol.CodeActionOptions:
+		if opts == nil {
+			return false
+		}
+		for _, k := range opts.CodeActionKinds {
+			kinds = append(kinds, string(k))
+		}
+	case map[string]interface{}:
+		list, _ := opts["codeActionKinds"].([]interface{})
+		for _, k := range list {
+			if str, ok := k.(string); ok {
+				kinds = append(kinds, str)
+			}
+		}
+	}
+
+	if len(kinds) == 0 {
+		return true
+	}
+	for _, k := range kinds {
+		if k == string(kind) || strings.HasPrefix(string(kind), k+".") {
+			return true
+		}
+	}
+	return false
+}
+
 // HasSignatureHelpSupport checks if the server supports textDocument/signatureHelp.
 //
 // SignatureHelpProvider is *SignatureHelpOptions type.
@@ -114,6 +185,22 @@ func HasSignatureHelpSupport(caps *protocol.ServerCapabilities) bool {
 	return caps.SignatureHelpProvider != nil
 }
 
+// HasExecuteCommandSupport checks if the server executes the given command via workspace/executeCommand.
+//
+// ExecuteCommandProvider is *ExecuteCommandOptions type. Commands not listed
+// there, such as rename prompts attached to code actions, are meant for the client.
+func HasExecuteCommandSupport(caps *protocol.ServerCapabilities, command string) bool {
+	if caps == nil || caps.ExecuteCommandProvider == nil {
+		return false
+	}
+	for _, c := range caps.ExecuteCommandProvider.Commands {
+		if c == command {
+			return true
+		}
+	}
+	return false
+}
+
 // HasCodeLensSupport checks if the server supports textDocument/codeLens.
 //
 // CodeLensProvider is *CodeLensOptions type.
@@ -172,6 +259,17 @@ func HasTypeHierarchySupport(caps *protocol.ServerCapabilities) bool {
 		caps.TypeHierarchyProvider.Value != nil
 }
 
+// HasImplementationSupport checks if the server supports textDocument/implementation.
+//
+// CRITICAL: Uses two-part check for Or_* type (pointer != nil && .Value != nil).
+func HasImplementationSupport(caps *protocol.ServerCapabilities) bool {
+	if caps == nil {
+		return false
+	}
+	return caps.ImplementationProvider != nil &&
+		caps.ImplementationProvider.Value != nil
+}
+
 // HasInlayHintSupport checks if the server supports textDocument/inlayHint.
 //
 // InlayHintProvider is interface{} type - can be InlayHintOptions or InlayHintRegistrationOptions.
@@ -187,6 +285,10 @@ func HasInlayHintSupport(caps *protocol.ServerCapabilities) bool {
 //
 // Core tools:
 // - edit_file: Requires TextDocumentSync, which every LSP server must provide
+// - apply_patch: Same as edit_file; file operation notifications are only sent when registered
+// - rename_file: Uses workspace/willRenameFiles when registered, otherwise falls back to a plain move
+// - create_file, delete_file: Use workspace/willCreateFiles and willDeleteFiles only when registered
+// - undo_edit, redo_edit: Replay the edit journal, no LSP requests involved
 // - diagnostics: Uses push notifications (textDocument/publishDiagnostics), not capability-based
 //
 // This function exists for documentation and consistency with the capability check pattern.
@@ -276,3 +378,65 @@ func HasOnTypeFormattingSupport(caps *protocol.ServerCapabilities) bool {
 	}
 	return caps.DocumentOnTypeFormattingProvider != nil
 }
+
+// fileOperations returns the server's workspace file operation registrations, or nil.
+//
+// FileOperations is only advertised by LSP 3.16+ servers that want to be told
+// about file creations, renames and deletions performed by the client.
+func fileOperations(caps *protocol.ServerCapabilities) *protocol.FileOperationOptions {
+	if caps == nil || caps.Workspace == nil {
+		return nil
+	}
+	return caps.Workspace.FileOperations
+}
+
+// HasDidCreateFilesSupport checks if the server wants workspace/didCreateFiles notifications.
+//
+// DidCreate is a *FileOperationRegistrationOptions pointer, not an Or_* type.
+func HasDidCreateFilesSupport(caps *protocol.ServerCapabilities) bool {
+	ops := fileOperations(caps)
+	return ops != nil && ops.DidCreate != nil
+}
+
+// HasDidRenameFilesSupport checks if the server wants workspace/didRenameFiles notifications.
+//
+// DidRename is a *FileOperationRegistrationOptions pointer, not an Or_* type.
+func HasDidRenameFilesSupport(caps *protocol.ServerCapabilities) bool {
+	ops := fileOperations(caps)
+	return ops != nil && ops.DidRename != nil
+}
+
+// HasWillCreateFilesSupport checks if the server can contribute edits via workspace/willCreateFiles
+// before files are created.
+//
+// WillCreate is a *FileOperationRegistrationOptions pointer, not an Or_* type.
+func HasWillCreateFilesSupport(caps *protocol.ServerCapabilities) bool {
+	ops := fileOperations(caps)
+	return ops != nil && ops.WillCreate != nil
+}
+
+// HasWillRenameFilesSupport checks if the server can update references via workspace/willRenameFiles
+// before files are renamed.
+//
+// WillRename is a *FileOperationRegistrationOptions pointer, not an Or_* type.
+func HasWillRenameFilesSupport(caps *protocol.ServerCapabilities) bool {
+	ops := fileOperations(caps)
+	return ops != nil && ops.WillRename != nil
+}
+
+// HasWillDeleteFilesSupport checks if the server can contribute edits via workspace/willDeleteFiles
+// before files are deleted.
+//
+// WillDelete is a *FileOperationRegistrationOptions pointer, not an Or_* type.
+func HasWillDeleteFilesSupport(caps *protocol.ServerCapabilities) bool {
+	ops := fileOperations(caps)
+	return ops != nil && ops.WillDelete != nil
+}
+
+// HasDidDeleteFilesSupport checks if the server wants workspace/didDeleteFiles notifications.
+//
+// DidDelete is a *FileOperationRegistrationOptions pointer, not an Or_* type.
+func HasDidDeleteFilesSupport(caps *protocol.ServerCapabilities) bool {
+	ops := fileOperations(caps)
+	return ops != nil && ops.DidDelete != nil
+}
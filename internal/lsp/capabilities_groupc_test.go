@@ -101,3 +101,145 @@ func TestHasSelectionRangeSupport(t *testing.T) {
 		})
 	}
 }
+
+func TestHasFileOperationNotificationSupport(t *testing.T) {
+	registration := &protocol.FileOperationRegistrationOptions{
+		Filters: []protocol.FileOperationFilter{{Pattern: protocol.FileOperationPattern{Glob: "**/*.go"}}},
+	}
+
+	tests := []struct {
+		name           string
+		caps           *protocol.ServerCapabilities
+		expectedCreate bool
+		expectedRename bool
+		expectedDelete bool
+	}{
+		{
+			name: "all file operations registered",
+			caps: &protocol.ServerCapabilities{
+				Workspace: &protocol.WorkspaceOptions{
+					FileOperations: &protocol.FileOperationOptions{
+						DidCreate: registration,
+						DidRename: registration,
+						DidDelete: registration,
+					},
+				},
+			},
+			expectedCreate: true,
+			expectedRename: true,
+			expectedDelete: true,
+		},
+		{
+			name: "only didDelete registered",
+			caps: &protocol.ServerCapabilities{
+				Workspace: &protocol.WorkspaceOptions{
+					FileOperations: &protocol.FileOperationOptions{
+						DidDelete: registration,
+					},
+				},
+			},
+			expectedDelete: true,
+		},
+		{
+			name: "file operations nil",
+			caps: &protocol.ServerCapabilities{
+				Workspace: &protocol.WorkspaceOptions{},
+			},
+		},
+		{
+			name: "workspace nil",
+			caps: &protocol.ServerCapabilities{},
+		},
+		{
+			name: "nil capabilities",
+			caps: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := HasDidCreateFilesSupport(tt.caps); result != tt.expectedCreate {
+				t.Errorf("HasDidCreateFilesSupport() = %v, expected %v", result, tt.expectedCreate)
+			}
+			if result := HasDidRenameFilesSupport(tt.caps); result != tt.expectedRename {
+				t.Errorf("HasDidRenameFilesSupport() = %v, expected %v", result, tt.expectedRename)
+			}
+			if result := HasDidDeleteFilesSupport(tt.caps); result != tt.expectedDelete {
+				t.Errorf("HasDidDeleteFilesSupport() = %v, expected %v", result, tt.expectedDelete)
+			}
+		})
+	}
+}
+
+func TestHasWillFileOperationSupport(t *testing.T) {
+	registration := &protocol.FileOperationRegistrationOptions{
+		Filters: []protocol.FileOperationFilter{{Pattern: protocol.FileOperationPattern{Glob: "**/*.ts"}}},
+	}
+
+	tests := []struct {
+		name           string
+		caps           *protocol.ServerCapabilities
+		expectedCreate bool
+		expectedRename bool
+		expectedDelete bool
+	}{
+		{
+			name: "all will operations registered",
+			caps: &protocol.ServerCapabilities{
+				Workspace: &protocol.WorkspaceOptions{
+					FileOperations: &protocol.FileOperationOptions{
+						WillCreate: registration,
+						WillRename: registration,
+						WillDelete: registration,
+					},
+				},
+			},
+			expectedCreate: true,
+			expectedRename: true,
+			expectedDelete: true,
+		},
+		{
+			name: "only willRename registered",
+			caps: &protocol.ServerCapabilities{
+				Workspace: &protocol.WorkspaceOptions{
+					FileOperations: &protocol.FileOperationOptions{WillRename: registration},
+				},
+			},
+			expectedRename: true,
+		},
+		{
+			name: "only did operations registered",
+			caps: &protocol.ServerCapabilities{
+				Workspace: &protocol.WorkspaceOptions{
+					FileOperations: &protocol.FileOperationOptions{
+						DidCreate: registration,
+						DidRename: registration,
+						DidDelete: registration,
+					},
+				},
+			},
+		},
+		{
+			name: "workspace nil",
+			caps: &protocol.ServerCapabilities{},
+		},
+		{
+			name: "nil capabilities",
+			caps: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := HasWillCreateFilesSupport(tt.caps); result != tt.expectedCreate {
+				t.Errorf("HasWillCreateFilesSupport() = %v, expected %v", result, tt.expectedCreate)
+			}
+			if result := HasWillRenameFilesSupport(tt.caps); result != tt.expectedRename {
+				t.Errorf("HasWillRenameFilesSupport() = %v, expected %v", result, tt.expectedRename)
+			}
+			if result := HasWillDeleteFilesSupport(tt.caps); result != tt.expectedDelete {
+				t.Errorf("HasWillDeleteFilesSupport() = %v, expected %v", result, tt.expectedDelete)
+			}
+		})
+	}
+}
@@ -333,6 +333,57 @@ func TestHasCodeActionSupport(t *testing.T) {
 	}
 }
 
+func TestHasCodeActionResolveSupport(t *testing.T) {
+	tests := []struct {
+		name     string
+		caps     *protocol.ServerCapabilities
+		expected bool
+	}{
+		{
+			name: "resolve provider in decoded options",
+			caps: &protocol.ServerCapabilities{
+				CodeActionProvider: map[string]interface{}{"resolveProvider": true},
+			},
+			expected: true,
+		},
+		{
+			name: "resolve provider in typed options",
+			caps: &protocol.ServerCapabilities{
+				CodeActionProvider: protocol.CodeActionOptions{ResolveProvider: true},
+			},
+			expected: true,
+		},
+		{
+			name: "options without resolve provider",
+			caps: &protocol.ServerCapabilities{
+				CodeActionProvider: map[string]interface{}{"codeActionKinds": []string{"quickfix"}},
+			},
+			expected: false,
+		},
+		{
+			name: "code action supported as bool",
+			caps: &protocol.ServerCapabilities{
+				CodeActionProvider: true,
+			},
+			expected: false,
+		},
+		{
+			name:     "nil capabilities",
+			caps:     nil,
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := HasCodeActionResolveSupport(tt.caps)
+			if result != tt.expected {
+				t.Errorf("HasCodeActionResolveSupport() = %v, expected %v", result, tt.expected)
+			}
+		})
+	}
+}
+
 func TestHasSignatureHelpSupport(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -488,6 +539,54 @@ func TestHasTypeHierarchySupport(t *testing.T) {
 	}
 }
 
+func TestHasImplementationSupport(t *testing.T) {
+	tests := []struct {
+		name     string
+		caps     *protocol.ServerCapabilities
+		expected bool
+	}{
+		{
+			name: "implementation supported",
+			caps: &protocol.ServerCapabilities{
+				ImplementationProvider: &protocol.Or_ServerCapabilities_implementationProvider{
+					Value: true,
+				},
+			},
+			expected: true,
+		},
+		{
+			name: "implementation Value nil",
+			caps: &protocol.ServerCapabilities{
+				ImplementationProvider: &protocol.Or_ServerCapabilities_implementationProvider{
+					Value: nil,
+				},
+			},
+			expected: false,
+		},
+		{
+			name: "implementation provider nil",
+			caps: &protocol.ServerCapabilities{
+				ImplementationProvider: nil,
+			},
+			expected: false,
+		},
+		{
+			name:     "nil capabilities",
+			caps:     nil,
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := HasImplementationSupport(tt.caps)
+			if result != tt.expected {
+				t.Errorf("HasImplementationSupport() = %v, expected %v", result, tt.expected)
+			}
+		})
+	}
+}
+
 func TestHasInlayHintSupport(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -526,3 +625,99 @@ func TestHasInlayHintSupport(t *testing.T) {
 		})
 	}
 }
+
+func TestHasCodeActionKindSupport(t *testing.T) {
+	tests := []struct {
+		name     string
+		caps     *protocol.ServerCapabilities
+		kind     protocol.CodeActionKind
+		expected bool
+	}{
+		{
+			name:     "code action supported as bool",
+			caps:     &protocol.ServerCapabilities{CodeActionProvider: true},
+			kind:     protocol.SourceOrganizeImports,
+			expected: true,
+		},
+		{
+			name:     "code action disabled as bool",
+			caps:     &protocol.ServerCapabilities{CodeActionProvider: false},
+			kind:     protocol.SourceOrganizeImports,
+			expected: false,
+		},
+		{
+			name: "kind listed in decoded options",
+			caps: &protocol.ServerCapabilities{
+				CodeActionProvider: map[string]interface{}{
+					"codeActionKinds": []interface{}{"quickfix", "source.organizeImports"},
+				},
+			},
+			kind:     protocol.SourceOrganizeImports,
+			expected: true,
+		},
+		{
+			name: "parent kind listed in typed options",
+			caps: &protocol.ServerCapabilities{
+				CodeActionProvider: protocol.CodeActionOptions{
+					CodeActionKinds: []protocol.CodeActionKind{protocol.Source},
+				},
+			},
+			kind:     protocol.SourceFixAll,
+			expected: true,
+		},
+		{
+			name: "kind not listed",
+			caps: &protocol.ServerCapabilities{
+				CodeActionProvider: map[string]interface{}{
+					"codeActionKinds": []interface{}{"quickfix", "refactor"},
+				},
+			},
+			kind:     protocol.SourceFixAll,
+			expected: false,
+		},
+		{
+			name: "options without kinds",
+			caps: &protocol.ServerCapabilities{
+				CodeActionProvider: map[string]interface{}{"resolveProvider": true},
+			},
+			kind:     protocol.SourceFixAll,
+			expected: true,
+		},
+		{
+			name:     "nil capabilities",
+			caps:     nil,
+			kind:     protocol.SourceFixAll,
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := HasCodeActionKindSupport(tt.caps, tt.kind)
+			if result != tt.expected {
+				t.Errorf("HasCodeActionKindSupport() = %v, expected %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestHasExecuteCommandSupport(t *testing.T) {
+	caps := &protocol.ServerCapabilities{
+		ExecuteCommandProvider: &protocol.ExecuteCommandOptions{
+			Commands: []string{"gopls.tidy", "gopls.apply_fix"},
+		},
+	}
+
+	if !HasExecuteCommandSupport(caps, "gopls.apply_fix") {
+		t.Error("HasExecuteCommandSupport() = false for a listed command")
+	}
+	if HasExecuteCommandSupport(caps, "editor.action.rename") {
+		t.Error("HasExecuteCommandSupport() = true for a client-side command")
+	}
+	if HasExecuteCommandSupport(&protocol.ServerCapabilities{}, "gopls.tidy") {
+		t.Error("HasExecuteCommandSupport() = true without an executeCommandProvider")
+	}
+	if HasExecuteCommandSupport(nil, "gopls.tidy") {
+		t.Error("HasExecuteCommandSupport() = true for nil capabilities")
+	}
+}
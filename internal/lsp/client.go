@@ -51,9 +51,15 @@ type Client struct {
 	// Server capabilities
 	capabilities *protocol.ServerCapabilities
 
+	// Workspace root the server was initialized with
+	workspaceDir string
+
 	// File operations handler
 	fileOpsHandler FileOperationsHandler
 
+	// readOnly refuses workspace/applyEdit requests from the server
+	readOnly bool
+
 	// Close synchronization
 	closeOnce sync.Once
 	closeErr  error
@@ -127,6 +133,30 @@ func (c *Client) RegisterServerRequestHandler(method string, handler ServerReque
 	c.serverRequestHandlers[method] = handler
 }
 
+// SetReadOnly makes the client refuse workspace/applyEdit requests from the
+// server. It must be called before InitializeLSPClient.
+func (c *Client) SetReadOnly(readOnly bool) {
+	c.readOnly = readOnly
+}
+
+// transactionalFailureHandling is advertised so servers know a failed workspace edit changes nothing
+var transactionalFailureHandling = protocol.Transactional
+
+// supportedCodeActionKinds are advertised so servers return code action
+// literals, including the source actions behind organize_imports and fix_all
+var supportedCodeActionKinds = []protocol.CodeActionKind{
+	protocol.Empty,
+	protocol.QuickFix,
+	protocol.Refactor,
+	protocol.RefactorExtract,
+	protocol.RefactorInline,
+	protocol.RefactorMove,
+	protocol.RefactorRewrite,
+	protocol.Source,
+	protocol.SourceOrganizeImports,
+	protocol.SourceFixAll,
+}
+
 func (c *Client) InitializeLSPClient(ctx context.Context, workspaceDir string) (*protocol.InitializeResult, error) {
 	initParams := &protocol.InitializeParams{
 		WorkspaceFoldersInitializeParams: protocol.WorkspaceFoldersInitializeParams{
@@ -156,6 +186,18 @@ func (c *Client) InitializeLSPClient(ctx context.Context, workspaceDir string) (
 						DynamicRegistration:    true,
 						RelativePatternSupport: true,
 					},
+					WorkspaceEdit: &protocol.WorkspaceEditClientCapabilities{
+						// Workspace edits are applied all-or-nothing with rollback
+						FailureHandling: &transactionalFailureHandling,
+					},
+					FileOperations: &protocol.FileOperationClientCapabilities{
+						DidCreate:  true,
+						DidRename:  true,
+						DidDelete:  true,
+						WillCreate: true,
+						WillRename: true,
+						WillDelete: true,
+					},
 				},
 				TextDocument: protocol.TextDocumentClientCapabilities{
 					Synchronization: &protocol.TextDocumentSyncClientCapabilities{
@@ -172,9 +214,15 @@ func (c *Client) InitializeLSPClient(ctx context.Context, workspaceDir string) (
 					CodeAction: protocol.CodeActionClientCapabilities{
 						CodeActionLiteralSupport: protocol.ClientCodeActionLiteralOptions{
 							CodeActionKind: protocol.ClientCodeActionKindOptions{
-								ValueSet: []protocol.CodeActionKind{},
+								ValueSet: supportedCodeActionKinds,
 							},
 						},
+						IsPreferredSupport: true,
+						DisabledSupport:    true,
+						DataSupport:        true,
+						ResolveSupport: &protocol.ClientCodeActionResolveOptions{
+							Properties: []string{"edit"},
+						},
 					},
 					PublishDiagnostics: protocol.PublishDiagnosticsClientCapabilities{
 						VersionSupport: true,
@@ -212,13 +260,18 @@ func (c *Client) InitializeLSPClient(ctx context.Context, workspaceDir string) (
 
 	// Store server capabilities
 	c.capabilities = &result.Capabilities
+	c.workspaceDir = workspaceDir
 
 	if err := c.Notify(ctx, "initialized", struct{}{}); err != nil {
 		return nil, fmt.Errorf("initialized notification failed: %w", err)
 	}
 
 	// Register handlers
-	c.RegisterServerRequestHandler("workspace/applyEdit", HandleApplyEdit)
+	if c.readOnly {
+		c.RegisterServerRequestHandler("workspace/applyEdit", HandleReadOnlyApplyEdit)
+	} else {
+		c.RegisterServerRequestHandler("workspace/applyEdit", HandleApplyEdit)
+	}
 	c.RegisterServerRequestHandler("workspace/configuration", HandleWorkspaceConfiguration)
 	c.RegisterServerRequestHandler("client/registerCapability", HandleRegisterCapability)
 	c.RegisterNotificationHandler("window/showMessage", HandleServerMessage)
@@ -494,3 +547,9 @@ func (c *Client) WaitForDiagnostics(ctx context.Context, uri string, timeout tim
 func (c *Client) GetCapabilities() *protocol.ServerCapabilities {
 	return c.capabilities
 }
+
+// WorkspaceDir returns the workspace root the server was initialized with,
+// or an empty string before initialization
+func (c *Client) WorkspaceDir() string {
+	return c.workspaceDir
+}
@@ -18,6 +18,11 @@ func (c *Client) SetFileOperationsHandler(handler *fileops.FileOperationsHandler
 	c.fileOpsHandler = handler
 }
 
+// GetFileOperationsHandler returns the file operations handler, or nil if none is set
+func (c *Client) GetFileOperationsHandler() FileOperationsHandler {
+	return c.fileOpsHandler
+}
+
 // LSP file operation parameter types
 type createFilesParams struct {
 	Files []fileops.FileCreate `json:"files"`
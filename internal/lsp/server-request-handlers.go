@@ -66,21 +66,36 @@ func HandleApplyEdit(params json.RawMessage) (any, error) {
 		return protocol.ApplyWorkspaceEditResult{Applied: false}, err
 	}
 
-	// Apply the edits
-	err := utilities.ApplyWorkspaceEdit(workspaceEdit.Edit)
+	// Apply the edits as a transaction, nothing is left changed on failure
+	result, err := utilities.ApplyJournaledWorkspaceEdit(utilities.ServerEditSource, workspaceEdit.Label, workspaceEdit.Edit)
 	if err != nil {
 		lspLogger.Error("Error applying workspace edit: %v", err)
-		return protocol.ApplyWorkspaceEditResult{
+		applyResult := protocol.ApplyWorkspaceEditResult{
 			Applied:       false,
 			FailureReason: workspaceEditFailure(err),
-		}, nil
+		}
+		if result.FailedChange >= 0 {
+			applyResult.FailedChange = uint32(result.FailedChange)
+		}
+		return applyResult, nil
 	}
+	lspLogger.Debug("Applied workspace edit to %d files: %v", len(result.Committed), result.Committed)
 
 	return protocol.ApplyWorkspaceEditResult{
 		Applied: true,
 	}, nil
 }
 
+// HandleReadOnlyApplyEdit refuses workspace/applyEdit, for clients that must
+// not change the workspace
+func HandleReadOnlyApplyEdit(params json.RawMessage) (any, error) {
+	lspLogger.Warn("Refused workspace/applyEdit: the client is read-only")
+	return protocol.ApplyWorkspaceEditResult{
+		Applied:       false,
+		FailureReason: "the client is read-only",
+	}, nil
+}
+
 func workspaceEditFailure(err error) string {
 	if err == nil {
 		return ""
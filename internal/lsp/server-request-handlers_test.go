@@ -0,0 +1,28 @@
+package lsp
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleReadOnlyApplyEdit(t *testing.T) {
+	params, err := json.Marshal(protocol.ApplyWorkspaceEditParams{
+		Edit: protocol.WorkspaceEdit{
+			Changes: map[protocol.DocumentUri][]protocol.TextEdit{
+				"file:///ws/main.go": {{NewText: "package main\n"}},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	result, err := HandleReadOnlyApplyEdit(params)
+	require.NoError(t, err)
+	applyResult, ok := result.(protocol.ApplyWorkspaceEditResult)
+	require.True(t, ok)
+	assert.False(t, applyResult.Applied)
+	assert.NotEmpty(t, applyResult.FailureReason)
+}
@@ -0,0 +1,267 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/fileops"
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/isaacphi/mcp-language-server/internal/utilities"
+)
+
+// patchedFile tracks what happened to one file of a patch so the LSP can be notified
+type patchedFile struct {
+	path    string
+	oldPath string
+	created bool
+	deleted bool
+	renamed bool
+	changed bool
+}
+
+// ApplyPatch applies a unified diff that may touch several files, create new
+// files and delete existing ones.
+//
+// Hunks are located by their context, tolerating line offsets and up to fuzz
+// context lines that no longer match at either end. Hunks that cannot be
+// placed are reported as rejected while the rest of the patch is still applied.
+// A file is only deleted when all of its hunks match. Afterwards the language
+// server is told about changed, created, renamed and deleted files.
+func ApplyPatch(ctx context.Context, client *lsp.Client, patch string, fuzz int) (*EditResult, error) {
+	filePatches, err := utilities.ParsePatch(patch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse patch: %v", err)
+	}
+	if len(filePatches) == 0 {
+		return nil, fmt.Errorf("no file changes found in patch")
+	}
+
+	var workspaceEdit protocol.WorkspaceEdit
+	var report strings.Builder
+	var files []patchedFile
+	rejectedCount := 0
+
+	for _, fp := range filePatches {
+		path, err := resolveWorkspacePath(fp.Path())
+		if err != nil {
+			return nil, fmt.Errorf("invalid patch: %v", err)
+		}
+		uri := protocol.DocumentUri("file://" + path)
+		file := patchedFile{path: path, oldPath: path}
+
+		var content []byte
+		switch {
+		case fp.IsNew():
+			if _, err := os.Stat(path); err == nil {
+				rejectedCount += len(fp.Hunks)
+				report.WriteString(fmt.Sprintf("A %s: REJECTED, file already exists\n", fp.NewPath))
+				continue
+			}
+		default:
+			oldPath, err := resolveWorkspacePath(fp.OldPath)
+			if err != nil {
+				return nil, fmt.Errorf("invalid patch: %v", err)
+			}
+			file.oldPath = oldPath
+			content, err = os.ReadFile(oldPath)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read %s: %v", fp.OldPath, err)
+			}
+		}
+
+		edits, results := utilities.PlanPatchEdits(string(content), fp.Hunks, fuzz)
+		applied := 0
+		for _, r := range results {
+			if r.Applied {
+				applied++
+			}
+		}
+		rejectedCount += len(results) - applied
+
+		switch {
+		case fp.IsNew():
+			report.WriteString(fmt.Sprintf("A %s: created\n", fp.NewPath))
+			workspaceEdit.DocumentChanges = append(workspaceEdit.DocumentChanges,
+				protocol.DocumentChange{CreateFile: &protocol.CreateFile{Kind: "create", URI: uri}})
+			file.created = true
+
+		case fp.IsDelete():
+			if applied < len(results) {
+				report.WriteString(fmt.Sprintf("D %s: REJECTED, file content does not match the patch\n", fp.OldPath))
+				writeHunkResults(&report, fp, results)
+				continue
+			}
+			report.WriteString(fmt.Sprintf("D %s: deleted\n", fp.OldPath))
+			workspaceEdit.DocumentChanges = append(workspaceEdit.DocumentChanges,
+				protocol.DocumentChange{DeleteFile: &protocol.DeleteFile{Kind: "delete", URI: uri}})
+			file.deleted = true
+			files = append(files, file)
+			continue
+
+		case fp.IsRename():
+			report.WriteString(fmt.Sprintf("R %s -> %s: %d/%d hunks applied\n", fp.OldPath, fp.NewPath, applied, len(results)))
+			file.renamed = true
+
+		default:
+			report.WriteString(fmt.Sprintf("M %s: %d/%d hunks applied\n", fp.NewPath, applied, len(results)))
+		}
+		writeHunkResults(&report, fp, results)
+
+		// Text edits are made before a rename, so they target the old path
+		if len(edits) > 0 {
+			textEdits := make([]protocol.Or_TextDocumentEdit_edits_Elem, len(edits))
+			for i, edit := range edits {
+				textEdits[i] = protocol.Or_TextDocumentEdit_edits_Elem{Value: edit}
+			}
+			workspaceEdit.DocumentChanges = append(workspaceEdit.DocumentChanges, protocol.DocumentChange{
+				TextDocumentEdit: &protocol.TextDocumentEdit{
+					TextDocument: protocol.OptionalVersionedTextDocumentIdentifier{
+						TextDocumentIdentifier: protocol.TextDocumentIdentifier{
+							URI: protocol.DocumentUri("file://" + file.oldPath),
+						},
+					},
+					Edits: textEdits,
+				},
+			})
+			file.changed = true
+		}
+
+		if file.renamed {
+			workspaceEdit.DocumentChanges = append(workspaceEdit.DocumentChanges, protocol.DocumentChange{
+				RenameFile: &protocol.RenameFile{
+					Kind:    "rename",
+					OldURI:  protocol.DocumentUri("file://" + file.oldPath),
+					NewURI:  uri,
+					Options: &protocol.RenameFileOptions{},
+				},
+			})
+		}
+
+		if file.created || file.renamed || file.changed {
+			files = append(files, file)
+		}
+	}
+
+	// New and moved files may live in directories that don't exist yet
+	for _, file := range files {
+		if file.created || file.renamed {
+			if err := os.MkdirAll(filepath.Dir(file.path), 0755); err != nil {
+				return nil, fmt.Errorf("failed to create directory for %s: %v", file.path, err)
+			}
+		}
+	}
+
+	var entry *utilities.JournalEntry
+	if len(workspaceEdit.DocumentChanges) > 0 {
+		result, err := utilities.ApplyJournaledWorkspaceEdit("apply_patch", "", workspaceEdit)
+		if err != nil {
+			return nil, fmt.Errorf("failed to apply patch: %v", err)
+		}
+		entry = result.Entry
+	}
+
+	notifyPatchedFiles(ctx, client, files)
+
+	summary := fmt.Sprintf("Applied patch to %d of %d files.", len(files), len(filePatches))
+	if rejectedCount > 0 {
+		summary += fmt.Sprintf(" %d hunks rejected.", rejectedCount)
+	}
+	return journaledEdits(client, summary+"\n"+report.String(), entry), nil
+}
+
+// writeHunkResults reports hunks that were rejected or needed an offset or fuzz to apply.
+// Rejected hunks are echoed back so they can be corrected and retried.
+func writeHunkResults(report *strings.Builder, fp utilities.FilePatch, results []utilities.HunkResult) {
+	for i, r := range results {
+		switch {
+		case !r.Applied:
+			report.WriteString(fmt.Sprintf("  hunk #%d REJECTED: %s\n", r.Index, r.Reason))
+			report.WriteString("    " + r.Header + "\n")
+			for _, line := range fp.Hunks[i].Lines {
+				report.WriteString("    " + line + "\n")
+			}
+		case r.Offset != 0 || r.Fuzz != 0:
+			report.WriteString(fmt.Sprintf("  hunk #%d applied at line %d (offset %d lines, fuzz %d)\n",
+				r.Index, r.Line, r.Offset, r.Fuzz))
+		}
+	}
+}
+
+// notifyPatchedFiles keeps the language server in sync with the patched files
+// and emits fileops events for the files created, renamed and deleted. It is
+// the one place tools report file operations, so every tool that creates,
+// renames or deletes files reports them the same way. Notification failures
+// are logged rather than returned since the files have already been written.
+func notifyPatchedFiles(ctx context.Context, client *lsp.Client, files []patchedFile) {
+	caps := client.GetCapabilities()
+
+	var created []protocol.FileCreate
+	var renamed []protocol.FileRename
+	var deleted []protocol.FileDelete
+
+	for _, file := range files {
+		switch {
+		case file.deleted:
+			if err := client.CloseFile(ctx, file.path); err != nil {
+				toolsLogger.Warn("Failed to close deleted file %s: %v", file.path, err)
+			}
+			deleted = append(deleted, protocol.FileDelete{URI: "file://" + file.path})
+			continue
+		case file.created:
+			created = append(created, protocol.FileCreate{URI: "file://" + file.path})
+		case file.renamed:
+			if err := client.CloseFile(ctx, file.oldPath); err != nil {
+				toolsLogger.Warn("Failed to close renamed file %s: %v", file.oldPath, err)
+			}
+			renamed = append(renamed, protocol.FileRename{OldURI: "file://" + file.oldPath, NewURI: "file://" + file.path})
+		}
+
+		var err error
+		if client.IsFileOpen(file.path) {
+			err = client.NotifyChange(ctx, file.path)
+		} else {
+			err = client.OpenFile(ctx, file.path)
+		}
+		if err != nil {
+			toolsLogger.Warn("Failed to sync patched file %s: %v", file.path, err)
+		}
+	}
+
+	if len(created) > 0 && lsp.HasDidCreateFilesSupport(caps) {
+		if err := client.DidCreateFiles(ctx, protocol.CreateFilesParams{Files: created}); err != nil {
+			toolsLogger.Warn("Failed to send didCreateFiles: %v", err)
+		}
+	}
+	if len(renamed) > 0 && lsp.HasDidRenameFilesSupport(caps) {
+		if err := client.DidRenameFiles(ctx, protocol.RenameFilesParams{Files: renamed}); err != nil {
+			toolsLogger.Warn("Failed to send didRenameFiles: %v", err)
+		}
+	}
+	if len(deleted) > 0 && lsp.HasDidDeleteFilesSupport(caps) {
+		if err := client.DidDeleteFiles(ctx, protocol.DeleteFilesParams{Files: deleted}); err != nil {
+			toolsLogger.Warn("Failed to send didDeleteFiles: %v", err)
+		}
+	}
+
+	if handler := client.GetFileOperationsHandler(); handler != nil {
+		var fileCreates []fileops.FileCreate
+		for _, file := range created {
+			fileCreates = append(fileCreates, fileops.FileCreate{URI: file.URI})
+		}
+		var fileRenames []fileops.FileRename
+		for _, file := range renamed {
+			fileRenames = append(fileRenames, fileops.FileRename{OldURI: file.OldURI, NewURI: file.NewURI})
+		}
+		var fileDeletes []fileops.FileDelete
+		for _, file := range deleted {
+			fileDeletes = append(fileDeletes, fileops.FileDelete{URI: file.URI})
+		}
+		handler.OnCreate(fileCreates)
+		handler.OnRename(fileRenames)
+		handler.OnDelete(fileDeletes)
+	}
+}
@@ -0,0 +1,437 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/isaacphi/mcp-language-server/internal/utilities"
+)
+
+const (
+	// DefaultCallGraphDepth is how many levels of calls are followed by default
+	DefaultCallGraphDepth = 3
+	// DefaultCallGraphNodes is the default node budget of a call graph
+	DefaultCallGraphNodes = 100
+)
+
+// CallGraphOptions controls how far a call graph is walked and how it is rendered
+type CallGraphOptions struct {
+	// Direction is "incoming", "outgoing" or "both"
+	Direction string
+	// MaxDepth is the number of call levels to follow from the root
+	MaxDepth int
+	// MaxNodes stops the walk once the graph has this many nodes
+	MaxNodes int
+	// Exclude lists globs, relative to the workspace root, of files whose
+	// functions are left out of the graph, e.g. "**/*_test.go" or "vendor/**"
+	Exclude []string
+	// Format is "json", "dot" or "mermaid"
+	Format string
+}
+
+// CallGraphNode is a function or method in a call graph
+type CallGraphNode struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Kind   string `json:"kind"`
+	Detail string `json:"detail,omitempty"`
+	Path   string `json:"path"`
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+	// Depth is the number of calls between the node and the root
+	Depth int `json:"depth"`
+	// Unexpanded is set if the node's calls were not followed because the
+	// depth limit or node budget was reached
+	Unexpanded bool `json:"unexpanded,omitempty"`
+}
+
+// CallGraphEdge is a call from one node to another
+type CallGraphEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	// CallSites are the positions of the calls in the caller's file
+	CallSites []string `json:"callSites,omitempty"`
+}
+
+// CallGraph is the result of walking the call hierarchy from a root symbol
+type CallGraph struct {
+	Root      string          `json:"root"`
+	Direction string          `json:"direction"`
+	Nodes     []CallGraphNode `json:"nodes"`
+	Edges     []CallGraphEdge `json:"edges"`
+	// Truncated is set if the node budget stopped the walk early
+	Truncated bool `json:"truncated"`
+
+	// format is how Format renders the graph
+	format string
+	// notFound describes the position if no symbol was found there
+	notFound string
+}
+
+// GetCallGraph walks the call hierarchy breadth-first from the symbol at the
+// given position and renders the resulting graph.
+//
+// Calls are followed through callHierarchy/incomingCalls, outgoingCalls or
+// both, up to opts.MaxDepth levels and opts.MaxNodes nodes. Each function is
+// visited once, so recursion and call cycles end the walk along that path.
+func GetCallGraph(ctx context.Context, client *lsp.Client, filePath string, line, column int, opts CallGraphOptions) (*CallGraph, error) {
+	opts, err := normalizeCallGraphOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	err = client.OpenFile(ctx, filePath)
+	if err != nil {
+		return nil, fmt.Errorf("could not open file: %v", err)
+	}
+
+	uri := protocol.DocumentUri("file://" + filePath)
+	items, err := client.PrepareCallHierarchy(ctx, protocol.CallHierarchyPrepareParams{
+		TextDocumentPositionParams: protocol.TextDocumentPositionParams{
+			TextDocument: protocol.TextDocumentIdentifier{URI: uri},
+			Position: protocol.Position{
+				Line:      uint32(line - 1),
+				Character: uint32(column - 1),
+			},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare call hierarchy: %v", err)
+	}
+	if len(items) == 0 {
+		return &CallGraph{
+			Direction: opts.Direction,
+			Nodes:     []CallGraphNode{},
+			Edges:     []CallGraphEdge{},
+			notFound:  fmt.Sprintf("%s:%d:%d", filePath, line, column),
+		}, nil
+	}
+
+	root := workspaceRoot(client)
+	graph, err := buildCallGraph(ctx, items[0], root, opts, lspCallFetcher(client))
+	if err != nil {
+		return nil, err
+	}
+	graph.format = opts.Format
+	return graph, nil
+}
+
+func (g *CallGraph) Format() string {
+	if g.notFound != "" {
+		return fmt.Sprintf("No symbol found at %s", g.notFound)
+	}
+	text, err := renderCallGraph(g, g.format)
+	if err != nil {
+		return err.Error()
+	}
+	return text
+}
+
+// normalizeCallGraphOptions validates options and fills in defaults
+func normalizeCallGraphOptions(opts CallGraphOptions) (CallGraphOptions, error) {
+	switch opts.Direction {
+	case "":
+		opts.Direction = "incoming"
+	case "incoming", "outgoing", "both":
+	default:
+		return opts, fmt.Errorf("direction must be 'incoming', 'outgoing' or 'both', got: %s", opts.Direction)
+	}
+	switch opts.Format {
+	case "":
+		opts.Format = "json"
+	case "json", "dot", "mermaid":
+	default:
+		return opts, fmt.Errorf("format must be 'json', 'dot' or 'mermaid', got: %s", opts.Format)
+	}
+	if opts.MaxDepth <= 0 {
+		opts.MaxDepth = DefaultCallGraphDepth
+	}
+	if opts.MaxNodes <= 0 {
+		opts.MaxNodes = DefaultCallGraphNodes
+	}
+	return opts, nil
+}
+
+// callGraphCall is a call to or from an item, with the call sites in the caller
+type callGraphCall struct {
+	item      protocol.CallHierarchyItem
+	callSites []protocol.Range
+}
+
+// callFetcher returns the calls to an item (incoming) or from it (outgoing)
+type callFetcher func(ctx context.Context, item protocol.CallHierarchyItem, incoming bool) ([]callGraphCall, error)
+
+func lspCallFetcher(client *lsp.Client) callFetcher {
+	return func(ctx context.Context, item protocol.CallHierarchyItem, incoming bool) ([]callGraphCall, error) {
+		var calls []callGraphCall
+		if incoming {
+			result, err := client.IncomingCalls(ctx, protocol.CallHierarchyIncomingCallsParams{Item: item})
+			if err != nil {
+				return nil, fmt.Errorf("failed to get incoming calls for %s: %v", item.Name, err)
+			}
+			for _, call := range result {
+				calls = append(calls, callGraphCall{item: call.From, callSites: call.FromRanges})
+			}
+			return calls, nil
+		}
+
+		result, err := client.OutgoingCalls(ctx, protocol.CallHierarchyOutgoingCallsParams{Item: item})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get outgoing calls for %s: %v", item.Name, err)
+		}
+		for _, call := range result {
+			calls = append(calls, callGraphCall{item: call.To, callSites: call.FromRanges})
+		}
+		return calls, nil
+	}
+}
+
+// buildCallGraph walks calls breadth-first from the root item. Incoming and
+// outgoing calls are walked separately so that "both" doesn't mix callers of
+// callees into the graph.
+func buildCallGraph(ctx context.Context, rootItem protocol.CallHierarchyItem, root string, opts CallGraphOptions, fetch callFetcher) (*CallGraph, error) {
+	var exclude []*utilities.Glob
+	for _, pattern := range opts.Exclude {
+		glob, err := utilities.CompileGlob(pattern)
+		if err != nil {
+			return nil, err
+		}
+		exclude = append(exclude, glob)
+	}
+
+	b := &callGraphBuilder{
+		root:    root,
+		opts:    opts,
+		exclude: exclude,
+		index:   make(map[string]int),
+		edges:   make(map[[2]string]int),
+		graph:   &CallGraph{Direction: opts.Direction, Edges: []CallGraphEdge{}},
+	}
+	rootID := b.addNode(rootItem, 0)
+	b.graph.Root = rootID
+
+	var directions []bool
+	switch opts.Direction {
+	case "incoming":
+		directions = []bool{true}
+	case "outgoing":
+		directions = []bool{false}
+	default:
+		directions = []bool{true, false}
+	}
+
+	for _, incoming := range directions {
+		if err := b.walk(ctx, rootItem, incoming, fetch); err != nil {
+			return nil, err
+		}
+	}
+	return b.graph, nil
+}
+
+type callGraphBuilder struct {
+	root    string
+	opts    CallGraphOptions
+	exclude []*utilities.Glob
+	// index maps node IDs to their position in graph.Nodes
+	index map[string]int
+	// edges maps caller and callee IDs to their position in graph.Edges
+	edges map[[2]string]int
+	graph *CallGraph
+}
+
+func (b *callGraphBuilder) walk(ctx context.Context, rootItem protocol.CallHierarchyItem, incoming bool, fetch callFetcher) error {
+	type queued struct {
+		item  protocol.CallHierarchyItem
+		depth int
+	}
+	queue := []queued{{item: rootItem}}
+	expanded := map[string]bool{b.nodeID(rootItem): true}
+
+	for len(queue) > 0 {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		current := queue[0]
+		queue = queue[1:]
+		currentID := b.nodeID(current.item)
+
+		if current.depth >= b.opts.MaxDepth {
+			b.graph.Nodes[b.index[currentID]].Unexpanded = true
+			continue
+		}
+
+		calls, err := fetch(ctx, current.item, incoming)
+		if err != nil {
+			return err
+		}
+
+		for _, call := range calls {
+			if b.excluded(call.item) {
+				continue
+			}
+			id := b.nodeID(call.item)
+			if _, ok := b.index[id]; !ok {
+				if len(b.graph.Nodes) >= b.opts.MaxNodes {
+					b.graph.Truncated = true
+					b.graph.Nodes[b.index[currentID]].Unexpanded = true
+					continue
+				}
+				b.addNode(call.item, current.depth+1)
+			}
+
+			if incoming {
+				b.addEdge(id, currentID, call.callSites)
+			} else {
+				b.addEdge(currentID, id, call.callSites)
+			}
+
+			// Functions already seen, including via a cycle, are not walked again
+			if !expanded[id] {
+				expanded[id] = true
+				queue = append(queue, queued{item: call.item, depth: current.depth + 1})
+			}
+		}
+	}
+	return nil
+}
+
+// nodeID identifies an item by where its name is declared
+func (b *callGraphBuilder) nodeID(item protocol.CallHierarchyItem) string {
+	return fmt.Sprintf("%s:%d:%d", b.itemPath(item), item.SelectionRange.Start.Line+1, item.SelectionRange.Start.Character+1)
+}
+
+func (b *callGraphBuilder) itemPath(item protocol.CallHierarchyItem) string {
+	return filepath.ToSlash(displayPath(b.root, strings.TrimPrefix(string(item.URI), "file://")))
+}
+
+func (b *callGraphBuilder) excluded(item protocol.CallHierarchyItem) bool {
+	path := b.itemPath(item)
+	for _, glob := range b.exclude {
+		if glob.Match(path) {
+			return true
+		}
+	}
+	return false
+}
+
+func (b *callGraphBuilder) addNode(item protocol.CallHierarchyItem, depth int) string {
+	id := b.nodeID(item)
+	b.index[id] = len(b.graph.Nodes)
+	b.graph.Nodes = append(b.graph.Nodes, CallGraphNode{
+		ID:     id,
+		Name:   item.Name,
+		Kind:   symbolKindToString(item.Kind),
+		Detail: item.Detail,
+		Path:   b.itemPath(item),
+		Line:   int(item.SelectionRange.Start.Line) + 1,
+		Column: int(item.SelectionRange.Start.Character) + 1,
+		Depth:  depth,
+	})
+	return id
+}
+
+func (b *callGraphBuilder) addEdge(from, to string, callSites []protocol.Range) {
+	key := [2]string{from, to}
+	i, ok := b.edges[key]
+	if !ok {
+		i = len(b.graph.Edges)
+		b.edges[key] = i
+		b.graph.Edges = append(b.graph.Edges, CallGraphEdge{From: from, To: to})
+	}
+	for _, r := range callSites {
+		site := fmt.Sprintf("L%d:C%d", r.Start.Line+1, r.Start.Character+1)
+		if !containsString(b.graph.Edges[i].CallSites, site) {
+			b.graph.Edges[i].CallSites = append(b.graph.Edges[i].CallSites, site)
+		}
+	}
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// renderCallGraph renders a call graph as JSON, Graphviz DOT or a Mermaid flowchart
+func renderCallGraph(graph *CallGraph, format string) (string, error) {
+	switch format {
+	case "dot":
+		return renderCallGraphDOT(graph), nil
+	case "mermaid":
+		return renderCallGraphMermaid(graph), nil
+	default:
+		data, err := json.MarshalIndent(graph, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("failed to encode call graph: %v", err)
+		}
+		return string(data) + "\n", nil
+	}
+}
+
+// shortIDs maps node IDs to identifiers usable in DOT and Mermaid, n0, n1, ...
+func shortIDs(graph *CallGraph) map[string]string {
+	ids := make(map[string]string, len(graph.Nodes))
+	for i, node := range graph.Nodes {
+		ids[node.ID] = fmt.Sprintf("n%d", i)
+	}
+	return ids
+}
+
+func renderCallGraphDOT(graph *CallGraph) string {
+	escape := strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace
+	ids := shortIDs(graph)
+
+	var out strings.Builder
+	out.WriteString("digraph calls {\n")
+	out.WriteString("  rankdir=LR;\n")
+	out.WriteString("  node [shape=box];\n")
+	for _, node := range graph.Nodes {
+		attrs := fmt.Sprintf(`label="%s\n%s:%d"`, escape(node.Name), escape(node.Path), node.Line)
+		if node.ID == graph.Root {
+			attrs += ", style=bold"
+		} else if node.Unexpanded {
+			attrs += ", style=dashed"
+		}
+		out.WriteString(fmt.Sprintf("  %s [%s];\n", ids[node.ID], attrs))
+	}
+	for _, edge := range graph.Edges {
+		out.WriteString(fmt.Sprintf("  %s -> %s;\n", ids[edge.From], ids[edge.To]))
+	}
+	out.WriteString("}\n")
+	if graph.Truncated {
+		out.WriteString(fmt.Sprintf("// Node budget reached after %d nodes, dashed nodes were not expanded\n", len(graph.Nodes)))
+	}
+	return out.String()
+}
+
+func renderCallGraphMermaid(graph *CallGraph) string {
+	escape := strings.NewReplacer(`"`, "#quot;").Replace
+	ids := shortIDs(graph)
+
+	var out strings.Builder
+	out.WriteString("flowchart LR\n")
+	for _, node := range graph.Nodes {
+		out.WriteString(fmt.Sprintf("  %s[\"%s<br/>%s:%d\"]\n", ids[node.ID], escape(node.Name), escape(node.Path), node.Line))
+	}
+	for _, edge := range graph.Edges {
+		out.WriteString(fmt.Sprintf("  %s --> %s\n", ids[edge.From], ids[edge.To]))
+	}
+	out.WriteString(fmt.Sprintf("  style %s stroke-width:3px\n", ids[graph.Root]))
+	for _, node := range graph.Nodes {
+		if node.Unexpanded && node.ID != graph.Root {
+			out.WriteString(fmt.Sprintf("  style %s stroke-dasharray:5\n", ids[node.ID]))
+		}
+	}
+	if graph.Truncated {
+		out.WriteString(fmt.Sprintf("%%%% Node budget reached after %d nodes, dashed nodes were not expanded\n", len(graph.Nodes)))
+	}
+	return out.String()
+}
@@ -0,0 +1,159 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func callItem(name, file string, line uint32) protocol.CallHierarchyItem {
+	position := protocol.Position{Line: line, Character: 5}
+	return protocol.CallHierarchyItem{
+		Name:           name,
+		Kind:           protocol.Function,
+		URI:            protocol.DocumentUri("file:///ws/" + file),
+		Range:          protocol.Range{Start: protocol.Position{Line: line}, End: position},
+		SelectionRange: protocol.Range{Start: position, End: position},
+	}
+}
+
+// fakeCalls serves outgoing calls from a map of caller name to callees, and
+// incoming calls by inverting it
+func fakeCalls(items map[string]protocol.CallHierarchyItem, calls map[string][]string) callFetcher {
+	return func(ctx context.Context, item protocol.CallHierarchyItem, incoming bool) ([]callGraphCall, error) {
+		var result []callGraphCall
+		site := []protocol.Range{{Start: protocol.Position{Line: item.Range.Start.Line + 1, Character: 1}}}
+		if !incoming {
+			for _, callee := range calls[item.Name] {
+				result = append(result, callGraphCall{item: items[callee], callSites: site})
+			}
+			return result, nil
+		}
+		for caller, callees := range calls {
+			for _, callee := range callees {
+				if callee == item.Name {
+					result = append(result, callGraphCall{item: items[caller]})
+				}
+			}
+		}
+		return result, nil
+	}
+}
+
+func TestBuildCallGraph(t *testing.T) {
+	items := map[string]protocol.CallHierarchyItem{
+		"main":    callItem("main", "main.go", 2),
+		"run":     callItem("run", "run.go", 4),
+		"step":    callItem("step", "run.go", 10),
+		"helper":  callItem("helper", "util/helper.go", 1),
+		"leaf":    callItem("leaf", "util/leaf.go", 1),
+		"fixture": callItem("fixture", "run_test.go", 3),
+	}
+	calls := map[string][]string{
+		"main":   {"run", "fixture"},
+		"run":    {"step", "run"},
+		"step":   {"run", "helper"},
+		"helper": {"leaf"},
+	}
+	fetch := fakeCalls(items, calls)
+
+	t.Run("cycles and recursion", func(t *testing.T) {
+		opts, err := normalizeCallGraphOptions(CallGraphOptions{Direction: "outgoing", MaxDepth: 10})
+		require.NoError(t, err)
+		graph, err := buildCallGraph(context.Background(), items["main"], "/ws", opts, fetch)
+		require.NoError(t, err)
+
+		var names []string
+		for _, node := range graph.Nodes {
+			names = append(names, node.Name)
+		}
+		assert.Equal(t, []string{"main", "run", "fixture", "step", "helper", "leaf"}, names)
+		assert.Equal(t, "main.go:3:6", graph.Root)
+		assert.Len(t, graph.Edges, 7, "the recursive call and the step -> run back edge are kept")
+		assert.False(t, graph.Truncated)
+		assert.Equal(t, []string{"L4:C2"}, graph.Edges[0].CallSites)
+	})
+
+	t.Run("depth limit", func(t *testing.T) {
+		opts, err := normalizeCallGraphOptions(CallGraphOptions{Direction: "outgoing", MaxDepth: 1})
+		require.NoError(t, err)
+		graph, err := buildCallGraph(context.Background(), items["main"], "/ws", opts, fetch)
+		require.NoError(t, err)
+		require.Len(t, graph.Nodes, 3)
+		assert.True(t, graph.Nodes[1].Unexpanded)
+		assert.Equal(t, 1, graph.Nodes[1].Depth)
+	})
+
+	t.Run("node budget", func(t *testing.T) {
+		opts, err := normalizeCallGraphOptions(CallGraphOptions{Direction: "outgoing", MaxNodes: 2})
+		require.NoError(t, err)
+		graph, err := buildCallGraph(context.Background(), items["main"], "/ws", opts, fetch)
+		require.NoError(t, err)
+		assert.Len(t, graph.Nodes, 2)
+		assert.True(t, graph.Truncated)
+		assert.True(t, graph.Nodes[0].Unexpanded)
+	})
+
+	t.Run("exclude and incoming", func(t *testing.T) {
+		opts, err := normalizeCallGraphOptions(CallGraphOptions{Exclude: []string{"**/*_test.go", "util/**"}})
+		require.NoError(t, err)
+		assert.Equal(t, "incoming", opts.Direction)
+		graph, err := buildCallGraph(context.Background(), items["step"], "/ws", opts, fetch)
+		require.NoError(t, err)
+		for _, node := range graph.Nodes {
+			assert.NotContains(t, node.Path, "util/")
+			assert.NotContains(t, node.Path, "_test.go")
+		}
+		for _, edge := range graph.Edges {
+			if edge.To == graph.Root {
+				assert.Equal(t, "run.go:5:6", edge.From)
+			}
+		}
+	})
+}
+
+func TestNormalizeCallGraphOptions_Invalid(t *testing.T) {
+	_, err := normalizeCallGraphOptions(CallGraphOptions{Direction: "sideways"})
+	assert.Error(t, err)
+	_, err = normalizeCallGraphOptions(CallGraphOptions{Format: "svg"})
+	assert.Error(t, err)
+}
+
+func TestRenderCallGraph(t *testing.T) {
+	graph := &CallGraph{
+		Root:      "a.go:1:6",
+		Direction: "outgoing",
+		Nodes: []CallGraphNode{
+			{ID: "a.go:1:6", Name: "main", Path: "a.go", Line: 1},
+			{ID: "b.go:2:6", Name: `say"hi"`, Path: "b.go", Line: 2, Unexpanded: true},
+		},
+		Edges:     []CallGraphEdge{{From: "a.go:1:6", To: "b.go:2:6"}},
+		Truncated: true,
+	}
+
+	text, err := renderCallGraph(graph, "json")
+	require.NoError(t, err)
+	var decoded CallGraph
+	require.NoError(t, json.Unmarshal([]byte(text), &decoded))
+	assert.Equal(t, *graph, decoded)
+
+	dot, err := renderCallGraph(graph, "dot")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(dot, "digraph calls {\n"))
+	assert.Contains(t, dot, `n0 [label="main\na.go:1", style=bold];`)
+	assert.Contains(t, dot, `n1 [label="say\"hi\"\nb.go:2", style=dashed];`)
+	assert.Contains(t, dot, "n0 -> n1;")
+	assert.Contains(t, dot, "// Node budget reached")
+
+	mermaid, err := renderCallGraph(graph, "mermaid")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(mermaid, "flowchart LR\n"))
+	assert.Contains(t, mermaid, `n1["say#quot;hi#quot;<br/>b.go:2"]`)
+	assert.Contains(t, mermaid, "n0 --> n1")
+	assert.Contains(t, mermaid, "%% Node budget reached")
+}
@@ -9,18 +9,34 @@ import (
 	"github.com/isaacphi/mcp-language-server/internal/protocol"
 )
 
+// Call is a caller or callee of a symbol, with the ranges of the calls
+type Call struct {
+	Item  HierarchyItem `json:"item"`
+	Sites []Range       `json:"sites"`
+}
+
+// CallHierarchyResult lists the incoming or outgoing calls of the symbol at a
+// position. Symbol is omitted when there's no symbol at the position.
+type CallHierarchyResult struct {
+	Path      string         `json:"path"`
+	Position  Position       `json:"position"`
+	Direction string         `json:"direction"`
+	Symbol    *HierarchyItem `json:"symbol,omitempty"`
+	Calls     []Call         `json:"calls"`
+}
+
 // GetCallHierarchy returns incoming or outgoing calls for a symbol at the given position
 // direction should be "incoming" or "outgoing"
-func GetCallHierarchy(ctx context.Context, client *lsp.Client, filePath string, line, column int, direction string) (string, error) {
+func GetCallHierarchy(ctx context.Context, client *lsp.Client, filePath string, line, column int, direction string) (*CallHierarchyResult, error) {
 	// Validate direction parameter
 	if direction != "incoming" && direction != "outgoing" {
-		return "", fmt.Errorf("direction must be 'incoming' or 'outgoing', got: %s", direction)
+		return nil, fmt.Errorf("direction must be 'incoming' or 'outgoing', got: %s", direction)
 	}
 
 	// Open the file first
 	err := client.OpenFile(ctx, filePath)
 	if err != nil {
-		return "", fmt.Errorf("failed to open file: %w", err)
+		return nil, fmt.Errorf("failed to open file: %w", err)
 	}
 
 	// Create URI from file path
@@ -33,8 +49,8 @@ func GetCallHierarchy(ctx context.Context, client *lsp.Client, filePath string,
 				URI: uri,
 			},
 			Position: protocol.Position{
-				Line:      uint32(line - 1),      // Convert from 1-indexed to 0-indexed
-				Character: uint32(column - 1),    // Convert from 1-indexed to 0-indexed
+				Line:      uint32(line - 1),   // Convert from 1-indexed to 0-indexed
+				Character: uint32(column - 1), // Convert from 1-indexed to 0-indexed
 			},
 		},
 	}
@@ -42,20 +58,27 @@ func GetCallHierarchy(ctx context.Context, client *lsp.Client, filePath string,
 	// Call PrepareCallHierarchy to get CallHierarchyItem
 	items, err := client.PrepareCallHierarchy(ctx, params)
 	if err != nil {
-		return "", fmt.Errorf("failed to prepare call hierarchy: %w", err)
+		return nil, fmt.Errorf("failed to prepare call hierarchy: %w", err)
+	}
+
+	result := &CallHierarchyResult{
+		Path:      filePath,
+		Position:  Position{Line: line, Column: column},
+		Direction: direction,
+		Calls:     []Call{},
 	}
 
 	// Check if we found any symbol at the position
 	if len(items) == 0 {
-		return fmt.Sprintf("No symbol found at %s:%d:%d", filePath, line, column), nil
+		return result, nil
 	}
 
 	// Take the first item (most relevant)
 	item := items[0]
+	symbol := callHierarchyItem(item)
+	result.Symbol = &symbol
 
 	// Get calls based on direction
-	var result strings.Builder
-
 	if direction == "incoming" {
 		// Get incoming calls (callers)
 		incomingParams := protocol.CallHierarchyIncomingCallsParams{
@@ -64,45 +87,11 @@ func GetCallHierarchy(ctx context.Context, client *lsp.Client, filePath string,
 
 		incomingCalls, err := client.IncomingCalls(ctx, incomingParams)
 		if err != nil {
-			return "", fmt.Errorf("failed to get incoming calls: %w", err)
+			return nil, fmt.Errorf("failed to get incoming calls: %w", err)
 		}
 
-		// Format the output
-		result.WriteString(fmt.Sprintf("Incoming calls to: %s", item.Name))
-		if item.Detail != "" {
-			result.WriteString(fmt.Sprintf(" (%s)", item.Detail))
-		}
-		result.WriteString(fmt.Sprintf(" at %s:%d\n\n",
-			strings.TrimPrefix(string(item.URI), "file://"),
-			item.Range.Start.Line+1))
-
-		if len(incomingCalls) == 0 {
-			result.WriteString("No incoming calls found\n")
-		} else {
-			for i, call := range incomingCalls {
-				// Format caller information
-				callerFile := strings.TrimPrefix(string(call.From.URI), "file://")
-				callerLine := call.From.Range.Start.Line + 1
-
-				result.WriteString(fmt.Sprintf("%d. %s", i+1, call.From.Name))
-				if call.From.Detail != "" {
-					result.WriteString(fmt.Sprintf(" (%s)", call.From.Detail))
-				}
-				result.WriteString(fmt.Sprintf(" at %s:%d\n",
-					callerFile,
-					callerLine))
-
-				// Show the ranges where the calls occur
-				if len(call.FromRanges) > 0 {
-					var ranges []string
-					for _, r := range call.FromRanges {
-						ranges = append(ranges, fmt.Sprintf("L%d:C%d",
-							r.Start.Line+1,
-							r.Start.Character+1))
-					}
-					result.WriteString(fmt.Sprintf("   Call sites: %s\n", strings.Join(ranges, ", ")))
-				}
-			}
+		for _, call := range incomingCalls {
+			result.Calls = append(result.Calls, Call{Item: callHierarchyItem(call.From), Sites: newRanges(call.FromRanges)})
 		}
 	} else {
 		// Get outgoing calls (callees)
@@ -112,47 +101,59 @@ func GetCallHierarchy(ctx context.Context, client *lsp.Client, filePath string,
 
 		outgoingCalls, err := client.OutgoingCalls(ctx, outgoingParams)
 		if err != nil {
-			return "", fmt.Errorf("failed to get outgoing calls: %w", err)
+			return nil, fmt.Errorf("failed to get outgoing calls: %w", err)
 		}
 
-		// Format the output
-		result.WriteString(fmt.Sprintf("Outgoing calls from: %s", item.Name))
-		if item.Detail != "" {
-			result.WriteString(fmt.Sprintf(" (%s)", item.Detail))
+		for _, call := range outgoingCalls {
+			result.Calls = append(result.Calls, Call{Item: callHierarchyItem(call.To), Sites: newRanges(call.FromRanges)})
 		}
-		result.WriteString(fmt.Sprintf(" at %s:%d\n\n",
-			strings.TrimPrefix(string(item.URI), "file://"),
-			item.Range.Start.Line+1))
-
-		if len(outgoingCalls) == 0 {
-			result.WriteString("No outgoing calls found\n")
-		} else {
-			for i, call := range outgoingCalls {
-				// Format callee information
-				calleeFile := strings.TrimPrefix(string(call.To.URI), "file://")
-				calleeLine := call.To.Range.Start.Line + 1
-
-				result.WriteString(fmt.Sprintf("%d. %s", i+1, call.To.Name))
-				if call.To.Detail != "" {
-					result.WriteString(fmt.Sprintf(" (%s)", call.To.Detail))
-				}
-				result.WriteString(fmt.Sprintf(" at %s:%d\n",
-					calleeFile,
-					calleeLine))
-
-				// Show the ranges where the calls are made from
-				if len(call.FromRanges) > 0 {
-					var ranges []string
-					for _, r := range call.FromRanges {
-						ranges = append(ranges, fmt.Sprintf("L%d:C%d",
-							r.Start.Line+1,
-							r.Start.Character+1))
-					}
-					result.WriteString(fmt.Sprintf("   Called at: %s\n", strings.Join(ranges, ", ")))
-				}
+	}
+
+	return result, nil
+}
+
+func callHierarchyItem(item protocol.CallHierarchyItem) HierarchyItem {
+	return newHierarchyItem(item.Name, item.Kind, item.Detail, item.URI, item.Range)
+}
+
+func (r *CallHierarchyResult) Format() string {
+	if r.Symbol == nil {
+		return fmt.Sprintf("No symbol found at %s:%d:%d", r.Path, r.Position.Line, r.Position.Column)
+	}
+
+	heading, sitesLabel := "Incoming calls to", "Call sites"
+	if r.Direction == "outgoing" {
+		heading, sitesLabel = "Outgoing calls from", "Called at"
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("%s: %s", heading, r.Symbol.Name))
+	if r.Symbol.Detail != "" {
+		result.WriteString(fmt.Sprintf(" (%s)", r.Symbol.Detail))
+	}
+	result.WriteString(fmt.Sprintf(" at %s:%d\n\n", r.Symbol.Location.Path, r.Symbol.Location.Range.Start.Line))
+
+	if len(r.Calls) == 0 {
+		result.WriteString(fmt.Sprintf("No %s calls found\n", r.Direction))
+		return result.String()
+	}
+
+	for i, call := range r.Calls {
+		result.WriteString(fmt.Sprintf("%d. %s", i+1, call.Item.Name))
+		if call.Item.Detail != "" {
+			result.WriteString(fmt.Sprintf(" (%s)", call.Item.Detail))
+		}
+		result.WriteString(fmt.Sprintf(" at %s:%d\n", call.Item.Location.Path, call.Item.Location.Range.Start.Line))
+
+		// Show the ranges where the calls occur
+		if len(call.Sites) > 0 {
+			var ranges []string
+			for _, r := range call.Sites {
+				ranges = append(ranges, fmt.Sprintf("L%d:C%d", r.Start.Line, r.Start.Column))
 			}
+			result.WriteString(fmt.Sprintf("   %s: %s\n", sitesLabel, strings.Join(ranges, ", ")))
 		}
 	}
 
-	return result.String(), nil
+	return result.String()
 }
@@ -2,6 +2,7 @@ package tools
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"strings"
 
@@ -9,12 +10,43 @@ import (
 	"github.com/isaacphi/mcp-language-server/internal/protocol"
 )
 
+// CodeAction is a code action offered by the language server. Index is its
+// 1-based position in the server's list.
+type CodeAction struct {
+	Index   int    `json:"index"`
+	Title   string `json:"title"`
+	Kind    string `json:"kind,omitempty"`
+	Command string `json:"command,omitempty"`
+	// Preview is the edit the action would make, for dry runs
+	Preview *EditResult `json:"preview,omitempty"`
+	// PreviewError explains why a dry run has no preview
+	PreviewError string `json:"previewError,omitempty"`
+
+	// unknown is set for items that aren't code actions
+	unknown bool
+}
+
+// CodeActionsResult lists the code actions available for a range
+type CodeActionsResult struct {
+	Path    string       `json:"path"`
+	Range   Range        `json:"range"`
+	Actions []CodeAction `json:"actions"`
+
+	dryRun bool
+	// total counts the items returned by the server, including empty ones
+	total int
+}
+
 // GetCodeActions returns available code actions for a range in a file
-func GetCodeActions(ctx context.Context, client *lsp.Client, filePath string, startLine, startColumn, endLine, endColumn int) (string, error) {
+//
+// If dryRun is true, the workspace edit of each action is rendered as unified
+// diffs, resolving the action first when the server supports codeAction/resolve.
+// Nothing is written to disk.
+func GetCodeActions(ctx context.Context, client *lsp.Client, filePath string, startLine, startColumn, endLine, endColumn int, dryRun bool) (*CodeActionsResult, error) {
 	// Open the file if not already open
 	err := client.OpenFile(ctx, filePath)
 	if err != nil {
-		return "", fmt.Errorf("could not open file: %v", err)
+		return nil, fmt.Errorf("could not open file: %v", err)
 	}
 
 	// Convert to URI format
@@ -49,17 +81,16 @@ func GetCodeActions(ctx context.Context, client *lsp.Client, filePath string, st
 	// Call the CodeAction method
 	actions, err := client.CodeAction(ctx, params)
 	if err != nil {
-		return "", fmt.Errorf("failed to get code actions: %v", err)
+		return nil, fmt.Errorf("failed to get code actions: %v", err)
 	}
 
-	if len(actions) == 0 {
-		return "No code actions available", nil
+	result := &CodeActionsResult{
+		Path:    filePath,
+		Range:   newRange(actionRange),
+		Actions: []CodeAction{},
+		dryRun:  dryRun,
+		total:   len(actions),
 	}
-
-	// Format the code actions
-	var result strings.Builder
-	result.WriteString(fmt.Sprintf("Code Actions (%d available):\n\n", len(actions)))
-
 	for i, actionItem := range actions {
 		// The Value field contains either a CodeAction or Command
 		if actionItem.Value == nil {
@@ -67,38 +98,70 @@ func GetCodeActions(ctx context.Context, client *lsp.Client, filePath string, st
 		}
 
 		// Type assert the value
+		action := CodeAction{Index: i + 1}
 		switch v := actionItem.Value.(type) {
 		case map[string]any:
 			// This is a CodeAction
-			title, _ := v["title"].(string)
-			kindStr, _ := v["kind"].(string)
-
-			kind := "Unknown"
-			if kindStr != "" {
-				kind = formatCodeActionKind(kindStr)
-			}
-
-			result.WriteString(fmt.Sprintf("%d. [%s] %s\n", i+1, kind, title))
+			action.Title, _ = v["title"].(string)
+			action.Kind, _ = v["kind"].(string)
 
 			// Add command if present
 			if cmdMap, ok := v["command"].(map[string]any); ok {
-				if cmdName, ok := cmdMap["command"].(string); ok {
-					result.WriteString(fmt.Sprintf("   Command: %s\n", cmdName))
+				action.Command, _ = cmdMap["command"].(string)
+			}
+
+			if dryRun {
+				preview, err := previewCodeAction(ctx, client, v)
+				if err != nil {
+					action.PreviewError = err.Error()
+				} else {
+					action.Preview = preview
 				}
 			}
 
 		default:
+			action.unknown = true
+		}
+		result.Actions = append(result.Actions, action)
+	}
+
+	return result, nil
+}
+
+func (r *CodeActionsResult) Format() string {
+	if r.total == 0 {
+		return "No code actions available"
+	}
+
+	// Format the code actions
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Code Actions (%d available):\n\n", r.total))
+
+	for _, action := range r.Actions {
+		if action.unknown {
 			// Unknown type, try to extract what we can
-			result.WriteString(fmt.Sprintf("%d. Unknown action type\n", i+1))
+			result.WriteString(fmt.Sprintf("%d. Unknown action type\n", action.Index))
+		} else {
+			kind := "Unknown"
+			if action.Kind != "" {
+				kind = formatCodeActionKind(action.Kind)
+			}
+			result.WriteString(fmt.Sprintf("%d. [%s] %s\n", action.Index, kind, action.Title))
+			if action.Command != "" {
+				result.WriteString(fmt.Sprintf("   Command: %s\n", action.Command))
+			}
+			if r.dryRun {
+				result.WriteString(formatCodeActionPreview(action))
+			}
 		}
 
 		// Add blank line between actions
-		if i < len(actions)-1 {
+		if action.Index < r.total {
 			result.WriteString("\n")
 		}
 	}
 
-	return result.String(), nil
+	return result.String()
 }
 
 // formatCodeActionKind converts a CodeActionKind string into a more readable format
@@ -124,3 +187,96 @@ func formatCodeActionKind(kind string) string {
 		return kind
 	}
 }
+
+// decodeCodeAction converts a code action decoded as a generic map into a protocol.CodeAction
+func decodeCodeAction(value map[string]any) (protocol.CodeAction, error) {
+	var action protocol.CodeAction
+	data, err := json.Marshal(value)
+	if err != nil {
+		return action, err
+	}
+	err = json.Unmarshal(data, &action)
+	return action, err
+}
+
+// resolveCodeAction fills in the edit of an action that was returned without
+// one, if the server supports codeAction/resolve
+func resolveCodeAction(ctx context.Context, client *lsp.Client, action protocol.CodeAction) (protocol.CodeAction, error) {
+	if action.Edit != nil || action.Data == nil || !lsp.HasCodeActionResolveSupport(client.GetCapabilities()) {
+		return action, nil
+	}
+	return client.ResolveCodeAction(ctx, action)
+}
+
+// previewCodeAction computes the edit a code action would make
+func previewCodeAction(ctx context.Context, client *lsp.Client, value map[string]any) (*EditResult, error) {
+	action, err := decodeCodeAction(value)
+	if err != nil {
+		return nil, err
+	}
+	action, err = resolveCodeAction(ctx, client, action)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve code action: %v", err)
+	}
+	if action.Edit == nil {
+		if action.Command != nil {
+			return nil, fmt.Errorf("action only runs a command on the server")
+		}
+		return nil, fmt.Errorf("action has no edit")
+	}
+	return PreviewWorkspaceEdit(client, *action.Edit)
+}
+
+// formatCodeActionPreview renders the preview of an action, indented to sit
+// under the action in the list
+func formatCodeActionPreview(action CodeAction) string {
+	if action.Preview == nil {
+		return fmt.Sprintf("   Preview unavailable: %s\n", action.PreviewError)
+	}
+	var output strings.Builder
+	for _, line := range strings.SplitAfter(strings.TrimSuffix(action.Preview.Message, "\n"), "\n") {
+		output.WriteString("   " + line)
+	}
+	output.WriteString("\n")
+	return output.String()
+}
+
+// chooseCodeAction picks an action from a list of choices: the one given by
+// its 1-based actionIndex, the only choice, or the only preferred one. It
+// reports false if the caller has to choose.
+func chooseCodeAction(choices []protocol.CodeAction, actionIndex int) (protocol.CodeAction, bool, error) {
+	if actionIndex > 0 {
+		if actionIndex > len(choices) {
+			return protocol.CodeAction{}, false, fmt.Errorf("actionIndex %d is out of range, %d actions are available", actionIndex, len(choices))
+		}
+		return choices[actionIndex-1], true, nil
+	}
+	if len(choices) == 1 {
+		return choices[0], true, nil
+	}
+	action, ok := preferredCodeAction(choices)
+	return action, ok, nil
+}
+
+// preferredCodeAction returns the only action marked as preferred, if exactly one is
+func preferredCodeAction(actions []protocol.CodeAction) (protocol.CodeAction, bool) {
+	var preferred []protocol.CodeAction
+	for _, action := range actions {
+		if action.IsPreferred {
+			preferred = append(preferred, action)
+		}
+	}
+	if len(preferred) != 1 {
+		return protocol.CodeAction{}, false
+	}
+	return preferred[0], true
+}
+
+// formatCodeActionChoices lists actions by their 1-based actionIndex
+func formatCodeActionChoices(choices []protocol.CodeAction) string {
+	var output strings.Builder
+	for i, choice := range choices {
+		output.WriteString(fmt.Sprintf("%d. %s\n", i+1, choice.Title))
+	}
+	return output.String()
+}
@@ -10,9 +10,27 @@ import (
 	"github.com/isaacphi/mcp-language-server/internal/protocol"
 )
 
+// Completion is a completion suggestion
+type Completion struct {
+	Label  string `json:"label"`
+	Kind   string `json:"kind"`
+	Detail string `json:"detail,omitempty"`
+	// Documentation is the first line of the item's documentation
+	Documentation string `json:"documentation,omitempty"`
+}
+
+// CompletionsResult lists the first completions at a position, sorted as the
+// server suggests. Total is the number of completions the server returned.
+type CompletionsResult struct {
+	Path        string       `json:"path"`
+	Position    Position     `json:"position"`
+	Total       int          `json:"total"`
+	Completions []Completion `json:"completions"`
+}
+
 // GetCompletions returns context-aware code completion suggestions
 // limit caps the number of results (default 20 if 0)
-func GetCompletions(ctx context.Context, client *lsp.Client, filePath string, line, column, limit int) (string, error) {
+func GetCompletions(ctx context.Context, client *lsp.Client, filePath string, line, column, limit int) (*CompletionsResult, error) {
 	// Default limit
 	if limit <= 0 {
 		limit = 20
@@ -21,7 +39,7 @@ func GetCompletions(ctx context.Context, client *lsp.Client, filePath string, li
 	// Open the file if not already open
 	err := client.OpenFile(ctx, filePath)
 	if err != nil {
-		return "", fmt.Errorf("could not open file: %v", err)
+		return nil, fmt.Errorf("could not open file: %v", err)
 	}
 
 	// Create completion parameters
@@ -41,7 +59,7 @@ func GetCompletions(ctx context.Context, client *lsp.Client, filePath string, li
 	// Execute the completion request
 	completionResult, err := client.Completion(ctx, params)
 	if err != nil {
-		return "", fmt.Errorf("failed to get completions: %v", err)
+		return nil, fmt.Errorf("failed to get completions: %v", err)
 	}
 
 	// Handle the response which can be CompletionList or []CompletionItem
@@ -49,8 +67,9 @@ func GetCompletions(ctx context.Context, client *lsp.Client, filePath string, li
 	var totalCount int
 
 	// Extract items from completionResult
+	result := &CompletionsResult{Path: filePath, Position: newPosition(position), Completions: []Completion{}}
 	if completionResult.Value == nil {
-		return "No completions available", nil
+		return result, nil
 	}
 
 	switch v := completionResult.Value.(type) {
@@ -75,11 +94,7 @@ func GetCompletions(ctx context.Context, client *lsp.Client, filePath string, li
 			}
 		}
 	default:
-		return "", fmt.Errorf("unexpected completion result type: %T", v)
-	}
-
-	if len(items) == 0 {
-		return "No completions available", nil
+		return nil, fmt.Errorf("unexpected completion result type: %T", v)
 	}
 
 	// Sort by SortText or Label
@@ -101,16 +116,38 @@ func GetCompletions(ctx context.Context, client *lsp.Client, filePath string, li
 		items = items[:limit]
 	}
 
-	// Format output
-	var output strings.Builder
-	output.WriteString(fmt.Sprintf("Completions (%d of %d):\n\n", len(items), totalCount))
+	result.Total = totalCount
+	for _, item := range items {
+		completion := Completion{
+			Label:  item.Label,
+			Kind:   getCompletionKindString(item.Kind),
+			Detail: item.Detail,
+		}
+
+		// Keep the first line of the documentation
+		if item.Documentation != nil {
+			docStr := extractDocumentation(item.Documentation)
+			if docStr != "" {
+				completion.Documentation = strings.Split(docStr, "\n")[0]
+			}
+		}
+		result.Completions = append(result.Completions, completion)
+	}
+
+	return result, nil
+}
+
+func (r *CompletionsResult) Format() string {
+	if len(r.Completions) == 0 {
+		return "No completions available"
+	}
 
-	for i, item := range items {
-		// Get kind string
-		kindStr := getCompletionKindString(item.Kind)
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("Completions (%d of %d):\n\n", len(r.Completions), r.Total))
 
+	for i, item := range r.Completions {
 		// Format: index. [Kind] Label
-		output.WriteString(fmt.Sprintf("%d. [%s] %s", i+1, kindStr, item.Label))
+		output.WriteString(fmt.Sprintf("%d. [%s] %s", i+1, item.Kind, item.Label))
 
 		// Add detail if available (type information)
 		if item.Detail != "" {
@@ -118,25 +155,18 @@ func GetCompletions(ctx context.Context, client *lsp.Client, filePath string, li
 		}
 
 		// Add truncated documentation if available
-		if item.Documentation != nil {
-			docStr := extractDocumentation(item.Documentation)
-			if docStr != "" {
-				// Truncate to first line or 100 chars
-				lines := strings.Split(docStr, "\n")
-				if len(lines) > 0 && lines[0] != "" {
-					doc := lines[0]
-					if len(doc) > 100 {
-						doc = doc[:97] + "..."
-					}
-					output.WriteString(fmt.Sprintf("\n   Doc: %s", doc))
-				}
+		if item.Documentation != "" {
+			doc := item.Documentation
+			if len(doc) > 100 {
+				doc = doc[:97] + "..."
 			}
+			output.WriteString(fmt.Sprintf("\n   Doc: %s", doc))
 		}
 
 		output.WriteString("\n\n")
 	}
 
-	return output.String(), nil
+	return output.String()
 }
 
 // parseCompletionItem converts a map[string]any to CompletionItem
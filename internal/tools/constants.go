@@ -13,3 +13,6 @@ const DefaultNotificationTimeout = 5 * time.Second
 
 // FileOperationDebounceWindow is the time window for debouncing file operation notifications
 const FileOperationDebounceWindow = 100 * time.Millisecond
+
+// minHashPrefixLength is the shortest content hash prefix accepted for optimistic concurrency checks
+const minHashPrefixLength = 8
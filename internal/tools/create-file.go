@@ -0,0 +1,203 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/isaacphi/mcp-language-server/internal/utilities"
+)
+
+// CreateFile creates a new file with the given content and tells the language
+// server about it.
+//
+// If the server registered for workspace/willCreateFiles, the edits it returns,
+// such as package declarations or updated barrel exports, are applied in the
+// same journaled transaction as the new file. The file is then opened in the
+// language server, didCreateFiles is sent and a fileops create event is emitted.
+func CreateFile(ctx context.Context, client *lsp.Client, filePath, content string) (*EditResult, error) {
+	path, err := resolveWorkspacePath(filePath)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(path); err == nil {
+		return nil, fmt.Errorf("cannot create %s: file already exists", path)
+	}
+
+	uri := protocol.DocumentUri("file://" + path)
+
+	serverEdit, warning := willFileOperation(func() (protocol.WorkspaceEdit, error) {
+		if !lsp.HasWillCreateFilesSupport(client.GetCapabilities()) {
+			return protocol.WorkspaceEdit{}, nil
+		}
+		return client.WillCreateFiles(ctx, protocol.CreateFilesParams{
+			Files: []protocol.FileCreate{{URI: string(uri)}},
+		})
+	})
+
+	// The file must exist before the server's edits, which may target it
+	workspaceEdit := protocol.WorkspaceEdit{
+		DocumentChanges: []protocol.DocumentChange{
+			{CreateFile: &protocol.CreateFile{Kind: "create", URI: uri}},
+		},
+	}
+	if content != "" {
+		workspaceEdit.DocumentChanges = append(workspaceEdit.DocumentChanges, protocol.DocumentChange{
+			TextDocumentEdit: &protocol.TextDocumentEdit{
+				TextDocument: protocol.OptionalVersionedTextDocumentIdentifier{
+					TextDocumentIdentifier: protocol.TextDocumentIdentifier{URI: uri},
+				},
+				Edits: []protocol.Or_TextDocumentEdit_edits_Elem{
+					{Value: protocol.TextEdit{NewText: content}},
+				},
+			},
+		})
+	}
+	workspaceEdit.DocumentChanges = append(workspaceEdit.DocumentChanges, textDocumentChanges(serverEdit.Changes)...)
+	workspaceEdit.DocumentChanges = append(workspaceEdit.DocumentChanges, serverEdit.DocumentChanges...)
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create directory for %s: %v", path, err)
+	}
+
+	result, err := utilities.ApplyJournaledWorkspaceEdit("create_file", displayPath(workspaceRoot(client), path), workspaceEdit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create file: %v", err)
+	}
+
+	files := []patchedFile{{path: path, oldPath: path, created: true}}
+	updated := otherCommittedFiles(result, path)
+	for _, other := range updated {
+		files = append(files, patchedFile{path: other, oldPath: other, changed: true})
+	}
+	notifyPatchedFiles(ctx, client, files)
+
+	return journaledEdits(client, formatFileOperationResult(fmt.Sprintf("Created %s", path), updated, warning), result.Entry), nil
+}
+
+// DeleteFile deletes a file and tells the language server about it.
+//
+// If the server registered for workspace/willDeleteFiles, the edits it returns
+// are applied in the same journaled transaction as the deletion. The file is
+// then closed in the language server, didDeleteFiles is sent and a fileops
+// delete event is emitted.
+func DeleteFile(ctx context.Context, client *lsp.Client, filePath string) (*EditResult, error) {
+	path, err := resolveWorkspacePath(filePath)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot delete %s: %v", path, err)
+	}
+	if info.IsDir() {
+		return nil, fmt.Errorf("cannot delete %s: is a directory", path)
+	}
+
+	uri := protocol.DocumentUri("file://" + path)
+
+	workspaceEdit, warning := willFileOperation(func() (protocol.WorkspaceEdit, error) {
+		if !lsp.HasWillDeleteFilesSupport(client.GetCapabilities()) {
+			return protocol.WorkspaceEdit{}, nil
+		}
+		return client.WillDeleteFiles(ctx, protocol.DeleteFilesParams{
+			Files: []protocol.FileDelete{{URI: string(uri)}},
+		})
+	})
+
+	// The server's edits target the workspace as it is before the deletion
+	workspaceEdit.DocumentChanges = append(workspaceEdit.DocumentChanges, protocol.DocumentChange{
+		DeleteFile: &protocol.DeleteFile{Kind: "delete", URI: uri},
+	})
+
+	result, err := utilities.ApplyJournaledWorkspaceEdit("delete_file", displayPath(workspaceRoot(client), path), workspaceEdit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete file: %v", err)
+	}
+
+	files := []patchedFile{{path: path, oldPath: path, deleted: true}}
+	updated := otherCommittedFiles(result, path)
+	for _, other := range updated {
+		files = append(files, patchedFile{path: other, oldPath: other, changed: true})
+	}
+	notifyPatchedFiles(ctx, client, files)
+
+	return journaledEdits(client, formatFileOperationResult(fmt.Sprintf("Deleted %s", path), updated, warning), result.Entry), nil
+}
+
+// willFileOperation runs a workspace/will* request. A failed request doesn't
+// block the file operation; it is reported as a warning instead.
+func willFileOperation(request func() (protocol.WorkspaceEdit, error)) (protocol.WorkspaceEdit, string) {
+	edit, err := request()
+	if err != nil {
+		toolsLogger.Warn("File operation request failed: %v", err)
+		return protocol.WorkspaceEdit{}, fmt.Sprintf("the language server failed to compute related edits (%v), so no other files were updated", err)
+	}
+	return edit, ""
+}
+
+// textDocumentChanges converts the Changes of a workspace edit into document
+// changes, sorted by URI, so they can be ordered relative to file operations
+func textDocumentChanges(changes map[protocol.DocumentUri][]protocol.TextEdit) []protocol.DocumentChange {
+	uris := make([]protocol.DocumentUri, 0, len(changes))
+	for uri := range changes {
+		uris = append(uris, uri)
+	}
+	sort.Slice(uris, func(i, j int) bool { return uris[i] < uris[j] })
+
+	documentChanges := make([]protocol.DocumentChange, 0, len(uris))
+	for _, uri := range uris {
+		edits := make([]protocol.Or_TextDocumentEdit_edits_Elem, len(changes[uri]))
+		for i, edit := range changes[uri] {
+			edits[i] = protocol.Or_TextDocumentEdit_edits_Elem{Value: edit}
+		}
+		documentChanges = append(documentChanges, protocol.DocumentChange{
+			TextDocumentEdit: &protocol.TextDocumentEdit{
+				TextDocument: protocol.OptionalVersionedTextDocumentIdentifier{
+					TextDocumentIdentifier: protocol.TextDocumentIdentifier{URI: uri},
+				},
+				Edits: edits,
+			},
+		})
+	}
+	return documentChanges
+}
+
+// otherCommittedFiles returns the files an edit changed besides the given paths
+func otherCommittedFiles(result *utilities.WorkspaceEditResult, exclude ...string) []string {
+	var files []string
+	for _, path := range result.Committed {
+		excluded := false
+		for _, e := range exclude {
+			if path == e {
+				excluded = true
+				break
+			}
+		}
+		if !excluded {
+			files = append(files, path)
+		}
+	}
+	return files
+}
+
+// formatFileOperationResult describes a file operation and the edits made alongside it
+func formatFileOperationResult(summary string, updated []string, warning string) string {
+	var output strings.Builder
+	output.WriteString(summary + "\n")
+	if warning != "" {
+		output.WriteString(fmt.Sprintf("WARNING: %s\n", warning))
+	}
+	if len(updated) > 0 {
+		output.WriteString(fmt.Sprintf("Updated %d related files:\n", len(updated)))
+		for _, path := range updated {
+			output.WriteString(path + "\n")
+		}
+	}
+	return output.String()
+}
@@ -0,0 +1,38 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTextDocumentChanges(t *testing.T) {
+	insert := protocol.TextEdit{NewText: "export * from './b';\n"}
+	changes := textDocumentChanges(map[protocol.DocumentUri][]protocol.TextEdit{
+		"file:///ws/src/index.ts": {insert},
+		"file:///ws/a.ts":         {insert, insert},
+	})
+
+	require.Len(t, changes, 2)
+	assert.Equal(t, protocol.DocumentUri("file:///ws/a.ts"), changes[0].TextDocumentEdit.TextDocument.URI)
+	assert.Len(t, changes[0].TextDocumentEdit.Edits, 2)
+	assert.Equal(t, protocol.DocumentUri("file:///ws/src/index.ts"), changes[1].TextDocumentEdit.TextDocument.URI)
+
+	edit, err := changes[1].TextDocumentEdit.Edits[0].AsTextEdit()
+	require.NoError(t, err)
+	assert.Equal(t, insert, edit)
+
+	assert.Empty(t, textDocumentChanges(nil))
+}
+
+func TestFormatFileOperationResult(t *testing.T) {
+	assert.Equal(t, "Created /ws/b.ts\n", formatFileOperationResult("Created /ws/b.ts", nil, ""))
+	assert.Equal(t,
+		"Deleted /ws/b.ts\nUpdated 1 related files:\n/ws/index.ts\n",
+		formatFileOperationResult("Deleted /ws/b.ts", []string{"/ws/index.ts"}, ""))
+	assert.Equal(t,
+		"Created /ws/b.ts\nWARNING: server failed\n",
+		formatFileOperationResult("Created /ws/b.ts", nil, "server failed"))
+}
@@ -9,22 +9,37 @@ import (
 	"github.com/isaacphi/mcp-language-server/internal/protocol"
 )
 
-func ReadDefinition(ctx context.Context, client *lsp.Client, symbolName string) (string, error) {
+// Definition is the full source of a symbol's definition
+type Definition struct {
+	Symbol    string   `json:"symbol"`
+	Kind      string   `json:"kind,omitempty"`
+	Container string   `json:"container,omitempty"`
+	Location  Location `json:"location"`
+	Source    string   `json:"source"`
+}
+
+// DefinitionResult lists the definitions of the symbols matching a name
+type DefinitionResult struct {
+	Query       string       `json:"query"`
+	Definitions []Definition `json:"definitions"`
+}
+
+func ReadDefinition(ctx context.Context, client *lsp.Client, symbolName string) (*DefinitionResult, error) {
 	// First, use workspace/symbol to find where the symbol is referenced
 	// This gives us a starting position to query for the definition
 	symbolResult, err := client.Symbol(ctx, protocol.WorkspaceSymbolParams{
 		Query: symbolName,
 	})
 	if err != nil {
-		return "", fmt.Errorf("failed to fetch symbol: %v", err)
+		return nil, fmt.Errorf("failed to fetch symbol: %v", err)
 	}
 
 	results, err := symbolResult.Results()
 	if err != nil {
-		return "", fmt.Errorf("failed to parse results: %v", err)
+		return nil, fmt.Errorf("failed to parse results: %v", err)
 	}
 
-	var definitions []string
+	result := &DefinitionResult{Query: symbolName, Definitions: []Definition{}}
 	seenLocations := make(map[string]bool) // Track unique locations to avoid duplicates
 
 	for _, symbol := range results {
@@ -36,10 +51,8 @@ func ReadDefinition(ctx context.Context, client *lsp.Client, symbolName string)
 		switch v := symbol.(type) {
 		case *protocol.SymbolInformation:
 			// SymbolInformation results have richer data.
-			kind = fmt.Sprintf("Kind: %s\n", protocol.TableKindMap[v.Kind])
-			if v.ContainerName != "" {
-				container = fmt.Sprintf("Container Name: %s\n", v.ContainerName)
-			}
+			kind = protocol.TableKindMap[v.Kind]
+			container = v.ContainerName
 
 			// Check if this symbol matches what we're looking for
 			if !symbolMatches(symbolName, symbol.GetName(), v.Kind, v.ContainerName) {
@@ -101,37 +114,45 @@ func ReadDefinition(ctx context.Context, client *lsp.Client, symbolName string)
 				continue
 			}
 
-			banner := "---\n\n"
 			definition, finalLoc, err := GetFullDefinition(ctx, client, defLoc)
-			locationInfo := fmt.Sprintf(
-				"Symbol: %s\n"+
-					"File: %s\n"+
-					kind+
-					container+
-					"Range: L%d:C%d - L%d:C%d\n\n",
-				symbol.GetName(),
-				strings.TrimPrefix(string(finalLoc.URI), "file://"),
-				finalLoc.Range.Start.Line+1,
-				finalLoc.Range.Start.Character+1,
-				finalLoc.Range.End.Line+1,
-				finalLoc.Range.End.Character+1,
-			)
-
 			if err != nil {
 				toolsLogger.Error("Error getting full definition: %v", err)
 				continue
 			}
 
-			definition = addLineNumbers(definition, int(finalLoc.Range.Start.Line)+1)
-			definitions = append(definitions, banner+locationInfo+definition+"\n")
+			result.Definitions = append(result.Definitions, Definition{
+				Symbol:    symbol.GetName(),
+				Kind:      kind,
+				Container: container,
+				Location:  newLocation(finalLoc),
+				Source:    definition,
+			})
 		}
 	}
 
-	if len(definitions) == 0 {
-		return fmt.Sprintf("%s not found", symbolName), nil
+	return result, nil
+}
+
+func (r *DefinitionResult) Format() string {
+	if len(r.Definitions) == 0 {
+		return fmt.Sprintf("%s not found", r.Query)
 	}
 
-	return strings.Join(definitions, ""), nil
+	var output strings.Builder
+	for _, def := range r.Definitions {
+		output.WriteString("---\n\n")
+		output.WriteString(fmt.Sprintf("Symbol: %s\nFile: %s\n", def.Symbol, def.Location.Path))
+		if def.Kind != "" {
+			output.WriteString(fmt.Sprintf("Kind: %s\n", def.Kind))
+		}
+		if def.Container != "" {
+			output.WriteString(fmt.Sprintf("Container Name: %s\n", def.Container))
+		}
+		rng := def.Location.Range
+		output.WriteString(fmt.Sprintf("Range: L%d:C%d - L%d:C%d\n\n", rng.Start.Line, rng.Start.Column, rng.End.Line, rng.End.Column))
+		output.WriteString(addLineNumbers(def.Source, rng.Start.Line) + "\n")
+	}
+	return output.String()
 }
 
 // extractDefinitionLocations extracts Location objects from a Definition result
@@ -6,14 +6,82 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/isaacphi/mcp-language-server/internal/lsp"
 	"github.com/isaacphi/mcp-language-server/internal/protocol"
 )
 
-// GetDiagnosticsForFile retrieves diagnostics for a specific file from the language server
-func GetDiagnosticsForFile(ctx context.Context, client *lsp.Client, filePath string, contextLines int, showLineNumbers bool) (string, error) {
+// diagnosticListing is the numbered list of diagnostics last shown for a file,
+// which fix_diagnostic refers to by index
+type diagnosticListing struct {
+	diagnostics []protocol.Diagnostic
+	// contentHash identifies the file content the diagnostics were listed for
+	contentHash string
+}
+
+var (
+	diagnosticListingsMu sync.Mutex
+	diagnosticListings   = make(map[protocol.DocumentUri]diagnosticListing)
+)
+
+func recordDiagnosticListing(uri protocol.DocumentUri, diagnostics []protocol.Diagnostic, content []byte) {
+	diagnosticListingsMu.Lock()
+	defer diagnosticListingsMu.Unlock()
+	diagnosticListings[uri] = diagnosticListing{
+		diagnostics: append([]protocol.Diagnostic(nil), diagnostics...),
+		contentHash: ContentHash(content),
+	}
+}
+
+func lastDiagnosticListing(uri protocol.DocumentUri) (diagnosticListing, bool) {
+	diagnosticListingsMu.Lock()
+	defer diagnosticListingsMu.Unlock()
+	listing, ok := diagnosticListings[uri]
+	return listing, ok
+}
+
+// Diagnostic is a problem reported by the language server
+type Diagnostic struct {
+	Severity string `json:"severity"`
+	Range    Range  `json:"range"`
+	Message  string `json:"message"`
+	Source   string `json:"source,omitempty"`
+	Code     string `json:"code,omitempty"`
+}
+
+func newDiagnostic(diag protocol.Diagnostic) Diagnostic {
+	d := Diagnostic{
+		Severity: getSeverityString(diag.Severity),
+		Range:    newRange(diag.Range),
+		Message:  diag.Message,
+		Source:   diag.Source,
+	}
+	if diag.Code != nil {
+		d.Code = fmt.Sprintf("%v", diag.Code)
+	}
+	return d
+}
+
+// DiagnosticsResult lists the diagnostics of a file, numbered from 1 in order
+type DiagnosticsResult struct {
+	Path        string       `json:"path"`
+	Diagnostics []Diagnostic `json:"diagnostics"`
+	// Hash is the SHA-256 of the file content the diagnostics are shown in,
+	// for edit_file's expectedHash
+	Hash string `json:"hash,omitempty"`
+	// Error is set when the file couldn't be read to show the diagnostics in context
+	Error string `json:"error,omitempty"`
+
+	lines           []string
+	lineRanges      []LineRange
+	showLineNumbers bool
+}
+
+// GetDiagnosticsForFile retrieves diagnostics for a specific file from the language server.
+// Diagnostics are numbered so fix_diagnostic can refer to them by index.
+func GetDiagnosticsForFile(ctx context.Context, client *lsp.Client, filePath string, contextLines int, showLineNumbers bool) (*DiagnosticsResult, error) {
 	// Override with environment variable if specified
 	if envLines := os.Getenv("LSP_CONTEXT_LINES"); envLines != "" {
 		if val, err := strconv.Atoi(envLines); err == nil && val >= 0 {
@@ -23,7 +91,7 @@ func GetDiagnosticsForFile(ctx context.Context, client *lsp.Client, filePath str
 
 	err := client.OpenFile(ctx, filePath)
 	if err != nil {
-		return "", fmt.Errorf("could not open file: %v", err)
+		return nil, fmt.Errorf("could not open file: %v", err)
 	}
 
 	// Wait for diagnostics
@@ -45,43 +113,14 @@ func GetDiagnosticsForFile(ctx context.Context, client *lsp.Client, filePath str
 	// Get diagnostics from the cache
 	diagnostics := client.GetFileDiagnostics(uri)
 
+	result := &DiagnosticsResult{Path: filePath, Diagnostics: []Diagnostic{}, showLineNumbers: showLineNumbers}
 	if len(diagnostics) == 0 {
-		return "No diagnostics found for " + filePath, nil
+		return result, nil
 	}
 
-	// Format file header
-	fileInfo := fmt.Sprintf("%s\nDiagnostics in File: %d\n",
-		filePath,
-		len(diagnostics),
-	)
-
-	// Create a summary of all the diagnostics
-	var diagSummaries []string
 	var diagLocations []protocol.Location
-
 	for _, diag := range diagnostics {
-		severity := getSeverityString(diag.Severity)
-		location := fmt.Sprintf("L%d:C%d",
-			diag.Range.Start.Line+1,
-			diag.Range.Start.Character+1)
-
-		summary := fmt.Sprintf("%s at %s: %s",
-			severity,
-			location,
-			diag.Message)
-
-		// Add source and code if available
-		if diag.Source != "" {
-			summary += fmt.Sprintf(" (Source: %s", diag.Source)
-			if diag.Code != nil {
-				summary += fmt.Sprintf(", Code: %v", diag.Code)
-			}
-			summary += ")"
-		} else if diag.Code != nil {
-			summary += fmt.Sprintf(" (Code: %v)", diag.Code)
-		}
-
-		diagSummaries = append(diagSummaries, summary)
+		result.Diagnostics = append(result.Diagnostics, newDiagnostic(diag))
 
 		// Create a location for this diagnostic to use with line ranges
 		diagLocations = append(diagLocations, protocol.Location{
@@ -93,8 +132,11 @@ func GetDiagnosticsForFile(ctx context.Context, client *lsp.Client, filePath str
 	// Format content with context
 	fileContent, err := os.ReadFile(filePath)
 	if err != nil {
-		return fileInfo + "\nError reading file: " + err.Error(), nil
+		result.Error = err.Error()
+		return result, nil
 	}
+	recordDiagnosticListing(uri, diagnostics, fileContent)
+	result.Hash = ContentHash(fileContent)
 
 	lines := strings.Split(string(fileContent), "\n")
 
@@ -118,21 +160,64 @@ func GetDiagnosticsForFile(ctx context.Context, client *lsp.Client, filePath str
 		}
 	}
 
-	// Convert to line ranges
-	lineRanges := ConvertLinesToRanges(linesToShow, len(lines))
+	result.lines = lines
+	result.lineRanges = ConvertLinesToRanges(linesToShow, len(lines))
+	return result, nil
+}
+
+func (r *DiagnosticsResult) Format() string {
+	if len(r.Diagnostics) == 0 {
+		return "No diagnostics found for " + r.Path
+	}
+
+	// Format file header
+	result := fmt.Sprintf("%s\nDiagnostics in File: %d\n",
+		r.Path,
+		len(r.Diagnostics),
+	)
+	if r.Error != "" {
+		return result + "\nError reading file: " + r.Error
+	}
+
+	result += fmt.Sprintf("File hash: %s\n", r.Hash)
 
 	// Format with diagnostics summary in header
-	result := fileInfo
-	if len(diagSummaries) > 0 {
-		result += strings.Join(diagSummaries, "\n") + "\n"
+	for i, diag := range r.Diagnostics {
+		result += fmt.Sprintf("%d. %s\n", i+1, diag.summary())
 	}
 
 	// Format the content with ranges
-	if showLineNumbers {
-		result += "\n" + FormatLinesWithRanges(lines, lineRanges)
+	if r.showLineNumbers {
+		result += "\n" + FormatLinesWithRanges(r.lines, r.lineRanges)
 	}
 
-	return result, nil
+	return result
+}
+
+// formatDiagnostic summarizes a diagnostic on one line, plus any lines of a multi-line message
+func formatDiagnostic(diag protocol.Diagnostic) string {
+	return newDiagnostic(diag).summary()
+}
+
+// summary describes the diagnostic on one line, plus any lines of a multi-line message
+func (d Diagnostic) summary() string {
+	summary := fmt.Sprintf("%s at L%d:C%d: %s",
+		d.Severity,
+		d.Range.Start.Line,
+		d.Range.Start.Column,
+		d.Message)
+
+	// Add source and code if available
+	if d.Source != "" {
+		summary += fmt.Sprintf(" (Source: %s", d.Source)
+		if d.Code != "" {
+			summary += fmt.Sprintf(", Code: %s", d.Code)
+		}
+		summary += ")"
+	} else if d.Code != "" {
+		summary += fmt.Sprintf(" (Code: %s)", d.Code)
+	}
+	return summary
 }
 
 func getSeverityString(severity protocol.DiagnosticSeverity) string {
@@ -9,12 +9,32 @@ import (
 	"github.com/isaacphi/mcp-language-server/internal/protocol"
 )
 
+// DocumentSymbol is a symbol declared in a file. Symbols nested in another
+// follow it with a greater depth.
+type DocumentSymbol struct {
+	Name      string `json:"name"`
+	Kind      string `json:"kind"`
+	Detail    string `json:"detail,omitempty"`
+	Container string `json:"container,omitempty"`
+	Depth     int    `json:"depth"`
+	Range     Range  `json:"range"`
+
+	// flat is set for symbols reported as SymbolInformation, without a hierarchy
+	flat bool
+}
+
+// DocumentSymbolsResult is the symbol outline of a file
+type DocumentSymbolsResult struct {
+	Path    string           `json:"path"`
+	Symbols []DocumentSymbol `json:"symbols"`
+}
+
 // GetDocumentSymbols returns the hierarchical symbol outline of a file
-func GetDocumentSymbols(ctx context.Context, client *lsp.Client, filePath string) (string, error) {
+func GetDocumentSymbols(ctx context.Context, client *lsp.Client, filePath string) (*DocumentSymbolsResult, error) {
 	// Open the file if not already open
 	err := client.OpenFile(ctx, filePath)
 	if err != nil {
-		return "", fmt.Errorf("could not open file: %v", err)
+		return nil, fmt.Errorf("could not open file: %v", err)
 	}
 
 	params := protocol.DocumentSymbolParams{
@@ -26,87 +46,99 @@ func GetDocumentSymbols(ctx context.Context, client *lsp.Client, filePath string
 	// Execute the document symbol request
 	symbolResult, err := client.DocumentSymbol(ctx, params)
 	if err != nil {
-		return "", fmt.Errorf("failed to get document symbols: %v", err)
+		return nil, fmt.Errorf("failed to get document symbols: %v", err)
 	}
 
 	results, err := symbolResult.Results()
 	if err != nil {
-		return "", fmt.Errorf("failed to parse symbol results: %v", err)
-	}
-
-	if len(results) == 0 {
-		return "No symbols found", nil
+		return nil, fmt.Errorf("failed to parse symbol results: %v", err)
 	}
 
-	var output strings.Builder
-	output.WriteString(fmt.Sprintf("Document Symbols for %s:\n\n", filePath))
+	result := &DocumentSymbolsResult{Path: filePath, Symbols: []DocumentSymbol{}}
 
 	// Process results - could be DocumentSymbol[] (hierarchical) or SymbolInformation[] (flat)
 	for _, symbol := range results {
 		switch v := symbol.(type) {
 		case *protocol.DocumentSymbol:
 			// Hierarchical symbols with children
-			formatDocumentSymbol(&output, v, 0)
+			result.Symbols = appendDocumentSymbol(result.Symbols, v, "", 0)
 		case *protocol.SymbolInformation:
 			// Flat symbol information
-			formatSymbolInformation(&output, v)
+			result.Symbols = append(result.Symbols, DocumentSymbol{
+				Name:      v.Name,
+				Kind:      protocol.TableKindMap[v.Kind],
+				Container: v.ContainerName,
+				Range:     newRange(v.Location.Range),
+				flat:      true,
+			})
 		}
 	}
 
-	return output.String(), nil
+	return result, nil
 }
 
-// formatDocumentSymbol formats a hierarchical DocumentSymbol with indentation
-func formatDocumentSymbol(output *strings.Builder, symbol *protocol.DocumentSymbol, depth int) {
-	indent := strings.Repeat("│   ", depth)
-	if depth > 0 {
-		indent = strings.Repeat("│   ", depth-1) + "├── "
+// appendDocumentSymbol appends a hierarchical DocumentSymbol and its children
+func appendDocumentSymbol(symbols []DocumentSymbol, symbol *protocol.DocumentSymbol, container string, depth int) []DocumentSymbol {
+	symbols = append(symbols, DocumentSymbol{
+		Name:      symbol.Name,
+		Kind:      protocol.TableKindMap[symbol.Kind],
+		Detail:    symbol.Detail,
+		Container: container,
+		Depth:     depth,
+		Range:     newRange(symbol.Range),
+	})
+	for _, child := range symbol.Children {
+		symbols = appendDocumentSymbol(symbols, &child, symbol.Name, depth+1)
 	}
+	return symbols
+}
 
-	kindStr := protocol.TableKindMap[symbol.Kind]
-	startLine := symbol.Range.Start.Line + 1
-	endLine := symbol.Range.End.Line + 1
+func (r *DocumentSymbolsResult) Format() string {
+	if len(r.Symbols) == 0 {
+		return "No symbols found"
+	}
+
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("Document Symbols for %s:\n\n", r.Path))
+	for _, symbol := range r.Symbols {
+		if symbol.flat {
+			formatSymbolInformation(&output, symbol)
+		} else {
+			formatDocumentSymbol(&output, symbol)
+		}
+	}
+	return output.String()
+}
+
+// formatDocumentSymbol formats a hierarchical symbol with indentation
+func formatDocumentSymbol(output *strings.Builder, symbol DocumentSymbol) {
+	indent := ""
+	if symbol.Depth > 0 {
+		indent = strings.Repeat("│   ", symbol.Depth-1) + "├── "
+	}
 
 	// Format: ├── <kind> <name> [detail] [startLine:startCol-endLine:endCol]
-	line := fmt.Sprintf("%s%s %s", indent, kindStr, symbol.Name)
+	line := fmt.Sprintf("%s%s %s", indent, symbol.Kind, symbol.Name)
 
 	if symbol.Detail != "" {
 		line += fmt.Sprintf(" (%s)", symbol.Detail)
 	}
 
-	line += fmt.Sprintf(" [%d:%d-%d:%d]\n",
-		startLine,
-		symbol.Range.Start.Character+1,
-		endLine,
-		symbol.Range.End.Character+1,
-	)
-
-	output.WriteString(line)
-
-	// Recursively format children
-	for _, child := range symbol.Children {
-		formatDocumentSymbol(output, &child, depth+1)
-	}
+	output.WriteString(line + formatSymbolRange(symbol.Range))
 }
 
-// formatSymbolInformation formats a flat SymbolInformation
-func formatSymbolInformation(output *strings.Builder, symbol *protocol.SymbolInformation) {
-	kindStr := protocol.TableKindMap[symbol.Kind]
-	startLine := symbol.Location.Range.Start.Line + 1
-	endLine := symbol.Location.Range.End.Line + 1
+// formatSymbolInformation formats a flat symbol
+func formatSymbolInformation(output *strings.Builder, symbol DocumentSymbol) {
+	line := fmt.Sprintf("• %s %s", symbol.Kind, symbol.Name)
 
-	line := fmt.Sprintf("• %s %s", kindStr, symbol.Name)
-
-	if symbol.ContainerName != "" {
-		line += fmt.Sprintf(" (in %s)", symbol.ContainerName)
+	if symbol.Container != "" {
+		line += fmt.Sprintf(" (in %s)", symbol.Container)
 	}
 
-	line += fmt.Sprintf(" [%d:%d-%d:%d]\n",
-		startLine,
-		symbol.Location.Range.Start.Character+1,
-		endLine,
-		symbol.Location.Range.End.Character+1,
-	)
+	output.WriteString(line + formatSymbolRange(symbol.Range))
+}
 
-	output.WriteString(line)
+// formatSymbolRange formats a range as " [startLine:startCol-endLine:endCol]"
+func formatSymbolRange(rng Range) string {
+	return fmt.Sprintf(" [%d:%d-%d:%d]\n", rng.Start.Line, rng.Start.Column, rng.End.Line, rng.End.Column)
 }
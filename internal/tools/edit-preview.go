@@ -0,0 +1,121 @@
+package tools
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/isaacphi/mcp-language-server/internal/utilities"
+	"github.com/isaacphi/mcp-language-server/internal/watcher"
+)
+
+// workspaceRoot returns the workspace the client was started in, falling back
+// to the working directory
+func workspaceRoot(client *lsp.Client) string {
+	if client != nil && client.WorkspaceDir() != "" {
+		return client.WorkspaceDir()
+	}
+	dir, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+	return dir
+}
+
+// PreviewWorkspaceEdit renders the changes a workspace edit would make as
+// per-file unified diffs without writing anything to disk. Edits that land
+// outside the workspace or in gitignored or excluded directories are flagged.
+func PreviewWorkspaceEdit(client *lsp.Client, edit protocol.WorkspaceEdit) (*EditResult, error) {
+	root := workspaceRoot(client)
+	diffs, err := utilities.PreviewWorkspaceEdit(edit, root, utilities.DefaultDiffContext)
+	if err != nil {
+		return nil, fmt.Errorf("failed to preview changes: %v", err)
+	}
+	result := &EditResult{DryRun: true, Changes: diffChanges(diffs)}
+	if len(diffs) == 0 {
+		result.Message = "No files would change.\n"
+		return result, nil
+	}
+
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("Dry run: %d files would change, nothing was written.\n", len(diffs)))
+
+	var paths []string
+	for _, diff := range diffs {
+		if diff.OldPath != "" {
+			paths = append(paths, diff.OldPath)
+		}
+		paths = append(paths, diff.Path)
+	}
+	if warnings := editLocationWarnings(root, paths); len(warnings) > 0 {
+		output.WriteString("\nWARNING: some edits land in files that are usually not edited by hand:\n")
+		for _, warning := range warnings {
+			output.WriteString("  " + warning + "\n")
+		}
+	}
+
+	for _, diff := range diffs {
+		output.WriteString("\n")
+		if diff.Diff == "" {
+			// Pure renames have no content change
+			output.WriteString(fmt.Sprintf("rename %s -> %s\n", displayPath(root, diff.OldPath), displayPath(root, diff.Path)))
+			continue
+		}
+		output.WriteString(diff.Diff)
+	}
+	result.Message = output.String()
+	return result, nil
+}
+
+// editLocationWarnings describes paths that are outside the workspace or inside
+// gitignored or excluded directories such as vendor and node_modules
+func editLocationWarnings(root string, paths []string) []string {
+	excludedDirs := watcher.DefaultWatcherConfig().ExcludedDirs
+	gitignore, err := watcher.NewGitignoreMatcher(root)
+	if err != nil {
+		toolsLogger.Warn("Failed to load .gitignore from %s: %v", root, err)
+	}
+
+	var warnings []string
+	seen := make(map[string]bool)
+	for _, path := range paths {
+		if seen[path] {
+			continue
+		}
+		seen[path] = true
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			warnings = append(warnings, fmt.Sprintf("%s: outside the workspace", path))
+			continue
+		}
+
+		// Check each parent directory from the workspace root down, then the file itself
+		parts := strings.Split(rel, string(filepath.Separator))
+		for i, part := range parts {
+			isDir := i < len(parts)-1
+			current := filepath.Join(root, filepath.Join(parts[:i+1]...))
+			if isDir && excludedDirs[part] {
+				warnings = append(warnings, fmt.Sprintf("%s: in excluded directory %s", rel, part))
+				break
+			}
+			if gitignore != nil && gitignore.ShouldIgnore(current, isDir) {
+				warnings = append(warnings, fmt.Sprintf("%s: gitignored", rel))
+				break
+			}
+		}
+	}
+	return warnings
+}
+
+// displayPath shows paths inside the workspace relative to it
+func displayPath(root, path string) string {
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return path
+	}
+	return rel
+}
@@ -0,0 +1,32 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEditLocationWarnings(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, ".gitignore"), []byte("generated/\n*.pb.go\n"), 0644))
+
+	warnings := editLocationWarnings(root, []string{
+		filepath.Join(root, "main.go"),
+		filepath.Join(root, "pkg", "util.go"),
+		filepath.Join(root, "vendor", "lib", "lib.go"),
+		filepath.Join(root, "generated", "api.go"),
+		filepath.Join(root, "pkg", "api.pb.go"),
+		filepath.Join(filepath.Dir(root), "elsewhere.go"),
+		filepath.Join(root, "main.go"),
+	})
+
+	assert.Equal(t, []string{
+		filepath.Join("vendor", "lib", "lib.go") + ": in excluded directory vendor",
+		filepath.Join("generated", "api.go") + ": gitignored",
+		filepath.Join("pkg", "api.pb.go") + ": gitignored",
+		filepath.Join(filepath.Dir(root), "elsewhere.go") + ": outside the workspace",
+	}, warnings)
+}
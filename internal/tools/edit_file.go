@@ -3,6 +3,8 @@ package tools
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"os"
 	"sort"
@@ -14,15 +16,50 @@ import (
 )
 
 type TextEdit struct {
-	StartLine int    `json:"startLine" jsonschema:"required,description=Start line to replace, inclusive"`
-	EndLine   int    `json:"endLine" jsonschema:"required,description=End line to replace, inclusive"`
-	NewText   string `json:"newText" jsonschema:"description=Replacement text. Replace with the new text. Leave blank to remove lines."`
+	StartLine    int    `json:"startLine" jsonschema:"description=Start line to replace, inclusive"`
+	EndLine      int    `json:"endLine" jsonschema:"description=End line to replace, inclusive"`
+	NewText      string `json:"newText" jsonschema:"description=Replacement text. Replace with the new text. Leave blank to remove lines."`
+	ExpectedText string `json:"expectedText,omitempty" jsonschema:"description=Current content of the lines being replaced. The edit is rejected if the file no longer matches."`
+	OldText      string `json:"oldText,omitempty" jsonschema:"description=Text to replace, located by search instead of line numbers. Must occur exactly once in the file."`
 }
 
-func ApplyTextEdits(ctx context.Context, client *lsp.Client, filePath string, edits []TextEdit) (string, error) {
+// ApplyTextEdits applies line-based and search-and-replace edits to a file.
+//
+// If expectedHash is non-empty it must match the SHA-256 of the current file
+// content (a unique prefix of at least 8 hex characters is accepted), otherwise
+// the whole edit is rejected. Edits carrying ExpectedText are checked against
+// the lines they replace and rejected with a diff when the file has changed;
+// ExpectedText can't be combined with OldText. The result includes the hash of
+// the file after the edit, to pass as expectedHash to the next edit.
+func ApplyTextEdits(ctx context.Context, client *lsp.Client, filePath string, edits []TextEdit, expectedHash string) (*EditResult, error) {
+	if err := checkTextEdits(edits); err != nil {
+		return nil, err
+	}
+
 	err := client.OpenFile(ctx, filePath)
 	if err != nil {
-		return "", fmt.Errorf("could not open file: %v", err)
+		return nil, fmt.Errorf("could not open file: %v", err)
+	}
+
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	if expectedHash != "" {
+		if err := checkContentHash(content, expectedHash); err != nil {
+			return nil, err
+		}
+	}
+
+	// Verify content anchors before touching the file
+	for i, edit := range edits {
+		if edit.OldText != "" || edit.ExpectedText == "" {
+			continue
+		}
+		if err := verifyExpectedText(content, edit); err != nil {
+			return nil, fmt.Errorf("edit %d rejected: %w", i+1, err)
+		}
 	}
 
 	// Create a sorted copy of edits for reporting
@@ -36,8 +73,11 @@ func ApplyTextEdits(ctx context.Context, client *lsp.Client, filePath string, ed
 	linesRemovedSorted := 0
 	linesAddedSorted := 0
 	for _, edit := range sortedEdits {
-		// Calculate lines removed: end - start + 1
+		// Calculate lines removed: end - start + 1, or the lines spanned by the searched text
 		removedLineCount := edit.EndLine - edit.StartLine + 1
+		if edit.OldText != "" {
+			removedLineCount = strings.Count(edit.OldText, "\n") + 1
+		}
 		linesRemovedSorted += removedLineCount
 
 		// Calculate lines added: count newlines in the replacement text + 1
@@ -59,10 +99,23 @@ func ApplyTextEdits(ctx context.Context, client *lsp.Client, filePath string, ed
 	// Convert from input format to protocol.TextEdit
 	var textEdits []protocol.TextEdit
 	for _, edit := range edits {
+		// Search-and-replace edits locate their range from the old text
+		if edit.OldText != "" {
+			rng, err := findTextRange(content, edit.OldText)
+			if err != nil {
+				return nil, err
+			}
+			textEdits = append(textEdits, protocol.TextEdit{
+				Range:   rng,
+				NewText: edit.NewText,
+			})
+			continue
+		}
+
 		// Get the range covering the requested lines
 		rng, err := getRange(edit.StartLine, edit.EndLine, filePath)
 		if err != nil {
-			return "", fmt.Errorf("invalid position: %v", err)
+			return nil, fmt.Errorf("invalid position: %v", err)
 		}
 
 		// Always do a replacement
@@ -78,11 +131,143 @@ func ApplyTextEdits(ctx context.Context, client *lsp.Client, filePath string, ed
 		},
 	}
 
-	if err := utilities.ApplyWorkspaceEdit(edit); err != nil {
-		return "", fmt.Errorf("failed to apply text edits: %v", err)
+	result, err := utilities.ApplyJournaledWorkspaceEdit("edit_file", filePath, edit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply text edits: %v", err)
+	}
+
+	// The hash of the content the edit left lets the next edit pass expectedHash
+	newContent := content
+	if result.Entry != nil {
+		if after, ok := result.Entry.Content(filePath); ok {
+			newContent = after
+		} else if newContent, err = os.ReadFile(filePath); err != nil {
+			return nil, fmt.Errorf("failed to read file after edit: %w", err)
+		}
+	}
+	hash := ContentHash(newContent)
+	summary := fmt.Sprintf("Successfully applied text edits. %d lines removed, %d lines added.\nFile hash: %s",
+		linesRemovedSorted, linesAddedSorted, hash)
+
+	editResult := journaledEdits(client, summary, result.Entry)
+	editResult.Hash = hash
+	return editResult, nil
+}
+
+// checkTextEdits rejects edits whose arguments contradict each other
+func checkTextEdits(edits []TextEdit) error {
+	for i, edit := range edits {
+		if edit.OldText != "" && edit.ExpectedText != "" {
+			return fmt.Errorf("edit %d rejected: expectedText can't be combined with oldText, which already has to match the file", i+1)
+		}
 	}
+	return nil
+}
 
-	return fmt.Sprintf("Successfully applied text edits. %d lines removed, %d lines added.", linesRemovedSorted, linesAddedSorted), nil
+// ContentHash returns the hex-encoded SHA-256 of file content
+func ContentHash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// checkContentHash verifies that content matches an expected hash or hash prefix
+func checkContentHash(content []byte, expectedHash string) error {
+	expected := strings.ToLower(strings.TrimSpace(expectedHash))
+	if len(expected) < minHashPrefixLength {
+		return fmt.Errorf("expectedHash must have at least %d hex characters", minHashPrefixLength)
+	}
+
+	actual := ContentHash(content)
+	if !strings.HasPrefix(actual, expected) {
+		return fmt.Errorf("file has changed since it was read (expected hash %s, current hash %s); re-read the file and retry", expected, actual)
+	}
+	return nil
+}
+
+// verifyExpectedText checks that the lines targeted by a line-based edit still
+// contain the text the caller expects, returning a diff if they do not
+func verifyExpectedText(content []byte, edit TextEdit) error {
+	lines := strings.Split(normalizeLineEndings(string(content)), "\n")
+	if edit.StartLine < 1 || edit.StartLine > len(lines) {
+		return fmt.Errorf("start line %d is outside the file (%d lines)", edit.StartLine, len(lines))
+	}
+
+	endLine := edit.EndLine
+	if endLine > len(lines) {
+		endLine = len(lines)
+	}
+	if endLine < edit.StartLine {
+		return fmt.Errorf("end line %d is before start line %d", edit.EndLine, edit.StartLine)
+	}
+
+	actual := strings.Join(lines[edit.StartLine-1:endLine], "\n")
+	expected := strings.TrimSuffix(normalizeLineEndings(edit.ExpectedText), "\n")
+	if actual == expected {
+		return nil
+	}
+
+	diff := utilities.UnifiedDiff(
+		"expected",
+		fmt.Sprintf("actual (lines %d-%d)", edit.StartLine, endLine),
+		expected+"\n",
+		actual+"\n",
+		utilities.DefaultDiffContext,
+	)
+	return fmt.Errorf("expectedText does not match the current file content:\n%s", diff)
+}
+
+// findTextRange locates a unique occurrence of oldText in content and returns its range
+func findTextRange(content []byte, oldText string) (protocol.Range, error) {
+	text := normalizeLineEndings(string(content))
+	needle := normalizeLineEndings(oldText)
+
+	count := strings.Count(text, needle)
+	if count == 0 {
+		return protocol.Range{}, fmt.Errorf("oldText not found in file: %q", truncateForError(oldText))
+	}
+	if count > 1 {
+		var lines []string
+		searchFrom := 0
+		for {
+			idx := strings.Index(text[searchFrom:], needle)
+			if idx < 0 {
+				break
+			}
+			pos := searchFrom + idx
+			lines = append(lines, fmt.Sprintf("L%d", strings.Count(text[:pos], "\n")+1))
+			searchFrom = pos + len(needle)
+		}
+		return protocol.Range{}, fmt.Errorf("oldText matches %d locations (%s); include more surrounding text to make it unique",
+			count, strings.Join(lines, ", "))
+	}
+
+	start := strings.Index(text, needle)
+	return protocol.Range{
+		Start: offsetToPosition(text, start),
+		End:   offsetToPosition(text, start+len(needle)),
+	}, nil
+}
+
+// offsetToPosition converts a byte offset in LF-normalized text to a position
+func offsetToPosition(text string, offset int) protocol.Position {
+	line := strings.Count(text[:offset], "\n")
+	lineStart := strings.LastIndex(text[:offset], "\n") + 1
+	return protocol.Position{
+		Line:      uint32(line),
+		Character: uint32(offset - lineStart),
+	}
+}
+
+func normalizeLineEndings(text string) string {
+	return strings.ReplaceAll(text, "\r\n", "\n")
+}
+
+func truncateForError(text string) string {
+	const maxLen = 80
+	if len(text) <= maxLen {
+		return text
+	}
+	return text[:maxLen] + "..."
 }
 
 // getRange creates a protocol.Range that covers the specified start and end lines
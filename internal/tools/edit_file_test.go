@@ -0,0 +1,82 @@
+package tools
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindTextRange(t *testing.T) {
+	content := []byte("package main\n\nfunc a() {\n\treturn\n}\n\nfunc b() {\n\treturn\n}\n")
+
+	t.Run("unique match spanning lines", func(t *testing.T) {
+		rng, err := findTextRange(content, "func b() {\n\treturn")
+		require.NoError(t, err)
+		assert.Equal(t, protocol.Position{Line: 6, Character: 0}, rng.Start)
+		assert.Equal(t, protocol.Position{Line: 7, Character: 7}, rng.End)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		_, err := findTextRange(content, "func c()")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not found")
+	})
+
+	t.Run("ambiguous match lists locations", func(t *testing.T) {
+		_, err := findTextRange(content, "\treturn")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "matches 2 locations (L4, L8)")
+	})
+
+	t.Run("CRLF content matches LF search text", func(t *testing.T) {
+		crlf := []byte(strings.ReplaceAll(string(content), "\n", "\r\n"))
+		rng, err := findTextRange(crlf, "func a() {\n")
+		require.NoError(t, err)
+		assert.Equal(t, protocol.Position{Line: 2, Character: 0}, rng.Start)
+		assert.Equal(t, protocol.Position{Line: 3, Character: 0}, rng.End)
+	})
+}
+
+func TestVerifyExpectedText(t *testing.T) {
+	content := []byte("one\ntwo\nthree\n")
+
+	t.Run("matching lines", func(t *testing.T) {
+		err := verifyExpectedText(content, TextEdit{StartLine: 2, EndLine: 3, ExpectedText: "two\nthree\n"})
+		assert.NoError(t, err)
+	})
+
+	t.Run("mismatch reports diff", func(t *testing.T) {
+		err := verifyExpectedText(content, TextEdit{StartLine: 2, EndLine: 2, ExpectedText: "TWO"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "-TWO\n+two\n")
+	})
+
+	t.Run("start line outside file", func(t *testing.T) {
+		err := verifyExpectedText(content, TextEdit{StartLine: 10, EndLine: 10, ExpectedText: "x"})
+		assert.Error(t, err)
+	})
+}
+
+func TestCheckContentHash(t *testing.T) {
+	content := []byte("hello\n")
+	hash := ContentHash(content)
+
+	assert.NoError(t, checkContentHash(content, hash))
+	assert.NoError(t, checkContentHash(content, strings.ToUpper(hash[:12])))
+	assert.Error(t, checkContentHash(content, hash[:4]), "short prefixes are rejected")
+	assert.Error(t, checkContentHash([]byte("changed\n"), hash))
+}
+
+func TestCheckTextEdits(t *testing.T) {
+	assert.NoError(t, checkTextEdits([]TextEdit{
+		{StartLine: 1, EndLine: 1, NewText: "a", ExpectedText: "b"},
+		{OldText: "c", NewText: "d"},
+	}))
+	assert.EqualError(t, checkTextEdits([]TextEdit{
+		{StartLine: 1, EndLine: 1, NewText: "a"},
+		{OldText: "c", NewText: "d", ExpectedText: "c"},
+	}), "edit 2 rejected: expectedText can't be combined with oldText, which already has to match the file")
+}
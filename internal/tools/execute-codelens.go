@@ -7,14 +7,15 @@ import (
 
 	"github.com/isaacphi/mcp-language-server/internal/lsp"
 	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/isaacphi/mcp-language-server/internal/utilities"
 )
 
 // ExecuteCodeLens executes a specific code lens command from a file.
-func ExecuteCodeLens(ctx context.Context, client *lsp.Client, filePath string, index int) (string, error) {
+func ExecuteCodeLens(ctx context.Context, client *lsp.Client, filePath string, index int) (*EditResult, error) {
 	// Open the file
 	err := client.OpenFile(ctx, filePath)
 	if err != nil {
-		return "", fmt.Errorf("could not open file: %v", err)
+		return nil, fmt.Errorf("could not open file: %v", err)
 	}
 	// TODO: find a more appropriate way to wait
 	time.Sleep(time.Second)
@@ -29,15 +30,15 @@ func ExecuteCodeLens(ctx context.Context, client *lsp.Client, filePath string, i
 	}
 	codeLenses, err := client.CodeLens(ctx, params)
 	if err != nil {
-		return "", fmt.Errorf("failed to get code lenses: %v", err)
+		return nil, fmt.Errorf("failed to get code lenses: %v", err)
 	}
 
 	if len(codeLenses) == 0 {
-		return "", fmt.Errorf("no code lenses found in file")
+		return nil, fmt.Errorf("no code lenses found in file")
 	}
 
 	if index < 1 || index > len(codeLenses) {
-		return "", fmt.Errorf("invalid code lens index: %d. Available range: 1-%d", index, len(codeLenses))
+		return nil, fmt.Errorf("invalid code lens index: %d. Available range: 1-%d", index, len(codeLenses))
 	}
 
 	lens := codeLenses[index-1]
@@ -46,23 +47,24 @@ func ExecuteCodeLens(ctx context.Context, client *lsp.Client, filePath string, i
 	if lens.Command == nil {
 		resolvedLens, err := client.ResolveCodeLens(ctx, lens)
 		if err != nil {
-			return "", fmt.Errorf("failed to resolve code lens: %v", err)
+			return nil, fmt.Errorf("failed to resolve code lens: %v", err)
 		}
 		lens = resolvedLens
 	}
 
 	if lens.Command == nil {
-		return "", fmt.Errorf("code lens has no command after resolution")
+		return nil, fmt.Errorf("code lens has no command after resolution")
 	}
 
-	// Execute the command
+	// Execute the command. Its edits arrive through workspace/applyEdit
+	since := utilities.DefaultJournal.LastID()
 	_, err = client.ExecuteCommand(ctx, protocol.ExecuteCommandParams{
 		Command:   lens.Command.Command,
 		Arguments: lens.Command.Arguments,
 	})
 	if err != nil {
-		return "", fmt.Errorf("failed to execute code lens command: %v", err)
+		return nil, fmt.Errorf("failed to execute code lens command: %v", err)
 	}
 
-	return fmt.Sprintf("Successfully executed code lens command: %s", lens.Command.Title), nil
+	return journaledEdits(client, fmt.Sprintf("Successfully executed code lens command: %s", lens.Command.Title), serverEdits(since)...), nil
 }
@@ -0,0 +1,275 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+// skeletonSymbol is a declaration whose body can be collapsed or expanded
+type skeletonSymbol struct {
+	name string
+	// qualified is the name prefixed with its containers, e.g. "Server.Start"
+	qualified string
+	kind      protocol.SymbolKind
+	rng       protocol.Range
+}
+
+// collapsedFold is a body hidden in a skeleton, from the line it starts on
+// through the last line it consumes
+type collapsedFold struct {
+	start, end int
+	// head and tail are the text kept before and after the placeholder
+	head, tail string
+}
+
+// LineSpan is a 1-indexed, inclusive range of lines
+type LineSpan struct {
+	StartLine int `json:"startLine"`
+	EndLine   int `json:"endLine"`
+}
+
+// FileSkeletonResult is a file with its function bodies collapsed. Source
+// is the numbered skeleton; Collapsed lists the hidden lines and Missing the
+// symbols to expand that weren't found.
+type FileSkeletonResult struct {
+	Path      string `json:"path"`
+	LineCount int    `json:"lineCount"`
+	// Hash is the SHA-256 of the file content, for edit_file's expectedHash
+	Hash      string     `json:"hash"`
+	Collapsed []LineSpan `json:"collapsed"`
+	Missing   []string   `json:"missing,omitempty"`
+	Source    string     `json:"source"`
+}
+
+// GetFileSkeleton returns the source of a file with function and method bodies
+// collapsed to "{ ... }", keeping the original line numbers. Bodies are found
+// with folding ranges inside the callable document symbols; the bodies of the
+// symbols named in expand, and anything they contain, are shown in full.
+func GetFileSkeleton(ctx context.Context, client *lsp.Client, filePath string, expand []string) (*FileSkeletonResult, error) {
+	err := client.OpenFile(ctx, filePath)
+	if err != nil {
+		return nil, fmt.Errorf("could not open file: %v", err)
+	}
+
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %v", err)
+	}
+
+	uri := protocol.DocumentUri("file://" + filePath)
+	symbolResult, err := client.DocumentSymbol(ctx, protocol.DocumentSymbolParams{
+		TextDocument: protocol.TextDocumentIdentifier{URI: uri},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get document symbols: %v", err)
+	}
+	results, err := symbolResult.Results()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse symbol results: %v", err)
+	}
+
+	folds, err := client.FoldingRange(ctx, protocol.FoldingRangeParams{
+		TextDocument: protocol.TextDocumentIdentifier{URI: uri},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get folding ranges: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+	collapsed, missing := skeletonFolds(lines, flattenSkeletonSymbols(results), folds, expand)
+
+	result := &FileSkeletonResult{
+		Path:      filePath,
+		LineCount: len(lines),
+		Hash:      ContentHash(content),
+		Collapsed: []LineSpan{},
+		Missing:   missing,
+		Source:    renderSkeleton(lines, collapsed),
+	}
+	for _, fold := range collapsed {
+		result.Collapsed = append(result.Collapsed, LineSpan{StartLine: fold.start + 1, EndLine: fold.end + 1})
+	}
+	return result, nil
+}
+
+func (r *FileSkeletonResult) Format() string {
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("Skeleton of %s (%d lines, %d bodies collapsed):\n", r.Path, r.LineCount, len(r.Collapsed)))
+	output.WriteString(fmt.Sprintf("File hash: %s\n", r.Hash))
+	if len(r.Missing) > 0 {
+		output.WriteString(fmt.Sprintf("Symbols to expand not found: %s\n", strings.Join(r.Missing, ", ")))
+	}
+	output.WriteString("\n")
+	output.WriteString(r.Source)
+	return output.String()
+}
+
+// flattenSkeletonSymbols lists document symbols with their qualified names
+func flattenSkeletonSymbols(results []protocol.DocumentSymbolResult) []skeletonSymbol {
+	var symbols []skeletonSymbol
+	var walk func(symbol *protocol.DocumentSymbol, container string)
+	walk = func(symbol *protocol.DocumentSymbol, container string) {
+		qualified := symbol.Name
+		if container != "" {
+			qualified = container + "." + symbol.Name
+		}
+		symbols = append(symbols, skeletonSymbol{
+			name:      symbol.Name,
+			qualified: qualified,
+			kind:      symbol.Kind,
+			rng:       symbol.Range,
+		})
+		for i := range symbol.Children {
+			walk(&symbol.Children[i], qualified)
+		}
+	}
+
+	for _, result := range results {
+		switch v := result.(type) {
+		case *protocol.DocumentSymbol:
+			walk(v, "")
+		case *protocol.SymbolInformation:
+			qualified := v.Name
+			if v.ContainerName != "" {
+				qualified = v.ContainerName + "." + v.Name
+			}
+			symbols = append(symbols, skeletonSymbol{
+				name:      v.Name,
+				qualified: qualified,
+				kind:      v.Kind,
+				rng:       v.Location.Range,
+			})
+		}
+	}
+	return symbols
+}
+
+// skeletonFolds picks the body of each callable symbol to collapse: the largest
+// code folding range inside it. Bodies overlapping an expanded symbol or an
+// already collapsed body are kept. The expand names that matched no symbol are
+// returned as well.
+func skeletonFolds(lines []string, symbols []skeletonSymbol, folds []protocol.FoldingRange, expand []string) ([]collapsedFold, []string) {
+	var expanded []protocol.Range
+	var missing []string
+	for _, name := range expand {
+		found := false
+		for _, symbol := range symbols {
+			if skeletonSymbolMatches(symbol, name) {
+				expanded = append(expanded, symbol.rng)
+				found = true
+			}
+		}
+		if !found {
+			missing = append(missing, name)
+		}
+	}
+
+	var bodies []protocol.FoldingRange
+	for _, symbol := range symbols {
+		if !callableKinds[symbol.kind] {
+			continue
+		}
+		best := -1
+		for i, fold := range folds {
+			if fold.Kind == string(protocol.Comment) || fold.Kind == string(protocol.Imports) ||
+				fold.EndLine <= fold.StartLine ||
+				fold.StartLine < symbol.rng.Start.Line || fold.EndLine > symbol.rng.End.Line {
+				continue
+			}
+			if best < 0 || fold.EndLine-fold.StartLine > folds[best].EndLine-folds[best].StartLine {
+				best = i
+			}
+		}
+		if best >= 0 {
+			bodies = append(bodies, folds[best])
+		}
+	}
+	sort.SliceStable(bodies, func(i, j int) bool {
+		if bodies[i].StartLine != bodies[j].StartLine {
+			return bodies[i].StartLine < bodies[j].StartLine
+		}
+		return bodies[i].EndLine > bodies[j].EndLine
+	})
+
+	var collapsed []collapsedFold
+	for _, body := range bodies {
+		if len(collapsed) > 0 && int(body.StartLine) <= collapsed[len(collapsed)-1].end {
+			continue
+		}
+		overlapsExpanded := false
+		for _, r := range expanded {
+			if body.StartLine <= r.End.Line && body.EndLine >= r.Start.Line {
+				overlapsExpanded = true
+				break
+			}
+		}
+		if overlapsExpanded || int(body.EndLine) >= len(lines) {
+			continue
+		}
+		collapsed = append(collapsed, collapseFold(lines, body))
+	}
+	return collapsed, missing
+}
+
+// collapseFold works out the text kept around a collapsed body. With character
+// positions the fold runs from after the opening brace to the closing one;
+// with whole lines the first line stays visible and a following line holding
+// only closing brackets is joined to it.
+func collapseFold(lines []string, fold protocol.FoldingRange) collapsedFold {
+	start, end := int(fold.StartLine), int(fold.EndLine)
+	result := collapsedFold{start: start, end: end, head: lines[start]}
+	if fold.StartCharacter > 0 {
+		result.head = lines[start][:byteOffset(lines[start], int(fold.StartCharacter))]
+	}
+
+	if fold.EndCharacter > 0 {
+		result.tail = strings.TrimSpace(lines[end][byteOffset(lines[end], int(fold.EndCharacter)):])
+	} else if fold.StartCharacter > 0 {
+		result.tail = strings.TrimSpace(lines[end])
+	} else if end+1 < len(lines) && isClosingLine(lines[end+1]) {
+		result.tail = strings.TrimSpace(lines[end+1])
+		result.end = end + 1
+	}
+	return result
+}
+
+// isClosingLine reports whether a line holds nothing but closing brackets and
+// punctuation, like "}" or "});"
+func isClosingLine(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return trimmed != "" && strings.Trim(trimmed, "})];,") == ""
+}
+
+// skeletonSymbolMatches reports whether name refers to a symbol, by its own
+// name or its qualified name. Go receivers such as "(*Server).Start" also
+// match "Server.Start".
+func skeletonSymbolMatches(symbol skeletonSymbol, name string) bool {
+	return name == symbol.name || name == symbol.qualified ||
+		name == normalizeSymbolName(symbol.name) || name == normalizeSymbolName(symbol.qualified)
+}
+
+// renderSkeleton numbers the lines of a file, replacing collapsed bodies with
+// a placeholder on the line they start on
+func renderSkeleton(lines []string, collapsed []collapsedFold) string {
+	width := len(strconv.Itoa(len(lines)))
+	var output strings.Builder
+	next := 0
+	for i := 0; i < len(lines); i++ {
+		number, text := i+1, lines[i]
+		if next < len(collapsed) && collapsed[next].start == i {
+			fold := collapsed[next]
+			text = strings.TrimRight(strings.TrimRight(fold.head, " \t")+" ... "+fold.tail, " ")
+			i = fold.end
+			next++
+		}
+		output.WriteString(fmt.Sprintf("%*d|%s\n", width, number, text))
+	}
+	return output.String()
+}
@@ -0,0 +1,111 @@
+package tools
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileSkeletonCharacterFolds(t *testing.T) {
+	lines := strings.Split(`package main
+
+import (
+	"fmt"
+)
+
+// Server serves.
+type Server struct {
+	addr string
+}
+
+func (s *Server) Start() error {
+	fmt.Println(s.addr)
+	return nil
+}
+
+func main() {
+	run := func() {
+		fmt.Println("hi")
+	}
+	run()
+}`, "\n")
+
+	symbols := flattenSkeletonSymbols([]protocol.DocumentSymbolResult{
+		&protocol.DocumentSymbol{Name: "Server", Kind: protocol.Struct, Range: lineRange(7, 9)},
+		&protocol.DocumentSymbol{Name: "(*Server).Start", Kind: protocol.Method, Range: lineRange(11, 14)},
+		&protocol.DocumentSymbol{Name: "main", Kind: protocol.Function, Range: lineRange(16, 21)},
+	})
+	folds := []protocol.FoldingRange{
+		{StartLine: 2, StartCharacter: 8, EndLine: 4, Kind: string(protocol.Imports)},
+		{StartLine: 7, StartCharacter: 20, EndLine: 9},
+		{StartLine: 11, StartCharacter: 32, EndLine: 14},
+		{StartLine: 16, StartCharacter: 13, EndLine: 21},
+		{StartLine: 17, StartCharacter: 15, EndLine: 19, EndCharacter: 1},
+	}
+
+	collapsed, missing := skeletonFolds(lines, symbols, folds, nil)
+	assert.Empty(t, missing)
+	assert.Equal(t, ` 1|package main
+ 2|
+ 3|import (
+ 4|	"fmt"
+ 5|)
+ 6|
+ 7|// Server serves.
+ 8|type Server struct {
+ 9|	addr string
+10|}
+11|
+12|func (s *Server) Start() error { ... }
+16|
+17|func main() { ... }
+`, renderSkeleton(lines, collapsed))
+
+	collapsed, missing = skeletonFolds(lines, symbols, folds, []string{"Server.Start", "missing"})
+	assert.Equal(t, []string{"missing"}, missing)
+	rendered := renderSkeleton(lines, collapsed)
+	assert.Contains(t, rendered, "13|\tfmt.Println(s.addr)\n")
+	assert.Contains(t, rendered, "17|func main() { ... }\n")
+}
+
+func TestFileSkeletonLineFolds(t *testing.T) {
+	lines := strings.Split(`class Handler:
+    def __init__(self):
+        self.count = 0
+
+    def handle(self, request):
+        self.count += 1
+        return request`, "\n")
+
+	symbols := flattenSkeletonSymbols([]protocol.DocumentSymbolResult{
+		&protocol.DocumentSymbol{Name: "Handler", Kind: protocol.Class, Range: lineRange(0, 6), Children: []protocol.DocumentSymbol{
+			{Name: "__init__", Kind: protocol.Method, Range: lineRange(1, 2)},
+			{Name: "handle", Kind: protocol.Method, Range: lineRange(4, 6)},
+		}},
+	})
+	folds := []protocol.FoldingRange{
+		{StartLine: 0, EndLine: 6},
+		{StartLine: 1, EndLine: 2},
+		{StartLine: 4, EndLine: 6},
+	}
+
+	collapsed, _ := skeletonFolds(lines, symbols, folds, []string{"Handler.__init__"})
+	assert.Equal(t, `1|class Handler:
+2|    def __init__(self):
+3|        self.count = 0
+4|
+5|    def handle(self, request): ...
+`, renderSkeleton(lines, collapsed))
+}
+
+func TestCollapseFoldJoinsClosingLine(t *testing.T) {
+	lines := []string{"function f() {", "  return 1;", "}", ""}
+	fold := collapseFold(lines, protocol.FoldingRange{StartLine: 0, EndLine: 1})
+	assert.Equal(t, collapsedFold{start: 0, end: 2, head: "function f() {", tail: "}"}, fold)
+}
+
+func lineRange(start, end uint32) protocol.Range {
+	return protocol.Range{Start: protocol.Position{Line: start}, End: protocol.Position{Line: end, Character: 1}}
+}
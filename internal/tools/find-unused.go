@@ -0,0 +1,506 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf16"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+const (
+	// DefaultFindUnusedConcurrency is how many requests find_unused has in flight by default
+	DefaultFindUnusedConcurrency = 4
+	// MaxFindUnusedConcurrency caps the requests in flight so the server isn't overwhelmed
+	MaxFindUnusedConcurrency = 16
+)
+
+// FindUnusedOptions selects the files and symbols FindUnused checks
+type FindUnusedOptions struct {
+	// FilePaths and Glob select the files to check, as for organize_imports
+	FilePaths []string
+	Glob      string
+	// IncludeTests checks test files and test functions too
+	IncludeTests bool
+	// IncludeMain checks entry points such as main and init too
+	IncludeMain bool
+	// IncludeInterfaceMethods checks methods declared in interfaces too
+	IncludeInterfaceMethods bool
+	// ExcludeAnnotations skips symbols whose declaration or the comment and
+	// annotation lines directly above it contain any of these strings, e.g.
+	// "@Override", "//export" or "#[no_mangle]"
+	ExcludeAnnotations []string
+	// Concurrency is the number of requests sent to the server at once
+	Concurrency int
+}
+
+// ProgressFunc is called as a long-running tool makes progress
+type ProgressFunc func(done, total int, message string)
+
+// unusedCandidate is a symbol whose references are checked
+type unusedCandidate struct {
+	path      string
+	name      string
+	kind      protocol.SymbolKind
+	container string
+	// declaration spans the whole symbol; references inside it, such as
+	// recursive calls, don't count as uses
+	declaration protocol.Range
+	// position is where the symbol's name is declared
+	position protocol.Position
+}
+
+// unusedSymbolKinds are the kinds of symbols checked. Fields, properties and
+// local variables are left out since they are mostly used implicitly.
+var unusedSymbolKinds = map[protocol.SymbolKind]bool{
+	protocol.Function:  true,
+	protocol.Method:    true,
+	protocol.Class:     true,
+	protocol.Struct:    true,
+	protocol.Interface: true,
+	protocol.Enum:      true,
+	protocol.Constant:  true,
+	protocol.Variable:  true,
+}
+
+// unusedContainerKinds are the kinds whose members are checked as well
+var unusedContainerKinds = map[protocol.SymbolKind]bool{
+	protocol.Class:     true,
+	protocol.Struct:    true,
+	protocol.Interface: true,
+	protocol.Enum:      true,
+	protocol.Module:    true,
+	protocol.Namespace: true,
+	protocol.Package:   true,
+}
+
+// UnusedSymbol is a symbol with no references outside its own declaration
+type UnusedSymbol struct {
+	Path      string `json:"path"`
+	Line      int    `json:"line"`
+	Name      string `json:"name"`
+	Container string `json:"container,omitempty"`
+	Kind      string `json:"kind"`
+}
+
+// FindUnusedResult lists the unused symbols among those checked. Failures
+// describe the files and symbols that could not be checked.
+type FindUnusedResult struct {
+	Files    int            `json:"files"`
+	Checked  int            `json:"checked"`
+	Unused   []UnusedSymbol `json:"unused"`
+	Failures []string       `json:"failures,omitempty"`
+	Page     *Page          `json:"page,omitempty"`
+}
+
+// FindUnused reports symbols that have no references outside their own
+// declaration.
+//
+// Symbols are enumerated per file with textDocument/documentSymbol, then
+// textDocument/references is requested for each one, with at most
+// opts.Concurrency requests in flight. progress, if not nil, is called as
+// each file is listed and each symbol is checked.
+func FindUnused(ctx context.Context, client *lsp.Client, opts FindUnusedOptions, progress ProgressFunc) (*FindUnusedResult, error) {
+	if len(opts.FilePaths) == 0 && opts.Glob == "" {
+		return nil, fmt.Errorf("either filePaths or glob is required")
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = DefaultFindUnusedConcurrency
+	}
+	if opts.Concurrency > MaxFindUnusedConcurrency {
+		opts.Concurrency = MaxFindUnusedConcurrency
+	}
+	if progress == nil {
+		progress = func(done, total int, message string) {}
+	}
+
+	root := workspaceRoot(client)
+	files, err := collectFiles(root, opts.FilePaths, opts.Glob)
+	if err != nil {
+		return nil, err
+	}
+	if !opts.IncludeTests {
+		files = filterTestFiles(files)
+	}
+	result := &FindUnusedResult{Unused: []UnusedSymbol{}}
+	if len(files) == 0 {
+		return result, nil
+	}
+
+	var candidates []unusedCandidate
+	var failures []string
+	for i, path := range files {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		progress(i, len(files), fmt.Sprintf("Listing symbols in %s", displayPath(root, path)))
+
+		found, err := fileUnusedCandidates(ctx, client, path, opts)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", displayPath(root, path), err))
+			continue
+		}
+		candidates = append(candidates, found...)
+	}
+
+	references := func(ctx context.Context, candidate unusedCandidate) ([]protocol.Location, error) {
+		return client.References(ctx, protocol.ReferenceParams{
+			TextDocumentPositionParams: protocol.TextDocumentPositionParams{
+				TextDocument: protocol.TextDocumentIdentifier{URI: protocol.DocumentUri("file://" + candidate.path)},
+				Position:     candidate.position,
+			},
+			Context: protocol.ReferenceContext{IncludeDeclaration: false},
+		})
+	}
+	// Progress keeps counting up from the files listed so it never goes back
+	checkProgress := func(done, total int, message string) {
+		progress(len(files)+done, len(files)+total, message)
+	}
+	unused, checkFailures, err := checkUnusedCandidates(ctx, candidates, opts.Concurrency, references, checkProgress)
+	if err != nil {
+		return nil, err
+	}
+	for _, failure := range checkFailures {
+		failures = append(failures, fmt.Sprintf("%s:%d %s: %v", displayPath(root, failure.candidate.path),
+			failure.candidate.position.Line+1, failure.candidate.name, failure.err))
+	}
+
+	result.Files = len(files)
+	result.Checked = len(candidates)
+	result.Failures = failures
+	for _, candidate := range unused {
+		result.Unused = append(result.Unused, UnusedSymbol{
+			Path:      displayPath(root, candidate.path),
+			Line:      int(candidate.position.Line) + 1,
+			Name:      candidate.name,
+			Container: candidate.container,
+			Kind:      symbolKindToString(candidate.kind),
+		})
+	}
+	return result, nil
+}
+
+func (r *FindUnusedResult) Format() string {
+	if r.Files == 0 {
+		return "No files to check"
+	}
+
+	unused := len(r.Unused)
+	if r.Page != nil {
+		unused = r.Page.Total
+	}
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("Checked %d symbols in %d files, %d have no references outside their own declaration:\n",
+		r.Checked, r.Files, unused))
+	for _, symbol := range r.Unused {
+		output.WriteString(formatUnusedSymbol(symbol))
+	}
+	output.WriteString(r.Page.Format())
+	if len(r.Failures) > 0 {
+		output.WriteString(fmt.Sprintf("\n%d could not be checked:\n", len(r.Failures)))
+		for _, failure := range r.Failures {
+			output.WriteString(failure + "\n")
+		}
+	}
+	output.WriteString("\nSymbols used only through reflection, interface satisfaction or generated code may be listed even though they are needed.\n")
+	return output.String()
+}
+
+func (r *FindUnusedResult) pageItems() []pageItem {
+	items := make([]pageItem, len(r.Unused))
+	for i, symbol := range r.Unused {
+		items[i] = pageItem{file: symbol.Path, size: len(formatUnusedSymbol(symbol))}
+	}
+	return items
+}
+
+func (r *FindUnusedResult) keepPage(start, end int, page *Page) {
+	r.Unused, r.Page = r.Unused[start:end], page
+}
+
+// formatUnusedSymbol shows an unused symbol's location, qualified name and kind
+func formatUnusedSymbol(symbol UnusedSymbol) string {
+	name := symbol.Name
+	if symbol.Container != "" {
+		name = symbol.Container + "." + name
+	}
+	return fmt.Sprintf("%s:L%d: %s (%s)\n", symbol.Path, symbol.Line, name, symbol.Kind)
+}
+
+// fileUnusedCandidates lists the symbols in a file that should be checked
+func fileUnusedCandidates(ctx context.Context, client *lsp.Client, path string, opts FindUnusedOptions) ([]unusedCandidate, error) {
+	if err := client.OpenFile(ctx, path); err != nil {
+		return nil, fmt.Errorf("could not open file: %v", err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %v", err)
+	}
+
+	result, err := client.DocumentSymbol(ctx, protocol.DocumentSymbolParams{
+		TextDocument: protocol.TextDocumentIdentifier{URI: protocol.DocumentUri("file://" + path)},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get document symbols: %v", err)
+	}
+	symbols, err := result.Results()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse symbol results: %v", err)
+	}
+	return unusedCandidates(path, string(content), symbols, opts), nil
+}
+
+// unusedCandidates picks the symbols to check from a file's document symbols
+func unusedCandidates(path, content string, symbols []protocol.DocumentSymbolResult, opts FindUnusedOptions) []unusedCandidate {
+	lines := strings.Split(content, "\n")
+	var candidates []unusedCandidate
+
+	add := func(candidate unusedCandidate) {
+		if !unusedSymbolKinds[candidate.kind] || candidate.name == "" || candidate.name == "_" {
+			return
+		}
+		if !opts.IncludeMain && isEntryPoint(candidate.name) {
+			return
+		}
+		if !opts.IncludeTests && isTestSymbol(candidate.name) {
+			return
+		}
+		if hasAnnotation(lines, candidate.declaration, candidate.position, opts.ExcludeAnnotations) {
+			return
+		}
+		candidate.path = path
+		candidates = append(candidates, candidate)
+	}
+
+	var walk func(symbol *protocol.DocumentSymbol, container *protocol.DocumentSymbol)
+	walk = func(symbol *protocol.DocumentSymbol, container *protocol.DocumentSymbol) {
+		candidate := unusedCandidate{
+			name:        symbol.Name,
+			kind:        symbol.Kind,
+			declaration: symbol.Range,
+			position:    symbol.SelectionRange.Start,
+		}
+		if container != nil {
+			candidate.container = container.Name
+		}
+		skip := container != nil && container.Kind == protocol.Interface && !opts.IncludeInterfaceMethods
+		if !skip {
+			add(candidate)
+		}
+		if unusedContainerKinds[symbol.Kind] {
+			for i := range symbol.Children {
+				walk(&symbol.Children[i], symbol)
+			}
+		}
+	}
+
+	for _, result := range symbols {
+		switch symbol := result.(type) {
+		case *protocol.DocumentSymbol:
+			walk(symbol, nil)
+		case *protocol.SymbolInformation:
+			// Flat symbols don't say where the name is, so look for it in the declaration
+			position, ok := namePosition(lines, symbol.Location.Range, symbol.Name)
+			if !ok {
+				continue
+			}
+			add(unusedCandidate{
+				name:        symbol.Name,
+				kind:        symbol.Kind,
+				container:   symbol.ContainerName,
+				declaration: symbol.Location.Range,
+				position:    position,
+			})
+		}
+	}
+	return candidates
+}
+
+// unusedCheckFailure is a symbol whose references could not be requested
+type unusedCheckFailure struct {
+	candidate unusedCandidate
+	err       error
+}
+
+// checkUnusedCandidates requests the references of each candidate with at most
+// concurrency requests in flight, and returns the candidates without any
+// outside their declaration, sorted by location
+func checkUnusedCandidates(ctx context.Context, candidates []unusedCandidate, concurrency int,
+	references func(context.Context, unusedCandidate) ([]protocol.Location, error), progress ProgressFunc,
+) ([]unusedCandidate, []unusedCheckFailure, error) {
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		unused   []unusedCandidate
+		failures []unusedCheckFailure
+		done     int
+	)
+	sem := make(chan struct{}, concurrency)
+
+	for _, candidate := range candidates {
+		if ctx.Err() != nil {
+			break
+		}
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(candidate unusedCandidate) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			locations, err := references(ctx, candidate)
+
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err != nil:
+				failures = append(failures, unusedCheckFailure{candidate: candidate, err: err})
+			case !hasExternalReference(candidate, locations):
+				unused = append(unused, candidate)
+			}
+			done++
+			progress(done, len(candidates), fmt.Sprintf("Checked references to %s", candidate.name))
+		}(candidate)
+	}
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	sort.Slice(unused, func(i, j int) bool {
+		return candidateLess(unused[i], unused[j])
+	})
+	sort.Slice(failures, func(i, j int) bool {
+		return candidateLess(failures[i].candidate, failures[j].candidate)
+	})
+	return unused, failures, nil
+}
+
+func candidateLess(a, b unusedCandidate) bool {
+	if a.path != b.path {
+		return a.path < b.path
+	}
+	if a.position.Line != b.position.Line {
+		return a.position.Line < b.position.Line
+	}
+	return a.position.Character < b.position.Character
+}
+
+// hasExternalReference reports whether any reference lies outside the
+// candidate's own declaration
+func hasExternalReference(candidate unusedCandidate, locations []protocol.Location) bool {
+	uri := protocol.DocumentUri("file://" + candidate.path)
+	for _, location := range locations {
+		if location.URI == uri &&
+			(containsPosition(candidate.declaration, location.Range.Start) || location.Range.Start == candidate.position) {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// testFilePattern matches test files of common languages
+var testFilePattern = regexp.MustCompile(`(_test\.go|_test\.py|^test_.*\.py|\.(test|spec)\.[cm]?[jt]sx?|Test\.java|Tests?\.cs|_spec\.rb)$`)
+
+// filterTestFiles drops test files and files in test directories
+func filterTestFiles(files []string) []string {
+	var kept []string
+	for _, path := range files {
+		if testFilePattern.MatchString(filepath.Base(path)) {
+			continue
+		}
+		dir := filepath.ToSlash(filepath.Dir(path))
+		if strings.Contains(dir+"/", "/__tests__/") || strings.Contains(dir+"/", "/testdata/") {
+			continue
+		}
+		kept = append(kept, path)
+	}
+	return kept
+}
+
+// isEntryPoint reports whether a symbol is called by the runtime rather than by code
+func isEntryPoint(name string) bool {
+	switch name {
+	case "main", "init", "__init__", "__main__":
+		return true
+	}
+	return false
+}
+
+// testSymbolPattern matches test, benchmark, example and fuzz functions
+var testSymbolPattern = regexp.MustCompile(`^(Test|Benchmark|Example|Fuzz)([A-Z_]|$)|^test_`)
+
+func isTestSymbol(name string) bool {
+	return testSymbolPattern.MatchString(name)
+}
+
+// hasAnnotation reports whether the declaration's first line, or the comment
+// and annotation lines directly above its name, contain any of annotations
+func hasAnnotation(lines []string, declaration protocol.Range, position protocol.Position, annotations []string) bool {
+	if len(annotations) == 0 {
+		return false
+	}
+
+	var block []string
+	start := int(declaration.Start.Line)
+	if int(position.Line) < start {
+		start = int(position.Line)
+	}
+	for line := start; line <= int(position.Line) && line < len(lines); line++ {
+		block = append(block, lines[line])
+	}
+	for line := start - 1; line >= 0 && line < len(lines); line-- {
+		text := strings.TrimSpace(lines[line])
+		if !isAnnotationLine(text) {
+			break
+		}
+		block = append(block, text)
+	}
+
+	for _, text := range block {
+		for _, annotation := range annotations {
+			if annotation != "" && strings.Contains(text, annotation) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// namePosition finds where name first occurs in a range
+func namePosition(lines []string, r protocol.Range, name string) (protocol.Position, bool) {
+	for line := int(r.Start.Line); line <= int(r.End.Line) && line < len(lines); line++ {
+		text := lines[line]
+		offset := 0
+		if line == int(r.Start.Line) {
+			offset = byteOffset(text, int(r.Start.Character))
+		}
+		if i := strings.Index(text[offset:], name); i >= 0 {
+			return protocol.Position{
+				Line:      uint32(line),
+				Character: uint32(len(utf16.Encode([]rune(text[:offset+i])))),
+			}, true
+		}
+	}
+	return protocol.Position{}, false
+}
+
+// byteOffset converts a UTF-16 character offset in a line to a byte offset
+func byteOffset(line string, character int) int {
+	units := 0
+	for i, r := range line {
+		if units >= character {
+			return i
+		}
+		units += len(utf16.Encode([]rune{r}))
+	}
+	return len(line)
+}
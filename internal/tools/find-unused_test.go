@@ -0,0 +1,169 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func symbolAt(name string, kind protocol.SymbolKind, start, end uint32, children ...protocol.DocumentSymbol) protocol.DocumentSymbol {
+	return protocol.DocumentSymbol{
+		Name:           name,
+		Kind:           kind,
+		Range:          protocol.Range{Start: protocol.Position{Line: start}, End: protocol.Position{Line: end}},
+		SelectionRange: protocol.Range{Start: protocol.Position{Line: start, Character: 5}},
+		Children:       children,
+	}
+}
+
+func TestUnusedCandidates(t *testing.T) {
+	content := "package main\n" + // 0
+		"\n" + // 1
+		"func main() {}\n" + // 2
+		"\n" + // 3
+		"//export Exported\n" + // 4
+		"func Exported() {}\n" + // 5
+		"func helper() {}\n" + // 6
+		"type Reader interface {\n" + // 7
+		"\tRead() error\n" + // 8
+		"}\n" + // 9
+		"type Server struct {\n" + // 10
+		"\taddr string\n" + // 11
+		"}\n" + // 12
+		"func TestHelper() {}\n" // 13
+
+	server := symbolAt("Server", protocol.Struct, 10, 12, symbolAt("addr", protocol.Field, 11, 11))
+	reader := symbolAt("Reader", protocol.Interface, 7, 9, symbolAt("Read", protocol.Method, 8, 8))
+	documentSymbols := []protocol.DocumentSymbol{
+		symbolAt("main", protocol.Function, 2, 2),
+		symbolAt("Exported", protocol.Function, 5, 5),
+		symbolAt("helper", protocol.Function, 6, 6, symbolAt("local", protocol.Variable, 6, 6)),
+		reader,
+		server,
+		symbolAt("TestHelper", protocol.Function, 13, 13),
+	}
+	symbols := make([]protocol.DocumentSymbolResult, len(documentSymbols))
+	for i := range documentSymbols {
+		symbols[i] = &documentSymbols[i]
+	}
+
+	names := func(candidates []unusedCandidate) []string {
+		var result []string
+		for _, candidate := range candidates {
+			if candidate.container != "" {
+				result = append(result, candidate.container+"."+candidate.name)
+			} else {
+				result = append(result, candidate.name)
+			}
+		}
+		return result
+	}
+
+	candidates := unusedCandidates("/ws/main.go", content, symbols, FindUnusedOptions{ExcludeAnnotations: []string{"//export"}})
+	assert.Equal(t, []string{"helper", "Reader", "Server"}, names(candidates))
+	assert.Equal(t, "/ws/main.go", candidates[0].path)
+
+	candidates = unusedCandidates("/ws/main.go", content, symbols, FindUnusedOptions{
+		IncludeTests:            true,
+		IncludeMain:             true,
+		IncludeInterfaceMethods: true,
+	})
+	assert.Equal(t, []string{"main", "Exported", "helper", "Reader", "Reader.Read", "Server", "TestHelper"}, names(candidates))
+}
+
+func TestUnusedCandidates_SymbolInformation(t *testing.T) {
+	content := "def unused():\n    pass\n"
+	symbols := []protocol.DocumentSymbolResult{
+		&protocol.SymbolInformation{
+			Name: "unused",
+			Kind: protocol.Function,
+			Location: protocol.Location{
+				URI:   "file:///ws/app.py",
+				Range: protocol.Range{End: protocol.Position{Line: 1, Character: 8}},
+			},
+		},
+	}
+	candidates := unusedCandidates("/ws/app.py", content, symbols, FindUnusedOptions{})
+	require.Len(t, candidates, 1)
+	assert.Equal(t, protocol.Position{Line: 0, Character: 4}, candidates[0].position)
+}
+
+func TestHasExternalReference(t *testing.T) {
+	candidate := unusedCandidate{
+		path:        "/ws/fib.go",
+		declaration: protocol.Range{Start: protocol.Position{Line: 2}, End: protocol.Position{Line: 7, Character: 1}},
+		position:    protocol.Position{Line: 2, Character: 5},
+	}
+	recursive := protocol.Location{URI: "file:///ws/fib.go", Range: protocol.Range{Start: protocol.Position{Line: 5, Character: 9}}}
+	caller := protocol.Location{URI: "file:///ws/main.go", Range: protocol.Range{Start: protocol.Position{Line: 3, Character: 1}}}
+
+	assert.False(t, hasExternalReference(candidate, nil))
+	assert.False(t, hasExternalReference(candidate, []protocol.Location{recursive}), "recursive calls are not uses")
+	assert.True(t, hasExternalReference(candidate, []protocol.Location{recursive, caller}))
+}
+
+func TestCheckUnusedCandidates(t *testing.T) {
+	var candidates []unusedCandidate
+	for i := 0; i < 20; i++ {
+		candidates = append(candidates, unusedCandidate{
+			path:     "/ws/a.go",
+			name:     fmt.Sprintf("f%d", i),
+			position: protocol.Position{Line: uint32(20 - i)},
+		})
+	}
+
+	var inFlight, maxInFlight int32
+	references := func(ctx context.Context, candidate unusedCandidate) ([]protocol.Location, error) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			max := atomic.LoadInt32(&maxInFlight)
+			if n <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, n) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+
+		switch {
+		case candidate.name == "f3":
+			return nil, fmt.Errorf("server error")
+		case candidate.position.Line%2 == 0:
+			return []protocol.Location{{URI: "file:///ws/b.go"}}, nil
+		}
+		return nil, nil
+	}
+
+	var progressCalls int32
+	var lastDone, lastTotal int
+	progress := func(done, total int, message string) {
+		atomic.AddInt32(&progressCalls, 1)
+		lastDone, lastTotal = done, total
+	}
+
+	unused, failures, err := checkUnusedCandidates(context.Background(), candidates, 3, references, progress)
+	require.NoError(t, err)
+	assert.LessOrEqual(t, maxInFlight, int32(3))
+	require.Len(t, failures, 1)
+	assert.Equal(t, "f3", failures[0].candidate.name)
+	assert.Len(t, unused, 9)
+	for i := 1; i < len(unused); i++ {
+		assert.Less(t, unused[i-1].position.Line, unused[i].position.Line, "results are sorted by location")
+	}
+	assert.Equal(t, int32(20), progressCalls)
+	assert.Equal(t, 20, lastDone)
+	assert.Equal(t, 20, lastTotal)
+}
+
+func TestFilterTestFiles(t *testing.T) {
+	files := []string{
+		"/ws/main.go", "/ws/main_test.go", "/ws/app.py", "/ws/test_app.py",
+		"/ws/src/view.ts", "/ws/src/view.test.ts", "/ws/src/__tests__/util.ts", "/ws/pkg/testdata/x.go",
+	}
+	assert.Equal(t, []string{"/ws/main.go", "/ws/app.py", "/ws/src/view.ts"}, filterTestFiles(files))
+}
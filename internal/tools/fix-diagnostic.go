@@ -0,0 +1,116 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+// FixDiagnostic applies a quick fix for a diagnostic, given by its 1-based
+// index in the last diagnostics listing for the file.
+//
+// Quick fixes are requested for the diagnostic's range with the diagnostic in
+// the code action context. If the server offers several fixes and none is
+// preferred, the choices are listed instead and nothing is applied; pass
+// actionIndex to pick one of them.
+func FixDiagnostic(ctx context.Context, client *lsp.Client, filePath string, index, actionIndex int) (*EditResult, error) {
+	err := client.OpenFile(ctx, filePath)
+	if err != nil {
+		return nil, fmt.Errorf("could not open file: %v", err)
+	}
+
+	uri := protocol.DocumentUri("file://" + filePath)
+	diag, err := listedDiagnostic(uri, filePath, index)
+	if err != nil {
+		return nil, err
+	}
+
+	params := protocol.CodeActionParams{
+		TextDocument: protocol.TextDocumentIdentifier{URI: uri},
+		Range:        diag.Range,
+		Context: protocol.CodeActionContext{
+			Diagnostics: []protocol.Diagnostic{diag},
+			Only:        []protocol.CodeActionKind{protocol.QuickFix},
+		},
+	}
+
+	items, err := client.CodeAction(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get code actions: %v", err)
+	}
+
+	choices := quickFixChoices(items)
+	description := fmt.Sprintf("diagnostic #%d (%s)", index, formatDiagnostic(diag))
+	if len(choices) == 0 {
+		return noEdits(fmt.Sprintf("No quick fixes available for %s", description)), nil
+	}
+
+	action, ok, err := chooseCodeAction(choices, actionIndex)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		var output strings.Builder
+		output.WriteString(fmt.Sprintf("%d quick fixes are available for %s.\n", len(choices), description))
+		output.WriteString("Call fix_diagnostic again with actionIndex set to one of:\n")
+		output.WriteString(formatCodeActionChoices(choices))
+		return noEdits(output.String()), nil
+	}
+
+	action, err = resolveCodeAction(ctx, client, action)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve code action: %v", err)
+	}
+	result, err := applyCodeAction(ctx, client, "fix_diagnostic", action)
+	if err != nil {
+		return nil, err
+	}
+	return journaledEdits(client, fmt.Sprintf("Applied quick fix %q for %s", action.Title, description), result.entries...), nil
+}
+
+// listedDiagnostic returns the diagnostic with the given 1-based index from
+// the last listing, refusing if the file changed since it was listed
+func listedDiagnostic(uri protocol.DocumentUri, filePath string, index int) (protocol.Diagnostic, error) {
+	listing, ok := lastDiagnosticListing(uri)
+	if !ok {
+		return protocol.Diagnostic{}, fmt.Errorf("no diagnostics have been listed for %s, run the diagnostics tool first", filePath)
+	}
+
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return protocol.Diagnostic{}, fmt.Errorf("failed to read file: %v", err)
+	}
+	if ContentHash(content) != listing.contentHash {
+		return protocol.Diagnostic{}, fmt.Errorf("%s changed since its diagnostics were listed, run the diagnostics tool again", filePath)
+	}
+
+	if index < 1 || index > len(listing.diagnostics) {
+		return protocol.Diagnostic{}, fmt.Errorf("diagnostic index %d is out of range, the last listing has %d diagnostics", index, len(listing.diagnostics))
+	}
+	return listing.diagnostics[index-1], nil
+}
+
+// quickFixChoices returns the enabled quick fix actions in a code action
+// response. Actions without a kind are included since not every server sets one.
+func quickFixChoices(items []protocol.Or_Result_textDocument_codeAction_Item0_Elem) []protocol.CodeAction {
+	var choices []protocol.CodeAction
+	for _, item := range items {
+		value, ok := item.Value.(map[string]any)
+		if !ok {
+			continue
+		}
+		action, err := decodeCodeAction(value)
+		if err != nil || action.Disabled != nil {
+			continue
+		}
+		if action.Kind != "" && !codeActionKindMatches(action.Kind, protocol.QuickFix) {
+			continue
+		}
+		choices = append(choices, action)
+	}
+	return choices
+}
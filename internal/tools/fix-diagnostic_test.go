@@ -0,0 +1,62 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuickFixChoices(t *testing.T) {
+	items := []protocol.Or_Result_textDocument_codeAction_Item0_Elem{
+		{Value: map[string]any{"title": "Add import", "kind": "quickfix", "isPreferred": true}},
+		{Value: map[string]any{"title": "Extract function", "kind": "refactor.extract"}},
+		{Value: map[string]any{"title": "Ignore error", "kind": "quickfix", "disabled": map[string]any{"reason": "not here"}}},
+		{Value: map[string]any{"title": "Fix spelling"}},
+		{Value: map[string]any{"title": "Bare command", "command": "fix"}},
+	}
+
+	choices := quickFixChoices(items)
+	require.Len(t, choices, 2, "only enabled quick fix code actions are choices")
+	assert.Equal(t, "Add import", choices[0].Title)
+	assert.Equal(t, "Fix spelling", choices[1].Title)
+
+	preferred, ok := preferredCodeAction(choices)
+	require.True(t, ok)
+	assert.Equal(t, "Add import", preferred.Title)
+
+	choices[1].IsPreferred = true
+	_, ok = preferredCodeAction(choices)
+	assert.False(t, ok, "several preferred actions leave the choice to the caller")
+}
+
+func TestListedDiagnostic(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "main.go")
+	require.NoError(t, os.WriteFile(path, []byte("package main\n"), 0644))
+	uri := protocol.DocumentUri("file://" + path)
+
+	_, err := listedDiagnostic(uri, path, 1)
+	assert.ErrorContains(t, err, "run the diagnostics tool first")
+
+	diagnostics := []protocol.Diagnostic{
+		{Message: "first"},
+		{Message: "second"},
+	}
+	recordDiagnosticListing(uri, diagnostics, []byte("package main\n"))
+
+	diag, err := listedDiagnostic(uri, path, 2)
+	require.NoError(t, err)
+	assert.Equal(t, "second", diag.Message)
+
+	_, err = listedDiagnostic(uri, path, 3)
+	assert.ErrorContains(t, err, "out of range")
+	_, err = listedDiagnostic(uri, path, 0)
+	assert.ErrorContains(t, err, "out of range")
+
+	require.NoError(t, os.WriteFile(path, []byte("package other\n"), 0644))
+	_, err = listedDiagnostic(uri, path, 1)
+	assert.ErrorContains(t, err, "changed since its diagnostics were listed")
+}
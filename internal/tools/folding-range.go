@@ -9,12 +9,28 @@ import (
 	"github.com/isaacphi/mcp-language-server/internal/protocol"
 )
 
+// FoldingRange is a foldable region of a file. Columns are omitted when the
+// server doesn't give them.
+type FoldingRange struct {
+	Kind        string `json:"kind"`
+	StartLine   int    `json:"startLine"`
+	EndLine     int    `json:"endLine"`
+	StartColumn int    `json:"startColumn,omitempty"`
+	EndColumn   int    `json:"endColumn,omitempty"`
+}
+
+// FoldingRangesResult lists the folding ranges of a file
+type FoldingRangesResult struct {
+	Path   string         `json:"path"`
+	Ranges []FoldingRange `json:"ranges"`
+}
+
 // GetFoldingRanges retrieves folding ranges for a document
-func GetFoldingRanges(ctx context.Context, client *lsp.Client, filePath string) (string, error) {
+func GetFoldingRanges(ctx context.Context, client *lsp.Client, filePath string) (*FoldingRangesResult, error) {
 	// Open the file if not already open
 	err := client.OpenFile(ctx, filePath)
 	if err != nil {
-		return "", fmt.Errorf("could not open file: %v", err)
+		return nil, fmt.Errorf("could not open file: %v", err)
 	}
 
 	params := protocol.FoldingRangeParams{
@@ -26,37 +42,51 @@ func GetFoldingRanges(ctx context.Context, client *lsp.Client, filePath string)
 	// Execute the folding range request
 	foldingRanges, err := client.FoldingRange(ctx, params)
 	if err != nil {
-		return "", fmt.Errorf("failed to get folding ranges: %v", err)
-	}
-
-	if len(foldingRanges) == 0 {
-		return "No folding ranges found", nil
+		return nil, fmt.Errorf("failed to get folding ranges: %v", err)
 	}
 
-	var output strings.Builder
-	output.WriteString(fmt.Sprintf("Folding Ranges for %s:\n\n", filePath))
-
-	for i, fr := range foldingRanges {
-		// Convert from 0-indexed to 1-indexed for display
-		startLine := fr.StartLine + 1
-		endLine := fr.EndLine + 1
-
+	result := &FoldingRangesResult{Path: filePath, Ranges: []FoldingRange{}}
+	for _, fr := range foldingRanges {
 		kind := fr.Kind
 		if kind == "" {
 			kind = "region"
 		}
 
+		// Convert from 0-indexed to 1-indexed
+		foldingRange := FoldingRange{
+			Kind:      kind,
+			StartLine: int(fr.StartLine) + 1,
+			EndLine:   int(fr.EndLine) + 1,
+		}
+		if fr.StartCharacter > 0 || fr.EndCharacter > 0 {
+			foldingRange.StartColumn = int(fr.StartCharacter) + 1
+			foldingRange.EndColumn = int(fr.EndCharacter) + 1
+		}
+		result.Ranges = append(result.Ranges, foldingRange)
+	}
+	return result, nil
+}
+
+func (r *FoldingRangesResult) Format() string {
+	if len(r.Ranges) == 0 {
+		return "No folding ranges found"
+	}
+
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("Folding Ranges for %s:\n\n", r.Path))
+
+	for i, fr := range r.Ranges {
 		output.WriteString(fmt.Sprintf("%d. %s: lines %d-%d",
-			i+1, kind, startLine, endLine))
+			i+1, fr.Kind, fr.StartLine, fr.EndLine))
 
 		// Add character positions if specified
-		if fr.StartCharacter > 0 || fr.EndCharacter > 0 {
+		if fr.StartColumn > 0 {
 			output.WriteString(fmt.Sprintf(" (chars %d-%d)",
-				fr.StartCharacter+1, fr.EndCharacter+1))
+				fr.StartColumn, fr.EndColumn))
 		}
 
 		output.WriteString("\n")
 	}
 
-	return output.String(), nil
+	return output.String()
 }
@@ -20,7 +20,9 @@ import (
 // For "full" mode, the range parameters are ignored.
 // For "range" mode, startLine/startCol and endLine/endCol define the range.
 // For "ontype" mode, startLine/startCol define the position, and triggerChar is the typed character.
-func FormatDocument(ctx context.Context, client *lsp.Client, filePath string, mode string, startLine, startCol, endLine, endCol int, triggerChar string) (string, error) {
+//
+// If dryRun is true, the formatting edits are rendered as a unified diff and nothing is written to disk.
+func FormatDocument(ctx context.Context, client *lsp.Client, filePath string, mode string, startLine, startCol, endLine, endCol int, triggerChar string, dryRun bool) (*EditResult, error) {
 	// Validate mode
 	validModes := map[string]bool{
 		"full":   true,
@@ -28,13 +30,13 @@ func FormatDocument(ctx context.Context, client *lsp.Client, filePath string, mo
 		"ontype": true,
 	}
 	if !validModes[mode] {
-		return "", fmt.Errorf("invalid mode '%s': must be one of 'full', 'range', or 'ontype'", mode)
+		return nil, fmt.Errorf("invalid mode '%s': must be one of 'full', 'range', or 'ontype'", mode)
 	}
 
 	// Open the file if not already open
 	err := client.OpenFile(ctx, filePath)
 	if err != nil {
-		return "", fmt.Errorf("could not open file: %v", err)
+		return nil, fmt.Errorf("could not open file: %v", err)
 	}
 
 	uri := protocol.DocumentUri("file://" + filePath)
@@ -56,7 +58,7 @@ func FormatDocument(ctx context.Context, client *lsp.Client, filePath string, mo
 
 		edits, err = client.Formatting(ctx, params)
 		if err != nil {
-			return "", fmt.Errorf("failed to format document: %v", err)
+			return nil, fmt.Errorf("failed to format document: %v", err)
 		}
 
 	case "range":
@@ -83,13 +85,13 @@ func FormatDocument(ctx context.Context, client *lsp.Client, filePath string, mo
 
 		edits, err = client.RangeFormatting(ctx, params)
 		if err != nil {
-			return "", fmt.Errorf("failed to format range: %v", err)
+			return nil, fmt.Errorf("failed to format range: %v", err)
 		}
 
 	case "ontype":
 		// Format on type
 		if triggerChar == "" {
-			return "", fmt.Errorf("triggerChar is required for ontype formatting")
+			return nil, fmt.Errorf("triggerChar is required for ontype formatting")
 		}
 
 		params := protocol.DocumentOnTypeFormattingParams{
@@ -109,12 +111,12 @@ func FormatDocument(ctx context.Context, client *lsp.Client, filePath string, mo
 
 		edits, err = client.OnTypeFormatting(ctx, params)
 		if err != nil {
-			return "", fmt.Errorf("failed to format on type: %v", err)
+			return nil, fmt.Errorf("failed to format on type: %v", err)
 		}
 	}
 
 	if len(edits) == 0 {
-		return "No formatting changes needed.", nil
+		return noEdits("No formatting changes needed."), nil
 	}
 
 	// Apply the edits
@@ -124,8 +126,18 @@ func FormatDocument(ctx context.Context, client *lsp.Client, filePath string, mo
 		},
 	}
 
-	if err := utilities.ApplyWorkspaceEdit(workspaceEdit); err != nil {
-		return "", fmt.Errorf("failed to apply formatting changes: %v", err)
+	if dryRun {
+		preview, err := PreviewWorkspaceEdit(client, workspaceEdit)
+		if err != nil {
+			return nil, err
+		}
+		preview.Message = fmt.Sprintf("Formatting (%s mode) would apply %d change(s):\n%s", mode, len(edits), preview.Message)
+		return preview, nil
+	}
+
+	result, err := utilities.ApplyJournaledWorkspaceEdit("format_document", mode, workspaceEdit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply formatting changes: %v", err)
 	}
 
 	// Build output summary
@@ -143,5 +155,5 @@ func FormatDocument(ctx context.Context, client *lsp.Client, filePath string, mo
 		))
 	}
 
-	return output.String(), nil
+	return journaledEdits(client, output.String(), result.Entry), nil
 }
@@ -11,5 +11,5 @@ import (
 func TestFormatDocument_Signature(t *testing.T) {
 	// Verify that FormatDocument function exists and has correct signature
 	// mode: "full", "range", "ontype"
-	var _ func(context.Context, *lsp.Client, string, string, int, int, int, int, string) (string, error) = FormatDocument
+	var _ func(context.Context, *lsp.Client, string, string, int, int, int, int, string, bool) (*EditResult, error) = FormatDocument
 }
@@ -10,11 +10,35 @@ import (
 	"github.com/isaacphi/mcp-language-server/internal/protocol"
 )
 
+// CodeLens is a code lens in a file. Index is its 1-based position, which
+// execute_codelens takes.
+type CodeLens struct {
+	Index   int    `json:"index"`
+	Range   Range  `json:"range"`
+	Title   string `json:"title,omitempty"`
+	Command string `json:"command,omitempty"`
+	// Arguments are the command's arguments as JSON
+	Arguments []string `json:"arguments,omitempty"`
+	// Data is what the server attached to the lens to resolve it
+	Data any `json:"data,omitempty"`
+
+	hasCommand bool
+}
+
+// CodeLensResult lists the code lenses of a file
+type CodeLensResult struct {
+	Path   string     `json:"path"`
+	Lenses []CodeLens `json:"lenses"`
+
+	// unsupported is set when the server returned no result at all
+	unsupported bool
+}
+
 // GetCodeLens retrieves code lens hints for a given file location
-func GetCodeLens(ctx context.Context, client *lsp.Client, filePath string) (string, error) {
+func GetCodeLens(ctx context.Context, client *lsp.Client, filePath string) (*CodeLensResult, error) {
 	err := client.OpenFile(ctx, filePath)
 	if err != nil {
-		return "", fmt.Errorf("could not open file: %v", err)
+		return nil, fmt.Errorf("could not open file: %v", err)
 	}
 	// TODO: find a more appropriate way to wait
 	time.Sleep(time.Second)
@@ -30,31 +54,48 @@ func GetCodeLens(ctx context.Context, client *lsp.Client, filePath string) (stri
 	}
 	codeLensResult, err := client.CodeLens(ctx, params)
 	if err != nil {
-		return "", fmt.Errorf("failed to get code lens: %w", err)
+		return nil, fmt.Errorf("failed to get code lens: %w", err)
 	}
 
-	if codeLensResult == nil {
-		return "No code lens providers available for this file.", nil
+	result := &CodeLensResult{Path: filePath, Lenses: []CodeLens{}, unsupported: codeLensResult == nil}
+	for i, lens := range codeLensResult {
+		item := CodeLens{Index: i + 1, Range: newRange(lens.Range), Data: lens.Data}
+		if lens.Command != nil {
+			item.hasCommand = true
+			item.Title = lens.Command.Title
+			item.Command = lens.Command.Command
+			for _, arg := range lens.Command.Arguments {
+				item.Arguments = append(item.Arguments, string(arg))
+			}
+		}
+		result.Lenses = append(result.Lenses, item)
+	}
+	return result, nil
+}
+
+func (r *CodeLensResult) Format() string {
+	if r.unsupported {
+		return "No code lens providers available for this file."
 	}
 
 	// Format the code lens results
 	var output strings.Builder
-	output.WriteString(fmt.Sprintf("Code Lens results for %s:\n\n", filePath))
+	output.WriteString(fmt.Sprintf("Code Lens results for %s:\n\n", r.Path))
 
-	for i, lens := range codeLensResult {
+	for _, lens := range r.Lenses {
 		output.WriteString(fmt.Sprintf("[%d] Location: Lines %d-%d\n",
-			i+1,
-			lens.Range.Start.Line+1,
-			lens.Range.End.Line+1))
+			lens.Index,
+			lens.Range.Start.Line,
+			lens.Range.End.Line))
 
-		if lens.Command != nil {
-			output.WriteString(fmt.Sprintf("    Title: %s\n", lens.Command.Title))
-			if lens.Command.Command != "" {
-				output.WriteString(fmt.Sprintf("    Command: %s\n", lens.Command.Command))
+		if lens.hasCommand {
+			output.WriteString(fmt.Sprintf("    Title: %s\n", lens.Title))
+			if lens.Command != "" {
+				output.WriteString(fmt.Sprintf("    Command: %s\n", lens.Command))
 			}
-			if lens.Command.Arguments != nil {
+			if lens.Arguments != nil {
 				output.WriteString("    Arguments:\n")
-				for _, arg := range lens.Command.Arguments {
+				for _, arg := range lens.Arguments {
 					output.WriteString(fmt.Sprintf("%s\n", arg))
 				}
 			}
@@ -68,11 +109,11 @@ func GetCodeLens(ctx context.Context, client *lsp.Client, filePath string) (stri
 		output.WriteString("\n")
 	}
 
-	if len(codeLensResult) == 0 {
+	if len(r.Lenses) == 0 {
 		output.WriteString("No code lens found for this file.\n")
 	} else {
-		output.WriteString(fmt.Sprintf("Found %d code lens items.\n", len(codeLensResult)))
+		output.WriteString(fmt.Sprintf("Found %d code lens items.\n", len(r.Lenses)))
 	}
 
-	return output.String(), nil
+	return output.String()
 }
@@ -3,18 +3,26 @@ package tools
 import (
 	"context"
 	"fmt"
-	"strings"
 
 	"github.com/isaacphi/mcp-language-server/internal/lsp"
 	"github.com/isaacphi/mcp-language-server/internal/protocol"
 )
 
+// HoverResult is the hover information at a position. When there is none,
+// Line holds the source line of the position.
+type HoverResult struct {
+	Path     string   `json:"path"`
+	Position Position `json:"position"`
+	Contents string   `json:"contents"`
+	Line     string   `json:"line,omitempty"`
+}
+
 // GetHoverInfo retrieves hover information (type, documentation) for a symbol at the specified position
-func GetHoverInfo(ctx context.Context, client *lsp.Client, filePath string, line, column int) (string, error) {
+func GetHoverInfo(ctx context.Context, client *lsp.Client, filePath string, line, column int) (*HoverResult, error) {
 	// Open the file if not already open
 	err := client.OpenFile(ctx, filePath)
 	if err != nil {
-		return "", fmt.Errorf("could not open file: %v", err)
+		return nil, fmt.Errorf("could not open file: %v", err)
 	}
 
 	params := protocol.HoverParams{}
@@ -33,13 +41,17 @@ func GetHoverInfo(ctx context.Context, client *lsp.Client, filePath string, line
 	// Execute the hover request
 	hoverResult, err := client.Hover(ctx, params)
 	if err != nil {
-		return "", fmt.Errorf("failed to get hover information: %v", err)
+		return nil, fmt.Errorf("failed to get hover information: %v", err)
 	}
 
-	var result strings.Builder
+	result := &HoverResult{
+		Path:     filePath,
+		Position: newPosition(position),
+		Contents: hoverResult.Contents.Value,
+	}
 
 	// Process the hover contents based on Markup content
-	if hoverResult.Contents.Value == "" {
+	if result.Contents == "" {
 		// Extract the line where the hover was requested
 		lineText, err := ExtractTextFromLocation(protocol.Location{
 			URI: uri,
@@ -57,10 +69,15 @@ func GetHoverInfo(ctx context.Context, client *lsp.Client, filePath string, line
 		if err != nil {
 			toolsLogger.Warn("failed to extract line at position: %v", err)
 		}
-		result.WriteString(fmt.Sprintf("No hover information available for this position on the following line:\n%s", lineText))
-	} else {
-		result.WriteString(hoverResult.Contents.Value)
+		result.Line = lineText
 	}
 
-	return result.String(), nil
+	return result, nil
+}
+
+func (r *HoverResult) Format() string {
+	if r.Contents == "" {
+		return fmt.Sprintf("No hover information available for this position on the following line:\n%s", r.Line)
+	}
+	return r.Contents
 }
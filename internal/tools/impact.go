@@ -0,0 +1,737 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/isaacphi/mcp-language-server/internal/utilities"
+)
+
+const (
+	// DefaultImpactDepth is how many levels of callers are followed by default
+	DefaultImpactDepth = 3
+	// maxImpactSymbols caps the changed symbols analyzed in one call
+	maxImpactSymbols = 50
+	// maxImpactCallers is the node budget of each changed symbol's caller graph
+	maxImpactCallers = 50
+)
+
+// fileChange is what a diff changed in one file. Lines are 1-based and
+// numbered as in the new version of the file.
+type fileChange struct {
+	path    string
+	deleted bool
+	// lines were added or modified
+	lines []int
+	// deletions are the lines after which lines were removed
+	deletions []int
+}
+
+// impactSymbol is a declaration that changes can be attributed to
+type impactSymbol struct {
+	path      string
+	name      string
+	kind      protocol.SymbolKind
+	container string
+	rng       protocol.Range
+	selection protocol.Position
+}
+
+// impactSymbolKinds are the kinds of symbols changes are attributed to
+var impactSymbolKinds = map[protocol.SymbolKind]bool{
+	protocol.Function:    true,
+	protocol.Method:      true,
+	protocol.Constructor: true,
+	protocol.Class:       true,
+	protocol.Struct:      true,
+	protocol.Interface:   true,
+	protocol.Enum:        true,
+	protocol.Constant:    true,
+	protocol.Variable:    true,
+}
+
+// callableKinds are the kinds whose callers are followed
+var callableKinds = map[protocol.SymbolKind]bool{
+	protocol.Function:    true,
+	protocol.Method:      true,
+	protocol.Constructor: true,
+}
+
+// changedSymbol is a symbol touched by a diff
+type changedSymbol struct {
+	symbol impactSymbol
+	lines  []int
+}
+
+// affectedTest is a test function that calls or references changed code
+type affectedTest struct {
+	path string
+	name string
+	line int
+}
+
+// ImpactCaller is a transitive caller of a changed symbol. Depth is the
+// number of calls between it and the symbol.
+type ImpactCaller struct {
+	Path  string `json:"path"`
+	Line  int    `json:"line"`
+	Name  string `json:"name"`
+	Depth int    `json:"depth"`
+}
+
+// SymbolImpact is a changed symbol with its callers and references.
+// ChangedLines are 1-based lines in the new version of the file.
+// CallersError and ReferencesError are set if they couldn't be looked up.
+type SymbolImpact struct {
+	Path             string         `json:"path"`
+	Line             int            `json:"line"`
+	Name             string         `json:"name"`
+	Kind             string         `json:"kind"`
+	ChangedLines     []int          `json:"changedLines"`
+	Callers          []ImpactCaller `json:"callers,omitempty"`
+	CallersTruncated bool           `json:"callersTruncated,omitempty"`
+	CallersError     string         `json:"callersError,omitempty"`
+	References       int            `json:"references"`
+	ReferencingFiles int            `json:"referencingFiles"`
+	ReferencesError  string         `json:"referencesError,omitempty"`
+
+	// callersChecked and referencesChecked are set if the server was asked
+	callersChecked    bool
+	referencesChecked bool
+}
+
+// AffectedTest is a test that calls or references changed code
+type AffectedTest struct {
+	Path string `json:"path"`
+	Line int    `json:"line"`
+	Name string `json:"name"`
+}
+
+// UnattributedChange lists changed lines of a file outside any symbol
+type UnattributedChange struct {
+	Path  string `json:"path"`
+	Lines []int  `json:"lines"`
+}
+
+// ImpactResult is the code affected by the changes against Base. Symbols
+// lists the analyzed changed symbols, the first of SymbolsChanged.
+type ImpactResult struct {
+	Base             string               `json:"base"`
+	FilesChanged     int                  `json:"filesChanged"`
+	SymbolsChanged   int                  `json:"symbolsChanged"`
+	Symbols          []SymbolImpact       `json:"symbols"`
+	Tests            []AffectedTest       `json:"tests"`
+	ReferencingFiles []string             `json:"referencingFiles"`
+	Unattributed     []UnattributedChange `json:"unattributed,omitempty"`
+	Deleted          []string             `json:"deleted,omitempty"`
+}
+
+// ImpactOfChanges reports the code affected by the changes between base and
+// the working tree.
+//
+// The diff is read with git in the workspace root and each changed line is
+// mapped to its innermost enclosing declaration using document symbols. For
+// every changed symbol the transitive callers are collected through the call
+// hierarchy, up to maxDepth levels, along with the files referencing it.
+// Test functions among the callers and around the references are listed as
+// affected tests; in test files where document symbols don't name the tests,
+// code lenses are used instead.
+func ImpactOfChanges(ctx context.Context, client *lsp.Client, base string, maxDepth int) (*ImpactResult, error) {
+	if base == "" {
+		base = "HEAD"
+	}
+	if maxDepth <= 0 {
+		maxDepth = DefaultImpactDepth
+	}
+
+	root := workspaceRoot(client)
+	diff, err := gitDiff(ctx, root, base)
+	if err != nil {
+		return nil, err
+	}
+	patches, err := utilities.ParsePatch(diff)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse git diff: %v", err)
+	}
+	changes := fileChanges(root, patches)
+	result := &ImpactResult{
+		Base:             base,
+		FilesChanged:     len(changes),
+		Symbols:          []SymbolImpact{},
+		Tests:            []AffectedTest{},
+		ReferencingFiles: []string{},
+	}
+	if len(changes) == 0 {
+		return result, nil
+	}
+
+	analysis := &impactAnalysis{
+		ctx:     ctx,
+		client:  client,
+		root:    root,
+		caps:    client.GetCapabilities(),
+		symbols: make(map[string][]impactSymbol),
+		tests:   make(map[string]affectedTest),
+	}
+
+	var changed []changedSymbol
+	outside := make(map[string][]int)
+	for _, change := range changes {
+		if change.deleted {
+			result.Deleted = append(result.Deleted, displayPath(root, change.path))
+			continue
+		}
+		symbols, err := analysis.fileSymbols(change.path)
+		if err != nil {
+			toolsLogger.Warn("Failed to get symbols for %s: %v", change.path, err)
+		}
+		found, unattributed := attributeChanges(change, symbols)
+		changed = append(changed, found...)
+		if len(unattributed) > 0 {
+			outside[change.path] = unattributed
+		}
+	}
+
+	result.SymbolsChanged = len(changed)
+	if len(changed) > maxImpactSymbols {
+		changed = changed[:maxImpactSymbols]
+	}
+
+	referencingFiles := make(map[string]bool)
+	for _, change := range changed {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		result.Symbols = append(result.Symbols, analysis.symbolImpact(change, maxDepth, referencingFiles))
+	}
+
+	for _, test := range sortedTests(analysis.tests) {
+		result.Tests = append(result.Tests, AffectedTest{Path: displayPath(root, test.path), Line: test.line, Name: test.name})
+	}
+
+	for path := range referencingFiles {
+		result.ReferencingFiles = append(result.ReferencingFiles, displayPath(root, path))
+	}
+	sort.Strings(result.ReferencingFiles)
+
+	paths := make([]string, 0, len(outside))
+	for path := range outside {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		result.Unattributed = append(result.Unattributed, UnattributedChange{Path: displayPath(root, path), Lines: outside[path]})
+	}
+	return result, nil
+}
+
+func (r *ImpactResult) Format() string {
+	if r.FilesChanged == 0 {
+		return fmt.Sprintf("No changes against %s", r.Base)
+	}
+
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("Impact of changes against %s: %d files changed, %d symbols changed\n",
+		r.Base, r.FilesChanged, r.SymbolsChanged))
+
+	if r.SymbolsChanged > len(r.Symbols) {
+		output.WriteString(fmt.Sprintf("Only the first %d changed symbols are analyzed.\n", len(r.Symbols)))
+	}
+
+	for _, symbol := range r.Symbols {
+		output.WriteString("\n")
+		writeSymbolImpact(&output, symbol)
+	}
+
+	output.WriteString(fmt.Sprintf("\nAffected tests (%d):\n", len(r.Tests)))
+	if len(r.Tests) == 0 {
+		output.WriteString("  none found\n")
+	}
+	for _, test := range r.Tests {
+		output.WriteString(fmt.Sprintf("  %s:L%d: %s\n", test.Path, test.Line, test.Name))
+	}
+
+	if len(r.ReferencingFiles) > 0 {
+		output.WriteString(fmt.Sprintf("\nReferencing files (%d):\n", len(r.ReferencingFiles)))
+		for _, path := range r.ReferencingFiles {
+			output.WriteString("  " + path + "\n")
+		}
+	}
+
+	if len(r.Unattributed) > 0 {
+		output.WriteString("\nChanges outside any symbol:\n")
+		for _, change := range r.Unattributed {
+			output.WriteString(fmt.Sprintf("  %s: %s\n", change.Path, formatLineRanges(change.Lines)))
+		}
+	}
+
+	if len(r.Deleted) > 0 {
+		output.WriteString("\nDeleted files:\n")
+		for _, path := range r.Deleted {
+			output.WriteString("  " + path + "\n")
+		}
+	}
+	return output.String()
+}
+
+// gitDiff returns the zero-context diff between base and the working tree,
+// with paths relative to root. Untracked files that aren't ignored are
+// included as added files.
+func gitDiff(ctx context.Context, root, base string) (string, error) {
+	if strings.HasPrefix(base, "-") {
+		return "", fmt.Errorf("invalid base ref %q", base)
+	}
+	out, err := runGit(ctx, root, "diff", "--no-color", "--no-ext-diff", "--unified=0", "--relative", "-M", base, "--")
+	if err != nil {
+		return "", fmt.Errorf("git diff %s failed: %v", base, err)
+	}
+	untracked, err := runGit(ctx, root, "ls-files", "--others", "--exclude-standard", "-z")
+	if err != nil {
+		return "", fmt.Errorf("failed to list untracked files: %v", err)
+	}
+
+	var diff strings.Builder
+	diff.WriteString(out)
+	for _, path := range strings.Split(untracked, "\x00") {
+		if path == "" {
+			continue
+		}
+		// git diff --no-index exits with 1 when the files differ
+		added, err := runGit(ctx, root, "diff", "--no-index", "--no-color", "--no-ext-diff", "--unified=0", "--", utilities.DevNull, path)
+		var exitErr *exec.ExitError
+		if err != nil && !(errors.As(err, &exitErr) && exitErr.ExitCode() == 1) {
+			return "", fmt.Errorf("git diff of untracked file %s failed: %v", path, err)
+		}
+		diff.WriteString(added)
+	}
+	return diff.String(), nil
+}
+
+// runGit runs a git command in dir and returns its output. Errors include
+// what git wrote to stderr.
+func runGit(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return string(out), fmt.Errorf("%w: %s", err, message)
+		}
+		return string(out), err
+	}
+	return string(out), nil
+}
+
+// fileChanges converts parsed diffs into the lines changed in each file
+func fileChanges(root string, patches []utilities.FilePatch) []fileChange {
+	var changes []fileChange
+	for _, patch := range patches {
+		change := fileChange{
+			path:    filepath.Join(root, filepath.FromSlash(patch.Path())),
+			deleted: patch.IsDelete(),
+		}
+		for _, hunk := range patch.Hunks {
+			line := hunk.NewStart
+			if hunk.NewLines == 0 {
+				// An empty new side is numbered from the line before it
+				line++
+			}
+			// Removed lines followed by added ones were replaced, not removed
+			removed := false
+			for _, text := range hunk.Lines {
+				switch {
+				case strings.HasPrefix(text, "+"):
+					change.lines = append(change.lines, line)
+					removed = false
+					line++
+				case strings.HasPrefix(text, "-"):
+					removed = true
+				default:
+					if removed {
+						change.deletions = append(change.deletions, line-1)
+						removed = false
+					}
+					line++
+				}
+			}
+			if removed {
+				change.deletions = append(change.deletions, line-1)
+			}
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+// attributeChanges maps the changed lines of a file to the innermost symbols
+// enclosing them. Lines outside any symbol are returned separately. A removal
+// is attributed to the innermost symbol containing the lines on both sides of it.
+func attributeChanges(change fileChange, symbols []impactSymbol) ([]changedSymbol, []int) {
+	index := make(map[int]int)
+	var changed []changedSymbol
+	var outside []int
+
+	attribute := func(symbol int, line int) {
+		i, ok := index[symbol]
+		if !ok {
+			i = len(changed)
+			index[symbol] = i
+			changed = append(changed, changedSymbol{symbol: symbols[symbol]})
+		}
+		if !containsInt(changed[i].lines, line) {
+			changed[i].lines = append(changed[i].lines, line)
+		}
+	}
+
+	for _, line := range change.lines {
+		if symbol := innermostSymbol(symbols, line, line); symbol >= 0 {
+			attribute(symbol, line)
+		} else if !containsInt(outside, line) {
+			outside = append(outside, line)
+		}
+	}
+	for _, line := range change.deletions {
+		if symbol := innermostSymbol(symbols, line, line+1); symbol >= 0 {
+			attribute(symbol, line)
+		} else if line > 0 && !containsInt(outside, line) {
+			outside = append(outside, line)
+		}
+	}
+
+	for i := range changed {
+		sort.Ints(changed[i].lines)
+	}
+	sort.SliceStable(changed, func(i, j int) bool {
+		return changed[i].symbol.rng.Start.Line < changed[j].symbol.rng.Start.Line
+	})
+	sort.Ints(outside)
+	return changed, outside
+}
+
+// innermostSymbol returns the index of the smallest symbol containing the
+// 1-based lines from first to last, or -1
+func innermostSymbol(symbols []impactSymbol, first, last int) int {
+	best := -1
+	for i, symbol := range symbols {
+		if first-1 < int(symbol.rng.Start.Line) || last-1 > int(symbol.rng.End.Line) {
+			continue
+		}
+		if best < 0 || symbol.rng.End.Line-symbol.rng.Start.Line < symbols[best].rng.End.Line-symbols[best].rng.Start.Line {
+			best = i
+		}
+	}
+	return best
+}
+
+// flattenImpactSymbols lists the declarations in a document symbol result.
+// Symbols nested in functions, such as local variables, are left out.
+func flattenImpactSymbols(path string, results []protocol.DocumentSymbolResult) []impactSymbol {
+	var symbols []impactSymbol
+	var walk func(symbol *protocol.DocumentSymbol, container string)
+	walk = func(symbol *protocol.DocumentSymbol, container string) {
+		if impactSymbolKinds[symbol.Kind] {
+			symbols = append(symbols, impactSymbol{
+				path:      path,
+				name:      symbol.Name,
+				kind:      symbol.Kind,
+				container: container,
+				rng:       symbol.Range,
+				selection: symbol.SelectionRange.Start,
+			})
+		}
+		if callableKinds[symbol.Kind] {
+			return
+		}
+		for i := range symbol.Children {
+			walk(&symbol.Children[i], symbol.Name)
+		}
+	}
+
+	for _, result := range results {
+		switch symbol := result.(type) {
+		case *protocol.DocumentSymbol:
+			walk(symbol, "")
+		case *protocol.SymbolInformation:
+			if impactSymbolKinds[symbol.Kind] {
+				symbols = append(symbols, impactSymbol{
+					path:      path,
+					name:      symbol.Name,
+					kind:      symbol.Kind,
+					container: symbol.ContainerName,
+					rng:       symbol.Location.Range,
+					selection: symbol.Location.Range.Start,
+				})
+			}
+		}
+	}
+	return symbols
+}
+
+// impactAnalysis holds what is looked up while analyzing a diff
+type impactAnalysis struct {
+	ctx    context.Context
+	client *lsp.Client
+	root   string
+	caps   *protocol.ServerCapabilities
+	// symbols caches the declarations of each file
+	symbols map[string][]impactSymbol
+	// tests are the affected tests, by location
+	tests map[string]affectedTest
+}
+
+func (a *impactAnalysis) fileSymbols(path string) ([]impactSymbol, error) {
+	if symbols, ok := a.symbols[path]; ok {
+		return symbols, nil
+	}
+	a.symbols[path] = nil
+	if !lsp.HasDocumentSymbolSupport(a.caps) {
+		return nil, nil
+	}
+	if err := a.client.OpenFile(a.ctx, path); err != nil {
+		return nil, fmt.Errorf("could not open file: %v", err)
+	}
+	result, err := a.client.DocumentSymbol(a.ctx, protocol.DocumentSymbolParams{
+		TextDocument: protocol.TextDocumentIdentifier{URI: protocol.DocumentUri("file://" + path)},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get document symbols: %v", err)
+	}
+	results, err := result.Results()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse symbol results: %v", err)
+	}
+
+	// Flat symbols start at the declaration keyword, so find the name for references
+	symbols := flattenImpactSymbols(path, results)
+	if content, err := os.ReadFile(path); err == nil {
+		lines := strings.Split(string(content), "\n")
+		for i, symbol := range symbols {
+			if symbol.selection == symbol.rng.Start {
+				if position, ok := namePosition(lines, symbol.rng, symbol.name); ok {
+					symbols[i].selection = position
+				}
+			}
+		}
+	}
+	a.symbols[path] = symbols
+	return symbols, nil
+}
+
+// symbolImpact looks up the callers and references of one changed symbol
+func (a *impactAnalysis) symbolImpact(change changedSymbol, maxDepth int, referencingFiles map[string]bool) SymbolImpact {
+	symbol := change.symbol
+	name := symbol.name
+	if symbol.container != "" {
+		name = symbol.container + "." + name
+	}
+	impact := SymbolImpact{
+		Path:         displayPath(a.root, symbol.path),
+		Line:         int(symbol.selection.Line) + 1,
+		Name:         name,
+		Kind:         symbolKindToString(symbol.kind),
+		ChangedLines: change.lines,
+	}
+
+	if isTestFile(symbol.path) && isTestSymbol(symbol.name) {
+		a.addTest(symbol.path, symbol.name, int(symbol.selection.Line)+1)
+	}
+
+	uri := protocol.DocumentUri("file://" + symbol.path)
+	position := protocol.TextDocumentPositionParams{
+		TextDocument: protocol.TextDocumentIdentifier{URI: uri},
+		Position:     symbol.selection,
+	}
+
+	if callableKinds[symbol.kind] && lsp.HasCallHierarchySupport(a.caps) {
+		items, err := a.client.PrepareCallHierarchy(a.ctx, protocol.CallHierarchyPrepareParams{TextDocumentPositionParams: position})
+		switch {
+		case err != nil:
+			impact.CallersError = fmt.Sprintf("failed to prepare call hierarchy: %v", err)
+		case len(items) > 0:
+			opts := CallGraphOptions{Direction: "incoming", MaxDepth: maxDepth, MaxNodes: maxImpactCallers}
+			graph, err := buildCallGraph(a.ctx, items[0], a.root, opts, lspCallFetcher(a.client))
+			if err != nil {
+				impact.CallersError = err.Error()
+				break
+			}
+			impact.callersChecked = true
+			a.addCallers(&impact, graph)
+		}
+	}
+
+	if !lsp.HasReferencesSupport(a.caps) {
+		return impact
+	}
+	locations, err := a.client.References(a.ctx, protocol.ReferenceParams{
+		TextDocumentPositionParams: position,
+		Context:                    protocol.ReferenceContext{IncludeDeclaration: false},
+	})
+	if err != nil {
+		impact.ReferencesError = fmt.Sprintf("failed: %v", err)
+		return impact
+	}
+
+	files := make(map[string]bool)
+	for _, location := range locations {
+		path := strings.TrimPrefix(string(location.URI), "file://")
+		if path != symbol.path {
+			files[path] = true
+			referencingFiles[path] = true
+		}
+		if isTestFile(path) {
+			a.addEnclosingTest(path, location.Range.Start)
+		}
+	}
+	impact.referencesChecked = true
+	impact.References = len(locations)
+	impact.ReferencingFiles = len(files)
+	return impact
+}
+
+// addCallers records the transitive callers in a call graph, and those that are tests
+func (a *impactAnalysis) addCallers(impact *SymbolImpact, graph *CallGraph) {
+	for _, caller := range graph.Nodes[1:] {
+		impact.Callers = append(impact.Callers, ImpactCaller{Path: caller.Path, Line: caller.Line, Name: caller.Name, Depth: caller.Depth})
+		path := caller.Path
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(a.root, filepath.FromSlash(path))
+		}
+		if isTestFile(path) || isTestSymbol(caller.Name) {
+			a.addTest(path, caller.Name, caller.Line)
+		}
+	}
+	impact.CallersTruncated = graph.Truncated
+}
+
+// writeSymbolImpact writes the callers and references of one changed symbol
+func writeSymbolImpact(output *strings.Builder, impact SymbolImpact) {
+	output.WriteString(fmt.Sprintf("%s:L%d: %s (%s), changed %s\n", impact.Path,
+		impact.Line, impact.Name, impact.Kind, formatLineRanges(impact.ChangedLines)))
+
+	switch {
+	case impact.CallersError != "":
+		output.WriteString(fmt.Sprintf("  Callers: %s\n", impact.CallersError))
+	case !impact.callersChecked:
+	case len(impact.Callers) == 0:
+		output.WriteString("  Callers: none\n")
+	default:
+		output.WriteString(fmt.Sprintf("  Callers (%d):\n", len(impact.Callers)))
+		for _, caller := range impact.Callers {
+			output.WriteString(fmt.Sprintf("    %s:L%d: %s (depth %d)\n", caller.Path, caller.Line, caller.Name, caller.Depth))
+		}
+		if impact.CallersTruncated {
+			output.WriteString(fmt.Sprintf("    ... stopped after %d callers\n", len(impact.Callers)))
+		}
+	}
+
+	switch {
+	case impact.ReferencesError != "":
+		output.WriteString(fmt.Sprintf("  References: %s\n", impact.ReferencesError))
+	case impact.referencesChecked:
+		output.WriteString(fmt.Sprintf("  References: %d in %d other files\n", impact.References, impact.ReferencingFiles))
+	}
+}
+
+// addEnclosingTest records the test around a reference in a test file. The
+// enclosing function is taken from document symbols, or else the nearest
+// code lens above the reference, which is how many test runners mark tests.
+func (a *impactAnalysis) addEnclosingTest(path string, position protocol.Position) {
+	symbols, err := a.fileSymbols(path)
+	if err == nil {
+		if i := innermostSymbol(symbols, int(position.Line)+1, int(position.Line)+1); i >= 0 && callableKinds[symbols[i].kind] {
+			a.addTest(path, symbols[i].name, int(symbols[i].selection.Line)+1)
+			return
+		}
+	}
+
+	if !lsp.HasCodeLensSupport(a.caps) {
+		return
+	}
+	lenses, err := a.client.CodeLens(a.ctx, protocol.CodeLensParams{
+		TextDocument: protocol.TextDocumentIdentifier{URI: protocol.DocumentUri("file://" + path)},
+	})
+	if err != nil {
+		return
+	}
+	var nearest *protocol.CodeLens
+	for i, lens := range lenses {
+		if lens.Command == nil || !strings.Contains(strings.ToLower(lens.Command.Title), "test") {
+			continue
+		}
+		if lens.Range.Start.Line <= position.Line && (nearest == nil || lens.Range.Start.Line > nearest.Range.Start.Line) {
+			nearest = &lenses[i]
+		}
+	}
+	if nearest != nil {
+		content, err := os.ReadFile(path)
+		name := nearest.Command.Title
+		if err == nil {
+			lines := strings.Split(string(content), "\n")
+			if int(nearest.Range.Start.Line) < len(lines) {
+				name = strings.TrimSpace(lines[nearest.Range.Start.Line])
+			}
+		}
+		a.addTest(path, name, int(nearest.Range.Start.Line)+1)
+	}
+}
+
+func (a *impactAnalysis) addTest(path, name string, line int) {
+	a.tests[fmt.Sprintf("%s:%d", path, line)] = affectedTest{path: path, name: name, line: line}
+}
+
+func sortedTests(tests map[string]affectedTest) []affectedTest {
+	sorted := make([]affectedTest, 0, len(tests))
+	for _, test := range tests {
+		sorted = append(sorted, test)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].path != sorted[j].path {
+			return sorted[i].path < sorted[j].path
+		}
+		return sorted[i].line < sorted[j].line
+	})
+	return sorted
+}
+
+// isTestFile reports whether a path is a test file of a common language
+func isTestFile(path string) bool {
+	return len(filterTestFiles([]string{path})) == 0
+}
+
+// formatLineRanges formats sorted 1-based lines as "L3, L7-9"
+func formatLineRanges(lines []int) string {
+	var ranges []string
+	for i := 0; i < len(lines); {
+		j := i
+		for j+1 < len(lines) && lines[j+1] <= lines[j]+1 {
+			j++
+		}
+		if lines[j] == lines[i] {
+			ranges = append(ranges, fmt.Sprintf("L%d", lines[i]))
+		} else {
+			ranges = append(ranges, fmt.Sprintf("L%d-%d", lines[i], lines[j]))
+		}
+		i = j + 1
+	}
+	return strings.Join(ranges, ", ")
+}
+
+func containsInt(values []int, value int) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
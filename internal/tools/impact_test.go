@@ -0,0 +1,120 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/isaacphi/mcp-language-server/internal/utilities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGitDiffFileChanges(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	root := t.TempDir()
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = root
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	write := func(name, content string) {
+		require.NoError(t, os.WriteFile(filepath.Join(root, name), []byte(content), 0644))
+	}
+
+	write("main.go", "package main\n\nfunc a() {\n\tx := 1\n\ty := 2\n}\n\nfunc b() {\n\tz := 3\n}\n")
+	write("old.go", "package main\n")
+	git("init", "-q")
+	git("add", ".")
+	git("commit", "-q", "-m", "initial")
+
+	// Modify a line in a, remove a line from b, delete a file and add
+	// untracked ones
+	write("main.go", "package main\n\nfunc a() {\n\tx := 10\n\ty := 2\n}\n\nfunc b() {\n}\n")
+	require.NoError(t, os.Remove(filepath.Join(root, "old.go")))
+	write("new.go", "package main\n\nfunc c() {}\n")
+	write(".gitignore", "*.log\n")
+	write("debug.log", "ignored\n")
+
+	diff, err := gitDiff(context.Background(), root, "HEAD")
+	require.NoError(t, err)
+	patches, err := utilities.ParsePatch(diff)
+	require.NoError(t, err)
+	changes := fileChanges(root, patches)
+	require.Len(t, changes, 4)
+
+	assert.Equal(t, filepath.Join(root, "main.go"), changes[0].path)
+	assert.Equal(t, []int{4}, changes[0].lines)
+	assert.Equal(t, []int{8}, changes[0].deletions, "the removed line followed line 8")
+	assert.True(t, changes[1].deleted)
+	assert.Equal(t, filepath.Join(root, ".gitignore"), changes[2].path)
+	assert.Equal(t, filepath.Join(root, "new.go"), changes[3].path, "untracked files count as added")
+	assert.Equal(t, []int{1, 2, 3}, changes[3].lines)
+
+	symbols := []impactSymbol{
+		{name: "a", kind: protocol.Function, rng: protocol.Range{Start: protocol.Position{Line: 2}, End: protocol.Position{Line: 5, Character: 1}}},
+		{name: "b", kind: protocol.Function, rng: protocol.Range{Start: protocol.Position{Line: 7}, End: protocol.Position{Line: 8, Character: 1}}},
+	}
+	changed, outside := attributeChanges(changes[0], symbols)
+	require.Len(t, changed, 2)
+	assert.Equal(t, "a", changed[0].symbol.name)
+	assert.Equal(t, []int{4}, changed[0].lines)
+	assert.Equal(t, "b", changed[1].symbol.name)
+	assert.Empty(t, outside)
+
+	_, err = gitDiff(context.Background(), root, "--output=/tmp/x")
+	assert.ErrorContains(t, err, "invalid base ref")
+	_, err = gitDiff(context.Background(), root, "no-such-ref")
+	assert.ErrorContains(t, err, "git diff no-such-ref failed")
+}
+
+func TestAttributeChanges(t *testing.T) {
+	symbols := []impactSymbol{
+		{name: "Server", kind: protocol.Struct, rng: protocol.Range{Start: protocol.Position{Line: 0}, End: protocol.Position{Line: 20}}},
+		{name: "Start", kind: protocol.Method, rng: protocol.Range{Start: protocol.Position{Line: 4}, End: protocol.Position{Line: 9}}},
+	}
+	change := fileChange{
+		lines:     []int{6, 7, 3, 30},
+		deletions: []int{10, 25},
+	}
+
+	changed, outside := attributeChanges(change, symbols)
+	require.Len(t, changed, 2)
+	assert.Equal(t, "Server", changed[0].symbol.name)
+	assert.Equal(t, []int{3, 10}, changed[0].lines, "a removal at the end of Start is attributed to Server")
+	assert.Equal(t, "Start", changed[1].symbol.name)
+	assert.Equal(t, []int{6, 7}, changed[1].lines)
+	assert.Equal(t, []int{25, 30}, outside)
+}
+
+func TestFlattenImpactSymbols(t *testing.T) {
+	results := []protocol.DocumentSymbolResult{
+		&protocol.DocumentSymbol{
+			Name: "Server",
+			Kind: protocol.Class,
+			Children: []protocol.DocumentSymbol{
+				{Name: "port", Kind: protocol.Field},
+				{Name: "start", Kind: protocol.Method, Children: []protocol.DocumentSymbol{
+					{Name: "local", Kind: protocol.Variable},
+				}},
+			},
+		},
+	}
+	symbols := flattenImpactSymbols("/ws/server.ts", results)
+	require.Len(t, symbols, 2)
+	assert.Equal(t, "Server", symbols[0].name)
+	assert.Equal(t, "start", symbols[1].name)
+	assert.Equal(t, "Server", symbols[1].container)
+}
+
+func TestFormatLineRanges(t *testing.T) {
+	assert.Equal(t, "L3, L7-9, L12", formatLineRanges([]int{3, 7, 8, 9, 12}))
+	assert.Equal(t, "", formatLineRanges(nil))
+}
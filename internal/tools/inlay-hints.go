@@ -9,13 +9,30 @@ import (
 	"github.com/isaacphi/mcp-language-server/internal/protocol"
 )
 
+// InlayHint is an inline annotation such as a parameter name or an inferred type
+type InlayHint struct {
+	Position Position `json:"position"`
+	// Kind is "type", "parameter" or "hint"
+	Kind    string `json:"kind"`
+	Label   string `json:"label"`
+	Tooltip string `json:"tooltip,omitempty"`
+}
+
+// InlayHintsResult lists the inlay hints in a range of lines
+type InlayHintsResult struct {
+	Path      string      `json:"path"`
+	StartLine int         `json:"startLine"`
+	EndLine   int         `json:"endLine"`
+	Hints     []InlayHint `json:"hints"`
+}
+
 // GetInlayHints retrieves inlay hints for a range of lines in a file
 // Inlay hints provide inline annotations like parameter names and type hints
-func GetInlayHints(ctx context.Context, client *lsp.Client, filePath string, startLine, endLine int) (string, error) {
+func GetInlayHints(ctx context.Context, client *lsp.Client, filePath string, startLine, endLine int) (*InlayHintsResult, error) {
 	// Open the file if not already open
 	err := client.OpenFile(ctx, filePath)
 	if err != nil {
-		return "", fmt.Errorf("could not open file: %v", err)
+		return nil, fmt.Errorf("could not open file: %v", err)
 	}
 
 	params := protocol.InlayHintParams{}
@@ -39,21 +56,11 @@ func GetInlayHints(ctx context.Context, client *lsp.Client, filePath string, sta
 	// Execute the inlay hint request
 	hints, err := client.InlayHint(ctx, params)
 	if err != nil {
-		return "", fmt.Errorf("failed to get inlay hints: %v", err)
+		return nil, fmt.Errorf("failed to get inlay hints: %v", err)
 	}
 
-	if len(hints) == 0 {
-		return fmt.Sprintf("No inlay hints found for lines %d-%d", startLine, endLine), nil
-	}
-
-	var result strings.Builder
-
-	result.WriteString(fmt.Sprintf("Inlay Hints for lines %d-%d (%d hints):\n\n", startLine, endLine, len(hints)))
-
+	result := &InlayHintsResult{Path: filePath, StartLine: startLine, EndLine: endLine, Hints: []InlayHint{}}
 	for _, hint := range hints {
-		line := hint.Position.Line + 1
-		char := hint.Position.Character + 1
-
 		// Extract label text from the hint
 		var labelText string
 		if len(hint.Label) > 0 {
@@ -73,23 +80,50 @@ func GetInlayHints(ctx context.Context, client *lsp.Client, filePath string, sta
 			kindStr = "parameter"
 		}
 
+		var tooltipText string
+		if hint.Tooltip != nil {
+			tooltipText = fmt.Sprintf("%v", hint.Tooltip.Value)
+			if tooltipText == "<nil>" {
+				tooltipText = ""
+			}
+		}
+
+		result.Hints = append(result.Hints, InlayHint{
+			Position: newPosition(hint.Position),
+			Kind:     kindStr,
+			Label:    labelText,
+			Tooltip:  tooltipText,
+		})
+	}
+
+	return result, nil
+}
+
+func (r *InlayHintsResult) Format() string {
+	if len(r.Hints) == 0 {
+		return fmt.Sprintf("No inlay hints found for lines %d-%d", r.StartLine, r.EndLine)
+	}
+
+	var result strings.Builder
+
+	result.WriteString(fmt.Sprintf("Inlay Hints for lines %d-%d (%d hints):\n\n", r.StartLine, r.EndLine, len(r.Hints)))
+
+	for _, hint := range r.Hints {
 		result.WriteString(fmt.Sprintf("Line %d, Col %d [%s]: %s",
-			line, char, kindStr, labelText))
+			hint.Position.Line, hint.Position.Column, hint.Kind, hint.Label))
 
 		// Add tooltip if available
-		if hint.Tooltip != nil {
-			tooltipText := fmt.Sprintf("%v", hint.Tooltip.Value)
+		if hint.Tooltip != "" {
+			tooltipText := hint.Tooltip
 			// Truncate long tooltips
 			if len(tooltipText) > 100 {
 				tooltipText = tooltipText[:97] + "..."
 			}
-			if tooltipText != "" && tooltipText != "<nil>" {
-				result.WriteString(fmt.Sprintf(" (tooltip: %s)", tooltipText))
-			}
+			result.WriteString(fmt.Sprintf(" (tooltip: %s)", tooltipText))
 		}
 
 		result.WriteString("\n")
 	}
 
-	return result.String(), nil
+	return result.String()
 }
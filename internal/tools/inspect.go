@@ -0,0 +1,473 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+const (
+	// DefaultInspectReferenceSites is how many reference sites are listed by default
+	DefaultInspectReferenceSites = 10
+	// maxInspectDefinitionLines is the length definition snippets are cut to
+	maxInspectDefinitionLines = 30
+)
+
+// inspectResults holds the answers to the queries made for a position. Each
+// query fills in its own fields, so they can run concurrently.
+type inspectResults struct {
+	hover      string
+	hoverErr   error
+	definition *InspectDefinition
+	defErr     error
+	references []protocol.Location
+	refsErr    error
+	signature  *InspectSignature
+	sigErr     error
+	enclosing  *skeletonSymbol
+	symbolsErr error
+}
+
+// InspectDefinition is where the identifier is defined, with the first
+// lines of the definition's source. Truncated counts the lines left out.
+type InspectDefinition struct {
+	Location  Location   `json:"location"`
+	Source    string     `json:"source,omitempty"`
+	StartLine int        `json:"startLine,omitempty"`
+	Truncated int        `json:"truncated,omitempty"`
+	Others    []Location `json:"others,omitempty"`
+}
+
+// ReferenceCount is the number of references in one file
+type ReferenceCount struct {
+	Path  string `json:"path"`
+	Count int    `json:"count"`
+}
+
+// ReferenceSite is a reference with its line of code
+type ReferenceSite struct {
+	Location Location `json:"location"`
+	Text     string   `json:"text"`
+}
+
+// InspectReferences summarizes the references to the identifier: counts by
+// file, most referenced first, and the first sites in file order
+type InspectReferences struct {
+	Total int              `json:"total"`
+	Files []ReferenceCount `json:"files"`
+	Sites []ReferenceSite  `json:"sites,omitempty"`
+
+	// maxSites is how many sites were asked for
+	maxSites int
+}
+
+// EnclosingSymbol is the symbol around the position. FirstLine is its first
+// line of source, which holds the signature of functions and methods.
+type EnclosingSymbol struct {
+	Name      string   `json:"name"`
+	Kind      string   `json:"kind"`
+	Lines     LineSpan `json:"lines"`
+	FirstLine string   `json:"firstLine,omitempty"`
+}
+
+// InspectSignature is the signature of the call the identifier is an argument of
+type InspectSignature struct {
+	Label           string `json:"label"`
+	ActiveParameter string `json:"activeParameter,omitempty"`
+}
+
+// InspectResult is everything known about the identifier at a position.
+// Sections the server doesn't support are listed in Unsupported, and the
+// *Error fields are set for queries that failed.
+type InspectResult struct {
+	Path            string             `json:"path"`
+	Position        Position           `json:"position"`
+	Line            string             `json:"line"`
+	Hover           string             `json:"hover,omitempty"`
+	HoverError      string             `json:"hoverError,omitempty"`
+	Definition      *InspectDefinition `json:"definition,omitempty"`
+	DefinitionError string             `json:"definitionError,omitempty"`
+	References      *InspectReferences `json:"references,omitempty"`
+	ReferencesError string             `json:"referencesError,omitempty"`
+	Enclosing       *EnclosingSymbol   `json:"enclosing,omitempty"`
+	EnclosingError  string             `json:"enclosingError,omitempty"`
+	Signature       *InspectSignature  `json:"signature,omitempty"`
+	SignatureError  string             `json:"signatureError,omitempty"`
+	Unsupported     []string           `json:"unsupported,omitempty"`
+
+	root string
+}
+
+// Inspect explains the identifier at a position in one answer: its type and
+// docs from hover, its definition, its references grouped by file with the
+// first maxSites sites, the symbol enclosing the position, and the signature
+// of the call it's an argument of. The queries the server supports are run
+// concurrently, and a failing one doesn't hide the others.
+func Inspect(ctx context.Context, client *lsp.Client, filePath string, line, column, maxSites int) (*InspectResult, error) {
+	err := client.OpenFile(ctx, filePath)
+	if err != nil {
+		return nil, fmt.Errorf("could not open file: %v", err)
+	}
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %v", err)
+	}
+	lines := strings.Split(string(content), "\n")
+	if line < 1 || line > len(lines) {
+		return nil, fmt.Errorf("line %d is out of range, the file has %d lines", line, len(lines))
+	}
+
+	uri := protocol.DocumentUri("file://" + filePath)
+	position := protocol.TextDocumentPositionParams{
+		TextDocument: protocol.TextDocumentIdentifier{URI: uri},
+		Position:     protocol.Position{Line: uint32(line - 1), Character: uint32(column - 1)},
+	}
+	caps := client.GetCapabilities()
+	root := workspaceRoot(client)
+	result := &InspectResult{
+		Path:     filePath,
+		Position: newPosition(position.Position),
+		Line:     strings.TrimSpace(lines[line-1]),
+		root:     root,
+	}
+
+	var results inspectResults
+	var wg sync.WaitGroup
+	run := func(section string, supported bool, query func()) {
+		if !supported {
+			result.Unsupported = append(result.Unsupported, section)
+			return
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			query()
+		}()
+	}
+
+	run("hover", lsp.HasHoverSupport(caps), func() {
+		hover, err := client.Hover(ctx, protocol.HoverParams{TextDocumentPositionParams: position})
+		results.hover, results.hoverErr = strings.TrimSpace(hover.Contents.Value), err
+	})
+	run("definition", lsp.HasDefinitionSupport(caps), func() {
+		results.definition, results.defErr = inspectDefinition(ctx, client, position)
+	})
+	run("references", lsp.HasReferencesSupport(caps), func() {
+		results.references, results.refsErr = client.References(ctx, protocol.ReferenceParams{
+			TextDocumentPositionParams: position,
+			Context:                    protocol.ReferenceContext{IncludeDeclaration: false},
+		})
+	})
+	run("signature", lsp.HasSignatureHelpSupport(caps), func() {
+		help, err := client.SignatureHelp(ctx, protocol.SignatureHelpParams{TextDocumentPositionParams: position})
+		results.signature, results.sigErr = callSignature(help), err
+	})
+	run("enclosing", lsp.HasDocumentSymbolSupport(caps), func() {
+		symbols, err := fileDocumentSymbols(ctx, client, filePath)
+		if err != nil {
+			results.symbolsErr = err
+			return
+		}
+		if symbol, ok := enclosingSymbol(flattenSkeletonSymbols(symbols), position.Position); ok {
+			results.enclosing = &symbol
+		}
+	})
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	result.Hover, result.HoverError = results.hover, errorText(results.hoverErr)
+	result.Definition, result.DefinitionError = results.definition, errorText(results.defErr)
+	result.References, result.ReferencesError = summarizeReferences(results.references, maxSites), errorText(results.refsErr)
+	if results.enclosing != nil {
+		result.Enclosing = newEnclosingSymbol(lines, *results.enclosing)
+	}
+	result.EnclosingError = errorText(results.symbolsErr)
+	result.Signature, result.SignatureError = results.signature, errorText(results.sigErr)
+	return result, nil
+}
+
+// errorText returns the message of an error, or "" for nil
+func errorText(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+func (r *InspectResult) supported(section string) bool {
+	return !containsString(r.Unsupported, section)
+}
+
+func (r *InspectResult) Format() string {
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("Position: %s:%d:%d\n", displayPath(r.root, r.Path), r.Position.Line, r.Position.Column))
+	output.WriteString(fmt.Sprintf("Line: %s\n", r.Line))
+
+	output.WriteString("\nType and docs:\n")
+	writeInspectSection(&output, r.supported("hover"), r.Hover, r.HoverError, "No hover information")
+
+	output.WriteString("\nDefinition:\n")
+	writeInspectSection(&output, r.supported("definition"), formatInspectDefinition(r.root, r.Definition), r.DefinitionError, "No definition found")
+
+	output.WriteString("\nReferences:\n")
+	writeInspectSection(&output, r.supported("references"), formatInspectReferences(r.root, r.References), r.ReferencesError, "No references found")
+
+	output.WriteString("\nEnclosing symbol:\n")
+	writeInspectSection(&output, r.supported("enclosing"), formatEnclosingSymbol(r.Enclosing), r.EnclosingError, "Top level of the file")
+
+	output.WriteString("\nCall context:\n")
+	writeInspectSection(&output, r.supported("signature"), formatSignature(r.Signature), r.SignatureError, "Not inside a call")
+	return output.String()
+}
+
+// writeInspectSection writes the body of one section of an inspect answer
+func writeInspectSection(output *strings.Builder, supported bool, text string, err string, empty string) {
+	switch {
+	case !supported:
+		output.WriteString("  Not supported by the language server\n")
+	case err != "":
+		output.WriteString(fmt.Sprintf("  Failed: %s\n", err))
+	case text == "":
+		output.WriteString("  " + empty + "\n")
+	default:
+		for _, line := range strings.Split(strings.TrimRight(text, "\n"), "\n") {
+			output.WriteString("  " + line + "\n")
+		}
+	}
+}
+
+// inspectDefinition returns the location and source of the first definition
+// of the identifier at a position, listing any others
+func inspectDefinition(ctx context.Context, client *lsp.Client, position protocol.TextDocumentPositionParams) (*InspectDefinition, error) {
+	result, err := client.Definition(ctx, protocol.DefinitionParams{TextDocumentPositionParams: position})
+	if err != nil {
+		return nil, err
+	}
+	if result.Value == nil {
+		return nil, nil
+	}
+	locations, err := extractDefinitionLocations(result)
+	if err != nil || len(locations) == 0 {
+		return nil, err
+	}
+
+	first := locations[0]
+	definition := &InspectDefinition{Location: newLocation(first)}
+	if err := client.OpenFile(ctx, first.URI.Path()); err != nil {
+		toolsLogger.Warn("Could not open definition file: %v", err)
+	} else if text, loc, err := GetFullDefinition(ctx, client, first); err != nil {
+		toolsLogger.Debug("No full definition at %s: %v", first.URI, err)
+	} else {
+		snippet := strings.Split(text, "\n")
+		if more := len(snippet) - maxInspectDefinitionLines; more > 0 {
+			snippet = snippet[:maxInspectDefinitionLines]
+			definition.Truncated = more
+		}
+		definition.Source = strings.Join(snippet, "\n")
+		definition.StartLine = int(loc.Range.Start.Line) + 1
+	}
+	for _, loc := range locations[1:] {
+		definition.Others = append(definition.Others, newLocation(loc))
+	}
+	return definition, nil
+}
+
+// formatLocationStart formats where a location starts as path:line:column
+func formatLocationStart(root string, loc Location) string {
+	return fmt.Sprintf("%s:%d:%d", displayPath(root, loc.Path), loc.Range.Start.Line, loc.Range.Start.Column)
+}
+
+// formatInspectDefinition renders a definition with its numbered source
+func formatInspectDefinition(root string, definition *InspectDefinition) string {
+	if definition == nil {
+		return ""
+	}
+	var output strings.Builder
+	output.WriteString(formatLocationStart(root, definition.Location) + "\n")
+	if definition.StartLine > 0 {
+		output.WriteString(addLineNumbers(definition.Source, definition.StartLine))
+		if definition.Truncated > 0 {
+			output.WriteString(fmt.Sprintf("... %d more lines\n", definition.Truncated))
+		}
+	}
+	for _, loc := range definition.Others {
+		output.WriteString(fmt.Sprintf("Also defined at %s\n", formatLocationStart(root, loc)))
+	}
+	return output.String()
+}
+
+// summarizeReferences counts references by file, most referenced first, and
+// collects the first maxSites of them in file order with their line of code
+func summarizeReferences(refs []protocol.Location, maxSites int) *InspectReferences {
+	if len(refs) == 0 {
+		return nil
+	}
+	refs = append([]protocol.Location(nil), refs...)
+	sort.Slice(refs, func(i, j int) bool {
+		if refs[i].URI != refs[j].URI {
+			return refs[i].URI < refs[j].URI
+		}
+		if refs[i].Range.Start.Line != refs[j].Range.Start.Line {
+			return refs[i].Range.Start.Line < refs[j].Range.Start.Line
+		}
+		return refs[i].Range.Start.Character < refs[j].Range.Start.Character
+	})
+
+	counts := make(map[string]int)
+	var files []string
+	for _, ref := range refs {
+		path := ref.URI.Path()
+		if counts[path] == 0 {
+			files = append(files, path)
+		}
+		counts[path]++
+	}
+	sort.SliceStable(files, func(i, j int) bool {
+		return counts[files[i]] > counts[files[j]]
+	})
+
+	summary := &InspectReferences{Total: len(refs), Files: []ReferenceCount{}, maxSites: maxSites}
+	for _, path := range files {
+		summary.Files = append(summary.Files, ReferenceCount{Path: path, Count: counts[path]})
+	}
+
+	fileLines := make(map[string][]string)
+	for i, ref := range refs {
+		if i == maxSites {
+			break
+		}
+		path := ref.URI.Path()
+		lines, ok := fileLines[path]
+		if !ok {
+			if content, err := os.ReadFile(path); err == nil {
+				lines = strings.Split(string(content), "\n")
+			}
+			fileLines[path] = lines
+		}
+		text := ""
+		if int(ref.Range.Start.Line) < len(lines) {
+			text = strings.TrimSpace(lines[ref.Range.Start.Line])
+		}
+		summary.Sites = append(summary.Sites, ReferenceSite{Location: newLocation(ref), Text: text})
+	}
+	return summary
+}
+
+// formatInspectReferences renders a reference summary
+func formatInspectReferences(root string, summary *InspectReferences) string {
+	if summary == nil {
+		return ""
+	}
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("%d references in %d files\n", summary.Total, len(summary.Files)))
+	for _, file := range summary.Files {
+		output.WriteString(fmt.Sprintf("  %s: %d\n", displayPath(root, file.Path), file.Count))
+	}
+
+	if summary.maxSites <= 0 {
+		return output.String()
+	}
+	output.WriteString("Sites:\n")
+	for _, site := range summary.Sites {
+		output.WriteString(fmt.Sprintf("  %s: %s\n", formatLocationStart(root, site.Location), site.Text))
+	}
+	if more := summary.Total - len(summary.Sites); more > 0 {
+		output.WriteString(fmt.Sprintf("  ... %d more\n", more))
+	}
+	return output.String()
+}
+
+// enclosingSymbol returns the smallest symbol whose range contains a position
+func enclosingSymbol(symbols []skeletonSymbol, position protocol.Position) (skeletonSymbol, bool) {
+	best := -1
+	for i, symbol := range symbols {
+		if !containsPosition(symbol.rng, position) {
+			continue
+		}
+		if best < 0 || symbol.rng.End.Line-symbol.rng.Start.Line < symbols[best].rng.End.Line-symbols[best].rng.Start.Line {
+			best = i
+		}
+	}
+	if best < 0 {
+		return skeletonSymbol{}, false
+	}
+	return symbols[best], true
+}
+
+func newEnclosingSymbol(lines []string, symbol skeletonSymbol) *EnclosingSymbol {
+	enclosing := &EnclosingSymbol{
+		Name:  symbol.qualified,
+		Kind:  symbolKindToString(symbol.kind),
+		Lines: LineSpan{StartLine: int(symbol.rng.Start.Line) + 1, EndLine: int(symbol.rng.End.Line) + 1},
+	}
+	if start := int(symbol.rng.Start.Line); start < len(lines) {
+		enclosing.FirstLine = strings.TrimSpace(lines[start])
+	}
+	return enclosing
+}
+
+// formatEnclosingSymbol describes a symbol with its first line of source
+func formatEnclosingSymbol(symbol *EnclosingSymbol) string {
+	if symbol == nil {
+		return ""
+	}
+	text := fmt.Sprintf("%s %s L%d-%d", symbol.Kind, symbol.Name, symbol.Lines.StartLine, symbol.Lines.EndLine)
+	if symbol.FirstLine != "" {
+		text += "\n" + symbol.FirstLine
+	}
+	return text
+}
+
+// callSignature returns the active signature of a signature help result with
+// its active parameter, or nil if there is none
+func callSignature(help protocol.SignatureHelp) *InspectSignature {
+	if len(help.Signatures) == 0 {
+		return nil
+	}
+	index := int(help.ActiveSignature)
+	if index >= len(help.Signatures) {
+		index = 0
+	}
+	signature := help.Signatures[index]
+	result := &InspectSignature{Label: signature.Label}
+
+	active := int(help.ActiveParameter)
+	if signature.ActiveParameter != 0 {
+		active = int(signature.ActiveParameter)
+	}
+	if active < len(signature.Parameters) {
+		switch v := signature.Parameters[active].Label.Value.(type) {
+		case string:
+			result.ActiveParameter = v
+		case protocol.Tuple_ParameterInformation_label_Item1:
+			// The offsets count UTF-16 code units, not bytes
+			start := byteOffset(signature.Label, int(v.Fld0))
+			end := byteOffset(signature.Label, int(v.Fld1))
+			if start < end {
+				result.ActiveParameter = signature.Label[start:end]
+			}
+		}
+	}
+	return result
+}
+
+// formatSignature renders a signature with its active parameter
+func formatSignature(signature *InspectSignature) string {
+	if signature == nil {
+		return ""
+	}
+	text := signature.Label
+	if signature.ActiveParameter != "" {
+		text += "\nActive parameter: " + signature.ActiveParameter
+	}
+	return text
+}
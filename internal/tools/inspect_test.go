@@ -0,0 +1,95 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatInspectReferences(t *testing.T) {
+	root := t.TempDir()
+	main := filepath.Join(root, "main.go")
+	util := filepath.Join(root, "util.go")
+	require.NoError(t, os.WriteFile(main, []byte("package main\n\nfunc main() {\n\trun()\n\trun()\n}\n"), 0644))
+	require.NoError(t, os.WriteFile(util, []byte("package main\n\nvar f = run\n"), 0644))
+
+	at := func(path string, line, char uint32) protocol.Location {
+		return protocol.Location{
+			URI:   protocol.DocumentUri("file://" + path),
+			Range: protocol.Range{Start: protocol.Position{Line: line, Character: char}},
+		}
+	}
+	refs := []protocol.Location{at(util, 2, 8), at(main, 4, 1), at(main, 3, 1)}
+
+	assert.Equal(t, `3 references in 2 files
+  main.go: 2
+  util.go: 1
+Sites:
+  main.go:4:2: run()
+  main.go:5:2: run()
+  ... 1 more
+`, formatInspectReferences(root, summarizeReferences(refs, 2)))
+
+	assert.Equal(t, "3 references in 2 files\n  main.go: 2\n  util.go: 1\n", formatInspectReferences(root, summarizeReferences(refs, 0)))
+	assert.Nil(t, summarizeReferences(nil, 2))
+	assert.Equal(t, "", formatInspectReferences(root, nil))
+}
+
+func TestEnclosingSymbol(t *testing.T) {
+	symbols := []skeletonSymbol{
+		{name: "Server", qualified: "Server", kind: protocol.Class, rng: lineRange(0, 10)},
+		{name: "start", qualified: "Server.start", kind: protocol.Method, rng: lineRange(2, 5)},
+	}
+
+	symbol, ok := enclosingSymbol(symbols, protocol.Position{Line: 3, Character: 4})
+	require.True(t, ok)
+	assert.Equal(t, "Server.start", symbol.qualified)
+
+	symbol, ok = enclosingSymbol(symbols, protocol.Position{Line: 8})
+	require.True(t, ok)
+	assert.Equal(t, "Server", symbol.qualified)
+
+	_, ok = enclosingSymbol(symbols, protocol.Position{Line: 12})
+	assert.False(t, ok)
+}
+
+func TestCallSignature(t *testing.T) {
+	help := protocol.SignatureHelp{
+		Signatures: []protocol.SignatureInformation{{
+			Label: "func Add(a int, b int) int",
+			Parameters: []protocol.ParameterInformation{
+				{Label: protocol.Or_ParameterInformation_label{Value: "a int"}},
+				{Label: protocol.Or_ParameterInformation_label{Value: protocol.Tuple_ParameterInformation_label_Item1{Fld0: 16, Fld1: 21}}},
+			},
+		}},
+		ActiveParameter: 1,
+	}
+	signature := callSignature(help)
+	require.NotNil(t, signature)
+	assert.Equal(t, "b int", signature.ActiveParameter)
+	assert.Equal(t, "func Add(a int, b int) int\nActive parameter: b int", formatSignature(signature))
+
+	assert.Nil(t, callSignature(protocol.SignatureHelp{}))
+	assert.Equal(t, "", formatSignature(nil))
+}
+
+func TestCallSignatureUTF16Offsets(t *testing.T) {
+	// "é" is one UTF-16 unit but two bytes, "😀" two units and four bytes
+	help := protocol.SignatureHelp{
+		Signatures: []protocol.SignatureInformation{{
+			Label: "func Café(😀 int, b int)",
+			Parameters: []protocol.ParameterInformation{
+				{Label: protocol.Or_ParameterInformation_label{Value: protocol.Tuple_ParameterInformation_label_Item1{Fld0: 10, Fld1: 16}}},
+				{Label: protocol.Or_ParameterInformation_label{Value: protocol.Tuple_ParameterInformation_label_Item1{Fld0: 18, Fld1: 23}}},
+			},
+		}},
+	}
+	assert.Equal(t, "😀 int", callSignature(help).ActiveParameter)
+
+	help.ActiveParameter = 1
+	assert.Equal(t, "b int", callSignature(help).ActiveParameter)
+}
@@ -0,0 +1,399 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/isaacphi/mcp-language-server/internal/utilities"
+)
+
+// goPackageClause matches the package clause of a Go file
+var goPackageClause = regexp.MustCompile(`(?m)^package \w+$`)
+
+const (
+	// tsMoveToFile is tsserver's refactoring that moves declarations to a
+	// file the client chooses
+	tsMoveToFile = "Move to file"
+	// maxBrokenReferences caps the references listed when a move by text
+	// leaves them unchanged
+	maxBrokenReferences = 20
+)
+
+// MoveSymbol moves a symbol, given by its dotted path, from one file to
+// another and returns the change as a unified diff.
+//
+// A refactor.move code action from the server is used if it can move the
+// symbol to the target file, since it also updates imports and references.
+// Otherwise the symbol's declaration and doc comment are cut from the source
+// file and appended to the target, which is created if needed, and organize
+// imports is run on both files. A move by text to another directory leaves
+// the symbol's references as they are, so they are listed in a warning.
+// Either way the move is a single undoable edit.
+func MoveSymbol(ctx context.Context, client *lsp.Client, filePath, symbolPath, targetPath string) (*EditResult, error) {
+	source, err := resolveWorkspacePath(filePath)
+	if err != nil {
+		return nil, err
+	}
+	target, err := resolveWorkspacePath(targetPath)
+	if err != nil {
+		return nil, err
+	}
+	if source == target {
+		return nil, fmt.Errorf("the symbol is already in %s", target)
+	}
+
+	targets, err := findSymbolTargets(ctx, client, source, symbolPath)
+	if err != nil {
+		return nil, err
+	}
+	if len(targets) > 1 {
+		return nil, fmt.Errorf("symbol path %s is ambiguous, it matches %d symbols", symbolPath, len(targets))
+	}
+	symbol := targets[0]
+
+	_, statErr := os.Stat(target)
+	targetExists := statErr == nil
+
+	root := workspaceRoot(client)
+	var output strings.Builder
+	moved, title, err := serverMoveSymbol(ctx, client, symbol, target)
+	if err != nil {
+		return nil, err
+	}
+	entries := moved.entries
+	if title != "" {
+		output.WriteString(fmt.Sprintf("Moved %s to %s with %q\n", symbol.name, displayPath(root, target), title))
+	} else {
+		// References are looked up before the move, while their positions hold
+		warning := brokenReferencesWarning(ctx, client, root, symbol, target)
+		entries, err = moveSymbolText(ctx, client, symbol, target, targetExists)
+		if err != nil {
+			return nil, err
+		}
+		output.WriteString(fmt.Sprintf("Moved %s to %s\n", symbol.name, displayPath(root, target)))
+		output.WriteString(warning)
+	}
+	return journaledEdits(client, output.String(), entries...), nil
+}
+
+// serverMoveSymbol applies a refactor.move code action that moves the symbol
+// to target. tsserver's "Move to file" refactoring is given target as its
+// destination. Other actions, such as those of gopls and rust-analyzer, pick
+// the destination themselves, so they are only used if their edit writes
+// target. The action's title is empty if none moves the symbol there.
+func serverMoveSymbol(ctx context.Context, client *lsp.Client, symbol symbolTarget, target string) (codeActionResult, string, error) {
+	if !lsp.HasCodeActionKindSupport(client.GetCapabilities(), protocol.RefactorMove) {
+		return codeActionResult{}, "", nil
+	}
+
+	uri := protocol.DocumentUri("file://" + symbol.path)
+	items, err := client.CodeAction(ctx, protocol.CodeActionParams{
+		TextDocument: protocol.TextDocumentIdentifier{URI: uri},
+		Range:        symbol.selection,
+		Context: protocol.CodeActionContext{
+			Diagnostics: client.GetFileDiagnostics(uri),
+			Only:        []protocol.CodeActionKind{protocol.RefactorMove},
+		},
+	})
+	if err != nil {
+		toolsLogger.Warn("Failed to get move code actions: %v", err)
+		return codeActionResult{}, "", nil
+	}
+
+	for _, item := range items {
+		value, ok := item.Value.(map[string]any)
+		if !ok {
+			continue
+		}
+		action, err := decodeCodeAction(value)
+		if err != nil || action.Disabled != nil || !codeActionKindMatches(action.Kind, protocol.RefactorMove) {
+			continue
+		}
+		if !setMoveTarget(&action, target) {
+			action, err = resolveCodeAction(ctx, client, action)
+			if err != nil {
+				return codeActionResult{}, "", fmt.Errorf("failed to resolve code action: %v", err)
+			}
+			if !editWritesPath(action.Edit, target) {
+				continue
+			}
+		}
+		result, err := applyCodeAction(ctx, client, "move_symbol", action)
+		if err != nil {
+			return codeActionResult{}, "", err
+		}
+		if result.changed() {
+			return result, action.Title, nil
+		}
+	}
+	return codeActionResult{}, "", nil
+}
+
+// setMoveTarget makes target the destination of a tsserver "Move to file"
+// refactoring, which typescript-language-server runs as a command passing its
+// argument on to getEditsForRefactor. It reports whether action is one.
+func setMoveTarget(action *protocol.CodeAction, target string) bool {
+	if action.Command == nil || len(action.Command.Arguments) == 0 {
+		return false
+	}
+	var args map[string]any
+	if err := json.Unmarshal(action.Command.Arguments[0], &args); err != nil || args["refactor"] != tsMoveToFile {
+		return false
+	}
+	args["interactiveRefactorArguments"] = map[string]any{"targetFile": target}
+	data, err := json.Marshal(args)
+	if err != nil {
+		return false
+	}
+	action.Command.Arguments[0] = data
+	return true
+}
+
+// editWritesPath reports whether a workspace edit creates or changes path
+func editWritesPath(edit *protocol.WorkspaceEdit, path string) bool {
+	if edit == nil {
+		return false
+	}
+	uri := protocol.DocumentUri("file://" + path)
+	if _, ok := edit.Changes[uri]; ok {
+		return true
+	}
+	for _, change := range edit.DocumentChanges {
+		switch {
+		case change.TextDocumentEdit != nil && change.TextDocumentEdit.TextDocument.URI == uri,
+			change.CreateFile != nil && change.CreateFile.URI == uri,
+			change.RenameFile != nil && change.RenameFile.NewURI == uri:
+			return true
+		}
+	}
+	return false
+}
+
+// brokenReferencesWarning warns that moving a symbol by text to another
+// directory, and so another package or module, leaves its references as they
+// were. It lists the references outside the symbol itself, so it has to run
+// before the move. It is empty for a move within the directory.
+func brokenReferencesWarning(ctx context.Context, client *lsp.Client, root string, symbol symbolTarget, target string) string {
+	if filepath.Dir(symbol.path) == filepath.Dir(target) {
+		return ""
+	}
+	warning := fmt.Sprintf("WARNING: %s moved to another directory without updating its references", symbol.name)
+	if !lsp.HasReferencesSupport(client.GetCapabilities()) {
+		return warning + ". The language server can't list them, so check its uses by hand.\n"
+	}
+	locations, err := client.References(ctx, protocol.ReferenceParams{
+		TextDocumentPositionParams: protocol.TextDocumentPositionParams{
+			TextDocument: protocol.TextDocumentIdentifier{URI: protocol.DocumentUri("file://" + symbol.path)},
+			Position:     symbol.selection.Start,
+		},
+		Context: protocol.ReferenceContext{IncludeDeclaration: false},
+	})
+	if err != nil {
+		return fmt.Sprintf("%s. Listing them failed: %v\n", warning, err)
+	}
+
+	var refs []string
+	for _, location := range locations {
+		path := location.URI.Path()
+		if path == symbol.path && containsPosition(symbol.rng, location.Range.Start) {
+			continue
+		}
+		refs = append(refs, fmt.Sprintf("%s:L%d:C%d", displayPath(root, path),
+			location.Range.Start.Line+1, location.Range.Start.Character+1))
+	}
+	if len(refs) == 0 {
+		return ""
+	}
+
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("%s. These %d references may now be broken:\n", warning, len(refs)))
+	for i, ref := range refs {
+		if i == maxBrokenReferences {
+			output.WriteString(fmt.Sprintf("  and %d more\n", len(refs)-i))
+			break
+		}
+		output.WriteString("  " + ref + "\n")
+	}
+	return output.String()
+}
+
+// moveSymbolText cuts a symbol and its doc comment from its file, appends it
+// to target and organizes the imports of both files, recording it all as one
+// journal entry where possible. It returns the journal entries.
+func moveSymbolText(ctx context.Context, client *lsp.Client, symbol symbolTarget, target string, targetExists bool) ([]*utilities.JournalEntry, error) {
+	contents := readFiles([]string{symbol.path, target})
+	sourceContent, ok := contents[symbol.path]
+	if !ok {
+		return nil, fmt.Errorf("failed to read %s", symbol.path)
+	}
+	targetContent := contents[target]
+	header := ""
+	if strings.TrimSpace(targetContent) == "" {
+		var err error
+		if header, err = newFileHeader(target); err != nil {
+			return nil, err
+		}
+	}
+	text, removal := cutSymbol(strings.Split(sourceContent, "\n"), symbol.rng)
+
+	sourceURI := protocol.DocumentUri("file://" + symbol.path)
+	targetURI := protocol.DocumentUri("file://" + target)
+	edit := protocol.WorkspaceEdit{
+		DocumentChanges: []protocol.DocumentChange{
+			{TextDocumentEdit: &protocol.TextDocumentEdit{
+				TextDocument: protocol.OptionalVersionedTextDocumentIdentifier{
+					TextDocumentIdentifier: protocol.TextDocumentIdentifier{URI: sourceURI},
+				},
+				Edits: []protocol.Or_TextDocumentEdit_edits_Elem{{Value: removal}},
+			}},
+		},
+	}
+	if !targetExists {
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return nil, fmt.Errorf("failed to create directory for %s: %v", target, err)
+		}
+		edit.DocumentChanges = append(edit.DocumentChanges, protocol.DocumentChange{
+			CreateFile: &protocol.CreateFile{Kind: "create", URI: targetURI},
+		})
+	}
+	edit.DocumentChanges = append(edit.DocumentChanges, protocol.DocumentChange{
+		TextDocumentEdit: &protocol.TextDocumentEdit{
+			TextDocument: protocol.OptionalVersionedTextDocumentIdentifier{
+				TextDocumentIdentifier: protocol.TextDocumentIdentifier{URI: targetURI},
+			},
+			Edits: []protocol.Or_TextDocumentEdit_edits_Elem{{
+				Value: appendSymbol(targetContent, text, header),
+			}},
+		},
+	})
+
+	result, err := utilities.ApplyJournaledWorkspaceEdit("move_symbol", symbol.name, edit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to move symbol: %v", err)
+	}
+	notifyPatchedFiles(ctx, client, []patchedFile{
+		{path: symbol.path, oldPath: symbol.path, changed: true},
+		{path: target, oldPath: target, created: !targetExists, changed: targetExists},
+	})
+
+	// The imports are organized as part of the move, so one undo reverts both
+	entries := []*utilities.JournalEntry{result.Entry}
+	for _, path := range []string{symbol.path, target} {
+		entry, err := organizeImports(ctx, client, path, entries[len(entries)-1])
+		if err != nil {
+			toolsLogger.Warn("Failed to organize imports in %s: %v", path, err)
+			continue
+		}
+		if entry != nil && entry != entries[len(entries)-1] {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+// cutSymbol returns the text of a symbol with its doc comment, and the edit
+// removing it along with one blank line next to it
+func cutSymbol(lines []string, rng protocol.Range) (string, protocol.TextEdit) {
+	start, end := symbolLines(lines, rng, true)
+	text := strings.Join(lines[start:end+1], "\n")
+
+	// The last element is empty when the file ends with a newline
+	if end+2 < len(lines) && strings.TrimSpace(lines[end+1]) == "" {
+		end++
+	} else if start > 0 && strings.TrimSpace(lines[start-1]) == "" {
+		start--
+	}
+	return text, protocol.TextEdit{
+		Range: protocol.Range{
+			Start: protocol.Position{Line: uint32(start)},
+			End:   protocol.Position{Line: uint32(end), Character: uint32(len(strings.TrimSuffix(lines[end], "\r")))},
+		},
+	}
+}
+
+// appendSymbol returns the edit adding text at the end of a file, after a
+// blank line. An empty file gets header first.
+func appendSymbol(content, text, header string) protocol.TextEdit {
+	if strings.TrimSpace(content) == "" {
+		return protocol.TextEdit{NewText: header + text + "\n"}
+	}
+	lines := strings.Split(content, "\n")
+	last := len(lines) - 1
+	end := protocol.Position{Line: uint32(last), Character: uint32(len(strings.TrimSuffix(lines[last], "\r")))}
+	newText := "\n\n" + text
+	if lines[last] == "" {
+		// The file ends with a newline, which is kept after the new text
+		newText = "\n" + text
+	}
+	return protocol.TextEdit{Range: protocol.Range{Start: end, End: end}, NewText: newText}
+}
+
+// newFileHeader returns what a new file needs before a moved symbol. A Go file
+// gets the package clause of the other Go files in its directory, and can't be
+// created in a directory without any.
+func newFileHeader(target string) (string, error) {
+	if filepath.Ext(target) != ".go" {
+		return "", nil
+	}
+	dir := filepath.Dir(target)
+	paths, _ := filepath.Glob(filepath.Join(dir, "*.go"))
+	for _, path := range paths {
+		if path == target || strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		if clause := goPackageClause.FindString(string(content)); clause != "" {
+			return clause + "\n\n", nil
+		}
+	}
+	return "", fmt.Errorf("can't tell which package %s belongs to: %s has no other Go files", target, dir)
+}
+
+// organizeImports applies the edit of a file's organize imports code action,
+// adding it to entry. It returns the entry the edit was journaled in, nil if
+// there was nothing to organize.
+func organizeImports(ctx context.Context, client *lsp.Client, path string, entry *utilities.JournalEntry) (*utilities.JournalEntry, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	uri := protocol.DocumentUri("file://" + path)
+	items, err := client.CodeAction(ctx, protocol.CodeActionParams{
+		TextDocument: protocol.TextDocumentIdentifier{URI: uri},
+		Range:        documentRange(string(content)),
+		Context: protocol.CodeActionContext{
+			Diagnostics: client.GetFileDiagnostics(uri),
+			Only:        []protocol.CodeActionKind{protocol.SourceOrganizeImports},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	action, ok := selectCodeAction(items, protocol.SourceOrganizeImports)
+	if !ok {
+		return nil, nil
+	}
+	action, err = resolveCodeAction(ctx, client, action)
+	if err != nil {
+		return nil, err
+	}
+	if action.Edit == nil {
+		return nil, nil
+	}
+	result, err := utilities.DefaultJournal.Amend(entry, *action.Edit)
+	if err != nil {
+		return nil, err
+	}
+	syncJournalFiles(ctx, client, result.Committed)
+	return result.Entry, nil
+}
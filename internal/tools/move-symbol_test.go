@@ -0,0 +1,113 @@
+package tools
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/isaacphi/mcp-language-server/internal/utilities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const moveSymbolSource = `package server
+
+import "fmt"
+
+// Start starts serving.
+func Start() {
+	fmt.Println("start")
+}
+
+func Stop() {}
+`
+
+// applyTestEdit writes content to a temporary file, applies edit to it and
+// returns the result
+func applyTestEdit(t *testing.T, content string, edit protocol.TextEdit) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "file.go")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	require.NoError(t, utilities.ApplyTextEdits(protocol.DocumentUri("file://"+path), []protocol.TextEdit{edit}))
+	result, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return string(result)
+}
+
+func TestCutSymbol(t *testing.T) {
+	lines := strings.Split(moveSymbolSource, "\n")
+
+	t.Run("takes the doc comment and the blank line after", func(t *testing.T) {
+		rng := protocol.Range{Start: protocol.Position{Line: 5}, End: protocol.Position{Line: 7, Character: 1}}
+		text, edit := cutSymbol(lines, rng)
+		assert.Equal(t, "// Start starts serving.\nfunc Start() {\n\tfmt.Println(\"start\")\n}", text)
+		assert.Equal(t, "package server\n\nimport \"fmt\"\n\nfunc Stop() {}\n", applyTestEdit(t, moveSymbolSource, edit))
+	})
+
+	t.Run("takes the blank line before the last symbol", func(t *testing.T) {
+		rng := protocol.Range{Start: protocol.Position{Line: 9}, End: protocol.Position{Line: 9, Character: 14}}
+		text, edit := cutSymbol(lines, rng)
+		assert.Equal(t, "func Stop() {}", text)
+		assert.Equal(t, "package server\n\nimport \"fmt\"\n\n// Start starts serving.\nfunc Start() {\n\tfmt.Println(\"start\")\n}\n",
+			applyTestEdit(t, moveSymbolSource, edit))
+	})
+}
+
+func TestAppendSymbol(t *testing.T) {
+	text := "func Stop() {}"
+	assert.Equal(t, "package server\n\nfunc Run() {}\n\nfunc Stop() {}\n",
+		applyTestEdit(t, "package server\n\nfunc Run() {}\n", appendSymbol("package server\n\nfunc Run() {}\n", text, "")))
+	assert.Equal(t, "package server\n\nfunc Run() {}\n\nfunc Stop() {}",
+		applyTestEdit(t, "package server\n\nfunc Run() {}", appendSymbol("package server\n\nfunc Run() {}", text, "")))
+	assert.Equal(t, "package server\n\nfunc Stop() {}\n",
+		applyTestEdit(t, "", appendSymbol("", text, "package server\n\n")))
+}
+
+func TestNewFileHeader(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.go"), []byte(moveSymbolSource), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a_test.go"), []byte("package server_test\n"), 0644))
+
+	header, err := newFileHeader(filepath.Join(dir, "b.go"))
+	require.NoError(t, err)
+	assert.Equal(t, "package server\n\n", header)
+
+	_, err = newFileHeader(filepath.Join(t.TempDir(), "b.go"))
+	assert.Error(t, err, "a directory without Go files has no package to use")
+
+	header, err = newFileHeader(filepath.Join(t.TempDir(), "b.py"))
+	require.NoError(t, err)
+	assert.Equal(t, "", header)
+}
+
+func TestSetMoveTarget(t *testing.T) {
+	action := protocol.CodeAction{Title: "Move to file", Command: &protocol.Command{
+		Command:   "_typescript.applyRefactoring",
+		Arguments: []json.RawMessage{json.RawMessage(`{"file":"/ws/a.ts","refactor":"Move to file","action":"Move to file"}`)},
+	}}
+	require.True(t, setMoveTarget(&action, "/ws/b.ts"))
+	assert.JSONEq(t, `{"file":"/ws/a.ts","refactor":"Move to file","action":"Move to file","interactiveRefactorArguments":{"targetFile":"/ws/b.ts"}}`,
+		string(action.Command.Arguments[0]))
+
+	newFile := protocol.CodeAction{Title: "Move to a new file", Command: &protocol.Command{
+		Command:   "_typescript.applyRefactoring",
+		Arguments: []json.RawMessage{json.RawMessage(`{"refactor":"Move to a new file"}`)},
+	}}
+	assert.False(t, setMoveTarget(&newFile, "/ws/b.ts"))
+	assert.False(t, setMoveTarget(&protocol.CodeAction{Title: "Move"}, "/ws/b.ts"))
+}
+
+func TestEditWritesPath(t *testing.T) {
+	create := &protocol.WorkspaceEdit{DocumentChanges: []protocol.DocumentChange{
+		{CreateFile: &protocol.CreateFile{Kind: "create", URI: "file:///ws/b.go"}},
+	}}
+	assert.True(t, editWritesPath(create, "/ws/b.go"))
+	assert.False(t, editWritesPath(create, "/ws/c.go"))
+
+	changes := &protocol.WorkspaceEdit{Changes: map[protocol.DocumentUri][]protocol.TextEdit{"file:///ws/b.go": nil}}
+	assert.True(t, editWritesPath(changes, "/ws/b.go"))
+	assert.False(t, editWritesPath(nil, "/ws/b.go"))
+}
@@ -0,0 +1,358 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+const (
+	// DefaultOutlineDepth is how many levels of subdirectories are outlined by default
+	DefaultOutlineDepth = 0
+	// DefaultOutlineMaxBytes caps the size of an outline by default
+	DefaultOutlineMaxBytes = 20000
+	// maxOutlineDetail is the length signatures are cut to
+	maxOutlineDetail = 120
+)
+
+// PackageOutlineOptions selects the files and symbols of an outline
+type PackageOutlineOptions struct {
+	Directory string
+	// Language is a language ID such as "go" or "python". If empty, the most
+	// common language among the files in the directory is used.
+	Language string
+	// Depth is how many levels of subdirectories are walked, 0 for the
+	// directory itself only
+	Depth int
+	// ExportedOnly leaves out symbols that aren't visible outside their package
+	ExportedOnly bool
+	// MaxBytes caps the size of the outline; files that don't fit are counted
+	// but not shown
+	MaxBytes int
+}
+
+// outlineKinds are the kinds of symbols listed in an outline
+var outlineKinds = map[protocol.SymbolKind]bool{
+	protocol.Class:       true,
+	protocol.Struct:      true,
+	protocol.Interface:   true,
+	protocol.Enum:        true,
+	protocol.Function:    true,
+	protocol.Method:      true,
+	protocol.Constructor: true,
+}
+
+// outlineNamespaceKinds are the kinds whose members are listed without them
+var outlineNamespaceKinds = map[protocol.SymbolKind]bool{
+	protocol.Module:    true,
+	protocol.Namespace: true,
+	protocol.Package:   true,
+}
+
+// OutlineSymbol is a declaration in an outline. Depth is 1 for top-level
+// symbols and grows for members of types; Line is 1-indexed.
+type OutlineSymbol struct {
+	Name   string `json:"name"`
+	Kind   string `json:"kind"`
+	Detail string `json:"detail,omitempty"`
+	Depth  int    `json:"depth"`
+	Line   int    `json:"line"`
+}
+
+// OutlineFile is the outline of one file
+type OutlineFile struct {
+	Path    string          `json:"path"`
+	Symbols []OutlineSymbol `json:"symbols"`
+}
+
+// PackageOutlineResult is the outline of a directory. Files without
+// matching symbols are counted in Empty; the files left out once the byte
+// limit was reached are counted in Truncated.
+type PackageOutlineResult struct {
+	Directory string        `json:"directory"`
+	Language  string        `json:"language,omitempty"`
+	FileCount int           `json:"fileCount"`
+	Files     []OutlineFile `json:"files"`
+	Empty     int           `json:"empty,omitempty"`
+	Truncated int           `json:"truncated,omitempty"`
+	Failures  []string      `json:"failures,omitempty"`
+
+	maxBytes int
+}
+
+// PackageOutline renders a compact tree of the types, functions and methods
+// declared in the files of a directory, with signatures taken from the
+// document symbol details.
+//
+// Subdirectories are walked up to opts.Depth levels, skipping excluded and
+// gitignored paths, and only files of one language are outlined.
+func PackageOutline(ctx context.Context, client *lsp.Client, opts PackageOutlineOptions) (*PackageOutlineResult, error) {
+	if opts.Directory == "" {
+		return nil, fmt.Errorf("directory is required")
+	}
+	dir, err := resolveWorkspacePath(opts.Directory)
+	if err != nil {
+		return nil, err
+	}
+	if opts.MaxBytes <= 0 {
+		opts.MaxBytes = DefaultOutlineMaxBytes
+	}
+
+	root := workspaceRoot(client)
+	files, language, err := outlineFiles(root, dir, opts.Depth, protocol.LanguageKind(strings.ToLower(opts.Language)))
+	if err != nil {
+		return nil, err
+	}
+	result := &PackageOutlineResult{
+		Directory: displayPath(root, dir),
+		Language:  string(language),
+		FileCount: len(files),
+		Files:     []OutlineFile{},
+		maxBytes:  opts.MaxBytes,
+	}
+	if len(files) == 0 {
+		return result, nil
+	}
+
+	size := len(result.header())
+	for i, path := range files {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		symbols, err := outlineSymbols(ctx, client, path)
+		if err != nil {
+			result.Failures = append(result.Failures, fmt.Sprintf("%s: %v", displayPath(root, path), err))
+			continue
+		}
+
+		file := OutlineFile{Path: displayPath(root, path), Symbols: collectOutlineSymbols(symbols, language, opts.ExportedOnly)}
+		if len(file.Symbols) == 0 {
+			result.Empty++
+			continue
+		}
+		text := formatOutlineFile(file)
+		if size+len(text) > opts.MaxBytes {
+			result.Truncated = len(files) - i
+			break
+		}
+		size += len(text)
+		result.Files = append(result.Files, file)
+	}
+	return result, nil
+}
+
+func (r *PackageOutlineResult) header() string {
+	return fmt.Sprintf("Outline of %s (%d %s files):\n", r.Directory, r.FileCount, r.Language)
+}
+
+func (r *PackageOutlineResult) Format() string {
+	if r.FileCount == 0 {
+		if r.Language == "" {
+			return fmt.Sprintf("No source files found in %s", r.Directory)
+		}
+		return fmt.Sprintf("No %s files found in %s", r.Language, r.Directory)
+	}
+
+	var output strings.Builder
+	output.WriteString(r.header())
+	for _, file := range r.Files {
+		output.WriteString(formatOutlineFile(file))
+	}
+	if r.Truncated > 0 {
+		output.WriteString(fmt.Sprintf("\nOutput limit of %d bytes reached, %d more files not shown. Narrow the directory or depth, or raise maxBytes.\n",
+			r.maxBytes, r.Truncated))
+	}
+
+	if len(r.Files) == 0 && len(r.Failures) == 0 {
+		output.WriteString("\nNo matching symbols found\n")
+	} else if r.Empty > 0 {
+		output.WriteString(fmt.Sprintf("\n%d files without matching symbols not shown\n", r.Empty))
+	}
+	if len(r.Failures) > 0 {
+		output.WriteString(fmt.Sprintf("\n%d files could not be outlined:\n", len(r.Failures)))
+		for _, failure := range r.Failures {
+			output.WriteString(failure + "\n")
+		}
+	}
+	return output.String()
+}
+
+// formatOutlineFile renders a file's path followed by its symbols
+func formatOutlineFile(file OutlineFile) string {
+	var output strings.Builder
+	output.WriteString("\n" + file.Path + "\n")
+	writeOutlineSymbols(&output, file.Symbols)
+	return output.String()
+}
+
+// outlineFiles lists the files of one language in dir and up to depth levels
+// of subdirectories, sorted. If language is empty, the most common one is
+// picked and returned.
+func outlineFiles(root, dir string, depth int, language protocol.LanguageKind) ([]string, protocol.LanguageKind, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read directory: %v", err)
+	}
+	if !info.IsDir() {
+		return nil, "", fmt.Errorf("%s is not a directory", dir)
+	}
+
+	filter := newWorkspaceFilter(root)
+	byLanguage := make(map[protocol.LanguageKind][]string)
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			toolsLogger.Debug("Skipping %s: %v", path, err)
+			return nil
+		}
+		if d.IsDir() {
+			if path == dir {
+				return nil
+			}
+			rel, err := filepath.Rel(dir, path)
+			if err != nil || strings.Count(rel, string(filepath.Separator)) >= depth || filter.skipDir(path) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if filter.skipFile(path) {
+			return nil
+		}
+		if kind := lsp.DetectLanguageID(path); kind != "" {
+			byLanguage[kind] = append(byLanguage[kind], path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to walk directory: %v", err)
+	}
+
+	if language == "" {
+		for kind, files := range byLanguage {
+			if language == "" || len(files) > len(byLanguage[language]) ||
+				(len(files) == len(byLanguage[language]) && kind < language) {
+				language = kind
+			}
+		}
+	}
+	files := byLanguage[language]
+	sort.Strings(files)
+	return files, language, nil
+}
+
+// outlineSymbols requests the document symbols of a file
+func outlineSymbols(ctx context.Context, client *lsp.Client, path string) ([]protocol.DocumentSymbolResult, error) {
+	if err := client.OpenFile(ctx, path); err != nil {
+		return nil, fmt.Errorf("could not open file: %v", err)
+	}
+	result, err := client.DocumentSymbol(ctx, protocol.DocumentSymbolParams{
+		TextDocument: protocol.TextDocumentIdentifier{URI: protocol.DocumentUri("file://" + path)},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get document symbols: %v", err)
+	}
+	symbols, err := result.Results()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse symbol results: %v", err)
+	}
+	return symbols, nil
+}
+
+// collectOutlineSymbols lists the outlined symbols of a file, members
+// following their type one level deeper
+func collectOutlineSymbols(symbols []protocol.DocumentSymbolResult, language protocol.LanguageKind, exportedOnly bool) []OutlineSymbol {
+	var outline []OutlineSymbol
+	add := func(depth int, kind protocol.SymbolKind, name, detail string, line uint32) {
+		outline = append(outline, OutlineSymbol{
+			Name:   name,
+			Kind:   symbolKindToString(kind),
+			Detail: strings.Join(strings.Fields(detail), " "),
+			Depth:  depth,
+			Line:   int(line) + 1,
+		})
+	}
+
+	var walk func(symbol *protocol.DocumentSymbol, depth int)
+	walk = func(symbol *protocol.DocumentSymbol, depth int) {
+		if outlineNamespaceKinds[symbol.Kind] {
+			for i := range symbol.Children {
+				walk(&symbol.Children[i], depth)
+			}
+			return
+		}
+		if !outlineKinds[symbol.Kind] || (exportedOnly && !isExportedName(symbol.Name, language)) {
+			return
+		}
+		add(depth, symbol.Kind, symbol.Name, symbol.Detail, symbol.SelectionRange.Start.Line)
+		for i := range symbol.Children {
+			walk(&symbol.Children[i], depth+1)
+		}
+	}
+
+	for _, result := range symbols {
+		switch v := result.(type) {
+		case *protocol.DocumentSymbol:
+			walk(v, 1)
+		case *protocol.SymbolInformation:
+			if !outlineKinds[v.Kind] || (exportedOnly && !isExportedName(v.Name, language)) {
+				continue
+			}
+			name := v.Name
+			if v.ContainerName != "" {
+				name = v.ContainerName + "." + name
+			}
+			add(1, v.Kind, name, "", v.Location.Range.Start.Line)
+		}
+	}
+	return outline
+}
+
+// writeOutlineSymbols writes one line per outlined symbol, formatted as
+// "Kind name: detail L<line>" and indented by depth
+func writeOutlineSymbols(output *strings.Builder, symbols []OutlineSymbol) {
+	for _, symbol := range symbols {
+		text := strings.Repeat("  ", symbol.Depth) + symbol.Kind + " " + symbol.Name
+		if detail := symbol.Detail; detail != "" {
+			if len(detail) > maxOutlineDetail {
+				cut := maxOutlineDetail
+				for cut > 0 && !utf8.RuneStart(detail[cut]) {
+					cut--
+				}
+				detail = detail[:cut] + "..."
+			}
+			text += ": " + detail
+		}
+		output.WriteString(fmt.Sprintf("%s L%d\n", text, symbol.Line))
+	}
+}
+
+// isExportedName reports whether a symbol is visible outside its package. Go
+// exports capitalized names; elsewhere a leading underscore or # marks a name
+// as private by convention, except for dunder names. Qualified names such as
+// "(*T).Method" are judged by their last part.
+func isExportedName(name string, language protocol.LanguageKind) bool {
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		name = name[i+1:]
+	}
+	if name == "" {
+		return false
+	}
+	if language == protocol.LangGo {
+		r, _ := utf8.DecodeRuneInString(name)
+		return unicode.IsUpper(r)
+	}
+	if strings.HasPrefix(name, "__") && strings.HasSuffix(name, "__") {
+		// Special methods such as __init__ are public
+		return true
+	}
+	return !strings.HasPrefix(name, "_") && !strings.HasPrefix(name, "#")
+}
@@ -0,0 +1,132 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutlineFiles(t *testing.T) {
+	root := t.TempDir()
+	for _, file := range []string{
+		".gitignore",
+		"pkg/a.go",
+		"pkg/b.go",
+		"pkg/script.py",
+		"pkg/sub/c.go",
+		"pkg/sub/deep/d.go",
+		"pkg/gen/e.go",
+		"pkg/node_modules/f.go",
+	} {
+		path := filepath.Join(root, file)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		content := ""
+		if file == ".gitignore" {
+			content = "gen/\n"
+		}
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	dir := filepath.Join(root, "pkg")
+
+	files, language, err := outlineFiles(root, dir, 0, "")
+	require.NoError(t, err)
+	assert.Equal(t, protocol.LangGo, language, "the most common language is picked")
+	assert.Equal(t, []string{filepath.Join(dir, "a.go"), filepath.Join(dir, "b.go")}, files)
+
+	files, _, err = outlineFiles(root, dir, 1, protocol.LangGo)
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "a.go"), filepath.Join(dir, "b.go"), filepath.Join(dir, "sub/c.go")}, files,
+		"gitignored and excluded directories are skipped")
+
+	files, _, err = outlineFiles(root, dir, 5, protocol.LangGo)
+	require.NoError(t, err)
+	assert.Len(t, files, 4)
+
+	files, language, err = outlineFiles(root, dir, 0, protocol.LangPython)
+	require.NoError(t, err)
+	assert.Equal(t, protocol.LangPython, language)
+	assert.Equal(t, []string{filepath.Join(dir, "script.py")}, files)
+
+	_, _, err = outlineFiles(root, filepath.Join(dir, "a.go"), 0, "")
+	assert.Error(t, err)
+}
+
+func TestWriteOutlineSymbols(t *testing.T) {
+	symbols := []protocol.DocumentSymbolResult{
+		&protocol.DocumentSymbol{
+			Name:           "Server",
+			Kind:           protocol.Struct,
+			Detail:         "struct{...}",
+			SelectionRange: protocol.Range{Start: protocol.Position{Line: 4}},
+			Children: []protocol.DocumentSymbol{
+				{Name: "addr", Kind: protocol.Field, Detail: "string"},
+			},
+		},
+		&protocol.DocumentSymbol{
+			Name:           "(*Server).Start",
+			Kind:           protocol.Method,
+			Detail:         "func(ctx context.Context,\n\taddr string) error",
+			SelectionRange: protocol.Range{Start: protocol.Position{Line: 9}},
+		},
+		&protocol.DocumentSymbol{
+			Name:           "(*Server).stop",
+			Kind:           protocol.Method,
+			Detail:         "func()",
+			SelectionRange: protocol.Range{Start: protocol.Position{Line: 19}},
+		},
+		&protocol.DocumentSymbol{
+			Name: "limit",
+			Kind: protocol.Constant,
+		},
+	}
+
+	var output strings.Builder
+	writeOutlineSymbols(&output, collectOutlineSymbols(symbols, protocol.LangGo, false))
+	assert.Equal(t, "  Struct Server: struct{...} L5\n"+
+		"  Method (*Server).Start: func(ctx context.Context, addr string) error L10\n"+
+		"  Method (*Server).stop: func() L20\n", output.String())
+
+	output.Reset()
+	writeOutlineSymbols(&output, collectOutlineSymbols(symbols, protocol.LangGo, true))
+	assert.NotContains(t, output.String(), "stop")
+
+	pythonSymbols := []protocol.DocumentSymbolResult{
+		&protocol.DocumentSymbol{
+			Name:           "Handler",
+			Kind:           protocol.Class,
+			SelectionRange: protocol.Range{Start: protocol.Position{Line: 0}},
+			Children: []protocol.DocumentSymbol{
+				{Name: "__init__", Kind: protocol.Constructor, SelectionRange: protocol.Range{Start: protocol.Position{Line: 1}}},
+				{Name: "handle", Kind: protocol.Method, SelectionRange: protocol.Range{Start: protocol.Position{Line: 3}}},
+				{Name: "_parse", Kind: protocol.Method, SelectionRange: protocol.Range{Start: protocol.Position{Line: 5}}},
+			},
+		},
+	}
+	output.Reset()
+	writeOutlineSymbols(&output, collectOutlineSymbols(pythonSymbols, protocol.LangPython, true))
+	assert.Equal(t, "  Class Handler L1\n    Constructor __init__ L2\n    Method handle L4\n", output.String())
+}
+
+func TestWriteOutlineSymbolsTruncatesOnRuneBoundary(t *testing.T) {
+	// The cut at maxOutlineDetail bytes falls inside the two-byte "é"
+	detail := strings.Repeat("a", maxOutlineDetail-1) + "éb"
+	var output strings.Builder
+	writeOutlineSymbols(&output, []OutlineSymbol{{Kind: "Function", Name: "f", Detail: detail, Line: 1}})
+	assert.Equal(t, "Function f: "+strings.Repeat("a", maxOutlineDetail-1)+"... L1\n", output.String())
+	assert.True(t, utf8.ValidString(output.String()))
+}
+
+func TestIsExportedName(t *testing.T) {
+	assert.True(t, isExportedName("Server", protocol.LangGo))
+	assert.False(t, isExportedName("server", protocol.LangGo))
+	assert.True(t, isExportedName("(*server).Start", protocol.LangGo))
+	assert.False(t, isExportedName("_private", protocol.LangPython))
+	assert.True(t, isExportedName("handle", protocol.LangTypeScript))
+	assert.False(t, isExportedName("#field", protocol.LangJavaScript))
+}
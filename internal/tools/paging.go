@@ -0,0 +1,184 @@
+package tools
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	// DefaultMaxTokens caps the output of a paged tool call when no limit is given
+	DefaultMaxTokens = 20000
+	// charsPerToken is the rough number of characters per token used to
+	// estimate output size
+	charsPerToken = 4
+	// maxOmittedFiles caps the files named in the summary of what a page left out
+	maxOmittedFiles = 10
+)
+
+// PageOptions limits how much of a result one call returns
+type PageOptions struct {
+	// MaxTokens is the estimated output budget, DefaultMaxTokens if 0
+	MaxTokens int
+	// MaxResults caps the number of results, unlimited if 0
+	MaxResults int
+	// Cursor is the NextCursor of the previous page, empty for the first
+	Cursor string
+	// Key identifies the request, so a cursor can't be passed to a call with
+	// different arguments
+	Key string
+}
+
+// OmittedFile counts the results in a file that a page left out
+type OmittedFile struct {
+	Path  string `json:"path"`
+	Count int    `json:"count"`
+}
+
+// Page describes the part of a result returned by one call. Results after the
+// page are summarised by file in Omitted and returned by calling again with
+// NextCursor.
+type Page struct {
+	Offset     int           `json:"offset"`
+	Count      int           `json:"count"`
+	Total      int           `json:"total"`
+	Omitted    []OmittedFile `json:"omitted,omitempty"`
+	NextCursor string        `json:"nextCursor,omitempty"`
+}
+
+// pageItem is one result of a paged result: the file it is in and its
+// estimated size in characters
+type pageItem struct {
+	file string
+	size int
+}
+
+// pageable is a result made of a list of results that can be returned a page
+// at a time. Items are in a fixed order, usually grouped by file.
+type pageable interface {
+	Result
+	pageItems() []pageItem
+	// keepPage drops every item outside start to end and records the page
+	keepPage(start, end int, page *Page)
+}
+
+// Paginate cuts a result down to the page selected by opts. Results are taken
+// in order until MaxResults or the token budget is reached; a page cut by the
+// budget ends at a file boundary when at least one whole file fits. At least
+// one result is always returned. Results that aren't pageable, and results
+// that fit in one page, are left unchanged.
+func Paginate(result Result, opts PageOptions) error {
+	paged, ok := result.(pageable)
+	if !ok {
+		return nil
+	}
+	items := paged.pageItems()
+	offset, err := decodeCursor(opts.Cursor, opts.Key)
+	if err != nil {
+		return err
+	}
+	if offset > len(items) || (offset > 0 && offset == len(items)) {
+		return fmt.Errorf("cursor is past the end of the %d results", len(items))
+	}
+
+	budget := opts.MaxTokens
+	if budget <= 0 {
+		budget = DefaultMaxTokens
+	}
+	budget *= charsPerToken
+
+	end, size := offset, 0
+	for end < len(items) {
+		if opts.MaxResults > 0 && end-offset >= opts.MaxResults {
+			break
+		}
+		if end > offset && size+items[end].size > budget {
+			if items[end].file == items[end-1].file {
+				fileStart := end - 1
+				for fileStart > offset && items[fileStart-1].file == items[end].file {
+					fileStart--
+				}
+				if fileStart > offset {
+					end = fileStart
+				}
+			}
+			break
+		}
+		size += items[end].size
+		end++
+	}
+	if offset == 0 && end == len(items) {
+		return nil
+	}
+
+	page := &Page{Offset: offset, Count: end - offset, Total: len(items)}
+	omitted := make(map[string]int)
+	for _, item := range items[end:] {
+		if i, ok := omitted[item.file]; ok {
+			page.Omitted[i].Count++
+			continue
+		}
+		omitted[item.file] = len(page.Omitted)
+		page.Omitted = append(page.Omitted, OmittedFile{Path: item.file, Count: 1})
+	}
+	if end < len(items) {
+		page.NextCursor = encodeCursor(opts.Key, end)
+	}
+	paged.keepPage(offset, end, page)
+	return nil
+}
+
+// Format summarises what the page left out and how to get the next page
+func (p *Page) Format() string {
+	if p == nil {
+		return ""
+	}
+
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("\nShowing results %d-%d of %d.", p.Offset+1, p.Offset+p.Count, p.Total))
+	if len(p.Omitted) > 0 {
+		omitted := 0
+		var files []string
+		for i, file := range p.Omitted {
+			omitted += file.Count
+			if i < maxOmittedFiles {
+				files = append(files, fmt.Sprintf("%s (%d)", file.Path, file.Count))
+			}
+		}
+		if len(p.Omitted) > maxOmittedFiles {
+			files = append(files, fmt.Sprintf("and %d more files", len(p.Omitted)-maxOmittedFiles))
+		}
+		output.WriteString(fmt.Sprintf(" Omitted %d results in %d files: %s.", omitted, len(p.Omitted), strings.Join(files, ", ")))
+	}
+	if p.NextCursor != "" {
+		output.WriteString(fmt.Sprintf(" Pass cursor %q with the same arguments to get the next page.", p.NextCursor))
+	}
+	output.WriteString("\n")
+	return output.String()
+}
+
+// encodeCursor makes an opaque cursor for the page starting at offset
+func encodeCursor(key string, offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(key + ":" + strconv.Itoa(offset)))
+}
+
+// decodeCursor returns the offset a cursor starts at, 0 for an empty cursor
+func decodeCursor(cursor, key string) (int, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, fmt.Errorf("invalid cursor: %v", err)
+	}
+	cursorKey, value, ok := strings.Cut(string(data), ":")
+	offset, err := strconv.Atoi(value)
+	if !ok || err != nil || offset < 0 {
+		return 0, fmt.Errorf("invalid cursor %q", cursor)
+	}
+	if cursorKey != key {
+		return 0, fmt.Errorf("cursor %q is for a call with different arguments", cursor)
+	}
+	return offset, nil
+}
@@ -0,0 +1,95 @@
+package tools
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func unusedResult(paths ...string) *FindUnusedResult {
+	result := &FindUnusedResult{Files: len(paths), Checked: len(paths), Unused: []UnusedSymbol{}}
+	for i, path := range paths {
+		result.Unused = append(result.Unused, UnusedSymbol{Path: path, Line: i + 1, Name: fmt.Sprintf("f%d", i), Kind: "Function"})
+	}
+	return result
+}
+
+func TestPaginate(t *testing.T) {
+	t.Run("leaves a result that fits unchanged", func(t *testing.T) {
+		result := unusedResult("a.go", "b.go")
+		require.NoError(t, Paginate(result, PageOptions{Key: "k"}))
+		assert.Len(t, result.Unused, 2)
+		assert.Nil(t, result.Page)
+	})
+
+	t.Run("pages by result count", func(t *testing.T) {
+		result := unusedResult("a.go", "a.go", "b.go", "c.go", "c.go")
+		require.NoError(t, Paginate(result, PageOptions{MaxResults: 2, Key: "k"}))
+		assert.Equal(t, []string{"f0", "f1"}, []string{result.Unused[0].Name, result.Unused[1].Name})
+		assert.Equal(t, 0, result.Page.Offset)
+		assert.Equal(t, 2, result.Page.Count)
+		assert.Equal(t, 5, result.Page.Total)
+		assert.Equal(t, []OmittedFile{{Path: "b.go", Count: 1}, {Path: "c.go", Count: 2}}, result.Page.Omitted)
+		assert.Contains(t, result.Format(), "5 have no references")
+		assert.Contains(t, result.Format(), "Showing results 1-2 of 5. Omitted 3 results in 2 files: b.go (1), c.go (2).")
+
+		next := unusedResult("a.go", "a.go", "b.go", "c.go", "c.go")
+		require.NoError(t, Paginate(next, PageOptions{MaxResults: 2, Cursor: result.Page.NextCursor, Key: "k"}))
+		assert.Equal(t, "f2", next.Unused[0].Name)
+		assert.Equal(t, 2, next.Page.Offset)
+
+		last := unusedResult("a.go", "a.go", "b.go", "c.go", "c.go")
+		require.NoError(t, Paginate(last, PageOptions{MaxResults: 2, Cursor: next.Page.NextCursor, Key: "k"}))
+		assert.Len(t, last.Unused, 1)
+		assert.Empty(t, last.Page.NextCursor)
+		assert.Empty(t, last.Page.Omitted)
+	})
+
+	t.Run("ends a page cut by the token budget at a file boundary", func(t *testing.T) {
+		result := unusedResult("a.go", "b.go", "b.go", "b.go")
+		size := len(formatUnusedSymbol(result.Unused[0]))
+		require.NoError(t, Paginate(result, PageOptions{MaxTokens: (size*3 + 3) / charsPerToken, Key: "k"}))
+		assert.Len(t, result.Unused, 1, "b.go is left whole for the next page")
+
+		result = unusedResult("b.go", "b.go", "b.go")
+		require.NoError(t, Paginate(result, PageOptions{MaxTokens: 1, Key: "k"}))
+		assert.Len(t, result.Unused, 1, "a file bigger than the budget is split")
+	})
+
+	t.Run("rejects foreign cursors", func(t *testing.T) {
+		assert.Error(t, Paginate(unusedResult("a.go"), PageOptions{Cursor: "%%%", Key: "k"}))
+		assert.Error(t, Paginate(unusedResult("a.go"), PageOptions{Cursor: encodeCursor("other", 1), Key: "k"}))
+		assert.Error(t, Paginate(unusedResult("a.go"), PageOptions{Cursor: encodeCursor("k", 1), Key: "k"}))
+	})
+}
+
+func TestPaginateReferences(t *testing.T) {
+	location := func(path string, line uint32) protocol.Location {
+		return protocol.Location{URI: protocol.DocumentUri("file://" + path), Range: protocol.Range{Start: protocol.Position{Line: line}}}
+	}
+	symbols := []skeletonSymbol{
+		{qualified: "Run", kind: protocol.Function, rng: protocol.Range{Start: protocol.Position{Line: 0}, End: protocol.Position{Line: 2}}},
+		{qualified: "Stop", kind: protocol.Function, rng: protocol.Range{Start: protocol.Position{Line: 3}, End: protocol.Position{Line: 5}}},
+	}
+	lines := strings.Split("func Run() {\n\tuse()\n}\nfunc Stop() {\n\tuse()\n}", "\n")
+	aRefs := []protocol.Location{location("/ws/a.go", 1), location("/ws/a.go", 4)}
+	bRefs := []protocol.Location{location("/ws/b.go", 1)}
+	result := &ReferencesResult{Symbol: "use", Files: []FileReferences{
+		{Path: "/ws/a.go", References: []Reference{{Container: "Run"}, {Container: "Stop"}}, refs: aRefs, lines: lines, groups: groupReferences(symbols, aRefs)},
+		{Path: "/ws/b.go", References: []Reference{{Container: "Run"}}, refs: bRefs, lines: lines, groups: groupReferences(symbols, bRefs)},
+	}}
+
+	require.NoError(t, Paginate(result, PageOptions{MaxResults: 1, Cursor: encodeCursor("k", 1), Key: "k"}))
+	require.Len(t, result.Files, 1)
+	file := result.Files[0]
+	assert.Equal(t, "/ws/a.go", file.Path)
+	assert.Equal(t, []Reference{{Container: "Stop"}}, file.References)
+	require.Len(t, file.groups, 1)
+	assert.Equal(t, "Stop", file.groups[0].symbol.qualified)
+	assert.Equal(t, []OmittedFile{{Path: "/ws/b.go", Count: 1}}, result.Page.Omitted)
+	assert.Contains(t, result.Format(), "References in File: 1\nAt: L5:C1\n")
+}
@@ -0,0 +1,323 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+// symbolTarget is a declaration found by its symbol path
+type symbolTarget struct {
+	path string
+	// name is the symbol's qualified name as reported by the server
+	name      string
+	kind      protocol.SymbolKind
+	rng       protocol.Range
+	selection protocol.Range
+}
+
+// SymbolSource is the source of a symbol. Lines are the lines shown, which
+// start above the symbol's range when docs are included.
+type SymbolSource struct {
+	Name string `json:"name"`
+	Kind string `json:"kind"`
+	Path string `json:"path"`
+	// Hash is the SHA-256 of the file content, for edit_file's expectedHash
+	Hash   string   `json:"hash"`
+	Range  Range    `json:"range"`
+	Lines  LineSpan `json:"lines"`
+	Source string   `json:"source"`
+}
+
+// ReadSymbolResult lists the symbols matching a symbol path
+type ReadSymbolResult struct {
+	Symbols []SymbolSource `json:"symbols"`
+}
+
+// ReadSymbol returns the exact source of the symbol at a dotted path such as
+// "Server.handleRequest". With a file, the path is followed through the file's
+// document symbol tree; without one, the last part of the path is looked up
+// with workspace/symbol and the files found are searched, so leading package
+// or module qualifiers may be given. With includeDocs the doc comment,
+// decorators and attributes directly above the symbol are included.
+func ReadSymbol(ctx context.Context, client *lsp.Client, filePath, symbolPath string, includeDocs bool) (*ReadSymbolResult, error) {
+	targets, err := findSymbolTargets(ctx, client, filePath, symbolPath)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ReadSymbolResult{Symbols: []SymbolSource{}}
+	for _, target := range targets {
+		content, err := os.ReadFile(target.path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read file: %v", err)
+		}
+		lines := strings.Split(string(content), "\n")
+
+		start, end := symbolLines(lines, target.rng, includeDocs)
+		result.Symbols = append(result.Symbols, SymbolSource{
+			Name:   target.name,
+			Kind:   symbolKindToString(target.kind),
+			Path:   target.path,
+			Hash:   ContentHash(content),
+			Range:  newRange(target.rng),
+			Lines:  LineSpan{StartLine: start + 1, EndLine: end + 1},
+			Source: strings.Join(lines[start:end+1], "\n"),
+		})
+	}
+	return result, nil
+}
+
+func (r *ReadSymbolResult) Format() string {
+	var sections []string
+	for _, symbol := range r.Symbols {
+		var section strings.Builder
+		section.WriteString(fmt.Sprintf("Symbol: %s (%s)\n", symbol.Name, symbol.Kind))
+		section.WriteString(fmt.Sprintf("File: %s\n", symbol.Path))
+		section.WriteString(fmt.Sprintf("File hash: %s\n", symbol.Hash))
+		section.WriteString(fmt.Sprintf("Range: L%d:C%d - L%d:C%d\n\n",
+			symbol.Range.Start.Line, symbol.Range.Start.Column,
+			symbol.Range.End.Line, symbol.Range.End.Column))
+		section.WriteString(addLineNumbers(symbol.Source, symbol.Lines.StartLine))
+		sections = append(sections, section.String())
+	}
+	return strings.Join(sections, "\n---\n\n")
+}
+
+// findSymbolTargets resolves a symbol path in a file, or across the workspace
+// if filePath is empty. It fails if nothing matches.
+func findSymbolTargets(ctx context.Context, client *lsp.Client, filePath, symbolPath string) ([]symbolTarget, error) {
+	segments := splitSymbolPath(symbolPath)
+	if len(segments) == 0 {
+		return nil, fmt.Errorf("symbol path is empty")
+	}
+
+	if filePath != "" {
+		symbols, err := fileDocumentSymbols(ctx, client, filePath)
+		if err != nil {
+			return nil, err
+		}
+		targets := matchSymbolPath(filePath, symbols, segments)
+		if len(targets) == 0 {
+			return nil, fmt.Errorf("symbol %s not found in %s; top-level symbols are: %s",
+				symbolPath, filePath, strings.Join(topLevelSymbolNames(symbols), ", "))
+		}
+		return targets, nil
+	}
+
+	targets, err := findWorkspaceSymbolTargets(ctx, client, segments)
+	if err != nil {
+		return nil, err
+	}
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("symbol %s not found in the workspace", symbolPath)
+	}
+	return targets, nil
+}
+
+// findWorkspaceSymbolTargets searches the files workspace/symbol reports for
+// the last segment. Leading segments that don't match a symbol are accepted as
+// qualifiers if they name the file, one of its directories or the symbol's
+// container.
+func findWorkspaceSymbolTargets(ctx context.Context, client *lsp.Client, segments []string) ([]symbolTarget, error) {
+	last := segments[len(segments)-1]
+	result, err := client.Symbol(ctx, protocol.WorkspaceSymbolParams{Query: last})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search workspace symbols: %v", err)
+	}
+	results, err := result.Results()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse results: %v", err)
+	}
+
+	containers := make(map[string][]string)
+	for _, symbol := range results {
+		name := normalizeSymbolName(symbol.GetName())
+		if name != last && !strings.HasSuffix(name, "."+last) {
+			continue
+		}
+		path := symbol.GetLocation().URI.Path()
+		switch v := symbol.(type) {
+		case *protocol.SymbolInformation:
+			containers[path] = append(containers[path], v.ContainerName)
+		case *protocol.WorkspaceSymbol:
+			containers[path] = append(containers[path], v.ContainerName)
+		default:
+			containers[path] = append(containers[path], "")
+		}
+	}
+
+	paths := make([]string, 0, len(containers))
+	for path := range containers {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var targets []symbolTarget
+	for _, path := range paths {
+		symbols, err := fileDocumentSymbols(ctx, client, path)
+		if err != nil {
+			toolsLogger.Warn("Failed to get symbols for %s: %v", path, err)
+			continue
+		}
+		for drop := 0; drop < len(segments); drop++ {
+			if drop > 0 && !qualifiesPath(segments[drop-1], path, containers[path]) {
+				break
+			}
+			if found := matchSymbolPath(path, symbols, segments[drop:]); len(found) > 0 {
+				targets = append(targets, found...)
+				break
+			}
+		}
+	}
+	return targets, nil
+}
+
+// fileDocumentSymbols opens a file and returns its document symbols
+func fileDocumentSymbols(ctx context.Context, client *lsp.Client, filePath string) ([]protocol.DocumentSymbolResult, error) {
+	err := client.OpenFile(ctx, filePath)
+	if err != nil {
+		return nil, fmt.Errorf("could not open file: %v", err)
+	}
+	result, err := client.DocumentSymbol(ctx, protocol.DocumentSymbolParams{
+		TextDocument: protocol.TextDocumentIdentifier{URI: protocol.DocumentUri("file://" + filePath)},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get document symbols: %v", err)
+	}
+	symbols, err := result.Results()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse symbol results: %v", err)
+	}
+	return symbols, nil
+}
+
+// splitSymbolPath splits "Server.handleRequest" or "Server::handle_request"
+// into its segments
+func splitSymbolPath(symbolPath string) []string {
+	var segments []string
+	for _, segment := range strings.Split(strings.ReplaceAll(symbolPath, "::", "."), ".") {
+		if segment = strings.TrimSpace(segment); segment != "" {
+			segments = append(segments, segment)
+		}
+	}
+	return segments
+}
+
+// normalizeSymbolName strips the decoration servers add to symbol names, so
+// that gopls's "(*Server).Start" reads "Server.Start" and rust-analyzer's
+// "impl Display for Server" reads "Server"
+func normalizeSymbolName(name string) string {
+	name = strings.NewReplacer("(", "", ")", "", "*", "", "::", ".").Replace(name)
+	if strings.HasPrefix(name, "impl ") {
+		name = strings.TrimPrefix(name, "impl ")
+		if i := strings.LastIndex(name, " for "); i >= 0 {
+			name = name[i+len(" for "):]
+		}
+		if i := strings.Index(name, "<"); i >= 0 {
+			name = name[:i]
+		}
+	}
+	return strings.TrimSpace(name)
+}
+
+// matchSymbolPath follows segments down a file's document symbol tree. A
+// symbol whose name has several parts, like gopls's "(*Server).Start", matches
+// as many segments at once. Every match is returned, so overloads all appear.
+func matchSymbolPath(path string, symbols []protocol.DocumentSymbolResult, segments []string) []symbolTarget {
+	var targets []symbolTarget
+	var walk func(symbol *protocol.DocumentSymbol, segments []string, container string)
+	walk = func(symbol *protocol.DocumentSymbol, segments []string, container string) {
+		qualified := symbol.Name
+		if container != "" {
+			qualified = container + "." + symbol.Name
+		}
+		name := normalizeSymbolName(symbol.Name)
+		for n := len(segments); n >= 1; n-- {
+			if name != strings.Join(segments[:n], ".") {
+				continue
+			}
+			if n == len(segments) {
+				targets = append(targets, symbolTarget{
+					path:      path,
+					name:      qualified,
+					kind:      symbol.Kind,
+					rng:       symbol.Range,
+					selection: symbol.SelectionRange,
+				})
+				continue
+			}
+			for i := range symbol.Children {
+				walk(&symbol.Children[i], segments[n:], qualified)
+			}
+		}
+	}
+
+	full := strings.Join(segments, ".")
+	for _, result := range symbols {
+		switch v := result.(type) {
+		case *protocol.DocumentSymbol:
+			walk(v, segments, "")
+		case *protocol.SymbolInformation:
+			qualified := v.Name
+			if v.ContainerName != "" {
+				qualified = v.ContainerName + "." + v.Name
+			}
+			if normalizeSymbolName(qualified) == full || normalizeSymbolName(v.Name) == full {
+				targets = append(targets, symbolTarget{
+					path:      path,
+					name:      qualified,
+					kind:      v.Kind,
+					rng:       v.Location.Range,
+					selection: v.Location.Range,
+				})
+			}
+		}
+	}
+	return targets
+}
+
+// qualifiesPath reports whether a leading path segment names the file, one of
+// its directories, or the container of the symbol found there
+func qualifiesPath(segment, path string, containers []string) bool {
+	base := filepath.Base(path)
+	if segment == strings.TrimSuffix(base, filepath.Ext(base)) {
+		return true
+	}
+	for _, dir := range strings.Split(filepath.ToSlash(filepath.Dir(path)), "/") {
+		if segment == dir {
+			return true
+		}
+	}
+	for _, container := range containers {
+		parts := strings.FieldsFunc(normalizeSymbolName(container), func(r rune) bool { return r == '.' || r == '/' })
+		for _, part := range parts {
+			if segment == part {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// topLevelSymbolNames lists the names at the root of a document symbol tree
+func topLevelSymbolNames(symbols []protocol.DocumentSymbolResult) []string {
+	var names []string
+	for _, result := range symbols {
+		switch v := result.(type) {
+		case *protocol.DocumentSymbol:
+			names = append(names, v.Name)
+		case *protocol.SymbolInformation:
+			if v.ContainerName == "" {
+				names = append(names, v.Name)
+			}
+		}
+	}
+	return names
+}
@@ -0,0 +1,63 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchSymbolPath(t *testing.T) {
+	goSymbols := []protocol.DocumentSymbolResult{
+		&protocol.DocumentSymbol{Name: "Server", Kind: protocol.Struct, Range: lineRange(2, 5), Children: []protocol.DocumentSymbol{
+			{Name: "addr", Kind: protocol.Field, Range: lineRange(3, 3)},
+		}},
+		&protocol.DocumentSymbol{Name: "(*Server).Start", Kind: protocol.Method, Range: lineRange(7, 10)},
+		&protocol.DocumentSymbol{Name: "(*Client).Start", Kind: protocol.Method, Range: lineRange(12, 15)},
+	}
+	targets := matchSymbolPath("/ws/server.go", goSymbols, []string{"Server", "Start"})
+	if assert.Len(t, targets, 1) {
+		assert.Equal(t, "(*Server).Start", targets[0].name)
+		assert.Equal(t, uint32(7), targets[0].rng.Start.Line)
+	}
+	assert.Len(t, matchSymbolPath("/ws/server.go", goSymbols, []string{"Server", "addr"}), 1)
+	assert.Empty(t, matchSymbolPath("/ws/server.go", goSymbols, []string{"Start"}))
+
+	pythonSymbols := []protocol.DocumentSymbolResult{
+		&protocol.DocumentSymbol{Name: "Server", Kind: protocol.Class, Range: lineRange(0, 20), Children: []protocol.DocumentSymbol{
+			{Name: "handle_request", Kind: protocol.Method, Range: lineRange(4, 8)},
+			{Name: "Config", Kind: protocol.Class, Range: lineRange(10, 14), Children: []protocol.DocumentSymbol{
+				{Name: "load", Kind: protocol.Method, Range: lineRange(11, 14)},
+			}},
+		}},
+	}
+	targets = matchSymbolPath("/ws/server.py", pythonSymbols, splitSymbolPath("Server.Config.load"))
+	if assert.Len(t, targets, 1) {
+		assert.Equal(t, "Server.Config.load", targets[0].name)
+	}
+
+	rustSymbols := []protocol.DocumentSymbolResult{
+		&protocol.DocumentSymbol{Name: "Server", Kind: protocol.Struct, Range: lineRange(0, 3)},
+		&protocol.DocumentSymbol{Name: "impl Display for Server", Kind: protocol.Object, Range: lineRange(5, 10), Children: []protocol.DocumentSymbol{
+			{Name: "fmt", Kind: protocol.Method, Range: lineRange(6, 9)},
+		}},
+	}
+	assert.Len(t, matchSymbolPath("/ws/lib.rs", rustSymbols, splitSymbolPath("Server::fmt")), 1)
+
+	flatSymbols := []protocol.DocumentSymbolResult{
+		&protocol.SymbolInformation{Name: "handle", Kind: protocol.Method, ContainerName: "Server"},
+		&protocol.SymbolInformation{Name: "handle", Kind: protocol.Method, ContainerName: "Client"},
+	}
+	targets = matchSymbolPath("/ws/a.ts", flatSymbols, []string{"Client", "handle"})
+	if assert.Len(t, targets, 1) {
+		assert.Equal(t, "Client.handle", targets[0].name)
+	}
+}
+
+func TestQualifiesPath(t *testing.T) {
+	assert.True(t, qualifiesPath("models", "/ws/app/models.py", nil))
+	assert.True(t, qualifiesPath("app", "/ws/app/models.py", nil))
+	assert.True(t, qualifiesPath("tools", "/ws/internal/x.go", []string{"github.com/x/tools"}))
+	assert.False(t, qualifiesPath("other", "/ws/app/models.py", []string{"Server"}))
+	assert.Equal(t, []string{"a", "b", "c"}, splitSymbolPath("a.b::c"))
+}
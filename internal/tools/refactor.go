@@ -0,0 +1,417 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+// refactorSpec describes which code actions count as a refactor kind.
+//
+// Servers disagree on how specific their kinds are: gopls and tsserver use
+// refactor.extract.function, while rust-analyzer and clangd report the generic
+// refactor.extract or refactor kinds. Actions with a generic kind are matched
+// by their title instead.
+type refactorSpec struct {
+	kinds []protocol.CodeActionKind
+	title func(title string) bool
+	// introducesSymbol is set for refactorings that create a named symbol
+	introducesSymbol bool
+}
+
+// RefactorKinds are the named refactor kinds accepted by Refactor
+var RefactorKinds = []string{"extract_function", "extract_variable", "extract_constant", "inline"}
+
+var refactorSpecs = map[string]refactorSpec{
+	"extract_function": {
+		kinds: []protocol.CodeActionKind{"refactor.extract.function", "refactor.extract.method"},
+		title: func(title string) bool {
+			return strings.Contains(title, "extract") && (strings.Contains(title, "function") || strings.Contains(title, "method"))
+		},
+		introducesSymbol: true,
+	},
+	"extract_variable": {
+		kinds: []protocol.CodeActionKind{"refactor.extract.variable"},
+		title: func(title string) bool {
+			return strings.Contains(title, "extract") && (strings.Contains(title, "variable") || strings.Contains(title, "local") || strings.Contains(title, "subexpression"))
+		},
+		introducesSymbol: true,
+	},
+	"extract_constant": {
+		kinds: []protocol.CodeActionKind{"refactor.extract.constant"},
+		title: func(title string) bool {
+			return strings.Contains(title, "extract") && strings.Contains(title, "constant")
+		},
+		introducesSymbol: true,
+	},
+	"inline": {
+		kinds: []protocol.CodeActionKind{protocol.RefactorInline},
+		title: func(title string) bool {
+			return strings.Contains(title, "inline")
+		},
+	},
+}
+
+// genericRefactorKinds are kinds too broad to say which refactoring an action does
+var genericRefactorKinds = map[protocol.CodeActionKind]bool{
+	"":                       true,
+	protocol.Refactor:        true,
+	protocol.RefactorExtract: true,
+	protocol.RefactorInline:  true,
+}
+
+// lookupRefactorSpec returns the spec for a named refactor kind. A raw
+// code action kind such as refactor.rewrite is also accepted.
+func lookupRefactorSpec(kind string) (refactorSpec, error) {
+	if spec, ok := refactorSpecs[kind]; ok {
+		return spec, nil
+	}
+	if codeActionKindMatches(protocol.CodeActionKind(kind), protocol.Refactor) {
+		return refactorSpec{
+			kinds:            []protocol.CodeActionKind{protocol.CodeActionKind(kind)},
+			introducesSymbol: codeActionKindMatches(protocol.CodeActionKind(kind), protocol.RefactorExtract),
+		}, nil
+	}
+	return refactorSpec{}, fmt.Errorf("unknown refactor kind %q, expected one of %s or a refactor.* code action kind", kind, strings.Join(RefactorKinds, ", "))
+}
+
+// matches reports whether a code action performs the refactoring
+func (s refactorSpec) matches(action protocol.CodeAction) bool {
+	for _, kind := range s.kinds {
+		if codeActionKindMatches(action.Kind, kind) {
+			return true
+		}
+	}
+	return s.title != nil && genericRefactorKinds[action.Kind] && s.title(strings.ToLower(action.Title))
+}
+
+// Refactor applies an extract or inline refactoring to a selection.
+//
+// Refactor code actions are requested for the range and filtered by kind. If
+// several match and none is preferred, they are listed instead and nothing is
+// applied; pass actionIndex to pick one of them. The chosen action is
+// resolved and applied, and its command is run if the server executes it.
+// For extractions the location of the new symbol is reported.
+func Refactor(ctx context.Context, client *lsp.Client, filePath string, startLine, startColumn, endLine, endColumn int, kind string, actionIndex int) (*EditResult, error) {
+	spec, err := lookupRefactorSpec(kind)
+	if err != nil {
+		return nil, err
+	}
+
+	err = client.OpenFile(ctx, filePath)
+	if err != nil {
+		return nil, fmt.Errorf("could not open file: %v", err)
+	}
+
+	uri := protocol.DocumentUri("file://" + filePath)
+	params := protocol.CodeActionParams{
+		TextDocument: protocol.TextDocumentIdentifier{URI: uri},
+		Range: protocol.Range{
+			Start: protocol.Position{Line: uint32(startLine - 1), Character: uint32(startColumn - 1)},
+			End:   protocol.Position{Line: uint32(endLine - 1), Character: uint32(endColumn - 1)},
+		},
+		Context: protocol.CodeActionContext{
+			Diagnostics: client.GetFileDiagnostics(uri),
+			// Filtered locally since not every server uses specific refactor kinds
+			Only: []protocol.CodeActionKind{protocol.Refactor},
+		},
+	}
+
+	items, err := client.CodeAction(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get code actions: %v", err)
+	}
+
+	choices, disabled := refactorChoices(items, spec)
+	if len(choices) == 0 {
+		var output strings.Builder
+		output.WriteString(fmt.Sprintf("No %s refactorings available for the selection in %s\n", kind, filePath))
+		for _, action := range disabled {
+			output.WriteString(fmt.Sprintf("%s: %s\n", action.Title, action.Disabled.Reason))
+		}
+		return noEdits(output.String()), nil
+	}
+
+	action, ok, err := chooseCodeAction(choices, actionIndex)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		var output strings.Builder
+		output.WriteString(fmt.Sprintf("%d %s refactorings are available.\n", len(choices), kind))
+		output.WriteString("Call refactor again with actionIndex set to one of:\n")
+		output.WriteString(formatCodeActionChoices(choices))
+		return noEdits(output.String()), nil
+	}
+
+	action, err = resolveCodeAction(ctx, client, action)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve code action: %v", err)
+	}
+
+	// Keep the original contents to find the names the refactoring introduced
+	before := readFiles(append([]string{filePath}, workspaceEditPaths(action.Edit)...))
+
+	result, err := applyCodeAction(ctx, client, "refactor", action)
+	if err != nil {
+		return nil, err
+	}
+	if !result.changed() {
+		return noEdits(fmt.Sprintf("Refactoring %q made no changes", action.Title)), nil
+	}
+
+	root := workspaceRoot(client)
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("Applied %q", action.Title))
+	if action.Kind != "" {
+		output.WriteString(fmt.Sprintf(" (%s)", action.Kind))
+	}
+	output.WriteString("\n")
+
+	if len(result.files) > 0 {
+		output.WriteString("Changed files:\n")
+		for _, path := range result.files {
+			output.WriteString(fmt.Sprintf("  %s\n", displayPath(root, path)))
+		}
+	}
+
+	if spec.introducesSymbol {
+		if symbol, ok := newSymbolLocation(result.skippedCommand, uri, before); ok {
+			output.WriteString(fmt.Sprintf("New symbol %s at %s\n", symbol.name, formatSymbolLocation(root, symbol.location)))
+			output.WriteString("Use rename_symbol at that location to give it a better name.\n")
+		}
+	}
+
+	if result.skippedCommand != nil {
+		output.WriteString(fmt.Sprintf("Skipped %s (%s), which the server expects the editor to run.\n",
+			result.skippedCommand.Title, result.skippedCommand.Command))
+	}
+	return journaledEdits(client, output.String(), result.entries...), nil
+}
+
+// refactorChoices returns the enabled code actions performing the refactoring,
+// and the disabled ones so their reasons can be reported
+func refactorChoices(items []protocol.Or_Result_textDocument_codeAction_Item0_Elem, spec refactorSpec) (choices, disabled []protocol.CodeAction) {
+	for _, item := range items {
+		value, ok := item.Value.(map[string]any)
+		if !ok {
+			continue
+		}
+		action, err := decodeCodeAction(value)
+		if err != nil || !spec.matches(action) {
+			continue
+		}
+		if action.Disabled != nil {
+			disabled = append(disabled, action)
+			continue
+		}
+		choices = append(choices, action)
+	}
+	return choices, disabled
+}
+
+// workspaceEditPaths returns the paths of the documents a workspace edit changes
+func workspaceEditPaths(edit *protocol.WorkspaceEdit) []string {
+	if edit == nil {
+		return nil
+	}
+	var paths []string
+	for uri := range edit.Changes {
+		paths = append(paths, strings.TrimPrefix(string(uri), "file://"))
+	}
+	for _, change := range edit.DocumentChanges {
+		if change.TextDocumentEdit != nil {
+			paths = append(paths, strings.TrimPrefix(string(change.TextDocumentEdit.TextDocument.URI), "file://"))
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// readFiles reads the given files, leaving out any that can't be read
+func readFiles(paths []string) map[string]string {
+	contents := make(map[string]string)
+	for _, path := range paths {
+		if _, ok := contents[path]; ok {
+			continue
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		contents[path] = string(content)
+	}
+	return contents
+}
+
+// symbolLocation is where a refactoring introduced a symbol
+type symbolLocation struct {
+	name     string
+	location protocol.Location
+}
+
+// newSymbolLocation finds the symbol a refactoring introduced. The location
+// given to the follow-up rename command is used if there is one; otherwise
+// the first identifier that did not occur before the refactoring is taken,
+// looking in the refactored file first.
+func newSymbolLocation(command *protocol.Command, uri protocol.DocumentUri, before map[string]string) (symbolLocation, bool) {
+	if location, ok := commandLocation(command, uri); ok {
+		path := strings.TrimPrefix(string(location.URI), "file://")
+		content, err := os.ReadFile(path)
+		if err == nil {
+			if name := identifierAt(string(content), location.Range.Start); name != "" {
+				return symbolLocation{name: name, location: location}, true
+			}
+		}
+	}
+
+	refactored := strings.TrimPrefix(string(uri), "file://")
+	paths := make([]string, 0, len(before))
+	for path := range before {
+		paths = append(paths, path)
+	}
+	sort.Slice(paths, func(i, j int) bool {
+		if (paths[i] == refactored) != (paths[j] == refactored) {
+			return paths[i] == refactored
+		}
+		return paths[i] < paths[j]
+	})
+
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		identifiers := newIdentifiers(before[path], string(content))
+		if len(identifiers) == 0 {
+			continue
+		}
+		return symbolLocation{
+			name: identifiers[0].name,
+			location: protocol.Location{
+				URI:   protocol.DocumentUri("file://" + path),
+				Range: protocol.Range{Start: identifiers[0].position, End: identifiers[0].position},
+			},
+		}, true
+	}
+	return symbolLocation{}, false
+}
+
+// commandLocation extracts a position from the arguments of a follow-up
+// command. Servers pass it as a Location, as TextDocumentPositionParams, or as
+// a URI and a Position in separate arguments.
+func commandLocation(command *protocol.Command, defaultURI protocol.DocumentUri) (protocol.Location, bool) {
+	if command == nil {
+		return protocol.Location{}, false
+	}
+
+	uri := defaultURI
+	for _, arg := range command.Arguments {
+		var value struct {
+			URI          protocol.DocumentUri `json:"uri"`
+			Range        *protocol.Range      `json:"range"`
+			TextDocument *struct {
+				URI protocol.DocumentUri `json:"uri"`
+			} `json:"textDocument"`
+			Position  *protocol.Position `json:"position"`
+			Line      *uint32            `json:"line"`
+			Character *uint32            `json:"character"`
+		}
+		var s string
+		if err := json.Unmarshal(arg, &s); err == nil {
+			if strings.HasPrefix(s, "file://") {
+				uri = protocol.DocumentUri(s)
+			}
+			continue
+		}
+		if err := json.Unmarshal(arg, &value); err != nil {
+			continue
+		}
+
+		switch {
+		case value.URI != "" && value.Range != nil:
+			return protocol.Location{URI: value.URI, Range: *value.Range}, true
+		case value.TextDocument != nil && value.Position != nil:
+			return protocol.Location{
+				URI:   value.TextDocument.URI,
+				Range: protocol.Range{Start: *value.Position, End: *value.Position},
+			}, true
+		case value.Line != nil && value.Character != nil:
+			position := protocol.Position{Line: *value.Line, Character: *value.Character}
+			return protocol.Location{URI: uri, Range: protocol.Range{Start: position, End: position}}, true
+		}
+	}
+	return protocol.Location{}, false
+}
+
+var identifierPattern = regexp.MustCompile(`[\p{L}_][\p{L}\p{N}_]*`)
+
+type identifierOccurrence struct {
+	name     string
+	position protocol.Position
+}
+
+// newIdentifiers returns the identifiers in after that don't occur in before,
+// in order of their first occurrence
+func newIdentifiers(before, after string) []identifierOccurrence {
+	existing := make(map[string]bool)
+	for _, name := range identifierPattern.FindAllString(before, -1) {
+		existing[name] = true
+	}
+
+	var identifiers []identifierOccurrence
+	for _, match := range identifierPattern.FindAllStringIndex(after, -1) {
+		name := after[match[0]:match[1]]
+		if existing[name] {
+			continue
+		}
+		existing[name] = true
+		identifiers = append(identifiers, identifierOccurrence{
+			name:     name,
+			position: utf16Position(after, match[0]),
+		})
+	}
+	return identifiers
+}
+
+// identifierAt returns the identifier containing a position, if any
+func identifierAt(content string, position protocol.Position) string {
+	lines := strings.Split(content, "\n")
+	if int(position.Line) >= len(lines) {
+		return ""
+	}
+	line := lines[position.Line]
+	for _, match := range identifierPattern.FindAllStringIndex(line, -1) {
+		start := uint32(len(utf16.Encode([]rune(line[:match[0]]))))
+		end := uint32(len(utf16.Encode([]rune(line[:match[1]]))))
+		if position.Character >= start && position.Character <= end {
+			return line[match[0]:match[1]]
+		}
+	}
+	return ""
+}
+
+// utf16Position converts a byte offset to a position counting UTF-16 code units
+func utf16Position(text string, offset int) protocol.Position {
+	lineStart := strings.LastIndex(text[:offset], "\n") + 1
+	return protocol.Position{
+		Line:      uint32(strings.Count(text[:offset], "\n")),
+		Character: uint32(len(utf16.Encode([]rune(text[lineStart:offset])))),
+	}
+}
+
+// formatSymbolLocation formats a location as path:Lline:Ccolumn, 1-indexed
+func formatSymbolLocation(root string, location protocol.Location) string {
+	return fmt.Sprintf("%s:L%d:C%d",
+		displayPath(root, strings.TrimPrefix(string(location.URI), "file://")),
+		location.Range.Start.Line+1,
+		location.Range.Start.Character+1)
+}
@@ -0,0 +1,169 @@
+package tools
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRefactorChoices(t *testing.T) {
+	items := []protocol.Or_Result_textDocument_codeAction_Item0_Elem{
+		{Value: map[string]any{"title": "Extract function", "kind": "refactor.extract.function"}},
+		{Value: map[string]any{"title": "Extract variable", "kind": "refactor.extract.variable"}},
+		{Value: map[string]any{"title": "Extract into function", "kind": "refactor.extract"}},
+		{Value: map[string]any{"title": "Extract to function", "kind": "refactor"}},
+		{Value: map[string]any{"title": "Extract to method in class 'A'", "kind": "refactor.extract.method", "disabled": map[string]any{"reason": "no class"}}},
+		{Value: map[string]any{"title": "Inline call to f", "kind": "refactor.inline.call"}},
+		{Value: map[string]any{"title": "Organize imports", "kind": "source.organizeImports"}},
+	}
+
+	spec, err := lookupRefactorSpec("extract_function")
+	require.NoError(t, err)
+	choices, disabled := refactorChoices(items, spec)
+	require.Len(t, choices, 3)
+	assert.Equal(t, "Extract function", choices[0].Title)
+	assert.Equal(t, "Extract into function", choices[1].Title)
+	assert.Equal(t, "Extract to function", choices[2].Title)
+	require.Len(t, disabled, 1)
+	assert.Equal(t, "no class", disabled[0].Disabled.Reason)
+
+	spec, err = lookupRefactorSpec("inline")
+	require.NoError(t, err)
+	choices, _ = refactorChoices(items, spec)
+	require.Len(t, choices, 1)
+	assert.Equal(t, "Inline call to f", choices[0].Title)
+
+	spec, err = lookupRefactorSpec("refactor.extract.variable")
+	require.NoError(t, err)
+	choices, _ = refactorChoices(items, spec)
+	require.Len(t, choices, 1)
+	assert.Equal(t, "Extract variable", choices[0].Title)
+
+	_, err = lookupRefactorSpec("source.fixAll")
+	assert.ErrorContains(t, err, "unknown refactor kind")
+}
+
+func TestChooseCodeAction(t *testing.T) {
+	choices := []protocol.CodeAction{{Title: "a"}, {Title: "b", IsPreferred: true}, {Title: "c"}}
+
+	action, ok, err := chooseCodeAction(choices, 0)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, "b", action.Title)
+
+	action, ok, err = chooseCodeAction(choices, 3)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, "c", action.Title)
+
+	_, _, err = chooseCodeAction(choices, 4)
+	assert.ErrorContains(t, err, "out of range")
+
+	choices[1].IsPreferred = false
+	_, ok, err = chooseCodeAction(choices, 0)
+	require.NoError(t, err)
+	assert.False(t, ok, "without a preferred action the caller has to choose")
+}
+
+func TestCommandLocation(t *testing.T) {
+	raw := func(v any) json.RawMessage {
+		data, err := json.Marshal(v)
+		require.NoError(t, err)
+		return data
+	}
+	defaultURI := protocol.DocumentUri("file:///ws/main.go")
+
+	tests := []struct {
+		name string
+		args []json.RawMessage
+		want protocol.Location
+		ok   bool
+	}{
+		{
+			name: "location",
+			args: []json.RawMessage{raw(protocol.Location{URI: "file:///ws/a.go", Range: protocol.Range{Start: protocol.Position{Line: 3, Character: 5}}})},
+			want: protocol.Location{URI: "file:///ws/a.go", Range: protocol.Range{Start: protocol.Position{Line: 3, Character: 5}}},
+			ok:   true,
+		},
+		{
+			name: "text document position",
+			args: []json.RawMessage{raw(map[string]any{"textDocument": map[string]any{"uri": "file:///ws/b.ts"}, "position": map[string]any{"line": 1, "character": 2}})},
+			want: protocol.Location{URI: "file:///ws/b.ts", Range: protocol.Range{Start: protocol.Position{Line: 1, Character: 2}, End: protocol.Position{Line: 1, Character: 2}}},
+			ok:   true,
+		},
+		{
+			name: "uri and position",
+			args: []json.RawMessage{raw("file:///ws/c.rs"), raw(protocol.Position{Line: 7, Character: 1})},
+			want: protocol.Location{URI: "file:///ws/c.rs", Range: protocol.Range{Start: protocol.Position{Line: 7, Character: 1}, End: protocol.Position{Line: 7, Character: 1}}},
+			ok:   true,
+		},
+		{
+			name: "position only",
+			args: []json.RawMessage{raw(protocol.Position{Line: 2, Character: 0})},
+			want: protocol.Location{URI: defaultURI, Range: protocol.Range{Start: protocol.Position{Line: 2}, End: protocol.Position{Line: 2}}},
+			ok:   true,
+		},
+		{
+			name: "no position",
+			args: []json.RawMessage{raw("trigger"), raw(map[string]any{"kind": "x"})},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := commandLocation(&protocol.Command{Command: "rename", Arguments: tt.args}, defaultURI)
+			assert.Equal(t, tt.ok, ok)
+			if tt.ok {
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestNewIdentifiers(t *testing.T) {
+	before := "func main() {\n\tx := a + b\n}\n"
+	after := "func main() {\n\tx := newFunction(a, b)\n}\n\nfunc newFunction(a, b int) int {\n\treturn a + b\n}\n"
+
+	identifiers := newIdentifiers(before, after)
+	names := make([]string, len(identifiers))
+	for i, identifier := range identifiers {
+		names[i] = identifier.name
+	}
+	assert.Equal(t, []string{"newFunction", "int", "return"}, names)
+	assert.Equal(t, protocol.Position{Line: 1, Character: 6}, identifiers[0].position)
+
+	assert.Equal(t, "newFunction", identifierAt(after, protocol.Position{Line: 4, Character: 8}))
+	assert.Equal(t, "", identifierAt(after, protocol.Position{Line: 9, Character: 0}))
+}
+
+func TestNewSymbolLocation(t *testing.T) {
+	dir := t.TempDir()
+	main := filepath.Join(dir, "main.go")
+	util := filepath.Join(dir, "util.go")
+	before := map[string]string{
+		util: "package main\n",
+		main: "package main\n\nfunc main() {\n\tx := 1 + 2\n}\n",
+	}
+	require.NoError(t, os.WriteFile(util, []byte("package main\n\nfunc helper() {}\n"), 0644))
+	require.NoError(t, os.WriteFile(main, []byte("package main\n\nfunc main() {\n\tsum := 1 + 2\n\tx := sum\n}\n"), 0644))
+	uri := protocol.DocumentUri("file://" + main)
+
+	symbol, ok := newSymbolLocation(nil, uri, before)
+	require.True(t, ok)
+	assert.Equal(t, "sum", symbol.name, "the refactored file is searched first")
+	assert.Equal(t, protocol.Position{Line: 3, Character: 1}, symbol.location.Range.Start)
+
+	command := &protocol.Command{
+		Command:   "editor.action.rename",
+		Arguments: []json.RawMessage{json.RawMessage(`"file://` + util + `"`), json.RawMessage(`{"line":2,"character":7}`)},
+	}
+	symbol, ok = newSymbolLocation(command, uri, before)
+	require.True(t, ok)
+	assert.Equal(t, "helper", symbol.name, "the rename command location takes precedence")
+	assert.Equal(t, protocol.DocumentUri("file://"+util), symbol.location.URI)
+}
@@ -4,21 +4,89 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"path/filepath"
 	"sort"
-	"strconv"
 	"strings"
 
 	"github.com/isaacphi/mcp-language-server/internal/lsp"
 	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/isaacphi/mcp-language-server/internal/utilities"
 )
 
-func FindReferences(ctx context.Context, client *lsp.Client, symbolName string) (string, error) {
-	// Get context lines from environment variable
-	contextLines := 5
-	if envLines := os.Getenv("LSP_CONTEXT_LINES"); envLines != "" {
-		if val, err := strconv.Atoi(envLines); err == nil && val >= 0 {
-			contextLines = val
-		}
+const (
+	// DefaultReferenceContextLines is how many lines are shown around each reference by default
+	DefaultReferenceContextLines = 5
+	// maxSignatureLines caps the lines of a caller signature
+	maxSignatureLines = 10
+)
+
+// ReferencesOptions controls which references are listed and how they're shown
+type ReferencesOptions struct {
+	// ContextLines is how many lines are shown around each reference, within
+	// its enclosing symbol
+	ContextLines int
+	// Signatures shows the full signature of the enclosing symbol and the
+	// referencing lines instead of context lines
+	Signatures bool
+	// IncludeDeclaration also lists the symbol's declaration
+	IncludeDeclaration bool
+	// Include and Exclude are globs relative to the workspace root; a
+	// reference is listed if its file matches an Include glob, or there are
+	// none, and matches no Exclude glob
+	Include []string
+	Exclude []string
+}
+
+// referenceGroup is the references in a file that share an enclosing symbol
+type referenceGroup struct {
+	// symbol is nil for references outside any symbol
+	symbol *skeletonSymbol
+	refs   []protocol.Location
+}
+
+// Reference is a reference to a symbol. Container is the qualified name of
+// the symbol it occurs in, empty outside any symbol.
+type Reference struct {
+	Range         Range  `json:"range"`
+	Container     string `json:"container,omitempty"`
+	ContainerKind string `json:"containerKind,omitempty"`
+}
+
+// FileReferences are the references in one file
+type FileReferences struct {
+	Path       string      `json:"path"`
+	References []Reference `json:"references"`
+	// Error is set when the file couldn't be read to show the references in context
+	Error string `json:"error,omitempty"`
+
+	refs   []protocol.Location
+	lines  []string
+	groups []referenceGroup
+}
+
+// ReferencesResult lists the references to a symbol by file
+type ReferencesResult struct {
+	Symbol string           `json:"symbol"`
+	Files  []FileReferences `json:"files"`
+	Page   *Page            `json:"page,omitempty"`
+
+	opts ReferencesOptions
+}
+
+// FindReferences lists the references to a symbol, found by name with
+// workspace/symbol, grouped by file and then by the symbol they occur in. The
+// document symbols of each file are requested once to find those.
+func FindReferences(ctx context.Context, client *lsp.Client, symbolName string, opts ReferencesOptions) (*ReferencesResult, error) {
+	include, err := compileGlobs(opts.Include)
+	if err != nil {
+		return nil, err
+	}
+	exclude, err := compileGlobs(opts.Exclude)
+	if err != nil {
+		return nil, err
+	}
+	if opts.ContextLines < 0 {
+		opts.ContextLines = 0
 	}
 
 	// First get the symbol location like ReadDefinition does
@@ -26,15 +94,17 @@ func FindReferences(ctx context.Context, client *lsp.Client, symbolName string)
 		Query: symbolName,
 	})
 	if err != nil {
-		return "", fmt.Errorf("failed to fetch symbol: %v", err)
+		return nil, fmt.Errorf("failed to fetch symbol: %v", err)
 	}
 
 	results, err := symbolResult.Results()
 	if err != nil {
-		return "", fmt.Errorf("failed to parse results: %v", err)
+		return nil, fmt.Errorf("failed to parse results: %v", err)
 	}
 
-	var allReferences []string
+	root := workspaceRoot(client)
+	seen := make(map[string]bool)
+	refsByFile := make(map[string][]protocol.Location)
 	for _, symbol := range results {
 		// Handle different matching strategies based on the search term
 		if strings.Contains(symbolName, ".") {
@@ -63,7 +133,7 @@ func FindReferences(ctx context.Context, client *lsp.Client, symbolName string)
 				Position: loc.Range.Start,
 			},
 			Context: protocol.ReferenceContext{
-				IncludeDeclaration: false,
+				IncludeDeclaration: opts.IncludeDeclaration,
 			},
 		}
 		// File is likely to be opened already, but may not be.
@@ -74,78 +144,307 @@ func FindReferences(ctx context.Context, client *lsp.Client, symbolName string)
 		}
 		refs, err := client.References(ctx, refsParams)
 		if err != nil {
-			return "", fmt.Errorf("failed to get references: %v", err)
+			return nil, fmt.Errorf("failed to get references: %v", err)
 		}
 
-		// Group references by file
-		refsByFile := make(map[protocol.DocumentUri][]protocol.Location)
 		for _, ref := range refs {
-			refsByFile[ref.URI] = append(refsByFile[ref.URI], ref)
+			key := fmt.Sprintf("%s:%d:%d", ref.URI, ref.Range.Start.Line, ref.Range.Start.Character)
+			path := ref.URI.Path()
+			if seen[key] || !matchesPathGlobs(filepath.ToSlash(displayPath(root, path)), include, exclude) {
+				continue
+			}
+			seen[key] = true
+			refsByFile[path] = append(refsByFile[path], ref)
 		}
+	}
 
-		// Get sorted list of URIs
-		uris := make([]string, 0, len(refsByFile))
-		for uri := range refsByFile {
-			uris = append(uris, string(uri))
-		}
-		sort.Strings(uris)
+	result := &ReferencesResult{Symbol: symbolName, Files: []FileReferences{}, opts: opts}
+	paths := make([]string, 0, len(refsByFile))
+	for path := range refsByFile {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
 
-		// Process each file's references in sorted order
-		for _, uriStr := range uris {
-			uri := protocol.DocumentUri(uriStr)
-			fileRefs := refsByFile[uri]
-			filePath := strings.TrimPrefix(uriStr, "file://")
+	for _, filePath := range paths {
+		fileRefs := refsByFile[filePath]
+		sort.Slice(fileRefs, func(i, j int) bool {
+			if fileRefs[i].Range.Start.Line != fileRefs[j].Range.Start.Line {
+				return fileRefs[i].Range.Start.Line < fileRefs[j].Range.Start.Line
+			}
+			return fileRefs[i].Range.Start.Character < fileRefs[j].Range.Start.Character
+		})
+		file := FileReferences{Path: filePath, References: []Reference{}, refs: fileRefs}
 
-			// Format file header
-			fileInfo := fmt.Sprintf("---\n\n%s\nReferences in File: %d\n",
-				filePath,
-				len(fileRefs),
-			)
+		fileContent, err := os.ReadFile(filePath)
+		if err != nil {
+			// Log error but continue with other files
+			file.Error = err.Error()
+			for _, ref := range fileRefs {
+				file.References = append(file.References, Reference{Range: newRange(ref.Range)})
+			}
+			result.Files = append(result.Files, file)
+			continue
+		}
+		file.lines = strings.Split(string(fileContent), "\n")
 
-			// Format locations with context
-			fileContent, err := os.ReadFile(filePath)
-			if err != nil {
-				// Log error but continue with other files
-				allReferences = append(allReferences, fileInfo+"\nError reading file: "+err.Error())
-				continue
+		var symbols []skeletonSymbol
+		if documentSymbols, err := fileDocumentSymbols(ctx, client, filePath); err != nil {
+			toolsLogger.Warn("Failed to get symbols for %s: %v", filePath, err)
+		} else {
+			symbols = flattenSkeletonSymbols(documentSymbols)
+		}
+
+		file.groups = groupReferences(symbols, fileRefs)
+		for _, ref := range fileRefs {
+			reference := Reference{Range: newRange(ref.Range)}
+			if i := referenceContainer(symbols, ref.Range.Start); i >= 0 {
+				reference.Container = symbols[i].qualified
+				reference.ContainerKind = symbolKindToString(symbols[i].kind)
 			}
+			file.References = append(file.References, reference)
+		}
+		result.Files = append(result.Files, file)
+	}
+
+	return result, nil
+}
 
-			lines := strings.Split(string(fileContent), "\n")
+func (r *ReferencesResult) Format() string {
+	if len(r.Files) == 0 {
+		return fmt.Sprintf("No references found for symbol: %s", r.Symbol)
+	}
 
-			// Track reference locations for header display
-			var locStrings []string
-			for _, ref := range fileRefs {
-				locStr := fmt.Sprintf("L%d:C%d",
-					ref.Range.Start.Line+1,
-					ref.Range.Start.Character+1)
-				locStrings = append(locStrings, locStr)
+	var allReferences []string
+	for _, file := range r.Files {
+		// Format file header
+		fileInfo := fmt.Sprintf("---\n\n%s\nReferences in File: %d\n",
+			file.Path,
+			len(file.refs),
+		)
+		fileInfo += "At: " + formatReferencePositions(file.refs) + "\n"
+
+		if file.Error != "" {
+			allReferences = append(allReferences, fileInfo+"\nError reading file: "+file.Error)
+			continue
+		}
+
+		var output strings.Builder
+		output.WriteString(fileInfo)
+		for _, group := range file.groups {
+			output.WriteString("\n" + formatReferenceGroup(file.lines, group, r.opts))
+		}
+		allReferences = append(allReferences, output.String())
+	}
+
+	return strings.Join(allReferences, "\n") + r.Page.Format()
+}
+
+// pageItems returns every reference in file order. The file header is counted
+// with a file's first reference, and each group's text is shared between its
+// references.
+func (r *ReferencesResult) pageItems() []pageItem {
+	var items []pageItem
+	for _, file := range r.Files {
+		sizes := make(map[protocol.Position]int)
+		for _, group := range file.groups {
+			size := len(formatReferenceGroup(file.lines, group, r.opts)) / len(group.refs)
+			for _, ref := range group.refs {
+				sizes[ref.Range.Start] = size
 			}
+		}
+		header := len(file.Path) + len(formatReferencePositions(file.refs)) + len(file.Error) + 40
+		for i, ref := range file.refs {
+			item := pageItem{file: file.Path, size: sizes[ref.Range.Start]}
+			if i == 0 {
+				item.size += header
+			}
+			items = append(items, item)
+		}
+	}
+	return items
+}
 
-			// Collect lines to display using the utility function
-			linesToShow, err := GetLineRangesToDisplay(ctx, client, fileRefs, len(lines), contextLines)
-			if err != nil {
-				// Log error but continue with other files
-				continue
+func (r *ReferencesResult) keepPage(start, end int, page *Page) {
+	var files []FileReferences
+	offset := 0
+	for _, file := range r.Files {
+		lo, hi := max(start-offset, 0), min(end-offset, len(file.refs))
+		offset += len(file.refs)
+		if lo >= hi {
+			continue
+		}
+		kept := make(map[protocol.Position]bool)
+		for _, ref := range file.refs[lo:hi] {
+			kept[ref.Range.Start] = true
+		}
+		var groups []referenceGroup
+		for _, group := range file.groups {
+			var refs []protocol.Location
+			for _, ref := range group.refs {
+				if kept[ref.Range.Start] {
+					refs = append(refs, ref)
+				}
+			}
+			if len(refs) > 0 {
+				groups = append(groups, referenceGroup{symbol: group.symbol, refs: refs})
 			}
+		}
+		file.refs, file.References, file.groups = file.refs[lo:hi], file.References[lo:hi], groups
+		files = append(files, file)
+	}
+	r.Files, r.Page = files, page
+}
+
+// compileGlobs compiles a list of glob patterns
+func compileGlobs(patterns []string) ([]*utilities.Glob, error) {
+	var globs []*utilities.Glob
+	for _, pattern := range patterns {
+		glob, err := utilities.CompileGlob(pattern)
+		if err != nil {
+			return nil, err
+		}
+		globs = append(globs, glob)
+	}
+	return globs, nil
+}
 
-			// Convert to line ranges using the utility function
-			lineRanges := ConvertLinesToRanges(linesToShow, len(lines))
+// matchesPathGlobs reports whether a slash-separated path matches one of the
+// include globs, or there are none, and none of the exclude globs
+func matchesPathGlobs(path string, include, exclude []*utilities.Glob) bool {
+	for _, glob := range exclude {
+		if glob.Match(path) {
+			return false
+		}
+	}
+	if len(include) == 0 {
+		return true
+	}
+	for _, glob := range include {
+		if glob.Match(path) {
+			return true
+		}
+	}
+	return false
+}
 
-			// Format with locations in header
-			formattedOutput := fileInfo
-			if len(locStrings) > 0 {
-				formattedOutput += "At: " + strings.Join(locStrings, ", ") + "\n"
-			}
+// groupReferences groups the sorted references in a file by the symbol they
+// occur in, in order of their first reference
+func groupReferences(symbols []skeletonSymbol, refs []protocol.Location) []referenceGroup {
+	var groups []referenceGroup
+	index := make(map[int]int)
+	for _, ref := range refs {
+		i := referenceContainer(symbols, ref.Range.Start)
+		if g, ok := index[i]; ok {
+			groups[g].refs = append(groups[g].refs, ref)
+			continue
+		}
+		group := referenceGroup{refs: []protocol.Location{ref}}
+		if i >= 0 {
+			group.symbol = &symbols[i]
+		}
+		index[i] = len(groups)
+		groups = append(groups, group)
+	}
+	return groups
+}
 
-			// Format the content with ranges
-			formattedOutput += "\n" + FormatLinesWithRanges(lines, lineRanges)
-			allReferences = append(allReferences, formattedOutput)
+// referenceContainer returns the index of the innermost function or method
+// containing a position, or of the innermost symbol of any kind if there is
+// none, or -1
+func referenceContainer(symbols []skeletonSymbol, position protocol.Position) int {
+	best, bestCallable := -1, -1
+	for i, symbol := range symbols {
+		if !containsPosition(symbol.rng, position) {
+			continue
+		}
+		if best < 0 || symbol.rng.End.Line-symbol.rng.Start.Line <= symbols[best].rng.End.Line-symbols[best].rng.Start.Line {
+			best = i
+		}
+		if callableKinds[symbol.kind] && (bestCallable < 0 ||
+			symbol.rng.End.Line-symbol.rng.Start.Line <= symbols[bestCallable].rng.End.Line-symbols[bestCallable].rng.Start.Line) {
+			bestCallable = i
 		}
 	}
+	if bestCallable >= 0 {
+		return bestCallable
+	}
+	return best
+}
 
-	if len(allReferences) == 0 {
-		return fmt.Sprintf("No references found for symbol: %s", symbolName), nil
+// formatReferencePositions lists references as "L<line>:C<column>"
+func formatReferencePositions(refs []protocol.Location) string {
+	var positions []string
+	for _, ref := range refs {
+		positions = append(positions, fmt.Sprintf("L%d:C%d", ref.Range.Start.Line+1, ref.Range.Start.Character+1))
+	}
+	return strings.Join(positions, ", ")
+}
+
+// formatReferenceGroup shows the references in one symbol under a heading
+// naming it, with either context lines or the symbol's signature
+func formatReferenceGroup(lines []string, group referenceGroup, opts ReferencesOptions) string {
+	var output strings.Builder
+	count := "1 reference"
+	if len(group.refs) != 1 {
+		count = fmt.Sprintf("%d references", len(group.refs))
+	}
+	if group.symbol != nil {
+		output.WriteString(fmt.Sprintf("In %s (%s), %s: %s\n", group.symbol.qualified, symbolKindToString(group.symbol.kind),
+			count, formatReferencePositions(group.refs)))
+	} else {
+		output.WriteString(fmt.Sprintf("At top level, %s: %s\n", count, formatReferencePositions(group.refs)))
+	}
+
+	linesToShow := make(map[int]bool)
+	start, end := 0, len(lines)-1
+	if group.symbol != nil {
+		start, end = symbolLines(lines, group.symbol.rng, false)
+		if opts.Signatures {
+			for _, line := range signatureLines(lines, start, end) {
+				linesToShow[line] = true
+			}
+		} else {
+			linesToShow[start] = true
+		}
+	}
+	for _, ref := range group.refs {
+		line := int(ref.Range.Start.Line)
+		linesToShow[line] = true
+		if opts.Signatures {
+			continue
+		}
+		for i := line - opts.ContextLines; i <= line+opts.ContextLines; i++ {
+			if i >= start && i <= end {
+				linesToShow[i] = true
+			}
+		}
 	}
 
-	return strings.Join(allReferences, "\n"), nil
+	output.WriteString(FormatLinesWithRanges(lines, ConvertLinesToRanges(linesToShow, len(lines))))
+	return output.String()
+}
+
+// signatureLines returns the lines of the signature of a symbol spanning the
+// given lines: its first line and, while brackets are left open, the lines
+// after it, up to maxSignatureLines
+func signatureLines(lines []string, start, end int) []int {
+	signature := []int{start}
+	depth := 0
+	for line := start; line <= end && len(signature) < maxSignatureLines; line++ {
+		if line > start {
+			signature = append(signature, line)
+		}
+		for _, char := range lines[line] {
+			switch char {
+			case '(', '[':
+				depth++
+			case ')', ']':
+				depth--
+			}
+		}
+		if depth <= 0 {
+			break
+		}
+	}
+	return signature
 }
@@ -0,0 +1,99 @@
+package tools
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const referencesSource = `package main
+
+var handler = Run
+
+func Run(
+	name string,
+	count int,
+) error {
+	first := Helper()
+	second := Helper()
+	return nil
+}
+`
+
+func referenceAt(line, char uint32) protocol.Location {
+	return protocol.Location{
+		URI:   "file:///ws/main.go",
+		Range: protocol.Range{Start: protocol.Position{Line: line, Character: char}},
+	}
+}
+
+func TestGroupReferences(t *testing.T) {
+	symbols := []skeletonSymbol{
+		{name: "handler", qualified: "handler", kind: protocol.Variable, rng: protocol.Range{
+			Start: protocol.Position{Line: 2}, End: protocol.Position{Line: 2, Character: 17},
+		}},
+		{name: "Run", qualified: "Run", kind: protocol.Function, rng: protocol.Range{
+			Start: protocol.Position{Line: 4}, End: protocol.Position{Line: 11, Character: 1},
+		}},
+		{name: "first", qualified: "Run.first", kind: protocol.Variable, rng: protocol.Range{
+			Start: protocol.Position{Line: 8, Character: 1}, End: protocol.Position{Line: 8, Character: 19},
+		}},
+	}
+	refs := []protocol.Location{referenceAt(2, 14), referenceAt(8, 10), referenceAt(9, 11), referenceAt(13, 0)}
+
+	groups := groupReferences(symbols, refs)
+	require.Len(t, groups, 3)
+	assert.Equal(t, "handler", groups[0].symbol.qualified)
+	// The function is preferred over the variable declared in it
+	assert.Equal(t, "Run", groups[1].symbol.qualified)
+	assert.Len(t, groups[1].refs, 2)
+	assert.Nil(t, groups[2].symbol)
+}
+
+func TestFormatReferenceGroup(t *testing.T) {
+	lines := strings.Split(referencesSource, "\n")
+	run := skeletonSymbol{name: "Run", qualified: "Run", kind: protocol.Function, rng: protocol.Range{
+		Start: protocol.Position{Line: 4}, End: protocol.Position{Line: 11, Character: 1},
+	}}
+	group := referenceGroup{symbol: &run, refs: []protocol.Location{referenceAt(8, 10), referenceAt(9, 11)}}
+
+	assert.Equal(t, `In Run (Function), 2 references: L9:C11, L10:C12
+5|func Run(
+...
+ 8|) error {
+ 9|	first := Helper()
+10|	second := Helper()
+11|	return nil
+`, formatReferenceGroup(lines, group, ReferencesOptions{ContextLines: 1}))
+
+	assert.Equal(t, `In Run (Function), 2 references: L9:C11, L10:C12
+ 5|func Run(
+ 6|	name string,
+ 7|	count int,
+ 8|) error {
+ 9|	first := Helper()
+10|	second := Helper()
+`, formatReferenceGroup(lines, group, ReferencesOptions{ContextLines: 1, Signatures: true}))
+
+	topLevel := referenceGroup{refs: []protocol.Location{referenceAt(2, 14)}}
+	assert.Equal(t, "At top level, 1 reference: L3:C15\n3|var handler = Run\n",
+		formatReferenceGroup(lines, topLevel, ReferencesOptions{}))
+}
+
+func TestMatchesPathGlobs(t *testing.T) {
+	include, err := compileGlobs([]string{"src/**"})
+	require.NoError(t, err)
+	exclude, err := compileGlobs([]string{"*_test.go"})
+	require.NoError(t, err)
+
+	assert.True(t, matchesPathGlobs("src/server/main.go", include, exclude))
+	assert.False(t, matchesPathGlobs("src/server/main_test.go", include, exclude))
+	assert.False(t, matchesPathGlobs("cmd/main.go", include, exclude))
+	assert.True(t, matchesPathGlobs("cmd/main.go", nil, exclude))
+
+	_, err = compileGlobs([]string{""})
+	assert.Error(t, err)
+}
@@ -0,0 +1,106 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/isaacphi/mcp-language-server/internal/utilities"
+)
+
+// RenameFile moves a file and updates imports and other references to it.
+//
+// If the server registered for workspace/willRenameFiles, it is asked for the
+// edits that keep references working. Those edits and the move are applied as
+// one journaled transaction, after which the server is sent didRenameFiles and
+// a fileops rename event is emitted. If the server can't update references,
+// the file is still moved and the result carries a warning.
+func RenameFile(ctx context.Context, client *lsp.Client, oldPath, newPath string) (*EditResult, error) {
+	oldPath, err := resolveWorkspacePath(oldPath)
+	if err != nil {
+		return nil, err
+	}
+	newPath, err = resolveWorkspacePath(newPath)
+	if err != nil {
+		return nil, err
+	}
+	if oldPath == newPath {
+		return nil, fmt.Errorf("source and destination are the same file: %s", oldPath)
+	}
+
+	info, err := os.Stat(oldPath)
+	if err != nil {
+		return nil, fmt.Errorf("cannot rename %s: %v", oldPath, err)
+	}
+	if info.IsDir() {
+		return nil, fmt.Errorf("cannot rename %s: is a directory", oldPath)
+	}
+	if _, err := os.Stat(newPath); err == nil {
+		return nil, fmt.Errorf("cannot rename to %s: file already exists", newPath)
+	}
+
+	oldURI := protocol.DocumentUri("file://" + oldPath)
+	newURI := protocol.DocumentUri("file://" + newPath)
+	rename := protocol.FileRename{OldURI: string(oldURI), NewURI: string(newURI)}
+
+	// Ask the server for edits that update references to the old path
+	var workspaceEdit protocol.WorkspaceEdit
+	var warning string
+	if lsp.HasWillRenameFilesSupport(client.GetCapabilities()) {
+		edit, err := client.WillRenameFiles(ctx, protocol.RenameFilesParams{Files: []protocol.FileRename{rename}})
+		if err != nil {
+			toolsLogger.Warn("willRenameFiles failed for %s: %v", oldPath, err)
+			warning = fmt.Sprintf("the language server failed to compute import updates (%v), so references to the file were not updated", err)
+		} else {
+			workspaceEdit = edit
+		}
+	} else {
+		warning = "the language server does not support workspace/willRenameFiles, so references to the file were not updated"
+	}
+
+	// The server's edits target paths as they are before the move
+	workspaceEdit.DocumentChanges = append(workspaceEdit.DocumentChanges, protocol.DocumentChange{
+		RenameFile: &protocol.RenameFile{
+			Kind:    "rename",
+			OldURI:  oldURI,
+			NewURI:  newURI,
+			Options: &protocol.RenameFileOptions{},
+		},
+	})
+
+	if err := os.MkdirAll(filepath.Dir(newPath), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create directory for %s: %v", newPath, err)
+	}
+
+	root := workspaceRoot(client)
+	label := displayPath(root, oldPath) + " -> " + displayPath(root, newPath)
+	result, err := utilities.ApplyJournaledWorkspaceEdit("rename_file", label, workspaceEdit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to rename file: %v", err)
+	}
+
+	files := []patchedFile{{path: newPath, oldPath: oldPath, renamed: true}}
+	updated := otherCommittedFiles(result, oldPath, newPath)
+	for _, path := range updated {
+		files = append(files, patchedFile{path: path, oldPath: path, changed: true})
+	}
+	notifyPatchedFiles(ctx, client, files)
+
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("Renamed %s -> %s\n", oldPath, newPath))
+	if warning != "" {
+		output.WriteString(fmt.Sprintf("WARNING: %s\n", warning))
+	} else if len(updated) == 0 {
+		output.WriteString("No references needed updating.\n")
+	} else {
+		output.WriteString(fmt.Sprintf("Updated references in %d files:\n", len(updated)))
+		for _, path := range updated {
+			output.WriteString(path + "\n")
+		}
+	}
+	return journaledEdits(client, output.String(), result.Entry), nil
+}
@@ -17,11 +17,13 @@ import (
 // If validate is true (default), calls PrepareRename first to validate the rename operation.
 // If PrepareRename fails, returns an error without attempting the rename.
 // Set validate to false to skip validation and attempt rename directly (for backward compatibility).
-func RenameSymbol(ctx context.Context, client *lsp.Client, filePath string, line, column int, newName string, validate bool) (string, error) {
+//
+// If dryRun is true, the edit is rendered as unified diffs and nothing is written to disk.
+func RenameSymbol(ctx context.Context, client *lsp.Client, filePath string, line, column int, newName string, validate, dryRun bool) (*EditResult, error) {
 	// Open the file if not already open
 	err := client.OpenFile(ctx, filePath)
 	if err != nil {
-		return "", fmt.Errorf("could not open file: %v", err)
+		return nil, fmt.Errorf("could not open file: %v", err)
 	}
 
 	// Convert 1-indexed line/column to 0-indexed for LSP protocol
@@ -53,19 +55,19 @@ func RenameSymbol(ctx context.Context, client *lsp.Client, filePath string, line
 
 		prepareResult, err := client.PrepareRename(ctx, prepareParams)
 		if err != nil {
-			return "", fmt.Errorf("rename validation failed: %v", err)
+			return nil, fmt.Errorf("rename validation failed: %v", err)
 		}
 
 		// If PrepareRename returns nil result, the position cannot be renamed
 		if prepareResult.Value == nil {
-			return "", fmt.Errorf("symbol at position cannot be renamed")
+			return nil, fmt.Errorf("symbol at position cannot be renamed")
 		}
 	}
 
 	// Execute the rename operation
 	workspaceEdit, err := client.Rename(ctx, params)
 	if err != nil {
-		return "", fmt.Errorf("failed to rename symbol: %v", err)
+		return nil, fmt.Errorf("failed to rename symbol: %v", err)
 	}
 
 	// Count the changes that will be made
@@ -135,16 +137,30 @@ func RenameSymbol(ctx context.Context, client *lsp.Client, filePath string, line
 		locationsBuilder.WriteString(fmt.Sprintf("%s: %s\n", change.URI, change.Locations))
 	}
 
+	if dryRun {
+		if fileCount == 0 || changeCount == 0 {
+			return noEdits("Failed to rename symbol. 0 occurrences found."), nil
+		}
+		preview, err := PreviewWorkspaceEdit(client, workspaceEdit)
+		if err != nil {
+			return nil, err
+		}
+		preview.Message = fmt.Sprintf("Renaming symbol to '%s' would update %d occurrences across %d files:\n%s\n%s",
+			newName, changeCount, fileCount, locationsBuilder.String(), preview.Message)
+		return preview, nil
+	}
+
 	// Apply the workspace edit to files:workspaceEdit
-	if err := utilities.ApplyWorkspaceEdit(workspaceEdit); err != nil {
-		return "", fmt.Errorf("failed to apply changes: %v", err)
+	result, err := utilities.ApplyJournaledWorkspaceEdit("rename_symbol", "rename to "+newName, workspaceEdit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply changes: %v", err)
 	}
 
 	if fileCount == 0 || changeCount == 0 {
-		return "Failed to rename symbol. 0 occurrences found.", nil
+		return journaledEdits(client, "Failed to rename symbol. 0 occurrences found.", result.Entry), nil
 	}
 
 	// Generate a summary of changes made
-	return fmt.Sprintf("Successfully renamed symbol to '%s'.\nUpdated %d occurrences across %d files:\n%s",
-		newName, changeCount, fileCount, locationsBuilder.String()), nil
+	return journaledEdits(client, fmt.Sprintf("Successfully renamed symbol to '%s'.\nUpdated %d occurrences across %d files:\n%s",
+		newName, changeCount, fileCount, locationsBuilder.String()), result.Entry), nil
 }
@@ -30,5 +30,5 @@ func TestRenameSymbol_ValidationParameter(t *testing.T) {
 func TestRenameSymbol_Signature(t *testing.T) {
 	// Verify that RenameSymbol function exists and has correct signature
 	// by attempting to reference it with the expected parameters
-	var _ func(context.Context, *lsp.Client, string, int, int, string, bool) (string, error) = RenameSymbol
+	var _ func(context.Context, *lsp.Client, string, int, int, string, bool, bool) (*EditResult, error) = RenameSymbol
 }
@@ -0,0 +1,147 @@
+package tools
+
+import (
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/isaacphi/mcp-language-server/internal/utilities"
+)
+
+// Result is the data collected by a tool. It's returned to MCP clients as
+// structured content, and Format renders it as the text shown to language
+// models.
+//
+// Result types must not be recursive: their output schemas are generated by
+// reflection with every definition inlined.
+type Result interface {
+	Format() string
+}
+
+// Position is a 1-indexed line and column
+type Position struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// Range is a span of text, the end being exclusive
+type Range struct {
+	Start Position `json:"start"`
+	End   Position `json:"end"`
+}
+
+// Location is a range in a file
+type Location struct {
+	Path  string `json:"path"`
+	Range Range  `json:"range"`
+}
+
+func newPosition(position protocol.Position) Position {
+	return Position{Line: int(position.Line) + 1, Column: int(position.Character) + 1}
+}
+
+func newRange(rng protocol.Range) Range {
+	return Range{Start: newPosition(rng.Start), End: newPosition(rng.End)}
+}
+
+func newRanges(ranges []protocol.Range) []Range {
+	result := make([]Range, 0, len(ranges))
+	for _, rng := range ranges {
+		result = append(result, newRange(rng))
+	}
+	return result
+}
+
+func newLocation(loc protocol.Location) Location {
+	return Location{Path: loc.URI.Path(), Range: newRange(loc.Range)}
+}
+
+// HierarchyItem is a symbol in a call or type hierarchy
+type HierarchyItem struct {
+	Name     string   `json:"name"`
+	Kind     string   `json:"kind,omitempty"`
+	Detail   string   `json:"detail,omitempty"`
+	Location Location `json:"location"`
+}
+
+func newHierarchyItem(name string, kind protocol.SymbolKind, detail string, uri protocol.DocumentUri, rng protocol.Range) HierarchyItem {
+	item := HierarchyItem{
+		Name:     name,
+		Detail:   detail,
+		Location: newLocation(protocol.Location{URI: uri, Range: rng}),
+	}
+	// Items built from plain locations have no kind
+	if kind != 0 {
+		item.Kind = symbolKindToString(kind)
+	}
+	return item
+}
+
+// FileChange is the change an edit made, or would make, to one file
+type FileChange struct {
+	// Path is the file after the edit, or the deleted file
+	Path string `json:"path"`
+	// OldPath is set when the file was renamed
+	OldPath string `json:"oldPath,omitempty"`
+	Created bool   `json:"created,omitempty"`
+	Deleted bool   `json:"deleted,omitempty"`
+	// Diff is a unified diff of the content change, empty if the content is unchanged
+	Diff string `json:"diff,omitempty"`
+}
+
+// EditResult is the outcome of a tool that edits files. Changes lists what
+// was written, or for a dry run what would be; it's empty when the tool
+// applied nothing, e.g. because it listed choices instead.
+type EditResult struct {
+	Message string       `json:"message"`
+	DryRun  bool         `json:"dryRun,omitempty"`
+	Changes []FileChange `json:"changes"`
+	// Diagnostics are the diagnostics of the edited file after the edit, if requested
+	Diagnostics []Diagnostic `json:"diagnostics,omitempty"`
+	// Hash is the SHA-256 of the edited file after the edit, set by edit_file
+	Hash string `json:"hash,omitempty"`
+}
+
+func (r *EditResult) Format() string {
+	return r.Message
+}
+
+// noEdits returns the result of a tool call that didn't edit anything
+func noEdits(message string) *EditResult {
+	return &EditResult{Message: message, Changes: []FileChange{}}
+}
+
+// journaledEdits returns the result of a tool call with the changes of the
+// journal entries it recorded. Nil entries, for edits that changed nothing,
+// are skipped.
+func journaledEdits(client *lsp.Client, message string, entries ...*utilities.JournalEntry) *EditResult {
+	result := noEdits(message)
+	root := workspaceRoot(client)
+	for _, entry := range entries {
+		if entry != nil {
+			result.Changes = append(result.Changes, diffChanges(entry.Diffs(root, utilities.DefaultDiffContext))...)
+		}
+	}
+	return result
+}
+
+// serverEdits returns the edits the language server applied through
+// workspace/applyEdit after the edit with ID since, e.g. while running a
+// command. The caller takes since from utilities.DefaultJournal.LastID before
+// the request. The server's edits can't be tied to the request that caused
+// them, so this includes any it applied meanwhile for other requests.
+func serverEdits(since int) []*utilities.JournalEntry {
+	var entries []*utilities.JournalEntry
+	for _, entry := range utilities.DefaultJournal.Since(since) {
+		if entry.Source == utilities.ServerEditSource {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+func diffChanges(diffs []utilities.FileDiff) []FileChange {
+	changes := make([]FileChange, 0, len(diffs))
+	for _, diff := range diffs {
+		changes = append(changes, FileChange(diff))
+	}
+	return changes
+}
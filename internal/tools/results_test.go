@@ -0,0 +1,63 @@
+package tools
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/isaacphi/mcp-language-server/internal/utilities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResultLocations(t *testing.T) {
+	loc := protocol.Location{
+		URI: "file:///ws/main.go",
+		Range: protocol.Range{
+			Start: protocol.Position{Line: 4, Character: 5},
+			End:   protocol.Position{Line: 4, Character: 9},
+		},
+	}
+	assert.Equal(t, Location{
+		Path:  "/ws/main.go",
+		Range: Range{Start: Position{Line: 5, Column: 6}, End: Position{Line: 5, Column: 10}},
+	}, newLocation(loc))
+	assert.Equal(t, []Range{}, newRanges(nil))
+
+	item := newHierarchyItem("Run", protocol.Function, "", loc.URI, loc.Range)
+	assert.Equal(t, "Function", item.Kind)
+	item = newHierarchyItem("Run", 0, "", loc.URI, loc.Range)
+	assert.Empty(t, item.Kind)
+}
+
+func TestEditResultJSON(t *testing.T) {
+	data, err := json.Marshal(noEdits("Nothing to do"))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"message": "Nothing to do", "changes": []}`, string(data))
+
+	result := &EditResult{
+		Message: "Renamed",
+		Changes: diffChanges([]utilities.FileDiff{
+			{Path: "/ws/new.go", OldPath: "/ws/old.go"},
+			{Path: "/ws/main.go", Diff: "--- a/main.go\n+++ b/main.go\n"},
+		}),
+	}
+	data, err = json.Marshal(result)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"message": "Renamed",
+		"changes": [
+			{"path": "/ws/new.go", "oldPath": "/ws/old.go"},
+			{"path": "/ws/main.go", "diff": "--- a/main.go\n+++ b/main.go\n"}
+		]
+	}`, string(data))
+	assert.Equal(t, "Renamed", result.Format())
+}
+
+func TestInlayHintsResultFormat(t *testing.T) {
+	result := &InlayHintsResult{Path: "/ws/main.go", StartLine: 1, EndLine: 5, Hints: []InlayHint{}}
+	assert.Equal(t, "No inlay hints found for lines 1-5", result.Format())
+
+	result.Hints = append(result.Hints, InlayHint{Position: Position{Line: 3, Column: 8}, Kind: "parameter", Label: "name:", Tooltip: "the name"})
+	assert.Equal(t, "Inlay Hints for lines 1-5 (1 hints):\n\nLine 3, Col 8 [parameter]: name: (tooltip: the name)\n", result.Format())
+}
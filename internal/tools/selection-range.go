@@ -9,14 +9,22 @@ import (
 	"github.com/isaacphi/mcp-language-server/internal/protocol"
 )
 
+// SelectionRangesResult is the hierarchy of selections at a position,
+// innermost first, each range containing the one before it
+type SelectionRangesResult struct {
+	Path     string   `json:"path"`
+	Position Position `json:"position"`
+	Ranges   []Range  `json:"ranges"`
+}
+
 // GetSelectionRanges retrieves selection ranges for a position in a document.
 // Selection ranges show the hierarchy of nested selections at a given position,
 // allowing progressive expansion of the selection.
-func GetSelectionRanges(ctx context.Context, client *lsp.Client, filePath string, line, column int) (string, error) {
+func GetSelectionRanges(ctx context.Context, client *lsp.Client, filePath string, line, column int) (*SelectionRangesResult, error) {
 	// Open the file if not already open
 	err := client.OpenFile(ctx, filePath)
 	if err != nil {
-		return "", fmt.Errorf("could not open file: %v", err)
+		return nil, fmt.Errorf("could not open file: %v", err)
 	}
 
 	// Convert 1-indexed line/column to 0-indexed for LSP protocol
@@ -35,33 +43,35 @@ func GetSelectionRanges(ctx context.Context, client *lsp.Client, filePath string
 	// Execute the selection range request
 	selectionRanges, err := client.SelectionRange(ctx, params)
 	if err != nil {
-		return "", fmt.Errorf("failed to get selection ranges: %v", err)
+		return nil, fmt.Errorf("failed to get selection ranges: %v", err)
 	}
 
+	result := &SelectionRangesResult{Path: filePath, Position: Position{Line: line, Column: column}, Ranges: []Range{}}
 	if len(selectionRanges) == 0 {
-		return "No selection ranges found", nil
+		return result, nil
+	}
+
+	// Walk the selection hierarchy for the first (and only) position
+	for selRange := &selectionRanges[0]; selRange != nil; selRange = selRange.Parent {
+		result.Ranges = append(result.Ranges, newRange(selRange.Range))
+	}
+	return result, nil
+}
+
+func (r *SelectionRangesResult) Format() string {
+	if len(r.Ranges) == 0 {
+		return "No selection ranges found"
 	}
 
 	var output strings.Builder
 	output.WriteString(fmt.Sprintf("Selection Ranges for %s at line %d, column %d:\n\n",
-		filePath, line, column))
-
-	// Walk the selection hierarchy for the first (and only) position
-	selRange := &selectionRanges[0]
-	level := 0
-	for selRange != nil {
-		startLine := selRange.Range.Start.Line + 1
-		startChar := selRange.Range.Start.Character + 1
-		endLine := selRange.Range.End.Line + 1
-		endChar := selRange.Range.End.Character + 1
+		r.Path, r.Position.Line, r.Position.Column))
 
+	for level, rng := range r.Ranges {
 		indent := strings.Repeat("  ", level)
 		output.WriteString(fmt.Sprintf("%sLevel %d: [%d:%d-%d:%d]\n",
-			indent, level, startLine, startChar, endLine, endChar))
-
-		selRange = selRange.Parent
-		level++
+			indent, level, rng.Start.Line, rng.Start.Column, rng.End.Line, rng.End.Column))
 	}
 
-	return output.String(), nil
+	return output.String()
 }
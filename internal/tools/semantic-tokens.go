@@ -9,12 +9,30 @@ import (
 	"github.com/isaacphi/mcp-language-server/internal/protocol"
 )
 
+// SemanticToken is a classified span of text
+type SemanticToken struct {
+	Position  Position `json:"position"`
+	Length    int      `json:"length"`
+	Type      string   `json:"type"`
+	Modifiers []string `json:"modifiers,omitempty"`
+}
+
+// SemanticTokensResult is the server's token legend and the first tokens of a
+// file. Count is the number of tokens in the whole file.
+type SemanticTokensResult struct {
+	Path           string          `json:"path"`
+	TokenTypes     []string        `json:"tokenTypes"`
+	TokenModifiers []string        `json:"tokenModifiers"`
+	Count          int             `json:"count"`
+	Tokens         []SemanticToken `json:"tokens"`
+}
+
 // GetSemanticTokens retrieves semantic tokens for a file, providing syntax highlighting information
-func GetSemanticTokens(ctx context.Context, client *lsp.Client, filePath string) (string, error) {
+func GetSemanticTokens(ctx context.Context, client *lsp.Client, filePath string) (*SemanticTokensResult, error) {
 	// Open the file if not already open
 	err := client.OpenFile(ctx, filePath)
 	if err != nil {
-		return "", fmt.Errorf("could not open file: %v", err)
+		return nil, fmt.Errorf("could not open file: %v", err)
 	}
 
 	params := protocol.SemanticTokensParams{}
@@ -26,15 +44,13 @@ func GetSemanticTokens(ctx context.Context, client *lsp.Client, filePath string)
 	// Execute the semantic tokens request
 	tokensResult, err := client.SemanticTokensFull(ctx, params)
 	if err != nil {
-		return "", fmt.Errorf("failed to get semantic tokens: %v", err)
+		return nil, fmt.Errorf("failed to get semantic tokens: %v", err)
 	}
 
-	var result strings.Builder
-
 	// Get the legend from server capabilities to decode token types and modifiers
 	caps := client.GetCapabilities()
 	if caps == nil || caps.SemanticTokensProvider == nil {
-		return "", fmt.Errorf("server does not support semantic tokens")
+		return nil, fmt.Errorf("server does not support semantic tokens")
 	}
 
 	// Extract legend from the semantic tokens provider
@@ -63,26 +79,19 @@ func GetSemanticTokens(ctx context.Context, client *lsp.Client, filePath string)
 	}
 
 	if len(tokenTypes) == 0 {
-		return "", fmt.Errorf("no token types found in server capabilities")
+		return nil, fmt.Errorf("no token types found in server capabilities")
 	}
 
 	// Decode the semantic tokens (delta-encoded integers)
 	tokens := tokensResult.Data
-	if len(tokens) == 0 {
-		return "No semantic tokens found in this file", nil
+	result := &SemanticTokensResult{
+		Path:           filePath,
+		TokenTypes:     tokenTypes,
+		TokenModifiers: tokenModifiers,
+		Count:          len(tokens) / 5,
+		Tokens:         []SemanticToken{},
 	}
 
-	result.WriteString(fmt.Sprintf("Semantic Tokens (%d tokens):\n\n", len(tokens)/5))
-	result.WriteString("Token Types Available:\n")
-	for i, tokenType := range tokenTypes {
-		result.WriteString(fmt.Sprintf("  %d: %s\n", i, tokenType))
-	}
-	result.WriteString("\nToken Modifiers Available:\n")
-	for i, tokenMod := range tokenModifiers {
-		result.WriteString(fmt.Sprintf("  %d: %s\n", i, tokenMod))
-	}
-
-	result.WriteString("\nTokens (first 10):\n")
 	line := uint32(0)
 	char := uint32(0)
 
@@ -115,18 +124,47 @@ func GetSemanticTokens(ctx context.Context, client *lsp.Client, filePath string)
 			}
 		}
 
+		result.Tokens = append(result.Tokens, SemanticToken{
+			Position:  newPosition(protocol.Position{Line: line, Character: char}),
+			Length:    int(length),
+			Type:      tokenTypeName,
+			Modifiers: modNames,
+		})
+	}
+
+	return result, nil
+}
+
+func (r *SemanticTokensResult) Format() string {
+	if r.Count == 0 {
+		return "No semantic tokens found in this file"
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Semantic Tokens (%d tokens):\n\n", r.Count))
+	result.WriteString("Token Types Available:\n")
+	for i, tokenType := range r.TokenTypes {
+		result.WriteString(fmt.Sprintf("  %d: %s\n", i, tokenType))
+	}
+	result.WriteString("\nToken Modifiers Available:\n")
+	for i, tokenMod := range r.TokenModifiers {
+		result.WriteString(fmt.Sprintf("  %d: %s\n", i, tokenMod))
+	}
+
+	result.WriteString("\nTokens (first 10):\n")
+	for _, token := range r.Tokens {
 		modStr := "none"
-		if len(modNames) > 0 {
-			modStr = strings.Join(modNames, ", ")
+		if len(token.Modifiers) > 0 {
+			modStr = strings.Join(token.Modifiers, ", ")
 		}
 
 		result.WriteString(fmt.Sprintf("  Line %d, Col %d, Len %d: %s [%s]\n",
-			line+1, char+1, length, tokenTypeName, modStr))
+			token.Position.Line, token.Position.Column, token.Length, token.Type, modStr))
 	}
 
-	if len(tokens)/5 > 10 {
-		result.WriteString(fmt.Sprintf("\n... and %d more tokens\n", len(tokens)/5-10))
+	if r.Count > len(r.Tokens) {
+		result.WriteString(fmt.Sprintf("\n... and %d more tokens\n", r.Count-len(r.Tokens)))
 	}
 
-	return result.String(), nil
+	return result.String()
 }
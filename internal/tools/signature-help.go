@@ -9,12 +9,37 @@ import (
 	"github.com/isaacphi/mcp-language-server/internal/protocol"
 )
 
+// SignatureParameter is a parameter of a signature
+type SignatureParameter struct {
+	Label         string `json:"label"`
+	Documentation string `json:"documentation,omitempty"`
+}
+
+// Signature is a signature of the function being called
+type Signature struct {
+	Label         string               `json:"label"`
+	Documentation string               `json:"documentation,omitempty"`
+	Parameters    []SignatureParameter `json:"parameters,omitempty"`
+}
+
+// SignatureHelpResult is the signature help at a position. ActiveSignature
+// and ActiveParameter are 0-based indexes. When there are no signatures, Line
+// holds the source line of the position.
+type SignatureHelpResult struct {
+	Path            string      `json:"path"`
+	Position        Position    `json:"position"`
+	Signatures      []Signature `json:"signatures"`
+	ActiveSignature int         `json:"activeSignature"`
+	ActiveParameter int         `json:"activeParameter"`
+	Line            string      `json:"line,omitempty"`
+}
+
 // GetSignatureHelp returns function signature information at the given position
-func GetSignatureHelp(ctx context.Context, client *lsp.Client, filePath string, line, column int) (string, error) {
+func GetSignatureHelp(ctx context.Context, client *lsp.Client, filePath string, line, column int) (*SignatureHelpResult, error) {
 	// Open the file if not already open
 	err := client.OpenFile(ctx, filePath)
 	if err != nil {
-		return "", fmt.Errorf("could not open file: %v", err)
+		return nil, fmt.Errorf("could not open file: %v", err)
 	}
 
 	params := protocol.SignatureHelpParams{}
@@ -33,10 +58,10 @@ func GetSignatureHelp(ctx context.Context, client *lsp.Client, filePath string,
 	// Execute the signature help request
 	signatureResult, err := client.SignatureHelp(ctx, params)
 	if err != nil {
-		return "", fmt.Errorf("failed to get signature help: %v", err)
+		return nil, fmt.Errorf("failed to get signature help: %v", err)
 	}
 
-	var result strings.Builder
+	result := &SignatureHelpResult{Path: filePath, Position: newPosition(position), Signatures: []Signature{}}
 
 	// Check if we have any signatures
 	if len(signatureResult.Signatures) == 0 {
@@ -57,86 +82,107 @@ func GetSignatureHelp(ctx context.Context, client *lsp.Client, filePath string,
 		if err != nil {
 			toolsLogger.Warn("failed to extract line at position: %v", err)
 		}
-		result.WriteString(fmt.Sprintf("No signature help available for this position on the following line:\n%s", lineText))
-		return result.String(), nil
+		result.Line = lineText
+		return result, nil
 	}
 
 	// Determine active signature index
-	activeSignatureIdx := int(signatureResult.ActiveSignature)
-	if activeSignatureIdx >= len(signatureResult.Signatures) {
-		activeSignatureIdx = 0
+	result.ActiveSignature = int(signatureResult.ActiveSignature)
+	if result.ActiveSignature >= len(signatureResult.Signatures) {
+		result.ActiveSignature = 0
 	}
 
 	// Determine active parameter index
-	activeParameterIdx := int(signatureResult.ActiveParameter)
+	result.ActiveParameter = int(signatureResult.ActiveParameter)
+
+	for _, sig := range signatureResult.Signatures {
+		signature := Signature{Label: sig.Label}
+		if sig.Documentation != nil {
+			switch v := sig.Documentation.Value.(type) {
+			case string:
+				signature.Documentation = v
+			case protocol.MarkupContent:
+				signature.Documentation = v.Value
+			}
+		}
+
+		for _, param := range sig.Parameters {
+			var parameter SignatureParameter
+			switch v := param.Label.Value.(type) {
+			case string:
+				parameter.Label = v
+			case protocol.Tuple_ParameterInformation_label_Item1:
+				// Extract label from signature using offsets
+				start := v.Fld0
+				end := v.Fld1
+				if int(start) < len(sig.Label) && int(end) <= len(sig.Label) {
+					parameter.Label = sig.Label[start:end]
+				}
+			}
+			if param.Documentation != nil {
+				switch v := param.Documentation.Value.(type) {
+				case string:
+					parameter.Documentation = v
+				case protocol.MarkupContent:
+					parameter.Documentation = v.Value
+				}
+			}
+			signature.Parameters = append(signature.Parameters, parameter)
+		}
+		result.Signatures = append(result.Signatures, signature)
+	}
+
+	return result, nil
+}
+
+func (r *SignatureHelpResult) Format() string {
+	if len(r.Signatures) == 0 {
+		return fmt.Sprintf("No signature help available for this position on the following line:\n%s", r.Line)
+	}
 
+	var result strings.Builder
 	result.WriteString("Signature Help:\n\n")
 
 	// Display all signatures
-	for i, sig := range signatureResult.Signatures {
+	for i, sig := range r.Signatures {
 		prefix := "  "
-		if i == activeSignatureIdx {
+		if i == r.ActiveSignature {
 			prefix = "▶ "
 		}
 		result.WriteString(fmt.Sprintf("%s%s\n", prefix, sig.Label))
 
 		// For the active signature, show detailed parameter information
-		if i == activeSignatureIdx {
-			if len(sig.Parameters) > 0 {
-				result.WriteString("\nParameters:\n")
-				for j, param := range sig.Parameters {
-					// Get parameter label
-					var paramLabel string
-					switch v := param.Label.Value.(type) {
-					case string:
-						paramLabel = v
-					case protocol.Tuple_ParameterInformation_label_Item1:
-						// Extract label from signature using offsets
-						start := v.Fld0
-						end := v.Fld1
-						if int(start) < len(sig.Label) && int(end) <= len(sig.Label) {
-							paramLabel = sig.Label[start:end]
-						}
-					}
-
-					// Mark active parameter
-					activeMarker := " "
-					if j == activeParameterIdx {
-						activeMarker = "▶"
-					}
-
-					// Get parameter documentation if available
-					paramDoc := ""
-					if param.Documentation != nil {
-						switch v := param.Documentation.Value.(type) {
-						case string:
-							paramDoc = fmt.Sprintf(" - %s", v)
-						case protocol.MarkupContent:
-							paramDoc = fmt.Sprintf(" - %s", v.Value)
-						}
-					}
-
-					result.WriteString(fmt.Sprintf("  %s %s%s\n", activeMarker, paramLabel, paramDoc))
+		if i != r.ActiveSignature {
+			continue
+		}
+		if len(sig.Parameters) > 0 {
+			result.WriteString("\nParameters:\n")
+			for j, param := range sig.Parameters {
+				// Mark active parameter
+				activeMarker := " "
+				if j == r.ActiveParameter {
+					activeMarker = "▶"
 				}
-			}
 
-			// Show signature documentation if available
-			if sig.Documentation != nil {
-				result.WriteString("\nDocumentation:\n")
-				switch v := sig.Documentation.Value.(type) {
-				case string:
-					result.WriteString(fmt.Sprintf("%s\n", v))
-				case protocol.MarkupContent:
-					result.WriteString(fmt.Sprintf("%s\n", v.Value))
+				paramDoc := ""
+				if param.Documentation != "" {
+					paramDoc = fmt.Sprintf(" - %s", param.Documentation)
 				}
+
+				result.WriteString(fmt.Sprintf("  %s %s%s\n", activeMarker, param.Label, paramDoc))
 			}
 		}
+
+		// Show signature documentation if available
+		if sig.Documentation != "" {
+			result.WriteString(fmt.Sprintf("\nDocumentation:\n%s\n", sig.Documentation))
+		}
 	}
 
-	if len(signatureResult.Signatures) > 1 {
+	if len(r.Signatures) > 1 {
 		result.WriteString(fmt.Sprintf("\nShowing %d of %d signatures (▶ marks active signature/parameter)\n",
-			activeSignatureIdx+1, len(signatureResult.Signatures)))
+			r.ActiveSignature+1, len(r.Signatures)))
 	}
 
-	return result.String(), nil
+	return result.String()
 }
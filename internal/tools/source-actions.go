@@ -0,0 +1,234 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/isaacphi/mcp-language-server/internal/utilities"
+)
+
+// OrganizeImports runs the server's source.organizeImports code action on
+// each file given by path or matched by glob, applying the resulting edits.
+func OrganizeImports(ctx context.Context, client *lsp.Client, filePaths []string, glob string) (*EditResult, error) {
+	return runSourceAction(ctx, client, "organize_imports", protocol.SourceOrganizeImports, filePaths, glob)
+}
+
+// FixAll runs the server's source.fixAll code action on each file given by
+// path or matched by glob, applying the resulting edits.
+func FixAll(ctx context.Context, client *lsp.Client, filePaths []string, glob string) (*EditResult, error) {
+	return runSourceAction(ctx, client, "fix_all", protocol.SourceFixAll, filePaths, glob)
+}
+
+// sourceActionOutcome is what happened to one file
+type sourceActionOutcome struct {
+	path    string
+	changed bool
+	message string
+	entries []*utilities.JournalEntry
+}
+
+func runSourceAction(ctx context.Context, client *lsp.Client, source string, kind protocol.CodeActionKind, filePaths []string, glob string) (*EditResult, error) {
+	if len(filePaths) == 0 && glob == "" {
+		return nil, fmt.Errorf("either filePaths or glob is required")
+	}
+
+	root := workspaceRoot(client)
+	files, err := collectFiles(root, filePaths, glob)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no files match %s", glob)
+	}
+
+	var outcomes []sourceActionOutcome
+	var entries []*utilities.JournalEntry
+	changed := 0
+	for _, path := range files {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		outcome := applySourceAction(ctx, client, source, kind, path)
+		outcome.path = displayPath(root, path)
+		entries = append(entries, outcome.entries...)
+		if outcome.changed {
+			changed++
+		}
+		outcomes = append(outcomes, outcome)
+	}
+
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("Ran %s on %d files, %d changed:\n", kind, len(files), changed))
+	for _, outcome := range outcomes {
+		output.WriteString(fmt.Sprintf("%s: %s\n", outcome.path, outcome.message))
+	}
+	return journaledEdits(client, output.String(), entries...), nil
+}
+
+// applySourceAction requests code actions of the given kind for a whole file
+// and applies the first one, preferring actions the server marks as preferred
+func applySourceAction(ctx context.Context, client *lsp.Client, source string, kind protocol.CodeActionKind, path string) sourceActionOutcome {
+	if err := client.OpenFile(ctx, path); err != nil {
+		return sourceActionOutcome{message: fmt.Sprintf("could not open file: %v", err)}
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return sourceActionOutcome{message: fmt.Sprintf("failed to read file: %v", err)}
+	}
+
+	uri := protocol.DocumentUri("file://" + path)
+	params := protocol.CodeActionParams{
+		TextDocument: protocol.TextDocumentIdentifier{URI: uri},
+		Range:        documentRange(string(content)),
+		Context: protocol.CodeActionContext{
+			Diagnostics: client.GetFileDiagnostics(uri),
+			Only:        []protocol.CodeActionKind{kind},
+		},
+	}
+
+	actions, err := client.CodeAction(ctx, params)
+	if err != nil {
+		return sourceActionOutcome{message: fmt.Sprintf("failed to get code actions: %v", err)}
+	}
+
+	action, ok := selectCodeAction(actions, kind)
+	if !ok {
+		return sourceActionOutcome{message: "no action available"}
+	}
+
+	action, err = resolveCodeAction(ctx, client, action)
+	if err != nil {
+		return sourceActionOutcome{message: fmt.Sprintf("failed to resolve code action: %v", err)}
+	}
+	result, err := applyCodeAction(ctx, client, source, action)
+	if err != nil {
+		return sourceActionOutcome{message: err.Error()}
+	}
+	if !result.changed() {
+		return sourceActionOutcome{message: "no changes"}
+	}
+	return sourceActionOutcome{changed: true, message: fmt.Sprintf("applied %q", action.Title), entries: result.entries}
+}
+
+// selectCodeAction picks the action of the given kind from a code action
+// response, preferring the one marked as preferred. Bare commands and disabled
+// actions are skipped.
+func selectCodeAction(items []protocol.Or_Result_textDocument_codeAction_Item0_Elem, kind protocol.CodeActionKind) (protocol.CodeAction, bool) {
+	var selected protocol.CodeAction
+	found := false
+	for _, item := range items {
+		value, ok := item.Value.(map[string]any)
+		if !ok {
+			continue
+		}
+		action, err := decodeCodeAction(value)
+		if err != nil || action.Disabled != nil || !codeActionKindMatches(action.Kind, kind) {
+			continue
+		}
+		if !found || (action.IsPreferred && !selected.IsPreferred) {
+			selected = action
+			found = true
+		}
+	}
+	return selected, found
+}
+
+// codeActionKindMatches reports whether kind is want or a sub-kind of it, e.g.
+// source.organizeImports.ruff for source.organizeImports
+func codeActionKindMatches(kind, want protocol.CodeActionKind) bool {
+	return kind == want || strings.HasPrefix(string(kind), string(want)+".")
+}
+
+// codeActionResult is what applying a code action did
+type codeActionResult struct {
+	// files are the paths changed by the action's edit
+	files []string
+	// commandRun is set if the action's command was executed on the server
+	commandRun bool
+	// skippedCommand is the action's command if the server doesn't execute
+	// it, e.g. a prompt to rename an extracted symbol meant for the editor
+	skippedCommand *protocol.Command
+	// entries are the journal entries of the action's edit and of the edits
+	// its command made
+	entries []*utilities.JournalEntry
+}
+
+// changed reports whether any file changed; a command counts as a change since
+// its edits arrive separately through workspace/applyEdit
+func (r codeActionResult) changed() bool {
+	return len(r.files) > 0 || r.commandRun
+}
+
+// applyCodeAction applies the edit of a resolved code action, then runs its
+// command if the server executes it.
+func applyCodeAction(ctx context.Context, client *lsp.Client, source string, action protocol.CodeAction) (codeActionResult, error) {
+	var result codeActionResult
+	if action.Edit != nil {
+		editResult, err := utilities.ApplyJournaledWorkspaceEdit(source, action.Title, *action.Edit)
+		if err != nil {
+			return result, fmt.Errorf("failed to apply code action: %v", err)
+		}
+		syncJournalFiles(ctx, client, editResult.Committed)
+		result.files = editResult.Committed
+		result.entries = append(result.entries, editResult.Entry)
+	}
+
+	if action.Command == nil {
+		return result, nil
+	}
+	if !lsp.HasExecuteCommandSupport(client.GetCapabilities(), action.Command.Command) {
+		toolsLogger.Debug("Not executing client-side command %s", action.Command.Command)
+		result.skippedCommand = action.Command
+		return result, nil
+	}
+
+	// Commands may make further edits through workspace/applyEdit
+	since := utilities.DefaultJournal.LastID()
+	_, err := client.ExecuteCommand(ctx, protocol.ExecuteCommandParams{
+		Command:   action.Command.Command,
+		Arguments: action.Command.Arguments,
+	})
+	if err != nil {
+		return result, fmt.Errorf("failed to execute command %s: %v", action.Command.Command, err)
+	}
+	result.commandRun = true
+	result.entries = append(result.entries, serverEdits(since)...)
+	return result, nil
+}
+
+// syncJournalFiles tells the language server about the files a journaled code
+// action edit wrote. Open files are updated and removed ones closed.
+func syncJournalFiles(ctx context.Context, client *lsp.Client, paths []string) {
+	for _, path := range paths {
+		if info, err := os.Stat(path); err != nil || info.IsDir() {
+			if err := client.CloseFile(ctx, path); err != nil {
+				toolsLogger.Warn("Failed to close removed file %s: %v", path, err)
+			}
+			continue
+		}
+		if client.IsFileOpen(path) {
+			if err := client.NotifyChange(ctx, path); err != nil {
+				toolsLogger.Warn("Failed to notify change for %s: %v", path, err)
+			}
+		}
+	}
+}
+
+// documentRange returns a range that spans the whole document
+func documentRange(content string) protocol.Range {
+	lines := strings.Split(content, "\n")
+	last := lines[len(lines)-1]
+	return protocol.Range{
+		Start: protocol.Position{Line: 0, Character: 0},
+		End: protocol.Position{
+			Line:      uint32(len(lines) - 1),
+			Character: uint32(len(utf16.Encode([]rune(last)))),
+		},
+	}
+}
@@ -0,0 +1,43 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelectCodeAction(t *testing.T) {
+	items := []protocol.Or_Result_textDocument_codeAction_Item0_Elem{
+		{Value: map[string]any{"title": "Fix typo", "kind": "quickfix"}},
+		{Value: map[string]any{"title": "Run organizer", "command": "organize"}},
+		{Value: map[string]any{"title": "Organize imports (disabled)", "kind": "source.organizeImports", "disabled": map[string]any{"reason": "busy"}}},
+		{Value: map[string]any{"title": "Organize imports", "kind": "source.organizeImports"}},
+		{Value: map[string]any{"title": "Organize imports (ruff)", "kind": "source.organizeImports.ruff", "isPreferred": true}},
+	}
+
+	action, ok := selectCodeAction(items, protocol.SourceOrganizeImports)
+	require.True(t, ok)
+	assert.Equal(t, "Organize imports (ruff)", action.Title)
+
+	action, ok = selectCodeAction(items[:4], protocol.SourceOrganizeImports)
+	require.True(t, ok)
+	assert.Equal(t, "Organize imports", action.Title)
+
+	_, ok = selectCodeAction(items, protocol.SourceFixAll)
+	assert.False(t, ok)
+}
+
+func TestCodeActionKindMatches(t *testing.T) {
+	assert.True(t, codeActionKindMatches("source.fixAll", protocol.SourceFixAll))
+	assert.True(t, codeActionKindMatches("source.fixAll.eslint", protocol.SourceFixAll))
+	assert.False(t, codeActionKindMatches("source.fixAllTheThings", protocol.SourceFixAll))
+	assert.False(t, codeActionKindMatches("source", protocol.SourceFixAll))
+}
+
+func TestDocumentRange(t *testing.T) {
+	assert.Equal(t, protocol.Range{End: protocol.Position{Line: 2, Character: 0}}, documentRange("a\nb\n"))
+	// Characters are counted in UTF-16 code units
+	assert.Equal(t, protocol.Range{End: protocol.Position{Line: 1, Character: 4}}, documentRange("a\nx😀y"))
+}
@@ -0,0 +1,269 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/isaacphi/mcp-language-server/internal/utilities"
+)
+
+// Symbol edit modes, named after the tools that apply them
+const (
+	ReplaceSymbol      = "replace_symbol"
+	InsertBeforeSymbol = "insert_before_symbol"
+	InsertAfterSymbol  = "insert_after_symbol"
+)
+
+// symbolEditDiagnosticsTimeout is how long to wait for diagnostics after an edit
+const symbolEditDiagnosticsTimeout = 3 * time.Second
+
+// SymbolEditOptions controls how a symbol edit is applied and reported
+type SymbolEditOptions struct {
+	// IncludeDocs makes a replacement cover the doc comment, decorators and
+	// attributes above the symbol as well
+	IncludeDocs bool
+	// Format runs textDocument/rangeFormatting over the new text
+	Format bool
+	// Diagnostics reports the file's diagnostics after the edit
+	Diagnostics bool
+}
+
+// EditSymbol replaces a symbol, or inserts text before or after it, given the
+// symbol's dotted path in a file. Insertions are separated from the symbol by
+// a blank line and go above its doc comment when inserting before it. Text
+// that isn't indented is indented like the symbol, so a method can be written
+// without the indentation of its class.
+//
+// The edit and any formatting are recorded as a single undoable edit.
+func EditSymbol(ctx context.Context, client *lsp.Client, filePath, symbolPath, mode, newText string, opts SymbolEditOptions) (*EditResult, error) {
+	if mode != ReplaceSymbol && mode != InsertBeforeSymbol && mode != InsertAfterSymbol {
+		return nil, fmt.Errorf("unknown symbol edit mode: %s", mode)
+	}
+	if filePath == "" {
+		return nil, fmt.Errorf("filePath is required")
+	}
+
+	targets, err := findSymbolTargets(ctx, client, filePath, symbolPath)
+	if err != nil {
+		return nil, err
+	}
+	if len(targets) > 1 {
+		var matches []string
+		for _, target := range targets {
+			matches = append(matches, fmt.Sprintf("%s (%s) at L%d", target.name, symbolKindToString(target.kind), target.rng.Start.Line+1))
+		}
+		return nil, fmt.Errorf("symbol path %s is ambiguous, it matches: %s", symbolPath, strings.Join(matches, ", "))
+	}
+	target := targets[0]
+
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %v", err)
+	}
+	lines := strings.Split(string(content), "\n")
+	edit, first, count := symbolTextEdit(lines, target.rng, mode, newText, opts.IncludeDocs)
+
+	uri := protocol.DocumentUri("file://" + filePath)
+	result, err := utilities.ApplyJournaledWorkspaceEdit(mode, symbolPath, protocol.WorkspaceEdit{
+		Changes: map[protocol.DocumentUri][]protocol.TextEdit{uri: {edit}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply edit: %v", err)
+	}
+
+	var output strings.Builder
+	switch mode {
+	case ReplaceSymbol:
+		output.WriteString(fmt.Sprintf("Replaced %s (%s)", target.name, symbolKindToString(target.kind)))
+	case InsertBeforeSymbol:
+		output.WriteString(fmt.Sprintf("Inserted %d lines before %s (%s)", count, target.name, symbolKindToString(target.kind)))
+	case InsertAfterSymbol:
+		output.WriteString(fmt.Sprintf("Inserted %d lines after %s (%s)", count, target.name, symbolKindToString(target.kind)))
+	}
+	output.WriteString(fmt.Sprintf(", new text at L%d-%d of %s\n", first+1, first+count, filePath))
+
+	if err := client.NotifyChange(ctx, filePath); err != nil {
+		toolsLogger.Warn("Failed to notify change for %s: %v", filePath, err)
+	}
+
+	entries := []*utilities.JournalEntry{result.Entry}
+	if opts.Format {
+		message, entry := formatInsertedLines(ctx, client, filePath, first, count, result.Entry)
+		output.WriteString(message + "\n")
+		if entry != nil && entry != result.Entry {
+			entries = append(entries, entry)
+		}
+	}
+
+	var diagnostics []Diagnostic
+	if opts.Diagnostics {
+		diagnostics = postEditDiagnostics(ctx, client, filePath)
+		output.WriteString("\n" + formatPostEditDiagnostics(diagnostics))
+	}
+	editResult := journaledEdits(client, output.String(), entries...)
+	editResult.Diagnostics = diagnostics
+	return editResult, nil
+}
+
+// symbolTextEdit builds the edit for a symbol edit mode, along with the
+// 0-based line the new text starts on afterwards and its number of lines
+func symbolTextEdit(lines []string, rng protocol.Range, mode, newText string, includeDocs bool) (protocol.TextEdit, int, int) {
+	start, end := symbolLines(lines, rng, false)
+	docStart := docStartLine(lines, start)
+
+	text := strings.TrimRight(strings.ReplaceAll(newText, "\r\n", "\n"), "\n")
+	text = indentLike(text, leadingWhitespace(lines[start]))
+	count := strings.Count(text, "\n") + 1
+	endOfSymbol := protocol.Position{Line: uint32(end), Character: uint32(len(strings.TrimSuffix(lines[end], "\r")))}
+
+	switch mode {
+	case InsertBeforeSymbol:
+		at := protocol.Position{Line: uint32(docStart)}
+		return protocol.TextEdit{Range: protocol.Range{Start: at, End: at}, NewText: text + "\n\n"}, docStart, count
+	case InsertAfterSymbol:
+		return protocol.TextEdit{Range: protocol.Range{Start: endOfSymbol, End: endOfSymbol}, NewText: "\n\n" + text}, end + 2, count
+	default:
+		if includeDocs {
+			start = docStart
+		}
+		return protocol.TextEdit{
+			Range:   protocol.Range{Start: protocol.Position{Line: uint32(start)}, End: endOfSymbol},
+			NewText: text,
+		}, start, count
+	}
+}
+
+// indentLike indents every non-blank line of text by indent, unless the text
+// is already indented
+func indentLike(text, indent string) string {
+	if indent == "" || leadingWhitespace(text) != "" {
+		return text
+	}
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if strings.TrimSpace(line) != "" {
+			lines[i] = indent + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// leadingWhitespace returns the spaces and tabs a line starts with
+func leadingWhitespace(line string) string {
+	return line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+}
+
+// formattingOptions guesses the indentation style of a file from the leading
+// whitespace of its lines: tabs or spaces, whichever indents more lines, with
+// the smallest space indentation as the tab size. Single spaces, such as
+// those before the "*" of block comments, are not counted. Files without
+// indentation get tabs.
+func formattingOptions(lines []string) protocol.FormattingOptions {
+	tabs, spaces, tabSize := 0, 0, 0
+	for _, line := range lines {
+		indent := leadingWhitespace(line)
+		if strings.TrimSpace(line) == "" || indent == "" {
+			continue
+		}
+		if indent[0] == '\t' {
+			tabs++
+			continue
+		}
+		if n := len(indent) - len(strings.TrimLeft(indent, " ")); n > 1 {
+			spaces++
+			if tabSize == 0 || n < tabSize {
+				tabSize = n
+			}
+		}
+	}
+	if spaces > tabs {
+		return protocol.FormattingOptions{TabSize: uint32(tabSize), InsertSpaces: true}
+	}
+	return protocol.FormattingOptions{TabSize: 4, InsertSpaces: false}
+}
+
+// formatInsertedLines formats lines of a file with textDocument/rangeFormatting,
+// adding the formatting to the edit's journal entry so one undo reverts both.
+// It describes the outcome and returns the entry the formatting was journaled
+// in, nil if nothing was formatted.
+func formatInsertedLines(ctx context.Context, client *lsp.Client, filePath string, first, count int, entry *utilities.JournalEntry) (string, *utilities.JournalEntry) {
+	if !lsp.HasRangeFormattingSupport(client.GetCapabilities()) {
+		return "Not formatted: the server doesn't support range formatting", nil
+	}
+
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return fmt.Sprintf("Not formatted: %v", err), nil
+	}
+	lines := strings.Split(string(content), "\n")
+	last := first + count - 1
+	if last >= len(lines) {
+		last = len(lines) - 1
+	}
+
+	uri := protocol.DocumentUri("file://" + filePath)
+	edits, err := client.RangeFormatting(ctx, protocol.DocumentRangeFormattingParams{
+		TextDocument: protocol.TextDocumentIdentifier{URI: uri},
+		Range: protocol.Range{
+			Start: protocol.Position{Line: uint32(first)},
+			End:   protocol.Position{Line: uint32(last), Character: uint32(len(strings.TrimSuffix(lines[last], "\r")))},
+		},
+		Options: formattingOptions(lines),
+	})
+	if err != nil {
+		return fmt.Sprintf("Not formatted: %v", err), nil
+	}
+	if len(edits) == 0 {
+		return "Formatting made no changes", nil
+	}
+
+	result, err := utilities.DefaultJournal.Amend(entry, protocol.WorkspaceEdit{
+		Changes: map[protocol.DocumentUri][]protocol.TextEdit{uri: edits},
+	})
+	if err != nil {
+		return fmt.Sprintf("Not formatted: %v", err), nil
+	}
+	if err := client.NotifyChange(ctx, filePath); err != nil {
+		toolsLogger.Warn("Failed to notify change for %s: %v", filePath, err)
+	}
+	return fmt.Sprintf("Applied %d formatting changes", len(edits)), result.Entry
+}
+
+// postEditDiagnostics waits briefly for the server to publish diagnostics for
+// an edited file and returns them, recording the listing for fix_diagnostic
+func postEditDiagnostics(ctx context.Context, client *lsp.Client, filePath string) []Diagnostic {
+	uri := protocol.DocumentUri("file://" + filePath)
+	if _, err := client.WaitForDiagnostics(ctx, string(uri), symbolEditDiagnosticsTimeout); err != nil {
+		toolsLogger.Debug("No diagnostics published for %s: %v", filePath, err)
+	}
+
+	diagnostics := client.GetFileDiagnostics(uri)
+	if content, err := os.ReadFile(filePath); err == nil && len(diagnostics) > 0 {
+		recordDiagnosticListing(uri, diagnostics, content)
+	}
+
+	result := []Diagnostic{}
+	for _, diag := range diagnostics {
+		result = append(result, newDiagnostic(diag))
+	}
+	return result
+}
+
+// formatPostEditDiagnostics lists the diagnostics after an edit, numbered for fix_diagnostic
+func formatPostEditDiagnostics(diagnostics []Diagnostic) string {
+	if len(diagnostics) == 0 {
+		return "No diagnostics after the edit\n"
+	}
+
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("Diagnostics after the edit (%d):\n", len(diagnostics)))
+	for i, diag := range diagnostics {
+		output.WriteString(fmt.Sprintf("%d. %s\n", i+1, diag.summary()))
+	}
+	return output.String()
+}
@@ -0,0 +1,143 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/isaacphi/mcp-language-server/internal/utilities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const symbolEditSource = `class Server:
+    # Starts serving.
+    @retry
+    def start(self):
+        return 1
+
+    def stop(self):
+        pass
+`
+
+func TestSymbolTextEdit(t *testing.T) {
+	// start is lines 3-4 (0-based), with a comment and decorator above it
+	rng := protocol.Range{Start: protocol.Position{Line: 3, Character: 4}, End: protocol.Position{Line: 4, Character: 16}}
+
+	tests := []struct {
+		name        string
+		mode        string
+		newText     string
+		includeDocs bool
+		want        string
+		first       int
+		count       int
+	}{
+		{
+			name:    "replace keeps docs and indents the new text",
+			mode:    ReplaceSymbol,
+			newText: "def start(self):\n    return 2\n",
+			want: `class Server:
+    # Starts serving.
+    @retry
+    def start(self):
+        return 2
+
+    def stop(self):
+        pass
+`,
+			first: 3,
+			count: 2,
+		},
+		{
+			name:        "replace with docs",
+			mode:        ReplaceSymbol,
+			newText:     "    def start(self):\n        return 2",
+			includeDocs: true,
+			want: `class Server:
+    def start(self):
+        return 2
+
+    def stop(self):
+        pass
+`,
+			first: 1,
+			count: 2,
+		},
+		{
+			name:    "insert before goes above the docs",
+			mode:    InsertBeforeSymbol,
+			newText: "def setup(self):\n    pass",
+			want: `class Server:
+    def setup(self):
+        pass
+
+    # Starts serving.
+    @retry
+    def start(self):
+        return 1
+
+    def stop(self):
+        pass
+`,
+			first: 1,
+			count: 2,
+		},
+		{
+			name:    "insert after",
+			mode:    InsertAfterSymbol,
+			newText: "def restart(self):\n    pass",
+			want: `class Server:
+    # Starts serving.
+    @retry
+    def start(self):
+        return 1
+
+    def restart(self):
+        pass
+
+    def stop(self):
+        pass
+`,
+			first: 6,
+			count: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "server.py")
+			require.NoError(t, os.WriteFile(path, []byte(symbolEditSource), 0644))
+
+			edit, first, count := symbolTextEdit(strings.Split(symbolEditSource, "\n"), rng, tt.mode, tt.newText, tt.includeDocs)
+			assert.Equal(t, tt.first, first)
+			assert.Equal(t, tt.count, count)
+
+			require.NoError(t, utilities.ApplyTextEdits(protocol.DocumentUri("file://"+path), []protocol.TextEdit{edit}))
+			content, err := os.ReadFile(path)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(content))
+		})
+	}
+}
+
+func TestIndentLike(t *testing.T) {
+	assert.Equal(t, "\tfunc() {\n\t\treturn\n\n\t}", indentLike("func() {\n\treturn\n\n}", "\t"))
+	assert.Equal(t, "  already", indentLike("  already", "\t"))
+	assert.Equal(t, "top", indentLike("top", ""))
+}
+
+func TestFormattingOptions(t *testing.T) {
+	lines := func(text string) []string { return strings.Split(text, "\n") }
+
+	assert.Equal(t, protocol.FormattingOptions{TabSize: 4, InsertSpaces: false},
+		formattingOptions(lines("func main() {\n\tif ok {\n\t\treturn\n\t}\n}")))
+	assert.Equal(t, protocol.FormattingOptions{TabSize: 2, InsertSpaces: true},
+		formattingOptions(lines("class A {\n  /**\n   * Docs\n   */\n  run() {\n    go();\n  }\n}")))
+	assert.Equal(t, protocol.FormattingOptions{TabSize: 4, InsertSpaces: true},
+		formattingOptions(lines("def f():\n    if x:\n        return 1\n\n    return 2")))
+	assert.Equal(t, protocol.FormattingOptions{TabSize: 4, InsertSpaces: false},
+		formattingOptions(lines("x = 1\ny = 2")), "unindented files get tabs")
+}
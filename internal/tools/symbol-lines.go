@@ -0,0 +1,49 @@
+package tools
+
+import (
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+// symbolLines returns the 0-based first and last lines of a symbol. A range
+// ending at the start of a line doesn't include that line. With includeDocs
+// the first line moves up over the comments, decorators and attributes
+// directly above the symbol.
+func symbolLines(lines []string, rng protocol.Range, includeDocs bool) (int, int) {
+	start, end := int(rng.Start.Line), int(rng.End.Line)
+	if rng.End.Character == 0 && end > start {
+		end--
+	}
+	// A stale range, or one the server reports past the end of the file, is
+	// kept inside the file
+	start = min(start, len(lines)-1)
+	end = min(max(end, start), len(lines)-1)
+	if includeDocs {
+		start = docStartLine(lines, start)
+	}
+	return start, end
+}
+
+// docStartLine walks up from a declaration's first line over the comment,
+// decorator and attribute lines directly above it
+func docStartLine(lines []string, line int) int {
+	for line > 0 && line <= len(lines) {
+		text := strings.TrimSpace(lines[line-1])
+		if text == "" || !(isAnnotationLine(text) || (strings.HasPrefix(text, "[") && strings.HasSuffix(text, "]"))) {
+			break
+		}
+		line--
+	}
+	return line
+}
+
+// isAnnotationLine reports whether a trimmed line is a comment, decorator or attribute
+func isAnnotationLine(text string) bool {
+	for _, prefix := range []string{"//", "#", "@", "/*", "*", "--", ";"} {
+		if strings.HasPrefix(text, prefix) {
+			return true
+		}
+	}
+	return false
+}
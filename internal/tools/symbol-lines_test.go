@@ -0,0 +1,47 @@
+package tools
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSymbolLinesWithDocs(t *testing.T) {
+	lines := strings.Split(`import functools
+
+# Handles a request.
+@functools.cache
+@route("/x")
+def handle(request):
+    return request
+
+`, "\n")
+	rng := protocol.Range{Start: protocol.Position{Line: 5}, End: protocol.Position{Line: 7}}
+
+	start, end := symbolLines(lines, rng, false)
+	assert.Equal(t, 5, start)
+	assert.Equal(t, 6, end, "a range ending at the start of a line leaves it out")
+
+	start, _ = symbolLines(lines, rng, true)
+	assert.Equal(t, 2, start)
+
+	csharp := []string{"", "/// <summary>Runs.</summary>", "[HttpGet]", "public void Run() {}"}
+	assert.Equal(t, 1, docStartLine(csharp, 3))
+}
+
+func TestSymbolLinesOutOfRange(t *testing.T) {
+	lines := strings.Split("package server\n\nfunc Run() {}\n", "\n")
+
+	start, end := symbolLines(lines, lineRange(6, 9), false)
+	assert.Equal(t, 3, start)
+	assert.Equal(t, 3, end)
+
+	start, end = symbolLines(lines, lineRange(2, 9), true)
+	assert.Equal(t, 2, start)
+	assert.Equal(t, 3, end)
+
+	text, _ := cutSymbol(lines, lineRange(6, 9))
+	assert.Equal(t, "", text)
+}
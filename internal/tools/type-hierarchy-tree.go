@@ -0,0 +1,403 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+// DefaultTypeTreeDepth is how many levels of a type hierarchy are shown by default
+const DefaultTypeTreeDepth = 5
+
+// typeTreeNode is a type in a rendered hierarchy
+type typeTreeNode struct {
+	item     protocol.TypeHierarchyItem
+	children []*typeTreeNode
+	// repeated is set if the type is already shown elsewhere in the tree, as
+	// happens with diamond inheritance; its children are not listed again
+	repeated bool
+	// unexpanded is set if the depth limit stopped the walk at this type
+	unexpanded bool
+}
+
+// TypeTreeNode is a type in a supertype or subtype tree, listed depth-first.
+// Depth 1 are the direct supertypes or subtypes.
+type TypeTreeNode struct {
+	Type  HierarchyItem `json:"type"`
+	Depth int           `json:"depth"`
+	// Repeated is set if the type is already listed elsewhere in the tree
+	Repeated bool `json:"repeated,omitempty"`
+	// Unexpanded is set if the depth limit stopped the walk at this type
+	Unexpanded bool `json:"unexpanded,omitempty"`
+}
+
+// TypeTree is the hierarchy of one type. Implementers are the concrete types
+// among its subtypes.
+type TypeTree struct {
+	Type         HierarchyItem   `json:"type"`
+	Supertypes   []TypeTreeNode  `json:"supertypes,omitempty"`
+	Subtypes     []TypeTreeNode  `json:"subtypes,omitempty"`
+	Implementers []HierarchyItem `json:"implementers,omitempty"`
+
+	item         protocol.TypeHierarchyItem
+	supertypes   *typeTreeNode
+	subtypes     *typeTreeNode
+	implementers []protocol.TypeHierarchyItem
+}
+
+// TypeHierarchyTreeResult lists the hierarchy trees of the types at a
+// position. Implementation is set if the server had no type hierarchy and
+// the supertypes and subtypes are textDocument/implementation results instead.
+type TypeHierarchyTreeResult struct {
+	Direction      string     `json:"direction"`
+	Implementation bool       `json:"implementation,omitempty"`
+	Types          []TypeTree `json:"types"`
+
+	root string
+}
+
+// typeFetcher returns the supertypes or subtypes of an item
+type typeFetcher func(ctx context.Context, item protocol.TypeHierarchyItem) ([]protocol.TypeHierarchyItem, error)
+
+// GetTypeHierarchyTree renders the full supertype and/or subtype tree of the
+// type at the given position, up to maxDepth levels, followed by a flat list
+// of the concrete types implementing it.
+//
+// If the server doesn't support type hierarchy, or has none for the symbol,
+// textDocument/implementation is used instead. gopls, for example, reports
+// the types implementing an interface and the interfaces a type implements
+// that way, so the interfaces found for a type that isn't one are its
+// supertypes and every other result is a subtype.
+func GetTypeHierarchyTree(ctx context.Context, client *lsp.Client, filePath string, line, column int, direction string, maxDepth int) (*TypeHierarchyTreeResult, error) {
+	if direction != "supertypes" && direction != "subtypes" && direction != "both" {
+		return nil, fmt.Errorf("direction must be 'supertypes', 'subtypes', or 'both'")
+	}
+	if maxDepth <= 0 {
+		maxDepth = DefaultTypeTreeDepth
+	}
+
+	err := client.OpenFile(ctx, filePath)
+	if err != nil {
+		return nil, fmt.Errorf("could not open file: %v", err)
+	}
+
+	uri := protocol.DocumentUri("file://" + filePath)
+	position := protocol.Position{
+		Line:      uint32(line - 1),
+		Character: uint32(column - 1),
+	}
+	root := workspaceRoot(client)
+
+	var items []protocol.TypeHierarchyItem
+	caps := client.GetCapabilities()
+	if lsp.HasTypeHierarchySupport(caps) {
+		params := protocol.TypeHierarchyPrepareParams{}
+		params.TextDocument = protocol.TextDocumentIdentifier{URI: uri}
+		params.Position = position
+		items, err = client.PrepareTypeHierarchy(ctx, params)
+		if err != nil {
+			toolsLogger.Warn("failed to prepare type hierarchy: %v", err)
+		}
+	}
+
+	supertypes := func(ctx context.Context, item protocol.TypeHierarchyItem) ([]protocol.TypeHierarchyItem, error) {
+		return client.Supertypes(ctx, protocol.TypeHierarchySupertypesParams{Item: item})
+	}
+	subtypes := func(ctx context.Context, item protocol.TypeHierarchyItem) ([]protocol.TypeHierarchyItem, error) {
+		return client.Subtypes(ctx, protocol.TypeHierarchySubtypesParams{Item: item})
+	}
+
+	implementation := false
+	if len(items) == 0 {
+		if !lsp.HasImplementationSupport(caps) {
+			return &TypeHierarchyTreeResult{Direction: direction, Types: []TypeTree{}}, nil
+		}
+		finder := &implementationFinder{client: client, symbols: make(map[string][]skeletonSymbol)}
+		items = []protocol.TypeHierarchyItem{finder.item(ctx, protocol.Location{
+			URI:   uri,
+			Range: protocol.Range{Start: position, End: position},
+		})}
+		supertypes, subtypes = finder.fetcher(true), finder.fetcher(false)
+		implementation = true
+	}
+
+	result := &TypeHierarchyTreeResult{Direction: direction, Implementation: implementation, Types: []TypeTree{}, root: root}
+	for _, item := range items {
+		tree := TypeTree{Type: typeHierarchyItem(item), item: item}
+
+		if direction == "supertypes" || direction == "both" {
+			node, err := buildTypeTree(ctx, item, maxDepth, supertypes)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get supertypes: %v", err)
+			}
+			tree.supertypes = node
+			tree.Supertypes = flattenTypeTree(node)
+		}
+
+		if direction == "subtypes" || direction == "both" {
+			node, err := buildTypeTree(ctx, item, maxDepth, subtypes)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get subtypes: %v", err)
+			}
+			tree.subtypes = node
+			tree.Subtypes = flattenTypeTree(node)
+			tree.setImplementers(concreteImplementers(node))
+		}
+		result.Types = append(result.Types, tree)
+	}
+	return result, nil
+}
+
+func (r *TypeHierarchyTreeResult) Format() string {
+	if len(r.Types) == 0 {
+		return "No type found at this position"
+	}
+	var output strings.Builder
+	for _, tree := range r.Types {
+		output.WriteString(fmt.Sprintf("Type hierarchy of %s\n", formatTypeItem(r.root, tree.item)))
+		if r.Implementation {
+			output.WriteString("Type hierarchy is not available for this symbol, showing textDocument/implementation results instead.\n")
+		}
+
+		if tree.supertypes != nil {
+			output.WriteString("\nSupertypes:\n")
+			writeTypeTree(&output, r.root, tree.supertypes)
+		}
+
+		if tree.subtypes != nil {
+			output.WriteString("\nSubtypes:\n")
+			writeTypeTree(&output, r.root, tree.subtypes)
+
+			output.WriteString(fmt.Sprintf("\nConcrete implementers (%d):\n", len(tree.implementers)))
+			for _, implementer := range tree.implementers {
+				output.WriteString(formatTypeItem(r.root, implementer) + "\n")
+			}
+		}
+		output.WriteString("\n")
+	}
+	return output.String()
+}
+
+// flattenTypeTree lists the types below the root of a tree depth-first, each
+// with its depth, the root's children being at depth 1
+func flattenTypeTree(tree *typeTreeNode) []TypeTreeNode {
+	nodes := []TypeTreeNode{}
+	var walk func(node *typeTreeNode, depth int)
+	walk = func(node *typeTreeNode, depth int) {
+		for _, child := range node.children {
+			nodes = append(nodes, TypeTreeNode{
+				Type:       typeHierarchyItem(child.item),
+				Depth:      depth,
+				Repeated:   child.repeated,
+				Unexpanded: child.unexpanded,
+			})
+			walk(child, depth+1)
+		}
+	}
+	walk(tree, 1)
+	return nodes
+}
+
+func (t *TypeTree) setImplementers(implementers []protocol.TypeHierarchyItem) {
+	t.implementers = implementers
+	t.Implementers = []HierarchyItem{}
+	for _, implementer := range implementers {
+		t.Implementers = append(t.Implementers, typeHierarchyItem(implementer))
+	}
+}
+
+// buildTypeTree walks supertypes or subtypes depth-first from item. Each type
+// is expanded once, so diamonds and cycles are shown as repeated leaves.
+func buildTypeTree(ctx context.Context, item protocol.TypeHierarchyItem, maxDepth int, fetch typeFetcher) (*typeTreeNode, error) {
+	seen := map[string]bool{typeItemKey(item): true}
+
+	var walk func(item protocol.TypeHierarchyItem, depth int) (*typeTreeNode, error)
+	walk = func(item protocol.TypeHierarchyItem, depth int) (*typeTreeNode, error) {
+		node := &typeTreeNode{item: item}
+		if depth >= maxDepth {
+			node.unexpanded = true
+			return node, nil
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		related, err := fetch(ctx, item)
+		if err != nil {
+			return nil, err
+		}
+		for _, child := range related {
+			key := typeItemKey(child)
+			if seen[key] {
+				node.children = append(node.children, &typeTreeNode{item: child, repeated: true})
+				continue
+			}
+			seen[key] = true
+			childNode, err := walk(child, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			node.children = append(node.children, childNode)
+		}
+		return node, nil
+	}
+	return walk(item, 0)
+}
+
+// typeItemKey identifies a type by where its name is declared
+func typeItemKey(item protocol.TypeHierarchyItem) string {
+	return fmt.Sprintf("%s:%d:%d", item.URI, item.SelectionRange.Start.Line, item.SelectionRange.Start.Character)
+}
+
+// writeTypeTree renders a tree with box-drawing branches, one type per line
+func writeTypeTree(output *strings.Builder, root string, tree *typeTreeNode) {
+	output.WriteString(formatTypeItem(root, tree.item) + "\n")
+	if len(tree.children) == 0 && !tree.unexpanded {
+		output.WriteString("└── (none)\n")
+		return
+	}
+
+	var write func(node *typeTreeNode, prefix string, last bool)
+	write = func(node *typeTreeNode, prefix string, last bool) {
+		branch, childPrefix := "├── ", prefix+"│   "
+		if last {
+			branch, childPrefix = "└── ", prefix+"    "
+		}
+		line := prefix + branch + formatTypeItem(root, node.item)
+		switch {
+		case node.repeated:
+			line += " (see above)"
+		case node.unexpanded:
+			line += " ..."
+		}
+		output.WriteString(line + "\n")
+		for i, child := range node.children {
+			write(child, childPrefix, i == len(node.children)-1)
+		}
+	}
+	for i, child := range tree.children {
+		write(child, "", i == len(tree.children)-1)
+	}
+	if tree.unexpanded {
+		output.WriteString("└── ...\n")
+	}
+}
+
+// concreteImplementers returns the types in a subtype tree that aren't
+// interfaces, each once, sorted by location. The root and types of unknown
+// kind are left out.
+func concreteImplementers(tree *typeTreeNode) []protocol.TypeHierarchyItem {
+	seen := make(map[string]bool)
+	var implementers []protocol.TypeHierarchyItem
+	var collect func(node *typeTreeNode)
+	collect = func(node *typeTreeNode) {
+		for _, child := range node.children {
+			key := typeItemKey(child.item)
+			if !seen[key] && child.item.Kind != 0 && child.item.Kind != protocol.Interface {
+				seen[key] = true
+				implementers = append(implementers, child.item)
+			}
+			collect(child)
+		}
+	}
+	collect(tree)
+
+	sort.SliceStable(implementers, func(i, j int) bool {
+		a, b := implementers[i], implementers[j]
+		if a.URI != b.URI {
+			return a.URI < b.URI
+		}
+		return a.SelectionRange.Start.Line < b.SelectionRange.Start.Line
+	})
+	return implementers
+}
+
+// formatTypeItem formats a type as "Name (Kind) at path:line"
+func formatTypeItem(root string, item protocol.TypeHierarchyItem) string {
+	text := item.Name
+	if item.Kind != 0 {
+		text += fmt.Sprintf(" (%s)", symbolKindToString(item.Kind))
+	}
+	return fmt.Sprintf("%s at %s:%d", text,
+		displayPath(root, strings.TrimPrefix(string(item.URI), "file://")),
+		item.SelectionRange.Start.Line+1)
+}
+
+// implementationFinder finds related types with textDocument/implementation
+// for servers without type hierarchy. Locations carry no kind, so it is taken
+// from the document symbol declared there.
+type implementationFinder struct {
+	client *lsp.Client
+	// symbols caches the flattened document symbols of each file
+	symbols map[string][]skeletonSymbol
+}
+
+// fetcher returns the supertypes, or else the subtypes, of an item among its
+// implementation results. An interface found for a type that isn't one is a
+// supertype, anything else a subtype.
+func (f *implementationFinder) fetcher(supertypes bool) typeFetcher {
+	return func(ctx context.Context, item protocol.TypeHierarchyItem) ([]protocol.TypeHierarchyItem, error) {
+		result, err := f.client.Implementation(ctx, protocol.ImplementationParams{
+			TextDocumentPositionParams: protocol.TextDocumentPositionParams{
+				TextDocument: protocol.TextDocumentIdentifier{URI: item.URI},
+				Position:     item.SelectionRange.Start,
+			},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get implementations: %v", err)
+		}
+		locations, err := extractDefinitionLocations(protocol.Or_Result_textDocument_definition{Value: result.Value})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read implementations: %v", err)
+		}
+
+		var related []protocol.TypeHierarchyItem
+		for _, location := range locations {
+			other := f.item(ctx, location)
+			isSupertype := item.Kind != protocol.Interface && other.Kind == protocol.Interface
+			if isSupertype == supertypes {
+				related = append(related, other)
+			}
+		}
+		return related, nil
+	}
+}
+
+// item builds a type item for a location, named after the identifier there.
+// Its kind is that of the innermost document symbol around the location if it
+// has the same name, such as "Server" or "(*Server).Start", and unknown
+// otherwise.
+func (f *implementationFinder) item(ctx context.Context, location protocol.Location) protocol.TypeHierarchyItem {
+	item := protocol.TypeHierarchyItem{
+		Name:           "?",
+		URI:            location.URI,
+		Range:          location.Range,
+		SelectionRange: location.Range,
+	}
+	path := strings.TrimPrefix(string(location.URI), "file://")
+	if content, err := os.ReadFile(path); err == nil {
+		if identifier := identifierAt(string(content), location.Range.Start); identifier != "" {
+			item.Name = identifier
+		}
+	}
+
+	symbols, ok := f.symbols[path]
+	if !ok {
+		results, err := fileDocumentSymbols(ctx, f.client, path)
+		if err != nil {
+			toolsLogger.Warn("Failed to get document symbols of %s: %v", path, err)
+		}
+		symbols = flattenSkeletonSymbols(results)
+		f.symbols[path] = symbols
+	}
+	symbol, ok := enclosingSymbol(symbols, location.Range.Start)
+	if ok && (symbol.name == item.Name || strings.HasSuffix(symbol.name, "."+item.Name)) {
+		item.Kind = symbol.kind
+	}
+	return item
+}
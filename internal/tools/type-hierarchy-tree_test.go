@@ -0,0 +1,88 @@
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func typeItem(name string, kind protocol.SymbolKind, line uint32) protocol.TypeHierarchyItem {
+	position := protocol.Position{Line: line, Character: 6}
+	return protocol.TypeHierarchyItem{
+		Name:           name,
+		Kind:           kind,
+		URI:            "file:///ws/shapes.ts",
+		SelectionRange: protocol.Range{Start: position, End: position},
+	}
+}
+
+func TestBuildTypeTree(t *testing.T) {
+	// Shape <- Polygon, Named; Square implements both (a diamond)
+	items := map[string]protocol.TypeHierarchyItem{
+		"Shape":   typeItem("Shape", protocol.Interface, 0),
+		"Polygon": typeItem("Polygon", protocol.Interface, 4),
+		"Named":   typeItem("Named", protocol.Interface, 8),
+		"Square":  typeItem("Square", protocol.Class, 12),
+		"Unit":    typeItem("Unit", protocol.Class, 20),
+	}
+	subtypes := map[string][]string{
+		"Shape":   {"Polygon", "Named"},
+		"Polygon": {"Square"},
+		"Named":   {"Square"},
+		"Square":  {"Unit"},
+	}
+	fetch := func(ctx context.Context, item protocol.TypeHierarchyItem) ([]protocol.TypeHierarchyItem, error) {
+		var result []protocol.TypeHierarchyItem
+		for _, name := range subtypes[item.Name] {
+			result = append(result, items[name])
+		}
+		return result, nil
+	}
+
+	tree, err := buildTypeTree(context.Background(), items["Shape"], 5, fetch)
+	require.NoError(t, err)
+
+	var output strings.Builder
+	writeTypeTree(&output, "/ws", tree)
+	assert.Equal(t, ""+
+		"Shape (Interface) at shapes.ts:1\n"+
+		"├── Polygon (Interface) at shapes.ts:5\n"+
+		"│   └── Square (Class) at shapes.ts:13\n"+
+		"│       └── Unit (Class) at shapes.ts:21\n"+
+		"└── Named (Interface) at shapes.ts:9\n"+
+		"    └── Square (Class) at shapes.ts:13 (see above)\n",
+		output.String())
+
+	implementers := concreteImplementers(tree)
+	require.Len(t, implementers, 2)
+	assert.Equal(t, "Square", implementers[0].Name)
+	assert.Equal(t, "Unit", implementers[1].Name)
+
+	tree, err = buildTypeTree(context.Background(), items["Shape"], 2, fetch)
+	require.NoError(t, err)
+	output.Reset()
+	writeTypeTree(&output, "/ws", tree)
+	assert.Contains(t, output.String(), "│   └── Square (Class) at shapes.ts:13 ...\n")
+	assert.NotContains(t, output.String(), "Unit")
+}
+
+func TestWriteTypeTree_NoRelatedTypes(t *testing.T) {
+	var output strings.Builder
+	writeTypeTree(&output, "/ws", &typeTreeNode{item: typeItem("Leaf", protocol.Class, 2)})
+	assert.Equal(t, "Leaf (Class) at shapes.ts:3\n└── (none)\n", output.String())
+}
+
+func TestConcreteImplementersSkipsUnknownKinds(t *testing.T) {
+	tree := &typeTreeNode{item: typeItem("Shape", protocol.Interface, 0), children: []*typeTreeNode{
+		{item: typeItem("Square", protocol.Struct, 4)},
+		{item: typeItem("Polygon", protocol.Interface, 8)},
+		{item: typeItem("unknown", 0, 12)},
+	}}
+	implementers := concreteImplementers(tree)
+	require.Len(t, implementers, 1)
+	assert.Equal(t, "Square", implementers[0].Name)
+}
@@ -9,17 +9,35 @@ import (
 	"github.com/isaacphi/mcp-language-server/internal/protocol"
 )
 
+// TypeHierarchyEntry is a type with its direct supertypes and subtypes
+type TypeHierarchyEntry struct {
+	Type       HierarchyItem   `json:"type"`
+	Supertypes []HierarchyItem `json:"supertypes,omitempty"`
+	Subtypes   []HierarchyItem `json:"subtypes,omitempty"`
+
+	item       protocol.TypeHierarchyItem
+	supertypes []protocol.TypeHierarchyItem
+	subtypes   []protocol.TypeHierarchyItem
+}
+
+// TypeHierarchyResult lists the types at a position with their supertypes,
+// subtypes or both
+type TypeHierarchyResult struct {
+	Direction string               `json:"direction"`
+	Types     []TypeHierarchyEntry `json:"types"`
+}
+
 // GetTypeHierarchy retrieves type hierarchy (supertypes, subtypes, or both) for a type at the specified position
-func GetTypeHierarchy(ctx context.Context, client *lsp.Client, filePath string, line, column int, direction string) (string, error) {
+func GetTypeHierarchy(ctx context.Context, client *lsp.Client, filePath string, line, column int, direction string) (*TypeHierarchyResult, error) {
 	// Validate direction parameter
 	if direction != "supertypes" && direction != "subtypes" && direction != "both" {
-		return "", fmt.Errorf("direction must be 'supertypes', 'subtypes', or 'both'")
+		return nil, fmt.Errorf("direction must be 'supertypes', 'subtypes', or 'both'")
 	}
 
 	// Open the file if not already open
 	err := client.OpenFile(ctx, filePath)
 	if err != nil {
-		return "", fmt.Errorf("could not open file: %v", err)
+		return nil, fmt.Errorf("could not open file: %v", err)
 	}
 
 	// Prepare type hierarchy (find the type at the given position)
@@ -36,26 +54,14 @@ func GetTypeHierarchy(ctx context.Context, client *lsp.Client, filePath string,
 
 	items, err := client.PrepareTypeHierarchy(ctx, prepareParams)
 	if err != nil {
-		return "", fmt.Errorf("failed to prepare type hierarchy: %v", err)
-	}
-
-	if len(items) == 0 {
-		return "No type found at this position", nil
+		return nil, fmt.Errorf("failed to prepare type hierarchy: %v", err)
 	}
 
-	var result strings.Builder
+	result := &TypeHierarchyResult{Direction: direction, Types: []TypeHierarchyEntry{}}
 
 	// Process each type hierarchy item
 	for _, item := range items {
-		result.WriteString(fmt.Sprintf("Type: %s\n", item.Name))
-		result.WriteString(fmt.Sprintf("Kind: %d\n", item.Kind))
-		if item.Detail != "" {
-			result.WriteString(fmt.Sprintf("Detail: %s\n", item.Detail))
-		}
-		result.WriteString(fmt.Sprintf("Location: %s:%d:%d\n\n",
-			item.URI,
-			item.Range.Start.Line+1,
-			item.Range.Start.Character+1))
+		entry := TypeHierarchyEntry{Type: typeHierarchyItem(item), item: item}
 
 		// Get supertypes if requested
 		if direction == "supertypes" || direction == "both" {
@@ -65,15 +71,10 @@ func GetTypeHierarchy(ctx context.Context, client *lsp.Client, filePath string,
 			supertypes, err := client.Supertypes(ctx, supertypesParams)
 			if err != nil {
 				toolsLogger.Warn("failed to get supertypes: %v", err)
-			} else if len(supertypes) > 0 {
-				result.WriteString("Supertypes:\n")
-				for _, supertype := range supertypes {
-					result.WriteString(fmt.Sprintf("  - %s (%d)\n", supertype.Name, supertype.Kind))
-					if supertype.Detail != "" {
-						result.WriteString(fmt.Sprintf("    Detail: %s\n", supertype.Detail))
-					}
-				}
-				result.WriteString("\n")
+			}
+			entry.supertypes = supertypes
+			for _, supertype := range supertypes {
+				entry.Supertypes = append(entry.Supertypes, typeHierarchyItem(supertype))
 			}
 		}
 
@@ -85,18 +86,58 @@ func GetTypeHierarchy(ctx context.Context, client *lsp.Client, filePath string,
 			subtypes, err := client.Subtypes(ctx, subtypesParams)
 			if err != nil {
 				toolsLogger.Warn("failed to get subtypes: %v", err)
-			} else if len(subtypes) > 0 {
-				result.WriteString("Subtypes:\n")
-				for _, subtype := range subtypes {
-					result.WriteString(fmt.Sprintf("  - %s (%d)\n", subtype.Name, subtype.Kind))
-					if subtype.Detail != "" {
-						result.WriteString(fmt.Sprintf("    Detail: %s\n", subtype.Detail))
-					}
-				}
-				result.WriteString("\n")
+			}
+			entry.subtypes = subtypes
+			for _, subtype := range subtypes {
+				entry.Subtypes = append(entry.Subtypes, typeHierarchyItem(subtype))
 			}
 		}
+		result.Types = append(result.Types, entry)
 	}
 
-	return result.String(), nil
+	return result, nil
+}
+
+func typeHierarchyItem(item protocol.TypeHierarchyItem) HierarchyItem {
+	return newHierarchyItem(item.Name, item.Kind, item.Detail, item.URI, item.Range)
+}
+
+func (r *TypeHierarchyResult) Format() string {
+	if len(r.Types) == 0 {
+		return "No type found at this position"
+	}
+
+	var result strings.Builder
+	for _, entry := range r.Types {
+		item := entry.item
+		result.WriteString(fmt.Sprintf("Type: %s\n", item.Name))
+		result.WriteString(fmt.Sprintf("Kind: %d\n", item.Kind))
+		if item.Detail != "" {
+			result.WriteString(fmt.Sprintf("Detail: %s\n", item.Detail))
+		}
+		result.WriteString(fmt.Sprintf("Location: %s:%d:%d\n\n",
+			item.URI,
+			item.Range.Start.Line+1,
+			item.Range.Start.Character+1))
+
+		writeRelatedTypes(&result, "Supertypes", entry.supertypes)
+		writeRelatedTypes(&result, "Subtypes", entry.subtypes)
+	}
+
+	return result.String()
+}
+
+// writeRelatedTypes lists the supertypes or subtypes of a type
+func writeRelatedTypes(result *strings.Builder, heading string, items []protocol.TypeHierarchyItem) {
+	if len(items) == 0 {
+		return
+	}
+	result.WriteString(heading + ":\n")
+	for _, item := range items {
+		result.WriteString(fmt.Sprintf("  - %s (%d)\n", item.Name, item.Kind))
+		if item.Detail != "" {
+			result.WriteString(fmt.Sprintf("    Detail: %s\n", item.Detail))
+		}
+	}
+	result.WriteString("\n")
 }
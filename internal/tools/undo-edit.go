@@ -0,0 +1,75 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/utilities"
+)
+
+// UndoEdit reverts the most recent edit in the edit journal. Every edit made by
+// a tool that writes files, and by the language server through
+// workspace/applyEdit, is journaled. The undo is refused if any affected file
+// changed after the edit.
+func UndoEdit(ctx context.Context, client *lsp.Client) (*EditResult, error) {
+	entry, err := utilities.DefaultJournal.Undo()
+	if err != nil {
+		return nil, err
+	}
+	diffs := entry.UndoDiffs(workspaceRoot(client), utilities.DefaultDiffContext)
+	notifyPatchedFiles(ctx, client, journalPatchedFiles(diffs))
+	return &EditResult{
+		Message: formatJournalResult("Undid", entry),
+		Changes: diffChanges(diffs),
+	}, nil
+}
+
+// RedoEdit reapplies the most recently undone edit. The redo is refused if any
+// affected file changed after the undo.
+func RedoEdit(ctx context.Context, client *lsp.Client) (*EditResult, error) {
+	entry, err := utilities.DefaultJournal.Redo()
+	if err != nil {
+		return nil, err
+	}
+	diffs := entry.Diffs(workspaceRoot(client), utilities.DefaultDiffContext)
+	notifyPatchedFiles(ctx, client, journalPatchedFiles(diffs))
+	return &EditResult{
+		Message: formatJournalResult("Redid", entry),
+		Changes: diffChanges(diffs),
+	}, nil
+}
+
+func formatJournalResult(action string, entry *utilities.JournalEntry) string {
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("%s edit %s:\n", action, entry.Describe()))
+	for _, path := range entry.Paths {
+		output.WriteString(path + "\n")
+	}
+
+	undoable, redoable := utilities.DefaultJournal.History()
+	output.WriteString(fmt.Sprintf("\n%d edits can be undone, %d can be redone.", len(undoable), len(redoable)))
+	if len(undoable) > 0 {
+		output.WriteString(fmt.Sprintf("\nNext undo: %s", undoable[0].Describe()))
+	}
+	return output.String()
+}
+
+// journalPatchedFiles turns the changes an undo or redo made into the files
+// to report with notifyPatchedFiles, so the language server and fileops
+// listeners see restored, removed and renamed files like any other edit
+func journalPatchedFiles(diffs []utilities.FileDiff) []patchedFile {
+	files := make([]patchedFile, 0, len(diffs))
+	for _, diff := range diffs {
+		file := patchedFile{path: diff.Path, oldPath: diff.Path, created: diff.Created, deleted: diff.Deleted}
+		switch {
+		case diff.OldPath != "":
+			file.oldPath, file.renamed = diff.OldPath, true
+		case !diff.Created && !diff.Deleted:
+			file.changed = true
+		}
+		files = append(files, file)
+	}
+	return files
+}
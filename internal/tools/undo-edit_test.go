@@ -0,0 +1,49 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/isaacphi/mcp-language-server/internal/utilities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJournalPatchedFiles(t *testing.T) {
+	dir := t.TempDir()
+	oldPath, newPath := filepath.Join(dir, "a.go"), filepath.Join(dir, "b.go")
+	created, edited := filepath.Join(dir, "c.go"), filepath.Join(dir, "d.go")
+	require.NoError(t, os.WriteFile(oldPath, []byte("package a\n"), 0644))
+	require.NoError(t, os.WriteFile(edited, []byte("package a\n"), 0644))
+
+	journal := utilities.NewEditJournal(10)
+	_, err := journal.Apply("test", "", protocol.WorkspaceEdit{DocumentChanges: []protocol.DocumentChange{
+		{RenameFile: &protocol.RenameFile{Kind: "rename", OldURI: protocol.DocumentUri("file://" + oldPath), NewURI: protocol.DocumentUri("file://" + newPath)}},
+		{CreateFile: &protocol.CreateFile{Kind: "create", URI: protocol.DocumentUri("file://" + created)}},
+		{TextDocumentEdit: &protocol.TextDocumentEdit{
+			TextDocument: protocol.OptionalVersionedTextDocumentIdentifier{
+				TextDocumentIdentifier: protocol.TextDocumentIdentifier{URI: protocol.DocumentUri("file://" + edited)},
+			},
+			Edits: []protocol.Or_TextDocumentEdit_edits_Elem{{Value: protocol.TextEdit{NewText: "// d\n"}}},
+		}},
+	}})
+	require.NoError(t, err)
+
+	entry, err := journal.Undo()
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []patchedFile{
+		{path: oldPath, oldPath: newPath, renamed: true},
+		{path: created, oldPath: created, deleted: true},
+		{path: edited, oldPath: edited, changed: true},
+	}, journalPatchedFiles(entry.UndoDiffs(dir, utilities.DefaultDiffContext)))
+
+	entry, err = journal.Redo()
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []patchedFile{
+		{path: newPath, oldPath: oldPath, renamed: true},
+		{path: created, oldPath: created, created: true},
+		{path: edited, oldPath: edited, changed: true},
+	}, journalPatchedFiles(entry.Diffs(dir, utilities.DefaultDiffContext)))
+}
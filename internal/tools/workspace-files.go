@@ -0,0 +1,139 @@
+package tools
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/utilities"
+	"github.com/isaacphi/mcp-language-server/internal/watcher"
+)
+
+// resolveWorkspacePath turns a path given to a tool into a clean absolute path.
+// Relative paths are resolved against the workspace, which is the working
+// directory.
+func resolveWorkspacePath(path string) (string, error) {
+	if path == "" {
+		return "", fmt.Errorf("file path is empty")
+	}
+	if filepath.IsAbs(path) {
+		return filepath.Clean(path), nil
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve path %s: %v", path, err)
+	}
+	return abs, nil
+}
+
+// workspaceFilter decides which workspace paths tools walk, skipping the same
+// directories as the workspace watcher and anything gitignored
+type workspaceFilter struct {
+	root         string
+	excludedDirs map[string]bool
+	gitignore    *watcher.GitignoreMatcher
+}
+
+func newWorkspaceFilter(root string) *workspaceFilter {
+	gitignore, err := watcher.NewGitignoreMatcher(root)
+	if err != nil {
+		toolsLogger.Warn("Failed to load .gitignore from %s: %v", root, err)
+	}
+	return &workspaceFilter{
+		root:         root,
+		excludedDirs: watcher.DefaultWatcherConfig().ExcludedDirs,
+		gitignore:    gitignore,
+	}
+}
+
+// skipDir reports whether a directory should not be walked
+func (f *workspaceFilter) skipDir(path string) bool {
+	if path == f.root {
+		return false
+	}
+	name := filepath.Base(path)
+	if f.excludedDirs[name] || strings.HasPrefix(name, ".") {
+		return true
+	}
+	return f.gitignore != nil && f.gitignore.ShouldIgnore(path, true)
+}
+
+// skipFile reports whether a file should be left out
+func (f *workspaceFilter) skipFile(path string) bool {
+	return f.gitignore != nil && f.gitignore.ShouldIgnore(path, false)
+}
+
+// globWorkspaceFiles returns the files under root matching pattern, which is
+// relative to root. Excluded and gitignored paths are skipped.
+func globWorkspaceFiles(root, pattern string) ([]string, error) {
+	glob, err := utilities.CompileGlob(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := newWorkspaceFilter(root)
+	var files []string
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			toolsLogger.Debug("Skipping %s: %v", path, err)
+			return nil
+		}
+		if d.IsDir() {
+			if filter.skipDir(path) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if filter.skipFile(path) {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return nil
+		}
+		if glob.Match(filepath.ToSlash(rel)) {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk workspace: %v", err)
+	}
+
+	sort.Strings(files)
+	return files, nil
+}
+
+// collectFiles resolves explicit file paths and a glob into a sorted, de-duplicated list
+func collectFiles(root string, filePaths []string, pattern string) ([]string, error) {
+	seen := make(map[string]bool)
+	var files []string
+	for _, filePath := range filePaths {
+		path, err := resolveWorkspacePath(filePath)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[path] {
+			seen[path] = true
+			files = append(files, path)
+		}
+	}
+
+	if pattern != "" {
+		matches, err := globWorkspaceFiles(root, pattern)
+		if err != nil {
+			return nil, err
+		}
+		for _, path := range matches {
+			if !seen[path] {
+				seen[path] = true
+				files = append(files, path)
+			}
+		}
+	}
+
+	sort.Strings(files)
+	return files, nil
+}
@@ -0,0 +1,67 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGlobWorkspaceFiles(t *testing.T) {
+	root := t.TempDir()
+	for _, file := range []string{
+		".gitignore",
+		"main.go",
+		"pkg/util.go",
+		"pkg/util_test.go",
+		"pkg/gen/api.go",
+		"vendor/lib/lib.go",
+		".hidden/x.go",
+		"README.md",
+	} {
+		path := filepath.Join(root, file)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		content := ""
+		if file == ".gitignore" {
+			content = "gen/\n"
+		}
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+
+	files, err := globWorkspaceFiles(root, "*.go")
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(root, "main.go"),
+		filepath.Join(root, "pkg/util.go"),
+		filepath.Join(root, "pkg/util_test.go"),
+	}, files)
+
+	files, err = globWorkspaceFiles(root, "pkg/**/*_test.go")
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(root, "pkg/util_test.go")}, files)
+
+	_, err = globWorkspaceFiles(root, "[abc")
+	assert.Error(t, err)
+
+	files, err = collectFiles(root, []string{filepath.Join(root, "README.md")}, "main.go")
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(root, "README.md"), filepath.Join(root, "main.go")}, files)
+}
+
+func TestResolveWorkspacePath(t *testing.T) {
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+
+	path, err := resolveWorkspacePath("sub/../main.go")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(wd, "main.go"), path)
+
+	path, err = resolveWorkspacePath("/ws/./main.go")
+	require.NoError(t, err)
+	assert.Equal(t, "/ws/main.go", path)
+
+	_, err = resolveWorkspacePath("")
+	assert.EqualError(t, err, "file path is empty")
+}
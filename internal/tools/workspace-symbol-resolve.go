@@ -9,6 +9,27 @@ import (
 	"github.com/isaacphi/mcp-language-server/internal/protocol"
 )
 
+// WorkspaceSymbol is a symbol found by a workspace symbol search. Range is
+// unset when the server only reports the symbol's file.
+type WorkspaceSymbol struct {
+	Name      string `json:"name"`
+	Kind      string `json:"kind"`
+	Container string `json:"container,omitempty"`
+	Path      string `json:"path,omitempty"`
+	Range     *Range `json:"range,omitempty"`
+	// Tags are LSP symbol tags, 1 meaning deprecated
+	Tags []int `json:"tags,omitempty"`
+
+	uri protocol.DocumentUri
+}
+
+// WorkspaceSymbolsResult lists the symbols matching a query
+type WorkspaceSymbolsResult struct {
+	Query   string            `json:"query"`
+	Symbols []WorkspaceSymbol `json:"symbols"`
+	Page    *Page             `json:"page,omitempty"`
+}
+
 // GetWorkspaceSymbolResolved searches for workspace symbols and resolves their details.
 //
 // This function performs two LSP operations:
@@ -17,7 +38,7 @@ import (
 //
 // The resolve step is only performed if the server supports WorkspaceSymbolOptions.ResolveProvider.
 // If resolve is not supported, returns the basic symbol information from workspace/symbol.
-func GetWorkspaceSymbolResolved(ctx context.Context, client *lsp.Client, query string) (string, error) {
+func GetWorkspaceSymbolResolved(ctx context.Context, client *lsp.Client, query string) (*WorkspaceSymbolsResult, error) {
 	// Step 1: Search for workspace symbols
 	params := protocol.WorkspaceSymbolParams{
 		Query: query,
@@ -25,7 +46,7 @@ func GetWorkspaceSymbolResolved(ctx context.Context, client *lsp.Client, query s
 
 	result, err := client.Symbol(ctx, params)
 	if err != nil {
-		return "", fmt.Errorf("failed to search workspace symbols: %v", err)
+		return nil, fmt.Errorf("failed to search workspace symbols: %v", err)
 	}
 
 	// Extract symbols from the Or_Result_workspace_symbol type
@@ -47,28 +68,16 @@ func GetWorkspaceSymbolResolved(ctx context.Context, client *lsp.Client, query s
 				symbols = append(symbols, ws)
 			}
 		default:
-			return "", fmt.Errorf("unexpected workspace symbol result type: %T", v)
+			return nil, fmt.Errorf("unexpected workspace symbol result type: %T", v)
 		}
 	}
 
-	if len(symbols) == 0 {
-		return fmt.Sprintf("No symbols found matching query: %s", query), nil
-	}
-
 	// Step 2: Resolve each symbol for additional details (if supported)
 	caps := client.GetCapabilities()
 	resolveSupported := lsp.HasWorkspaceSymbolResolveSupport(caps)
 
-	var output strings.Builder
-	output.WriteString(fmt.Sprintf("Found %d symbol(s) matching '%s':\n\n", len(symbols), query))
-
-	for i, symbol := range symbols {
-		output.WriteString(fmt.Sprintf("%d. %s (%s)\n", i+1, symbol.Name, symbolKindToString(symbol.Kind)))
-
-		if symbol.ContainerName != "" {
-			output.WriteString(fmt.Sprintf("   Container: %s\n", symbol.ContainerName))
-		}
-
+	output := &WorkspaceSymbolsResult{Query: query, Symbols: []WorkspaceSymbol{}}
+	for _, symbol := range symbols {
 		// Try to resolve for more details if supported
 		if resolveSupported {
 			resolved, err := client.ResolveWorkspaceSymbol(ctx, symbol)
@@ -79,27 +88,87 @@ func GetWorkspaceSymbolResolved(ctx context.Context, client *lsp.Client, query s
 			// Silently continue if resolve fails - we still have basic info
 		}
 
-		// Format location information
+		item := WorkspaceSymbol{
+			Name:      symbol.Name,
+			Kind:      symbolKindToString(symbol.Kind),
+			Container: symbol.ContainerName,
+		}
+		for _, tag := range symbol.Tags {
+			item.Tags = append(item.Tags, int(tag))
+		}
+
+		// Collect location information
 		if symbol.Location.Value != nil {
 			switch loc := symbol.Location.Value.(type) {
 			case protocol.Location:
-				output.WriteString(fmt.Sprintf("   Location: %s:%d:%d\n",
-					loc.URI,
-					loc.Range.Start.Line+1,
-					loc.Range.Start.Character+1))
+				rng := newRange(loc.Range)
+				item.Path, item.Range, item.uri = loc.URI.Path(), &rng, loc.URI
 			case protocol.LocationUriOnly:
-				output.WriteString(fmt.Sprintf("   URI: %s\n", loc.URI))
+				item.Path, item.uri = loc.URI.Path(), loc.URI
 			}
 		}
+		output.Symbols = append(output.Symbols, item)
+	}
 
-		if symbol.Tags != nil && len(symbol.Tags) > 0 {
-			output.WriteString(fmt.Sprintf("   Tags: %v\n", symbol.Tags))
-		}
+	return output, nil
+}
+
+func (r *WorkspaceSymbolsResult) Format() string {
+	if len(r.Symbols) == 0 {
+		return fmt.Sprintf("No symbols found matching query: %s", r.Query)
+	}
+
+	var output strings.Builder
+	offset, total := 0, len(r.Symbols)
+	if r.Page != nil {
+		offset, total = r.Page.Offset, r.Page.Total
+	}
+	output.WriteString(fmt.Sprintf("Found %d symbol(s) matching '%s':\n\n", total, r.Query))
+	for i, symbol := range r.Symbols {
+		output.WriteString(formatWorkspaceSymbol(offset+i+1, symbol))
+	}
+	output.WriteString(r.Page.Format())
+
+	return output.String()
+}
+
+func (r *WorkspaceSymbolsResult) pageItems() []pageItem {
+	items := make([]pageItem, len(r.Symbols))
+	for i, symbol := range r.Symbols {
+		items[i] = pageItem{file: symbol.Path, size: len(formatWorkspaceSymbol(i+1, symbol))}
+	}
+	return items
+}
+
+func (r *WorkspaceSymbolsResult) keepPage(start, end int, page *Page) {
+	r.Symbols, r.Page = r.Symbols[start:end], page
+}
+
+// formatWorkspaceSymbol shows a numbered symbol with its container, location and tags
+func formatWorkspaceSymbol(number int, symbol WorkspaceSymbol) string {
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("%d. %s (%s)\n", number, symbol.Name, symbol.Kind))
+
+	if symbol.Container != "" {
+		output.WriteString(fmt.Sprintf("   Container: %s\n", symbol.Container))
+	}
+
+	// Format location information
+	if symbol.Range != nil {
+		output.WriteString(fmt.Sprintf("   Location: %s:%d:%d\n",
+			symbol.uri,
+			symbol.Range.Start.Line,
+			symbol.Range.Start.Column))
+	} else if symbol.uri != "" {
+		output.WriteString(fmt.Sprintf("   URI: %s\n", symbol.uri))
+	}
 
-		output.WriteString("\n")
+	if len(symbol.Tags) > 0 {
+		output.WriteString(fmt.Sprintf("   Tags: %v\n", symbol.Tags))
 	}
 
-	return output.String(), nil
+	output.WriteString("\n")
+	return output.String()
 }
 
 // symbolKindToString converts a SymbolKind to its string representation
@@ -10,5 +10,5 @@ import (
 // TestGetWorkspaceSymbolResolved_Signature tests the function signature
 func TestGetWorkspaceSymbolResolved_Signature(t *testing.T) {
 	// Verify that GetWorkspaceSymbolResolved function exists and has correct signature
-	var _ func(context.Context, *lsp.Client, string) (string, error) = GetWorkspaceSymbolResolved
+	var _ func(context.Context, *lsp.Client, string) (*WorkspaceSymbolsResult, error) = GetWorkspaceSymbolResolved
 }
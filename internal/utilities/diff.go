@@ -0,0 +1,264 @@
+package utilities
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultDiffContext is the number of unchanged lines shown around each hunk
+const DefaultDiffContext = 3
+
+type diffOpKind int
+
+const (
+	diffEqual diffOpKind = iota
+	diffDelete
+	diffInsert
+)
+
+// diffOp is a single line-level operation in an edit script. For equal and
+// delete operations fromIdx is the line index in the old text, for equal and
+// insert operations toIdx is the line index in the new text.
+type diffOp struct {
+	kind    diffOpKind
+	fromIdx int
+	toIdx   int
+}
+
+// UnifiedDiff returns a unified diff that turns from into to, using fromName and
+// toName in the file headers. An empty string is returned when the texts are equal.
+func UnifiedDiff(fromName, toName, from, to string, contextLines int) string {
+	if from == to {
+		return ""
+	}
+	if contextLines < 0 {
+		contextLines = DefaultDiffContext
+	}
+
+	a := splitDiffLines(from)
+	b := splitDiffLines(to)
+	ops := diffLines(a, b)
+
+	var out strings.Builder
+	out.WriteString(fmt.Sprintf("--- %s\n+++ %s\n", fromName, toName))
+
+	for _, h := range groupHunks(ops, contextLines) {
+		fromStart, fromCount, toStart, toCount := hunkBounds(h)
+		out.WriteString(fmt.Sprintf("@@ -%s +%s @@\n",
+			formatHunkRange(fromStart, fromCount),
+			formatHunkRange(toStart, toCount)))
+
+		for _, op := range h {
+			switch op.kind {
+			case diffEqual:
+				writeDiffLine(&out, ' ', a[op.fromIdx])
+			case diffDelete:
+				writeDiffLine(&out, '-', a[op.fromIdx])
+			case diffInsert:
+				writeDiffLine(&out, '+', b[op.toIdx])
+			}
+		}
+	}
+
+	return out.String()
+}
+
+// splitDiffLines splits text into lines, keeping the trailing newline on each line
+func splitDiffLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+func writeDiffLine(out *strings.Builder, prefix byte, line string) {
+	out.WriteByte(prefix)
+	out.WriteString(line)
+	if !strings.HasSuffix(line, "\n") {
+		out.WriteString("\n\\ No newline at end of file\n")
+	}
+}
+
+// formatHunkRange formats a 0-indexed start and a line count the way diff(1) does
+func formatHunkRange(start, count int) string {
+	switch count {
+	case 0:
+		return fmt.Sprintf("%d,0", start)
+	case 1:
+		return fmt.Sprintf("%d", start+1)
+	default:
+		return fmt.Sprintf("%d,%d", start+1, count)
+	}
+}
+
+// hunkBounds returns the 0-indexed start and length of a hunk on both sides
+func hunkBounds(hunk []diffOp) (fromStart, fromCount, toStart, toCount int) {
+	fromStart, toStart = -1, -1
+	for _, op := range hunk {
+		if op.kind != diffInsert {
+			if fromStart < 0 {
+				fromStart = op.fromIdx
+			}
+			fromCount++
+		}
+		if op.kind != diffDelete {
+			if toStart < 0 {
+				toStart = op.toIdx
+			}
+			toCount++
+		}
+	}
+
+	// Pure insertions and deletions are anchored after the preceding line
+	if fromStart < 0 {
+		fromStart = hunk[0].fromIdx
+	}
+	if toStart < 0 {
+		toStart = hunk[0].toIdx
+	}
+	return fromStart, fromCount, toStart, toCount
+}
+
+// groupHunks splits an edit script into hunks with at most contextLines of
+// unchanged lines on each side, merging changes that are close together
+func groupHunks(ops []diffOp, contextLines int) [][]diffOp {
+	var hunks [][]diffOp
+	start := -1
+	lastChange := -1
+
+	for i, op := range ops {
+		if op.kind == diffEqual {
+			continue
+		}
+		if start >= 0 && i-lastChange-1 > 2*contextLines {
+			hunks = append(hunks, ops[start:min(len(ops), lastChange+contextLines+1)])
+			start = -1
+		}
+		if start < 0 {
+			start = max(0, i-contextLines)
+		}
+		lastChange = i
+	}
+	if start >= 0 {
+		hunks = append(hunks, ops[start:min(len(ops), lastChange+contextLines+1)])
+	}
+
+	return hunks
+}
+
+// diffLines computes a minimal line edit script using the Myers algorithm.
+// Common prefixes and suffixes are stripped first, which keeps the search
+// small for the typical case of a few localized changes.
+func diffLines(a, b []string) []diffOp {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix &&
+		a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	ops := make([]diffOp, 0, len(a)+len(b))
+	for i := 0; i < prefix; i++ {
+		ops = append(ops, diffOp{kind: diffEqual, fromIdx: i, toIdx: i})
+	}
+
+	for _, op := range myers(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]) {
+		op.fromIdx += prefix
+		op.toIdx += prefix
+		ops = append(ops, op)
+	}
+
+	for i := 0; i < suffix; i++ {
+		ops = append(ops, diffOp{
+			kind:    diffEqual,
+			fromIdx: len(a) - suffix + i,
+			toIdx:   len(b) - suffix + i,
+		})
+	}
+
+	return ops
+}
+
+// myers returns the shortest edit script between a and b
+func myers(a, b []string) []diffOp {
+	n, m := len(a), len(b)
+	if n == 0 && m == 0 {
+		return nil
+	}
+
+	maxD := n + m
+	offset := maxD
+	v := make([]int, 2*maxD+2)
+	var trace [][]int
+
+search:
+	for d := 0; d <= maxD; d++ {
+		snapshot := make([]int, len(v))
+		copy(snapshot, v)
+		trace = append(trace, snapshot)
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				break search
+			}
+		}
+	}
+
+	// Walk the trace backwards to recover the path
+	var ops []diffOp
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		vd := trace[d]
+		k := x - y
+
+		var prevK int
+		if k == -d || (k != d && vd[offset+k-1] < vd[offset+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := vd[offset+prevK]
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			x--
+			y--
+			ops = append(ops, diffOp{kind: diffEqual, fromIdx: x, toIdx: y})
+		}
+
+		if d > 0 {
+			if x == prevX {
+				y--
+				ops = append(ops, diffOp{kind: diffInsert, fromIdx: x, toIdx: y})
+			} else {
+				x--
+				ops = append(ops, diffOp{kind: diffDelete, fromIdx: x, toIdx: y})
+			}
+		}
+	}
+
+	// Reverse into forward order
+	for i, j := 0, len(ops)-1; i < j; i, j = i+1, j-1 {
+		ops[i], ops[j] = ops[j], ops[i]
+	}
+	return ops
+}
@@ -0,0 +1,105 @@
+package utilities
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		from     string
+		to       string
+		context  int
+		expected string
+	}{
+		{
+			name:     "Identical texts",
+			from:     "a\nb\n",
+			to:       "a\nb\n",
+			context:  3,
+			expected: "",
+		},
+		{
+			name:    "Single line change",
+			from:    "a\nb\nc\n",
+			to:      "a\nB\nc\n",
+			context: 3,
+			expected: "--- old\n+++ new\n" +
+				"@@ -1,3 +1,3 @@\n" +
+				" a\n-b\n+B\n c\n",
+		},
+		{
+			name:    "Insertion without context",
+			from:    "a\nc\n",
+			to:      "a\nb\nc\n",
+			context: 0,
+			expected: "--- old\n+++ new\n" +
+				"@@ -1,0 +2 @@\n" +
+				"+b\n",
+		},
+		{
+			name:    "Deletion at start",
+			from:    "a\nb\nc\n",
+			to:      "b\nc\n",
+			context: 1,
+			expected: "--- old\n+++ new\n" +
+				"@@ -1,2 +1 @@\n" +
+				"-a\n b\n",
+		},
+		{
+			name:    "Missing trailing newline",
+			from:    "a\nb",
+			to:      "a\nb\n",
+			context: 0,
+			expected: "--- old\n+++ new\n" +
+				"@@ -2 +2 @@\n" +
+				"-b\n\\ No newline at end of file\n+b\n",
+		},
+		{
+			name:    "New file",
+			from:    "",
+			to:      "x\ny\n",
+			context: 3,
+			expected: "--- old\n+++ new\n" +
+				"@@ -0,0 +1,2 @@\n" +
+				"+x\n+y\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := UnifiedDiff("old", "new", tt.from, tt.to, tt.context)
+			if got != tt.expected {
+				t.Errorf("UnifiedDiff() mismatch\ngot:\n%s\nwant:\n%s", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestUnifiedDiff_SeparateHunks(t *testing.T) {
+	var from, to strings.Builder
+	for i := 0; i < 20; i++ {
+		line := string(rune('a'+i)) + "\n"
+		from.WriteString(line)
+		switch i {
+		case 2:
+			to.WriteString("changed-c\n")
+		case 17:
+			to.WriteString("changed-r\n")
+		default:
+			to.WriteString(line)
+		}
+	}
+
+	diff := UnifiedDiff("old", "new", from.String(), to.String(), 2)
+	if got := strings.Count(diff, "@@ -"); got != 2 {
+		t.Fatalf("expected 2 hunks, got %d:\n%s", got, diff)
+	}
+	if !strings.Contains(diff, "@@ -1,5 +1,5 @@") {
+		t.Errorf("first hunk header missing:\n%s", diff)
+	}
+	if !strings.Contains(diff, "@@ -16,5 +16,5 @@") {
+		t.Errorf("second hunk header missing:\n%s", diff)
+	}
+}
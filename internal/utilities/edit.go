@@ -7,7 +7,6 @@ import (
 	"sort"
 	"strings"
 
-	"github.com/davecgh/go-spew/spew"
 	"github.com/isaacphi/mcp-language-server/internal/protocol"
 )
 
@@ -30,6 +29,20 @@ func ApplyTextEdits(uri protocol.DocumentUri, edits []protocol.TextEdit) error {
 		return fmt.Errorf("failed to read file: %w", err)
 	}
 
+	newContent, err := applyTextEditsToContent(content, edits)
+	if err != nil {
+		return err
+	}
+
+	if err := osWriteFile(path, newContent, 0644); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
+	return nil
+}
+
+// applyTextEditsToContent applies a sequence of text edits to file content in memory
+func applyTextEditsToContent(content []byte, edits []protocol.TextEdit) ([]byte, error) {
 	// Detect line ending style
 	var lineEnding string
 	if bytes.Contains(content, []byte("\r\n")) {
@@ -48,7 +61,7 @@ func ApplyTextEdits(uri protocol.DocumentUri, edits []protocol.TextEdit) error {
 	for i, edit1 := range edits {
 		for j := i + 1; j < len(edits); j++ {
 			if RangesOverlap(edit1.Range, edits[j].Range) {
-				return fmt.Errorf("overlapping edits detected between edit %d and %d", i, j)
+				return nil, fmt.Errorf("overlapping edits detected between edit %d and %d", i, j)
 			}
 		}
 	}
@@ -67,7 +80,7 @@ func ApplyTextEdits(uri protocol.DocumentUri, edits []protocol.TextEdit) error {
 	for _, edit := range sortedEdits {
 		newLines, err := ApplyTextEdit(lines, edit, lineEnding)
 		if err != nil {
-			return fmt.Errorf("failed to apply edit: %w", err)
+			return nil, fmt.Errorf("failed to apply edit: %w", err)
 		}
 		lines = newLines
 	}
@@ -86,11 +99,7 @@ func ApplyTextEdits(uri protocol.DocumentUri, edits []protocol.TextEdit) error {
 		newContent.WriteString(lineEnding)
 	}
 
-	if err := osWriteFile(path, []byte(newContent.String()), 0644); err != nil {
-		return fmt.Errorf("failed to write file: %w", err)
-	}
-
-	return nil
+	return []byte(newContent.String()), nil
 }
 
 // ApplyTextEdit applies a single text edit to a set of lines
@@ -231,24 +240,11 @@ func ApplyDocumentChange(change protocol.DocumentChange) error {
 	return nil
 }
 
-// ApplyWorkspaceEdit applies the given WorkspaceEdit to the filesystem
+// ApplyWorkspaceEdit applies the given WorkspaceEdit to the filesystem.
+// The edit is applied as a transaction, see ApplyWorkspaceEditTransaction.
 func ApplyWorkspaceEdit(edit protocol.WorkspaceEdit) error {
-	// Handle Changes field
-	for uri, textEdits := range edit.Changes {
-		if err := ApplyTextEdits(uri, textEdits); err != nil {
-			return fmt.Errorf("failed to apply text edits: %w", err)
-		}
-	}
-
-	// Handle DocumentChanges field
-	for _, change := range edit.DocumentChanges {
-		coreLogger.Warn("Document change: %v", spew.Sdump(change))
-		if err := ApplyDocumentChange(change); err != nil {
-			return fmt.Errorf("failed to apply document change: %w", err)
-		}
-	}
-
-	return nil
+	_, err := ApplyWorkspaceEditTransaction(edit)
+	return err
 }
 
 // RangesOverlap checks if two ranges overlap in position
@@ -2,6 +2,8 @@ package utilities
 
 import (
 	"errors"
+	"fmt"
+	"io/fs"
 	"os"
 	"reflect"
 	"strings"
@@ -27,6 +29,9 @@ func setupMockFileSystem(_ *testing.T, mfs *mockFileSystem) func() {
 	originalRemove := osRemove
 	originalRemoveAll := osRemoveAll
 	originalRename := osRename
+	originalMkdirAll := osMkdirAll
+	originalWalkDir := filepathWalkDir
+	originalCheckWritable := checkWritable
 
 	// Replace with mocks
 	osReadFile = func(filename string) ([]byte, error) {
@@ -96,6 +101,24 @@ func setupMockFileSystem(_ *testing.T, mfs *mockFileSystem) func() {
 		return os.ErrNotExist
 	}
 
+	osMkdirAll = func(path string, perm os.FileMode) error {
+		if err, ok := mfs.errors[path+"_mkdir"]; ok {
+			return err
+		}
+		return nil
+	}
+
+	filepathWalkDir = func(root string, fn fs.WalkDirFunc) error {
+		return fmt.Errorf("directory walk not supported by mock file system: %s", root)
+	}
+
+	checkWritable = func(path string) error {
+		if err, ok := mfs.errors[path+"_writable"]; ok {
+			return err
+		}
+		return nil
+	}
+
 	// Return cleanup function
 	return func() {
 		osReadFile = originalReadFile
@@ -104,6 +127,9 @@ func setupMockFileSystem(_ *testing.T, mfs *mockFileSystem) func() {
 		osRemove = originalRemove
 		osRemoveAll = originalRemoveAll
 		osRename = originalRename
+		osMkdirAll = originalMkdirAll
+		filepathWalkDir = originalWalkDir
+		checkWritable = originalCheckWritable
 	}
 }
 
@@ -0,0 +1,129 @@
+package utilities
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Glob is a compiled glob pattern for slash-separated paths.
+//
+// Supported syntax:
+//   - `*` matches any run of characters except `/`
+//   - `?` matches a single character except `/`
+//   - `**` matches any number of directories, e.g. `src/**/*.ts`
+//   - `[abc]`, `[a-z]` and `[!abc]` match character classes
+//   - `{a,b}` matches any of the alternatives
+//
+// A pattern without a `/` is matched against the base name, so `*.go` matches
+// Go files in any directory. A leading `./` anchors the pattern to the root.
+type Glob struct {
+	pattern  string
+	re       *regexp.Regexp
+	baseName bool
+}
+
+// CompileGlob parses a glob pattern
+func CompileGlob(pattern string) (*Glob, error) {
+	if pattern == "" {
+		return nil, fmt.Errorf("empty glob pattern")
+	}
+	expr, err := globToRegexp(strings.TrimPrefix(pattern, "./"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid glob pattern %q: %w", pattern, err)
+	}
+	re, err := regexp.Compile("^" + expr + "$")
+	if err != nil {
+		return nil, fmt.Errorf("invalid glob pattern %q: %w", pattern, err)
+	}
+	return &Glob{
+		pattern:  pattern,
+		re:       re,
+		baseName: !strings.Contains(pattern, "/"),
+	}, nil
+}
+
+// String returns the original pattern
+func (g *Glob) String() string {
+	return g.pattern
+}
+
+// Match reports whether the slash-separated path matches the pattern
+func (g *Glob) Match(p string) bool {
+	p = strings.TrimPrefix(p, "./")
+	if g.re.MatchString(p) {
+		return true
+	}
+	return g.baseName && g.re.MatchString(path.Base(p))
+}
+
+// globToRegexp translates a glob pattern into a regular expression
+func globToRegexp(pattern string) (string, error) {
+	var out strings.Builder
+	braceDepth := 0
+
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch c {
+		case '*':
+			if i+1 < len(pattern) && pattern[i+1] == '*' {
+				atStart := i == 0 || pattern[i-1] == '/'
+				i++
+				switch {
+				case atStart && i+1 < len(pattern) && pattern[i+1] == '/':
+					// "**/" matches zero or more directories
+					i++
+					out.WriteString("(?:.*/)?")
+				default:
+					out.WriteString(".*")
+				}
+				continue
+			}
+			out.WriteString("[^/]*")
+		case '?':
+			out.WriteString("[^/]")
+		case '[':
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end < 0 {
+				return "", fmt.Errorf("unterminated character class")
+			}
+			class := pattern[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			out.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end + 1
+		case '{':
+			braceDepth++
+			out.WriteString("(?:")
+		case '}':
+			if braceDepth == 0 {
+				out.WriteString(regexp.QuoteMeta("}"))
+				continue
+			}
+			braceDepth--
+			out.WriteString(")")
+		case ',':
+			if braceDepth > 0 {
+				out.WriteString("|")
+				continue
+			}
+			out.WriteString(",")
+		case '\\':
+			if i+1 < len(pattern) {
+				i++
+				out.WriteString(regexp.QuoteMeta(string(pattern[i])))
+				continue
+			}
+			out.WriteString(regexp.QuoteMeta(`\`))
+		default:
+			out.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	if braceDepth != 0 {
+		return "", fmt.Errorf("unterminated alternative")
+	}
+	return out.String(), nil
+}
@@ -0,0 +1,55 @@
+package utilities
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGlobMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{"*.go", "main.go", true},
+		{"*.go", "internal/tools/hover.go", true},
+		{"*.go", "main.go.orig", false},
+		{"src/*.ts", "src/index.ts", true},
+		{"src/*.ts", "src/lib/index.ts", false},
+		{"src/**/*.ts", "src/index.ts", true},
+		{"src/**/*.ts", "src/lib/deep/index.ts", true},
+		{"src/**/*.ts", "test/index.ts", false},
+		{"**/*_test.go", "internal/tools/hover_test.go", true},
+		{"**/*_test.go", "hover_test.go", true},
+		{"internal/**", "internal/tools/hover.go", true},
+		{"internal/**", "cmd/main.go", false},
+		{"*.{ts,tsx}", "app/view.tsx", true},
+		{"*.{ts,tsx}", "app/view.js", false},
+		{"file?.py", "file1.py", true},
+		{"file?.py", "file10.py", false},
+		{"[abc].rs", "b.rs", true},
+		{"[!abc].rs", "b.rs", false},
+		{"[!abc].rs", "d.rs", true},
+		{"./main.go", "main.go", true},
+		{"./main.go", "cmd/main.go", false},
+		{"main.go", "./main.go", true},
+		{"a+b.txt", "a+b.txt", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.path, func(t *testing.T) {
+			g, err := CompileGlob(tt.pattern)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, g.Match(tt.path))
+		})
+	}
+}
+
+func TestCompileGlob_Invalid(t *testing.T) {
+	for _, pattern := range []string{"", "[abc", "{a,b"} {
+		_, err := CompileGlob(pattern)
+		assert.Error(t, err, pattern)
+	}
+}
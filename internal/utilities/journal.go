@@ -0,0 +1,436 @@
+package utilities
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+const (
+	// DefaultJournalLimit is the number of edits kept for undo
+	DefaultJournalLimit = 100
+	// ServerEditSource is the source of edits the language server applies
+	// through workspace/applyEdit
+	ServerEditSource = "workspace/applyEdit"
+)
+
+// JournalEntry is one applied workspace edit. It stores the state of every
+// affected file before the edit, which is the edit's inverse, and after it,
+// which is used to detect conflicting changes and to redo the edit.
+type JournalEntry struct {
+	ID        int
+	Timestamp time.Time
+	// Source is the tool or request that applied the edit, e.g. "rename_symbol"
+	Source string
+	// Label is an optional description, such as the label of a workspace/applyEdit request
+	Label string
+	// Paths lists the affected files in the order they were touched
+	Paths []string
+
+	before []*fileSnapshot
+	after  []*fileSnapshot
+}
+
+// Describe returns a one-line summary of the entry
+func (e *JournalEntry) Describe() string {
+	desc := fmt.Sprintf("#%d %s", e.ID, e.Source)
+	if e.Label != "" {
+		desc += fmt.Sprintf(" (%s)", e.Label)
+	}
+	return fmt.Sprintf("%s at %s, %d files", desc, e.Timestamp.Format(time.RFC3339), len(e.Paths))
+}
+
+// Diffs describes the changes the entry made, in the order its files were
+// touched. Paths inside baseDir are shown relative to it in the diff headers.
+func (e *JournalEntry) Diffs(baseDir string, contextLines int) []FileDiff {
+	return snapshotDiffs(e.before, e.after, baseDir, contextLines)
+}
+
+// Content returns the content a file had after the edit, and false if the
+// entry didn't touch the path or the edit left no file there
+func (e *JournalEntry) Content(path string) ([]byte, bool) {
+	for _, snap := range e.after {
+		if snap.path == path && snap.existed && !snap.isDir {
+			return snap.content, true
+		}
+	}
+	return nil, false
+}
+
+// UndoDiffs describes the changes undoing the entry makes
+func (e *JournalEntry) UndoDiffs(baseDir string, contextLines int) []FileDiff {
+	return snapshotDiffs(e.after, e.before, baseDir, contextLines)
+}
+
+// snapshotDiffs compares the states of the same paths before and after a
+// change. A file deleted and created with the same content is reported as a
+// rename.
+func snapshotDiffs(from, to []*fileSnapshot, baseDir string, contextLines int) []FileDiff {
+	renamedFrom := make(map[string]string)
+	renameSources := make(map[string]bool)
+	for i, created := range to {
+		if from[i].existed || !created.existed || created.isDir {
+			continue
+		}
+		for j, deleted := range from {
+			if deleted.existed && !deleted.isDir && !to[j].existed && !renameSources[deleted.path] &&
+				bytes.Equal(deleted.content, created.content) {
+				renamedFrom[created.path] = deleted.path
+				renameSources[deleted.path] = true
+				break
+			}
+		}
+	}
+
+	var diffs []FileDiff
+	for i, before := range from {
+		after := to[i]
+		if renameSources[before.path] || before.isDir || after.isDir || snapshotsEqual(before, after) {
+			continue
+		}
+
+		diff := FileDiff{Path: before.path}
+		fromName, toName := displayPath(baseDir, before.path), displayPath(baseDir, before.path)
+		switch source, renamed := renamedFrom[before.path]; {
+		case renamed:
+			// Pure renames have no content change
+			diff.OldPath = source
+			diffs = append(diffs, diff)
+			continue
+		case !before.existed:
+			diff.Created = true
+			fromName = DevNull
+		case !after.existed:
+			diff.Deleted = true
+			toName = DevNull
+		}
+		diff.Diff = UnifiedDiff(fromName, toName, string(before.content), string(after.content), contextLines)
+		diffs = append(diffs, diff)
+	}
+	return diffs
+}
+
+// EditConflictError is returned when files changed after a journaled edit,
+// so undoing or redoing it would discard those changes
+type EditConflictError struct {
+	Action string
+	Entry  *JournalEntry
+	Paths  []string
+}
+
+func (e *EditConflictError) Error() string {
+	return fmt.Sprintf("refusing to %s edit %s: files changed since the edit: %s",
+		e.Action, e.Entry.Describe(), strings.Join(e.Paths, ", "))
+}
+
+// EditJournal is an undo/redo history of applied workspace edits
+type EditJournal struct {
+	mu     sync.Mutex
+	limit  int
+	nextID int
+	// applied holds edits that can be undone, oldest first
+	applied []*JournalEntry
+	// undone holds edits that can be redone, most recently undone last
+	undone []*JournalEntry
+}
+
+// NewEditJournal creates a journal that keeps at most limit edits
+func NewEditJournal(limit int) *EditJournal {
+	return &EditJournal{limit: limit, nextID: 1}
+}
+
+// DefaultJournal records edits applied by tools and by the language server
+var DefaultJournal = NewEditJournal(DefaultJournalLimit)
+
+// editMu serializes journaled edits, undos and redos, so the state an entry
+// records after its edit is the state the edit left. It must not be held
+// across language server requests, since the server's workspace/applyEdit
+// requests take it too.
+var editMu sync.Mutex
+
+// ApplyJournaledWorkspaceEdit applies edit as a transaction and records it in
+// DefaultJournal so it can be undone
+func ApplyJournaledWorkspaceEdit(source, label string, edit protocol.WorkspaceEdit) (*WorkspaceEditResult, error) {
+	return DefaultJournal.Apply(source, label, edit)
+}
+
+// Apply applies edit as a transaction and records it in the journal. The
+// entry is returned in the result's Entry, nil if nothing changed. Recording a
+// new edit clears the redo history.
+func (j *EditJournal) Apply(source, label string, edit protocol.WorkspaceEdit) (*WorkspaceEditResult, error) {
+	editMu.Lock()
+	defer editMu.Unlock()
+
+	result, err := ApplyWorkspaceEditTransaction(edit)
+	if err != nil {
+		return result, err
+	}
+	if result.Entry, err = j.record(source, label, result); err != nil {
+		coreLogger.Warn("Failed to record %s edit in journal: %v", source, err)
+	}
+	return result, nil
+}
+
+// Amend applies edit as a transaction and adds it to entry, so one undo
+// reverts both, e.g. for formatting that follows an edit. If entry is nil, no
+// longer the most recent edit, or its files changed since it was recorded,
+// the edit is recorded as a new entry instead. The result's Entry is the
+// entry the edit ended up in.
+func (j *EditJournal) Amend(entry *JournalEntry, edit protocol.WorkspaceEdit) (*WorkspaceEditResult, error) {
+	editMu.Lock()
+	defer editMu.Unlock()
+
+	result, err := ApplyWorkspaceEditTransaction(edit)
+	if err != nil || len(result.before) == 0 {
+		return result, err
+	}
+	if j.extend(entry, result) {
+		result.Entry = entry
+		return result, nil
+	}
+
+	source, label := "", ""
+	if entry != nil {
+		source, label = entry.Source, entry.Label
+	}
+	if result.Entry, err = j.record(source, label, result); err != nil {
+		coreLogger.Warn("Failed to record %s edit in journal: %v", source, err)
+	}
+	return result, nil
+}
+
+// extend adds a committed edit to entry if entry is the most recent edit and
+// the edit started from the state entry left. The caller holds editMu.
+func (j *EditJournal) extend(entry *JournalEntry, result *WorkspaceEditResult) bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if entry == nil || len(j.applied) == 0 || j.applied[len(j.applied)-1] != entry {
+		return false
+	}
+	after := make(map[string]*fileSnapshot, len(entry.after))
+	for _, snap := range entry.after {
+		after[snap.path] = snap
+	}
+	for _, snap := range result.before {
+		if prev, ok := after[snap.path]; ok && !snapshotsEqual(prev, snap) {
+			return false
+		}
+	}
+
+	before := append([]*fileSnapshot{}, entry.before...)
+	for _, snap := range result.before {
+		if _, ok := after[snap.path]; !ok {
+			before = append(before, snap)
+		}
+	}
+	var snapshots []*fileSnapshot
+	for _, snap := range before {
+		current, err := takeSnapshot(snap.path)
+		if err != nil {
+			coreLogger.Warn("Failed to snapshot %s: %v", snap.path, err)
+			return false
+		}
+		snapshots = append(snapshots, current)
+	}
+	for _, snap := range before[len(entry.before):] {
+		entry.Paths = append(entry.Paths, snap.path)
+	}
+	entry.before, entry.after = before, snapshots
+	return true
+}
+
+// record adds a committed edit to the journal and returns its entry, taking
+// the state of its files after the edit. The caller holds editMu.
+func (j *EditJournal) record(source, label string, result *WorkspaceEditResult) (*JournalEntry, error) {
+	if result == nil || len(result.before) == 0 {
+		return nil, nil
+	}
+
+	entry := &JournalEntry{
+		Timestamp: time.Now(),
+		Source:    source,
+		Label:     label,
+		Paths:     result.Committed,
+		before:    result.before,
+	}
+	for _, snap := range result.before {
+		after, err := takeSnapshot(snap.path)
+		if err != nil {
+			return nil, err
+		}
+		entry.after = append(entry.after, after)
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	entry.ID = j.nextID
+	j.nextID++
+	j.applied = append(j.applied, entry)
+	if len(j.applied) > j.limit {
+		j.applied = j.applied[len(j.applied)-j.limit:]
+	}
+	j.undone = nil
+	return entry, nil
+}
+
+// LastID returns the ID of the most recently recorded edit, or 0 if none was
+// recorded. Pass it to Since to find the edits recorded after it.
+func (j *EditJournal) LastID() int {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.nextID - 1
+}
+
+// Since returns the edits recorded after the edit with the given ID that are
+// still applied, oldest first
+func (j *EditJournal) Since(id int) []*JournalEntry {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	var entries []*JournalEntry
+	for _, entry := range j.applied {
+		if entry.ID > id {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// Undo reverts the most recent edit. It refuses with an *EditConflictError if
+// any affected file changed after the edit was applied.
+func (j *EditJournal) Undo() (*JournalEntry, error) {
+	editMu.Lock()
+	defer editMu.Unlock()
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if len(j.applied) == 0 {
+		return nil, fmt.Errorf("no edits to undo")
+	}
+	entry := j.applied[len(j.applied)-1]
+	if err := switchStates("undo", entry, entry.after, entry.before); err != nil {
+		return nil, err
+	}
+
+	j.applied = j.applied[:len(j.applied)-1]
+	j.undone = append(j.undone, entry)
+	return entry, nil
+}
+
+// Redo reapplies the most recently undone edit. It refuses with an
+// *EditConflictError if any affected file changed after the undo.
+func (j *EditJournal) Redo() (*JournalEntry, error) {
+	editMu.Lock()
+	defer editMu.Unlock()
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if len(j.undone) == 0 {
+		return nil, fmt.Errorf("no edits to redo")
+	}
+	entry := j.undone[len(j.undone)-1]
+	if err := switchStates("redo", entry, entry.before, entry.after); err != nil {
+		return nil, err
+	}
+
+	j.undone = j.undone[:len(j.undone)-1]
+	j.applied = append(j.applied, entry)
+	return entry, nil
+}
+
+// History returns the edits that can be undone and redone, most recent first
+func (j *EditJournal) History() (undoable, redoable []*JournalEntry) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	for i := len(j.applied) - 1; i >= 0; i-- {
+		undoable = append(undoable, j.applied[i])
+	}
+	for i := len(j.undone) - 1; i >= 0; i-- {
+		redoable = append(redoable, j.undone[i])
+	}
+	return undoable, redoable
+}
+
+// switchStates moves the files of entry from the expected states to the target
+// states, after checking that the files still match the expected states
+func switchStates(action string, entry *JournalEntry, expected, target []*fileSnapshot) error {
+	var conflicts []string
+	for _, want := range expected {
+		current, err := takeSnapshot(want.path)
+		if err != nil {
+			return err
+		}
+		if !snapshotsEqual(current, want) {
+			conflicts = append(conflicts, want.path)
+		}
+	}
+	if len(conflicts) > 0 {
+		return &EditConflictError{Action: action, Entry: entry, Paths: conflicts}
+	}
+
+	if err := restoreStates(target); err != nil {
+		return fmt.Errorf("failed to %s edit %s: %w", action, entry.Describe(), err)
+	}
+	return nil
+}
+
+// restoreStates writes back the given states as a transaction, restoring the
+// current content of every file if any write fails
+func restoreStates(states []*fileSnapshot) error {
+	tx := newEditTransaction()
+	for _, state := range states {
+		if _, err := tx.snapshot(state.path); err != nil {
+			return err
+		}
+	}
+
+	result := &WorkspaceEditResult{FailedChange: -1}
+
+	// Remove paths first so renamed files don't collide with their restored names
+	for _, state := range states {
+		if state.existed {
+			continue
+		}
+		tx.touched[state.path] = true
+		if err := removePath(state.path); err != nil {
+			tx.rollback(result)
+			return fmt.Errorf("failed to remove %s: %w%s", state.path, err, rollbackSummary(result))
+		}
+	}
+	for _, state := range states {
+		if !state.existed {
+			continue
+		}
+		tx.touched[state.path] = true
+		if err := restoreSnapshot(state); err != nil {
+			tx.rollback(result)
+			return fmt.Errorf("failed to restore %s: %w%s", state.path, err, rollbackSummary(result))
+		}
+	}
+	return nil
+}
+
+// snapshotsEqual reports whether two snapshots describe the same file state
+func snapshotsEqual(a, b *fileSnapshot) bool {
+	if a.existed != b.existed || a.isDir != b.isDir {
+		return false
+	}
+	if !a.isDir {
+		return bytes.Equal(a.content, b.content)
+	}
+	if len(a.children) != len(b.children) {
+		return false
+	}
+	for i := range a.children {
+		if a.children[i].path != b.children[i].path || !snapshotsEqual(&a.children[i], &b.children[i]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,186 @@
+package utilities
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEditJournal(t *testing.T) {
+	setup := func(t *testing.T) (*mockFileSystem, *EditJournal) {
+		mfs := &mockFileSystem{files: map[string][]byte{
+			"/test/a.txt":   []byte("old a\n"),
+			"/test/old.txt": []byte("moved\n"),
+		}}
+		t.Cleanup(setupMockFileSystem(t, mfs))
+		return mfs, NewEditJournal(10)
+	}
+
+	apply := func(t *testing.T, journal *EditJournal, edit protocol.WorkspaceEdit) {
+		result, err := journal.Apply("test_tool", "label", edit)
+		require.NoError(t, err)
+		require.NotNil(t, result.Entry)
+	}
+
+	renameAndEdit := protocol.WorkspaceEdit{
+		DocumentChanges: []protocol.DocumentChange{
+			textDocumentEdit("file:///test/a.txt", replaceFirstLine("new")),
+			{RenameFile: &protocol.RenameFile{OldURI: "file:///test/old.txt", NewURI: "file:///test/new.txt"}},
+		},
+	}
+
+	t.Run("undo and redo", func(t *testing.T) {
+		mfs, journal := setup(t)
+		apply(t, journal, renameAndEdit)
+
+		entry, err := journal.Undo()
+		require.NoError(t, err)
+		assert.Equal(t, 1, entry.ID)
+		assert.Equal(t, "test_tool", entry.Source)
+		assert.Equal(t, map[string][]byte{
+			"/test/a.txt":   []byte("old a\n"),
+			"/test/old.txt": []byte("moved\n"),
+		}, mfs.files)
+
+		_, err = journal.Undo()
+		assert.EqualError(t, err, "no edits to undo")
+
+		_, err = journal.Redo()
+		require.NoError(t, err)
+		assert.Equal(t, map[string][]byte{
+			"/test/a.txt":   []byte("new a\n"),
+			"/test/new.txt": []byte("moved\n"),
+		}, mfs.files)
+
+		undoable, redoable := journal.History()
+		assert.Len(t, undoable, 1)
+		assert.Empty(t, redoable)
+	})
+
+	t.Run("undo refuses when a file changed", func(t *testing.T) {
+		mfs, journal := setup(t)
+		apply(t, journal, renameAndEdit)
+		mfs.files["/test/a.txt"] = []byte("edited by hand\n")
+
+		_, err := journal.Undo()
+		var conflict *EditConflictError
+		require.True(t, errors.As(err, &conflict))
+		assert.Equal(t, []string{"/test/a.txt"}, conflict.Paths)
+		assert.Equal(t, "edited by hand\n", string(mfs.files["/test/a.txt"]))
+		assert.Equal(t, "moved\n", string(mfs.files["/test/new.txt"]))
+	})
+
+	t.Run("new edit clears redo history", func(t *testing.T) {
+		_, journal := setup(t)
+		apply(t, journal, renameAndEdit)
+		_, err := journal.Undo()
+		require.NoError(t, err)
+
+		apply(t, journal, protocol.WorkspaceEdit{
+			Changes: map[protocol.DocumentUri][]protocol.TextEdit{
+				"file:///test/a.txt": replaceFirstLine("NEW"),
+			},
+		})
+		_, err = journal.Redo()
+		assert.EqualError(t, err, "no edits to redo")
+	})
+
+	t.Run("failed undo restores current content", func(t *testing.T) {
+		mfs, journal := setup(t)
+		apply(t, journal, renameAndEdit)
+		mfs.errors = map[string]error{"/test/old.txt_write": errors.New("disk full")}
+
+		_, err := journal.Undo()
+		require.Error(t, err)
+		assert.Equal(t, map[string][]byte{
+			"/test/a.txt":   []byte("new a\n"),
+			"/test/new.txt": []byte("moved\n"),
+		}, mfs.files)
+
+		// The entry stays on the undo stack
+		undoable, _ := journal.History()
+		assert.Len(t, undoable, 1)
+	})
+
+	t.Run("diffs and edits since an ID", func(t *testing.T) {
+		_, journal := setup(t)
+		assert.Equal(t, 0, journal.LastID())
+		apply(t, journal, renameAndEdit)
+		since := journal.LastID()
+		apply(t, journal, protocol.WorkspaceEdit{
+			Changes: map[protocol.DocumentUri][]protocol.TextEdit{
+				"file:///test/a.txt": replaceFirstLine("NEW"),
+			},
+		})
+
+		entries := journal.Since(since)
+		require.Len(t, entries, 1)
+		assert.Equal(t, []FileDiff{{
+			Path: "/test/a.txt",
+			Diff: "--- a.txt\n+++ a.txt\n@@ -1 +1 @@\n-new a\n+NEW a\n",
+		}}, entries[0].Diffs("/test", DefaultDiffContext))
+
+		_, err := journal.Undo()
+		require.NoError(t, err)
+		assert.Empty(t, journal.Since(since))
+
+		undoable, _ := journal.History()
+		assert.Equal(t, []FileDiff{
+			{Path: "/test/a.txt", Diff: "--- a.txt\n+++ a.txt\n@@ -1 +1 @@\n-old a\n+new a\n"},
+			{Path: "/test/new.txt", OldPath: "/test/old.txt"},
+		}, undoable[0].Diffs("/test", DefaultDiffContext))
+		assert.Equal(t, []FileDiff{
+			{Path: "/test/a.txt", Diff: "--- a.txt\n+++ a.txt\n@@ -1 +1 @@\n-new a\n+old a\n"},
+			{Path: "/test/old.txt", OldPath: "/test/new.txt"},
+		}, undoable[0].UndoDiffs("/test", DefaultDiffContext))
+	})
+
+	t.Run("amend adds an edit to the last entry", func(t *testing.T) {
+		mfs, journal := setup(t)
+		result, err := journal.Apply("test_tool", "label", protocol.WorkspaceEdit{
+			Changes: map[protocol.DocumentUri][]protocol.TextEdit{"file:///test/a.txt": replaceFirstLine("new")},
+		})
+		require.NoError(t, err)
+		amended, err := journal.Amend(result.Entry, protocol.WorkspaceEdit{
+			Changes: map[protocol.DocumentUri][]protocol.TextEdit{
+				"file:///test/a.txt":   replaceFirstLine("NEW"),
+				"file:///test/old.txt": replaceFirstLine("MOVED"),
+			},
+		})
+		require.NoError(t, err)
+		assert.Same(t, result.Entry, amended.Entry)
+		assert.Equal(t, []string{"/test/a.txt", "/test/old.txt"}, result.Entry.Paths)
+		content, ok := result.Entry.Content("/test/a.txt")
+		assert.True(t, ok)
+		assert.Equal(t, "NEW a\n", string(content))
+
+		_, err = journal.Undo()
+		require.NoError(t, err)
+		assert.Equal(t, map[string][]byte{
+			"/test/a.txt":   []byte("old a\n"),
+			"/test/old.txt": []byte("moved\n"),
+		}, mfs.files)
+	})
+
+	t.Run("amend records a new entry after another edit", func(t *testing.T) {
+		_, journal := setup(t)
+		first, err := journal.Apply("test_tool", "label", protocol.WorkspaceEdit{
+			Changes: map[protocol.DocumentUri][]protocol.TextEdit{"file:///test/a.txt": replaceFirstLine("new")},
+		})
+		require.NoError(t, err)
+		apply(t, journal, protocol.WorkspaceEdit{
+			Changes: map[protocol.DocumentUri][]protocol.TextEdit{"file:///test/old.txt": replaceFirstLine("MOVED")},
+		})
+
+		amended, err := journal.Amend(first.Entry, protocol.WorkspaceEdit{
+			Changes: map[protocol.DocumentUri][]protocol.TextEdit{"file:///test/a.txt": replaceFirstLine("NEW")},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 3, amended.Entry.ID)
+		assert.Equal(t, "test_tool", amended.Entry.Source)
+		assert.Equal(t, []string{"/test/a.txt"}, first.Entry.Paths)
+	})
+}
@@ -0,0 +1,481 @@
+package utilities
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+// DevNull is the path used by unified diffs for the missing side of a new or deleted file
+const DevNull = "/dev/null"
+
+// DefaultPatchFuzz is the number of leading and trailing context lines that may
+// be ignored when a hunk does not match exactly, mirroring patch(1)
+const DefaultPatchFuzz = 2
+
+var hunkHeaderRegex = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@`)
+
+// PatchHunk is a single "@@" section of a unified diff. Lines keep their
+// leading ' ', '-' or '+' marker.
+type PatchHunk struct {
+	OldStart int
+	OldLines int
+	NewStart int
+	NewLines int
+	Lines    []string
+	Header   string
+
+	// NewNoNewline is set when the new side ends without a trailing newline
+	NewNoNewline bool
+}
+
+// FilePatch holds the hunks for one file in a unified diff
+type FilePatch struct {
+	OldPath string
+	NewPath string
+	Hunks   []PatchHunk
+}
+
+// IsNew reports whether the patch creates a file
+func (f FilePatch) IsNew() bool {
+	return f.OldPath == DevNull
+}
+
+// IsDelete reports whether the patch deletes a file
+func (f FilePatch) IsDelete() bool {
+	return f.NewPath == DevNull
+}
+
+// IsRename reports whether the patch moves a file to a new path
+func (f FilePatch) IsRename() bool {
+	return !f.IsNew() && !f.IsDelete() && f.OldPath != f.NewPath
+}
+
+// Path returns the path the patch applies to, the old path for deletions
+func (f FilePatch) Path() string {
+	if f.IsDelete() {
+		return f.OldPath
+	}
+	return f.NewPath
+}
+
+// oldSide returns the lines the hunk expects to find in the file
+func (h PatchHunk) oldSide() []string {
+	var lines []string
+	for _, line := range h.Lines {
+		if line[0] != '+' {
+			lines = append(lines, line[1:])
+		}
+	}
+	return lines
+}
+
+// replacementAt returns the lines the hunk leaves in the file when applied at
+// start. Context lines are taken from the file so that whitespace-insensitive
+// matches don't rewrite them.
+func (h PatchHunk) replacementAt(fileLines []string, start int) []string {
+	var lines []string
+	offset := start
+	for _, line := range h.Lines {
+		switch line[0] {
+		case ' ':
+			lines = append(lines, fileLines[offset])
+			offset++
+		case '-':
+			offset++
+		case '+':
+			lines = append(lines, line[1:])
+		}
+	}
+	return lines
+}
+
+// ParsePatch parses a unified diff that may span several files. Both plain
+// "---/+++" diffs and git-style diffs (including pure renames, new and
+// deleted files) are understood. The "a/" and "b/" prefixes git adds are stripped.
+func ParsePatch(text string) ([]FilePatch, error) {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+
+	var patches []FilePatch
+	var current *FilePatch
+	sawHeader := false
+
+	flush := func() {
+		if current != nil && (current.OldPath != "" || current.NewPath != "") {
+			patches = append(patches, *current)
+		}
+		current = nil
+		sawHeader = false
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			flush()
+			current = &FilePatch{}
+			if oldPath, newPath, ok := parseGitDiffPaths(strings.TrimPrefix(line, "diff --git ")); ok {
+				current.OldPath, current.NewPath = oldPath, newPath
+			}
+
+		case strings.HasPrefix(line, "new file mode") && current != nil:
+			current.OldPath = DevNull
+
+		case strings.HasPrefix(line, "deleted file mode") && current != nil:
+			current.NewPath = DevNull
+
+		case strings.HasPrefix(line, "rename from ") && current != nil:
+			current.OldPath = strings.TrimPrefix(line, "rename from ")
+
+		case strings.HasPrefix(line, "rename to ") && current != nil:
+			current.NewPath = strings.TrimPrefix(line, "rename to ")
+
+		case strings.HasPrefix(line, "--- ") && i+1 < len(lines) && strings.HasPrefix(lines[i+1], "+++ "):
+			// A second header or a header after hunks starts a new file
+			if current == nil || sawHeader || len(current.Hunks) > 0 {
+				flush()
+				current = &FilePatch{}
+			}
+			current.OldPath = parsePatchPath(strings.TrimPrefix(line, "--- "), "a/")
+			current.NewPath = parsePatchPath(strings.TrimPrefix(lines[i+1], "+++ "), "b/")
+			sawHeader = true
+			i++
+
+		case strings.HasPrefix(line, "@@ "):
+			if current == nil {
+				return nil, fmt.Errorf("line %d: hunk without a file header", i+1)
+			}
+			hunk, consumed, err := parseHunk(lines[i:])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", i+1, err)
+			}
+			current.Hunks = append(current.Hunks, hunk)
+			i += consumed - 1
+		}
+	}
+	flush()
+
+	return patches, nil
+}
+
+// parseHunk parses a hunk starting at its "@@" header and returns the number of lines consumed
+func parseHunk(lines []string) (PatchHunk, int, error) {
+	m := hunkHeaderRegex.FindStringSubmatch(lines[0])
+	if m == nil {
+		return PatchHunk{}, 0, fmt.Errorf("malformed hunk header: %q", lines[0])
+	}
+
+	hunk := PatchHunk{Header: lines[0]}
+	hunk.OldStart, _ = strconv.Atoi(m[1])
+	hunk.OldLines = 1
+	if m[2] != "" {
+		hunk.OldLines, _ = strconv.Atoi(m[2])
+	}
+	hunk.NewStart, _ = strconv.Atoi(m[3])
+	hunk.NewLines = 1
+	if m[4] != "" {
+		hunk.NewLines, _ = strconv.Atoi(m[4])
+	}
+
+	oldSeen, newSeen := 0, 0
+	i := 1
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		if oldSeen >= hunk.OldLines && newSeen >= hunk.NewLines {
+			// Only a "no newline" marker may follow a complete hunk
+			if strings.HasPrefix(line, `\`) {
+				hunk.markNoNewline()
+				continue
+			}
+			break
+		}
+
+		if line == "" {
+			// Some tools strip the space from empty context lines
+			line = " "
+		}
+		switch line[0] {
+		case ' ':
+			oldSeen++
+			newSeen++
+		case '-':
+			oldSeen++
+		case '+':
+			newSeen++
+		case '\\':
+			hunk.markNoNewline()
+			continue
+		default:
+			return PatchHunk{}, 0, fmt.Errorf("unexpected line in hunk %s: %q", hunk.Header, line)
+		}
+		hunk.Lines = append(hunk.Lines, line)
+	}
+
+	if oldSeen != hunk.OldLines || newSeen != hunk.NewLines {
+		return PatchHunk{}, 0, fmt.Errorf("hunk %s is truncated: expected %d old and %d new lines, found %d and %d",
+			hunk.Header, hunk.OldLines, hunk.NewLines, oldSeen, newSeen)
+	}
+
+	return hunk, i, nil
+}
+
+// markNoNewline records a "\ No newline at end of file" marker for the preceding line
+func (h *PatchHunk) markNoNewline() {
+	if len(h.Lines) > 0 && h.Lines[len(h.Lines)-1][0] != '-' {
+		h.NewNoNewline = true
+	}
+}
+
+// parsePatchPath extracts the file path from a "---" or "+++" header value
+func parsePatchPath(value, prefix string) string {
+	// Drop an optional timestamp separated by a tab
+	if idx := strings.Index(value, "\t"); idx >= 0 {
+		value = value[:idx]
+	}
+	value = strings.TrimSpace(value)
+	if value == DevNull {
+		return value
+	}
+	if unquoted, err := strconv.Unquote(value); err == nil {
+		value = unquoted
+	}
+	return strings.TrimPrefix(value, prefix)
+}
+
+// parseGitDiffPaths splits the "a/old b/new" part of a "diff --git" line
+func parseGitDiffPaths(value string) (string, string, bool) {
+	if !strings.HasPrefix(value, "a/") {
+		return "", "", false
+	}
+	idx := strings.Index(value, " b/")
+	if idx < 0 {
+		return "", "", false
+	}
+	return value[2:idx], value[idx+3:], true
+}
+
+// HunkResult describes where a hunk was applied, or why it was rejected
+type HunkResult struct {
+	// Index is the one-indexed position of the hunk in its file patch
+	Index  int
+	Header string
+	// Applied is false when no matching location was found
+	Applied bool
+	// Line is the one-indexed line in the original file where the hunk was applied
+	Line int
+	// Offset is the distance in lines from the position given in the hunk header
+	Offset int
+	// Fuzz is the number of context lines ignored at each end of the hunk
+	Fuzz int
+	// Reason explains a rejection
+	Reason string
+}
+
+// PlanPatchEdits locates each hunk in content and returns the text edits that
+// apply it. Hunks are first searched for exactly at the position from their
+// header, then at growing offsets, then ignoring trailing whitespace, and
+// finally with up to maxFuzz context lines dropped from each end. Hunks that
+// cannot be placed are reported as rejected and produce no edit.
+func PlanPatchEdits(content string, hunks []PatchHunk, maxFuzz int) ([]protocol.TextEdit, []HunkResult) {
+	lineEnding := "\n"
+	if strings.Contains(content, "\r\n") {
+		lineEnding = "\r\n"
+	}
+	lines := strings.Split(content, lineEnding)
+	endsWithNewline := content == "" || strings.HasSuffix(content, lineEnding)
+	// Lines that hunks can match, excluding the empty element after a trailing newline
+	fileLines := lines
+	if endsWithNewline {
+		fileLines = lines[:len(lines)-1]
+	}
+
+	var edits []protocol.TextEdit
+	results := make([]HunkResult, 0, len(hunks))
+	minLine := 0 // hunks must apply in order without overlapping
+	delta := 0   // offset of the previous hunk, applied to the next expected position
+
+	for i, hunk := range hunks {
+		result := HunkResult{Index: i + 1, Header: hunk.Header}
+
+		match, ok := locateHunk(fileLines, hunk, minLine, delta, maxFuzz)
+		if !ok {
+			result.Reason = "no matching context found"
+			results = append(results, result)
+			continue
+		}
+
+		result.Applied = true
+		result.Line = match.start + 1
+		result.Offset = match.start - match.expected
+		result.Fuzz = match.fuzz
+		results = append(results, result)
+
+		delta = result.Offset
+		minLine = match.start + len(match.old)
+		edits = append(edits, hunkTextEdit(lines, endsWithNewline, match.start, len(match.old), match.replacement, hunk.NewNoNewline))
+	}
+
+	return edits, results
+}
+
+// expectedHunkStart returns the zero-indexed line the hunk header points at
+func expectedHunkStart(hunk PatchHunk) int {
+	if hunk.OldLines == 0 {
+		// "-N,0" means insert after line N
+		return hunk.OldStart
+	}
+	return hunk.OldStart - 1
+}
+
+// hunkMatch is the location found for a hunk
+type hunkMatch struct {
+	start       int
+	expected    int
+	fuzz        int
+	old         []string
+	replacement []string
+}
+
+// locateHunk searches for the old side of a hunk, trimming context lines as
+// fuzz increases
+func locateHunk(fileLines []string, hunk PatchHunk, minLine, delta, maxFuzz int) (hunkMatch, bool) {
+	leading, trailing := contextRun(hunk.Lines), contextRun(reversed(hunk.Lines))
+
+	for fuzz := 0; fuzz <= maxFuzz; fuzz++ {
+		// Never drop lines that are not context, and stop once nothing more can be dropped
+		dropStart, dropEnd := min(fuzz, leading), min(fuzz, trailing)
+		if fuzz > 0 && dropStart < fuzz && dropEnd < fuzz {
+			break
+		}
+		if dropStart+dropEnd >= len(hunk.Lines) {
+			break
+		}
+		trimmed := hunk
+		trimmed.Lines = hunk.Lines[dropStart : len(hunk.Lines)-dropEnd]
+		old := trimmed.oldSide()
+
+		expected := expectedHunkStart(hunk) + dropStart
+		for _, equal := range []func(a, b string) bool{exactLineMatch, looseLineMatch} {
+			if start, ok := searchLines(fileLines, old, expected+delta, minLine, equal); ok {
+				return hunkMatch{
+					start:       start,
+					expected:    expected,
+					fuzz:        fuzz,
+					old:         old,
+					replacement: trimmed.replacementAt(fileLines, start),
+				}, true
+			}
+		}
+	}
+	return hunkMatch{}, false
+}
+
+// contextRun counts the context lines at the start of a hunk
+func contextRun(lines []string) int {
+	n := 0
+	for _, line := range lines {
+		if line[0] != ' ' {
+			break
+		}
+		n++
+	}
+	return n
+}
+
+func reversed(lines []string) []string {
+	out := make([]string, len(lines))
+	for i, line := range lines {
+		out[len(lines)-1-i] = line
+	}
+	return out
+}
+
+func exactLineMatch(a, b string) bool {
+	return a == b
+}
+
+func looseLineMatch(a, b string) bool {
+	return strings.TrimRight(a, " \t") == strings.TrimRight(b, " \t")
+}
+
+// searchLines finds want in lines, trying expected first and then moving
+// outwards one line at a time in both directions
+func searchLines(lines, want []string, expected, minLine int, equal func(a, b string) bool) (int, bool) {
+	last := len(lines) - len(want)
+	if last < minLine {
+		return 0, false
+	}
+	expected = max(minLine, min(expected, last))
+
+	matchesAt := func(start int) bool {
+		for j, line := range want {
+			if !equal(lines[start+j], line) {
+				return false
+			}
+		}
+		return true
+	}
+
+	for dist := 0; ; dist++ {
+		before, after := expected-dist, expected+dist
+		if before < minLine && after > last {
+			return 0, false
+		}
+		if before >= minLine && matchesAt(before) {
+			return before, true
+		}
+		if dist > 0 && after <= last && matchesAt(after) {
+			return after, true
+		}
+	}
+}
+
+// hunkTextEdit builds the edit that replaces count lines at start with replacement
+func hunkTextEdit(lines []string, endsWithNewline bool, start, count int, replacement []string, noNewline bool) protocol.TextEdit {
+	newText := strings.Join(replacement, "\n")
+	if len(replacement) > 0 {
+		newText += "\n"
+	}
+
+	end := start + count
+	if end < len(lines) && (endsWithNewline || end < len(lines)-1) {
+		if noNewline && end == len(lines)-1 {
+			newText = strings.TrimSuffix(newText, "\n")
+		}
+		return protocol.TextEdit{
+			Range: protocol.Range{
+				Start: protocol.Position{Line: uint32(start)},
+				End:   protocol.Position{Line: uint32(end)},
+			},
+			NewText: newText,
+		}
+	}
+
+	// The hunk reaches the last line of a file without a trailing newline,
+	// so the range ends at the end of that line instead of the next one
+	lastLine := len(lines) - 1
+	if count == 0 {
+		// Appending after the unterminated last line
+		return protocol.TextEdit{
+			Range: protocol.Range{
+				Start: protocol.Position{Line: uint32(lastLine), Character: uint32(len(lines[lastLine]))},
+				End:   protocol.Position{Line: uint32(lastLine), Character: uint32(len(lines[lastLine]))},
+			},
+			NewText: "\n" + strings.TrimSuffix(newText, "\n"),
+		}
+	}
+	if noNewline {
+		newText = strings.TrimSuffix(newText, "\n")
+	}
+	return protocol.TextEdit{
+		Range: protocol.Range{
+			Start: protocol.Position{Line: uint32(start)},
+			End:   protocol.Position{Line: uint32(lastLine), Character: uint32(len(lines[lastLine]))},
+		},
+		NewText: newText,
+	}
+}
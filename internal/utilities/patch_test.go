@@ -0,0 +1,172 @@
+package utilities
+
+import (
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// applyPatchToString applies planned edits through the file edit engine using the mock file system
+func applyPatchToString(t *testing.T, content string, hunks []PatchHunk, maxFuzz int) (string, []HunkResult) {
+	t.Helper()
+	mfs := &mockFileSystem{files: map[string][]byte{"/test/file.txt": []byte(content)}}
+	cleanup := setupMockFileSystem(t, mfs)
+	defer cleanup()
+
+	edits, results := PlanPatchEdits(content, hunks, maxFuzz)
+	require.NoError(t, ApplyTextEdits(protocol.DocumentUri("file:///test/file.txt"), edits))
+	return string(mfs.files["/test/file.txt"]), results
+}
+
+func TestParsePatch(t *testing.T) {
+	t.Run("git diff with several files", func(t *testing.T) {
+		patch := "diff --git a/main.go b/main.go\n" +
+			"index 1234567..89abcde 100644\n" +
+			"--- a/main.go\n" +
+			"+++ b/main.go\n" +
+			"@@ -1,3 +1,3 @@\n" +
+			" package main\n" +
+			"-var x = 1\n" +
+			"+var x = 2\n" +
+			" \n" +
+			"diff --git a/new.go b/new.go\n" +
+			"new file mode 100644\n" +
+			"--- /dev/null\n" +
+			"+++ b/new.go\n" +
+			"@@ -0,0 +1 @@\n" +
+			"+package main\n" +
+			"\\ No newline at end of file\n" +
+			"diff --git a/old.go b/old.go\n" +
+			"deleted file mode 100644\n" +
+			"--- a/old.go\n" +
+			"+++ /dev/null\n" +
+			"@@ -1 +0,0 @@\n" +
+			"-package main\n"
+
+		files, err := ParsePatch(patch)
+		require.NoError(t, err)
+		require.Len(t, files, 3)
+
+		assert.Equal(t, "main.go", files[0].Path())
+		require.Len(t, files[0].Hunks, 1)
+		assert.Equal(t, []string{" package main", "-var x = 1", "+var x = 2", " "}, files[0].Hunks[0].Lines)
+
+		assert.True(t, files[1].IsNew())
+		assert.Equal(t, "new.go", files[1].Path())
+		assert.True(t, files[1].Hunks[0].NewNoNewline)
+
+		assert.True(t, files[2].IsDelete())
+		assert.Equal(t, "old.go", files[2].Path())
+	})
+
+	t.Run("plain diff with timestamps", func(t *testing.T) {
+		patch := "--- a.txt\t2024-01-01 00:00:00\n" +
+			"+++ a.txt\t2024-01-02 00:00:00\n" +
+			"@@ -1 +1 @@\n" +
+			"-a\n" +
+			"+b\n"
+
+		files, err := ParsePatch(patch)
+		require.NoError(t, err)
+		require.Len(t, files, 1)
+		assert.Equal(t, "a.txt", files[0].OldPath)
+		assert.False(t, files[0].IsRename())
+	})
+
+	t.Run("pure rename without hunks", func(t *testing.T) {
+		patch := "diff --git a/old/name.go b/new/name.go\n" +
+			"similarity index 100%\n" +
+			"rename from old/name.go\n" +
+			"rename to new/name.go\n"
+
+		files, err := ParsePatch(patch)
+		require.NoError(t, err)
+		require.Len(t, files, 1)
+		assert.True(t, files[0].IsRename())
+		assert.Equal(t, "new/name.go", files[0].Path())
+		assert.Empty(t, files[0].Hunks)
+	})
+
+	t.Run("truncated hunk", func(t *testing.T) {
+		patch := "--- a.txt\n+++ a.txt\n@@ -1,3 +1,3 @@\n-a\n+b\n"
+		_, err := ParsePatch(patch)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "truncated")
+	})
+
+	t.Run("hunk without header", func(t *testing.T) {
+		_, err := ParsePatch("@@ -1 +1 @@\n-a\n+b\n")
+		assert.Error(t, err)
+	})
+}
+
+func TestPlanPatchEdits(t *testing.T) {
+	original := "one\ntwo\nthree\nfour\nfive\nsix\nseven\n"
+
+	parse := func(t *testing.T, body string) []PatchHunk {
+		files, err := ParsePatch("--- a/file.txt\n+++ b/file.txt\n" + body)
+		require.NoError(t, err)
+		require.Len(t, files, 1)
+		return files[0].Hunks
+	}
+
+	t.Run("exact match", func(t *testing.T) {
+		hunks := parse(t, "@@ -2,3 +2,3 @@\n two\n-three\n+THREE\n four\n")
+		got, results := applyPatchToString(t, original, hunks, 0)
+		assert.Equal(t, "one\ntwo\nTHREE\nfour\nfive\nsix\nseven\n", got)
+		assert.Equal(t, HunkResult{Index: 1, Header: "@@ -2,3 +2,3 @@", Applied: true, Line: 2}, results[0])
+	})
+
+	t.Run("offset match", func(t *testing.T) {
+		hunks := parse(t, "@@ -1,3 +1,3 @@\n five\n-six\n+SIX\n seven\n")
+		got, results := applyPatchToString(t, original, hunks, 0)
+		assert.Equal(t, "one\ntwo\nthree\nfour\nfive\nSIX\nseven\n", got)
+		assert.Equal(t, 5, results[0].Line)
+		assert.Equal(t, 4, results[0].Offset)
+	})
+
+	t.Run("fuzz drops stale context", func(t *testing.T) {
+		hunks := parse(t, "@@ -2,5 +2,5 @@\n stale\n three\n-four\n+FOUR\n five\n stale\n")
+		got, results := applyPatchToString(t, original, hunks, 1)
+		assert.Equal(t, "one\ntwo\nthree\nFOUR\nfive\nsix\nseven\n", got)
+		assert.Equal(t, 1, results[0].Fuzz)
+		assert.Equal(t, 0, results[0].Offset)
+	})
+
+	t.Run("rejected hunk leaves others applied", func(t *testing.T) {
+		hunks := parse(t, "@@ -1,2 +1,2 @@\n-one\n+ONE\n two\n@@ -5,2 +5,2 @@\n-missing\n+x\n six\n")
+		got, results := applyPatchToString(t, original, hunks, DefaultPatchFuzz)
+		assert.Equal(t, "ONE\ntwo\nthree\nfour\nfive\nsix\nseven\n", got)
+		require.Len(t, results, 2)
+		assert.True(t, results[0].Applied)
+		assert.False(t, results[1].Applied)
+		assert.NotEmpty(t, results[1].Reason)
+	})
+
+	t.Run("trailing whitespace differences", func(t *testing.T) {
+		hunks := parse(t, "@@ -1,2 +1,2 @@\n one  \n-two\n+TWO\n")
+		got, _ := applyPatchToString(t, original, hunks, 0)
+		assert.Equal(t, "one\nTWO\nthree\nfour\nfive\nsix\nseven\n", got)
+	})
+
+	t.Run("append to file without trailing newline", func(t *testing.T) {
+		hunks := parse(t, "@@ -2 +2,2 @@\n-b\n\\ No newline at end of file\n+b\n+c\n\\ No newline at end of file\n")
+		got, _ := applyPatchToString(t, "a\nb", hunks, 0)
+		assert.Equal(t, "a\nb\nc", got)
+	})
+
+	t.Run("new file content", func(t *testing.T) {
+		hunks := parse(t, "@@ -0,0 +1,2 @@\n+x\n+y\n")
+		got, results := applyPatchToString(t, "", hunks, 0)
+		assert.Equal(t, "x\ny\n", got)
+		assert.True(t, results[0].Applied)
+	})
+
+	t.Run("CRLF file", func(t *testing.T) {
+		hunks := parse(t, "@@ -1,2 +1,2 @@\n-a\n+A\n b\n")
+		got, _ := applyPatchToString(t, "a\r\nb\r\n", hunks, 0)
+		assert.Equal(t, "A\r\nb\r\n", got)
+	})
+}
@@ -0,0 +1,236 @@
+package utilities
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+// FileDiff is the change a workspace edit would make to one file
+type FileDiff struct {
+	// Path is the file after the edit, or the deleted file
+	Path string
+	// OldPath is set when the file is renamed
+	OldPath string
+	Created bool
+	Deleted bool
+	// Diff is a unified diff of the content change, empty if the content is unchanged
+	Diff string
+}
+
+// virtualFile is the simulated state of a path while previewing an edit
+type virtualFile struct {
+	exists  bool
+	content []byte
+}
+
+// editPreview simulates a workspace edit in memory
+type editPreview struct {
+	original map[string]virtualFile
+	current  map[string]virtualFile
+	order    []string
+	// renamedFrom maps a rename target to the path its content came from
+	renamedFrom map[string]string
+}
+
+// PreviewWorkspaceEdit computes the changes a WorkspaceEdit would make without
+// writing anything to disk. Diffs are returned in the order files are first
+// touched, with Changes sorted by URI as when the edit is applied. Paths inside
+// baseDir are shown relative to it in the diff headers.
+func PreviewWorkspaceEdit(edit protocol.WorkspaceEdit, baseDir string, contextLines int) ([]FileDiff, error) {
+	p := &editPreview{
+		original:    make(map[string]virtualFile),
+		current:     make(map[string]virtualFile),
+		renamedFrom: make(map[string]string),
+	}
+
+	uris := make([]protocol.DocumentUri, 0, len(edit.Changes))
+	for uri := range edit.Changes {
+		uris = append(uris, uri)
+	}
+	sort.Slice(uris, func(i, j int) bool { return uris[i] < uris[j] })
+
+	for _, uri := range uris {
+		if err := p.applyTextEdits(uriToPath(uri), edit.Changes[uri]); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, change := range edit.DocumentChanges {
+		if err := p.applyDocumentChange(change); err != nil {
+			return nil, err
+		}
+	}
+
+	return p.diffs(baseDir, contextLines), nil
+}
+
+// load returns the simulated state of path, reading it from disk the first time
+func (p *editPreview) load(path string) (virtualFile, error) {
+	if file, ok := p.current[path]; ok {
+		return file, nil
+	}
+
+	var file virtualFile
+	content, err := osReadFile(path)
+	switch {
+	case err == nil:
+		file = virtualFile{exists: true, content: content}
+	case errors.Is(err, os.ErrNotExist):
+	default:
+		return file, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	p.original[path] = file
+	p.current[path] = file
+	p.order = append(p.order, path)
+	return file, nil
+}
+
+func (p *editPreview) applyTextEdits(path string, edits []protocol.TextEdit) error {
+	file, err := p.load(path)
+	if err != nil {
+		return err
+	}
+	if !file.exists {
+		return fmt.Errorf("cannot edit %s: file does not exist", path)
+	}
+
+	content, err := applyTextEditsToContent(file.content, edits)
+	if err != nil {
+		return fmt.Errorf("failed to apply edits to %s: %w", path, err)
+	}
+	p.current[path] = virtualFile{exists: true, content: content}
+	return nil
+}
+
+func (p *editPreview) applyDocumentChange(change protocol.DocumentChange) error {
+	switch {
+	case change.CreateFile != nil:
+		path := uriToPath(change.CreateFile.URI)
+		file, err := p.load(path)
+		if err != nil {
+			return err
+		}
+		if opts := change.CreateFile.Options; file.exists && opts != nil && !opts.Overwrite && opts.IgnoreIfExists {
+			return nil
+		}
+		p.current[path] = virtualFile{exists: true}
+
+	case change.DeleteFile != nil:
+		path := uriToPath(change.DeleteFile.URI)
+		file, err := p.load(path)
+		if err != nil {
+			return err
+		}
+		if !file.exists {
+			if opts := change.DeleteFile.Options; opts != nil && opts.IgnoreIfNotExists {
+				return nil
+			}
+			return fmt.Errorf("cannot delete %s: file does not exist", path)
+		}
+		p.current[path] = virtualFile{}
+
+	case change.RenameFile != nil:
+		oldPath := uriToPath(change.RenameFile.OldURI)
+		newPath := uriToPath(change.RenameFile.NewURI)
+		file, err := p.load(oldPath)
+		if err != nil {
+			return err
+		}
+		target, err := p.load(newPath)
+		if err != nil {
+			return err
+		}
+		if !file.exists {
+			return fmt.Errorf("cannot rename %s: file does not exist", oldPath)
+		}
+		if opts := change.RenameFile.Options; opts != nil && !opts.Overwrite && target.exists {
+			if opts.IgnoreIfExists {
+				return nil
+			}
+			return fmt.Errorf("target file already exists and overwrite is not allowed: %s", newPath)
+		}
+		p.current[oldPath] = virtualFile{}
+		p.current[newPath] = file
+		source := oldPath
+		if from, ok := p.renamedFrom[oldPath]; ok {
+			source = from
+		}
+		p.renamedFrom[newPath] = source
+
+	case change.TextDocumentEdit != nil:
+		edits := make([]protocol.TextEdit, len(change.TextDocumentEdit.Edits))
+		for i, edit := range change.TextDocumentEdit.Edits {
+			var err error
+			edits[i], err = edit.AsTextEdit()
+			if err != nil {
+				return fmt.Errorf("invalid edit type: %w", err)
+			}
+		}
+		return p.applyTextEdits(uriToPath(change.TextDocumentEdit.TextDocument.URI), edits)
+	}
+
+	return nil
+}
+
+// diffs compares the original and simulated state of every touched path
+func (p *editPreview) diffs(baseDir string, contextLines int) []FileDiff {
+	// Sources of renames are reported together with their target
+	renameSources := make(map[string]bool)
+	for target, source := range p.renamedFrom {
+		if p.current[target].exists && !p.current[source].exists {
+			renameSources[source] = true
+		}
+	}
+
+	var diffs []FileDiff
+	for _, path := range p.order {
+		before, after := p.original[path], p.current[path]
+		if renameSources[path] {
+			continue
+		}
+
+		diff := FileDiff{Path: path}
+		fromName, toName := displayPath(baseDir, path), displayPath(baseDir, path)
+		if source, ok := p.renamedFrom[path]; ok && renameSources[source] {
+			before = p.original[source]
+			diff.OldPath = source
+			fromName = displayPath(baseDir, source)
+		}
+
+		switch {
+		case !before.exists && !after.exists:
+			continue
+		case !before.exists:
+			diff.Created = true
+			fromName = DevNull
+		case !after.exists:
+			diff.Deleted = true
+			toName = DevNull
+		case diff.OldPath == "" && string(before.content) == string(after.content):
+			continue
+		}
+
+		diff.Diff = UnifiedDiff(fromName, toName, string(before.content), string(after.content), contextLines)
+		diffs = append(diffs, diff)
+	}
+	return diffs
+}
+
+// displayPath returns path relative to baseDir when it lies inside it
+func displayPath(baseDir, path string) string {
+	if baseDir == "" {
+		return path
+	}
+	rel, err := filepath.Rel(baseDir, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return path
+	}
+	return rel
+}
@@ -0,0 +1,67 @@
+package utilities
+
+import (
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreviewWorkspaceEdit(t *testing.T) {
+	mfs := &mockFileSystem{files: map[string][]byte{
+		"/test/a.txt":    []byte("old a\nsame\n"),
+		"/test/old.txt":  []byte("moved\n"),
+		"/test/gone.txt": []byte("bye\n"),
+	}}
+	defer setupMockFileSystem(t, mfs)()
+
+	diffs, err := PreviewWorkspaceEdit(protocol.WorkspaceEdit{
+		Changes: map[protocol.DocumentUri][]protocol.TextEdit{
+			"file:///test/a.txt": replaceFirstLine("new"),
+		},
+		DocumentChanges: []protocol.DocumentChange{
+			{RenameFile: &protocol.RenameFile{OldURI: "file:///test/old.txt", NewURI: "file:///test/new.txt"}},
+			textDocumentEdit("file:///test/new.txt", replaceFirstLine("MOV")),
+			{CreateFile: &protocol.CreateFile{URI: "file:///test/created.txt"}},
+			textDocumentEdit("file:///test/created.txt", []protocol.TextEdit{{NewText: "hello\n"}}),
+			{DeleteFile: &protocol.DeleteFile{URI: "file:///test/gone.txt"}},
+		},
+	}, "/test", 3)
+	require.NoError(t, err)
+	require.Len(t, diffs, 4)
+
+	assert.Equal(t, FileDiff{
+		Path: "/test/a.txt",
+		Diff: "--- a.txt\n+++ a.txt\n@@ -1,2 +1,2 @@\n-old a\n+new a\n same\n",
+	}, diffs[0])
+
+	assert.Equal(t, "/test/new.txt", diffs[1].Path)
+	assert.Equal(t, "/test/old.txt", diffs[1].OldPath)
+	assert.Equal(t, "--- old.txt\n+++ new.txt\n@@ -1 +1 @@\n-moved\n+MOVed\n", diffs[1].Diff)
+
+	assert.True(t, diffs[2].Created)
+	assert.Equal(t, "--- /dev/null\n+++ created.txt\n@@ -0,0 +1 @@\n+hello\n", diffs[2].Diff)
+
+	assert.True(t, diffs[3].Deleted)
+	assert.Equal(t, "/test/gone.txt", diffs[3].Path)
+
+	// Nothing was written
+	assert.Equal(t, map[string][]byte{
+		"/test/a.txt":    []byte("old a\nsame\n"),
+		"/test/old.txt":  []byte("moved\n"),
+		"/test/gone.txt": []byte("bye\n"),
+	}, mfs.files)
+}
+
+func TestPreviewWorkspaceEdit_InvalidEdit(t *testing.T) {
+	mfs := &mockFileSystem{files: map[string][]byte{}}
+	defer setupMockFileSystem(t, mfs)()
+
+	_, err := PreviewWorkspaceEdit(protocol.WorkspaceEdit{
+		Changes: map[protocol.DocumentUri][]protocol.TextEdit{
+			"file:///test/missing.txt": replaceFirstLine("new"),
+		},
+	}, "", 3)
+	assert.Error(t, err)
+}
@@ -0,0 +1,388 @@
+package utilities
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+var (
+	osMkdirAll      = os.MkdirAll
+	filepathWalkDir = filepath.WalkDir
+	checkWritable   = func(path string) error {
+		f, err := os.OpenFile(path, os.O_WRONLY, 0)
+		if err != nil {
+			return err
+		}
+		return f.Close()
+	}
+)
+
+// WorkspaceEditResult reports the outcome of a transactional workspace edit
+type WorkspaceEditResult struct {
+	// Committed lists every path that was written, created, renamed or deleted,
+	// in the order it was first touched. It is empty when the edit failed.
+	Committed []string
+	// RolledBack lists the paths restored to their original state after a failure
+	RolledBack []string
+	// RollbackFailed lists paths that could not be restored and may be left modified
+	RollbackFailed []string
+	// FailedChange is the index into DocumentChanges of the change that failed,
+	// or -1 if the edit succeeded or failed outside DocumentChanges
+	FailedChange int
+	// Entry is the journal entry recording the edit, if it was journaled
+	Entry *JournalEntry
+
+	// before holds the original state of each committed path, used by the edit journal
+	before []*fileSnapshot
+}
+
+// fileSnapshot is the state of a path before a workspace edit touched it
+type fileSnapshot struct {
+	path    string
+	existed bool
+	isDir   bool
+	content []byte
+	mode    fs.FileMode
+	// children holds the files beneath a directory that is deleted recursively
+	children []fileSnapshot
+}
+
+// editTransaction tracks snapshots and the expected state of each path while
+// a workspace edit is validated and applied
+type editTransaction struct {
+	snapshots map[string]*fileSnapshot
+	order     []string
+	// exists is the simulated existence of each path after the changes validated so far
+	exists map[string]bool
+	// touched marks paths that a change has started to modify
+	touched map[string]bool
+}
+
+func newEditTransaction() *editTransaction {
+	return &editTransaction{
+		snapshots: make(map[string]*fileSnapshot),
+		exists:    make(map[string]bool),
+		touched:   make(map[string]bool),
+	}
+}
+
+// ApplyWorkspaceEditTransaction applies a WorkspaceEdit atomically. Every path
+// affected by text edits and resource operations is validated and snapshotted
+// before anything is written. If any change then fails, every path already
+// touched is restored from its snapshot.
+func ApplyWorkspaceEditTransaction(edit protocol.WorkspaceEdit) (*WorkspaceEditResult, error) {
+	result := &WorkspaceEditResult{FailedChange: -1}
+	tx := newEditTransaction()
+
+	// Apply Changes in a stable order so failures are reproducible
+	uris := make([]protocol.DocumentUri, 0, len(edit.Changes))
+	for uri := range edit.Changes {
+		uris = append(uris, uri)
+	}
+	sort.Slice(uris, func(i, j int) bool { return uris[i] < uris[j] })
+
+	// Validate and snapshot everything up front
+	for _, uri := range uris {
+		if err := tx.validateTextEdit(uriToPath(uri)); err != nil {
+			return result, fmt.Errorf("failed to apply text edits: %w", err)
+		}
+	}
+	skip := make([]bool, len(edit.DocumentChanges))
+	for i, change := range edit.DocumentChanges {
+		var err error
+		skip[i], err = tx.validateDocumentChange(change)
+		if err != nil {
+			result.FailedChange = i
+			return result, fmt.Errorf("failed to apply document change: %w", err)
+		}
+	}
+
+	// Apply, rolling back on the first failure
+	for _, uri := range uris {
+		tx.touched[uriToPath(uri)] = true
+		if err := ApplyTextEdits(uri, edit.Changes[uri]); err != nil {
+			tx.rollback(result)
+			return result, fmt.Errorf("failed to apply text edits: %w%s", err, rollbackSummary(result))
+		}
+	}
+	for i, change := range edit.DocumentChanges {
+		if skip[i] {
+			continue
+		}
+		paths := documentChangePaths(change)
+		coreLogger.Debug("Applying document change %d to %s", i, strings.Join(paths, ", "))
+		for _, path := range paths {
+			tx.touched[path] = true
+		}
+		if err := ApplyDocumentChange(change); err != nil {
+			result.FailedChange = i
+			tx.rollback(result)
+			return result, fmt.Errorf("failed to apply document change: %w%s", err, rollbackSummary(result))
+		}
+	}
+
+	result.Committed = tx.order
+	for _, path := range tx.order {
+		result.before = append(result.before, tx.snapshots[path])
+	}
+	return result, nil
+}
+
+// validateTextEdit checks that a file can be edited and snapshots it
+func (tx *editTransaction) validateTextEdit(path string) error {
+	snap, err := tx.snapshot(path)
+	if err != nil {
+		return err
+	}
+	if !tx.pathExists(path) {
+		return fmt.Errorf("cannot edit %s: file does not exist", path)
+	}
+	if snap.existed && snap.isDir {
+		return fmt.Errorf("cannot edit %s: is a directory", path)
+	}
+	if snap.existed {
+		if err := checkWritable(path); err != nil {
+			return fmt.Errorf("cannot edit %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// validateDocumentChange checks a single document change against the state the
+// preceding changes will leave behind. It reports whether the change is a no-op
+// that should be skipped.
+func (tx *editTransaction) validateDocumentChange(change protocol.DocumentChange) (bool, error) {
+	switch {
+	case change.CreateFile != nil:
+		path := uriToPath(change.CreateFile.URI)
+		if _, err := tx.snapshot(path); err != nil {
+			return false, err
+		}
+		if tx.pathExists(path) {
+			opts := change.CreateFile.Options
+			if opts != nil && !opts.Overwrite && opts.IgnoreIfExists {
+				return true, nil
+			}
+			if err := checkWritable(path); err != nil {
+				return false, fmt.Errorf("cannot create %s: %w", path, err)
+			}
+		}
+		tx.exists[path] = true
+
+	case change.DeleteFile != nil:
+		path := uriToPath(change.DeleteFile.URI)
+		snap, err := tx.snapshot(path)
+		if err != nil {
+			return false, err
+		}
+		opts := change.DeleteFile.Options
+		if !tx.pathExists(path) {
+			if opts != nil && opts.IgnoreIfNotExists {
+				return true, nil
+			}
+			return false, fmt.Errorf("cannot delete %s: file does not exist", path)
+		}
+		if snap.existed && snap.isDir && (opts == nil || !opts.Recursive) {
+			return false, fmt.Errorf("cannot delete directory %s without the recursive option", path)
+		}
+		tx.exists[path] = false
+
+	case change.RenameFile != nil:
+		oldPath := uriToPath(change.RenameFile.OldURI)
+		newPath := uriToPath(change.RenameFile.NewURI)
+		if _, err := tx.snapshot(oldPath); err != nil {
+			return false, err
+		}
+		if _, err := tx.snapshot(newPath); err != nil {
+			return false, err
+		}
+		if !tx.pathExists(oldPath) {
+			return false, fmt.Errorf("cannot rename %s: file does not exist", oldPath)
+		}
+		if opts := change.RenameFile.Options; opts != nil && !opts.Overwrite && tx.pathExists(newPath) {
+			if opts.IgnoreIfExists {
+				return true, nil
+			}
+			return false, fmt.Errorf("target file already exists and overwrite is not allowed: %s", newPath)
+		}
+		tx.exists[oldPath] = false
+		tx.exists[newPath] = true
+
+	case change.TextDocumentEdit != nil:
+		for _, edit := range change.TextDocumentEdit.Edits {
+			if _, err := edit.AsTextEdit(); err != nil {
+				return false, fmt.Errorf("invalid edit type: %w", err)
+			}
+		}
+		return false, tx.validateTextEdit(uriToPath(change.TextDocumentEdit.TextDocument.URI))
+	}
+
+	return false, nil
+}
+
+// pathExists reports whether path will exist once the changes validated so far are applied
+func (tx *editTransaction) pathExists(path string) bool {
+	if exists, ok := tx.exists[path]; ok {
+		return exists
+	}
+	return tx.snapshots[path].existed
+}
+
+// snapshot records the original state of path the first time it is touched
+func (tx *editTransaction) snapshot(path string) (*fileSnapshot, error) {
+	if snap, ok := tx.snapshots[path]; ok {
+		return snap, nil
+	}
+
+	snap, err := takeSnapshot(path)
+	if err != nil {
+		return nil, err
+	}
+	tx.snapshots[path] = snap
+	tx.order = append(tx.order, path)
+	return snap, nil
+}
+
+// takeSnapshot reads the current content of a file, or of every file beneath a directory
+func takeSnapshot(path string) (*fileSnapshot, error) {
+	snap := &fileSnapshot{path: path, mode: 0644}
+
+	info, statErr := osStat(path)
+	if statErr == nil {
+		snap.mode = info.Mode().Perm()
+		if info.IsDir() {
+			snap.existed = true
+			snap.isDir = true
+			err := filepathWalkDir(path, func(p string, d fs.DirEntry, err error) error {
+				if err != nil || d.IsDir() {
+					return err
+				}
+				child, err := takeSnapshot(p)
+				if err != nil {
+					return err
+				}
+				snap.children = append(snap.children, *child)
+				return nil
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to snapshot directory %s: %w", path, err)
+			}
+			return snap, nil
+		}
+	}
+
+	content, err := osReadFile(path)
+	switch {
+	case err == nil:
+		snap.existed = true
+		snap.content = content
+	case errors.Is(err, os.ErrNotExist):
+		// Nothing to restore, a rollback removes whatever the edit created
+	default:
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return snap, nil
+}
+
+// documentChangePaths returns the paths a document change modifies
+func documentChangePaths(change protocol.DocumentChange) []string {
+	switch {
+	case change.CreateFile != nil:
+		return []string{uriToPath(change.CreateFile.URI)}
+	case change.DeleteFile != nil:
+		return []string{uriToPath(change.DeleteFile.URI)}
+	case change.RenameFile != nil:
+		return []string{uriToPath(change.RenameFile.OldURI), uriToPath(change.RenameFile.NewURI)}
+	case change.TextDocumentEdit != nil:
+		return []string{uriToPath(change.TextDocumentEdit.TextDocument.URI)}
+	}
+	return nil
+}
+
+// rollback restores every path touched so far, recording the outcome in result
+func (tx *editTransaction) rollback(result *WorkspaceEditResult) {
+	// Remove paths that did not exist first, so a rename target created by the
+	// edit can't clobber a file restored afterwards
+	for i := len(tx.order) - 1; i >= 0; i-- {
+		snap := tx.snapshots[tx.order[i]]
+		if snap.existed || !tx.touched[snap.path] {
+			continue
+		}
+		if err := removePath(snap.path); err != nil {
+			coreLogger.Error("Failed to roll back %s: %v", snap.path, err)
+			result.RollbackFailed = append(result.RollbackFailed, snap.path)
+			continue
+		}
+		result.RolledBack = append(result.RolledBack, snap.path)
+	}
+
+	for i := len(tx.order) - 1; i >= 0; i-- {
+		snap := tx.snapshots[tx.order[i]]
+		if !snap.existed || !tx.touched[snap.path] {
+			continue
+		}
+		if err := restoreSnapshot(snap); err != nil {
+			coreLogger.Error("Failed to roll back %s: %v", snap.path, err)
+			result.RollbackFailed = append(result.RollbackFailed, snap.path)
+			continue
+		}
+		result.RolledBack = append(result.RolledBack, snap.path)
+	}
+}
+
+// restoreSnapshot writes back a file, or recreates a directory and its files
+func restoreSnapshot(snap *fileSnapshot) error {
+	if !snap.isDir {
+		// Skip files the failed change never managed to write
+		if current, err := osReadFile(snap.path); err == nil && bytes.Equal(current, snap.content) {
+			return nil
+		}
+		return osWriteFile(snap.path, snap.content, snap.mode)
+	}
+	if err := osMkdirAll(snap.path, 0755); err != nil {
+		return err
+	}
+	for i := range snap.children {
+		child := &snap.children[i]
+		if err := osMkdirAll(filepath.Dir(child.path), 0755); err != nil {
+			return err
+		}
+		if err := restoreSnapshot(child); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removePath deletes a file or directory created by a failed edit, if it exists
+func removePath(path string) error {
+	if info, err := osStat(path); err == nil && info.IsDir() {
+		return osRemoveAll(path)
+	}
+	if err := osRemove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// rollbackSummary describes the rollback for inclusion in an error message
+func rollbackSummary(result *WorkspaceEditResult) string {
+	if len(result.RollbackFailed) > 0 {
+		return fmt.Sprintf(" (rollback failed, these files may be left modified: %s)",
+			strings.Join(result.RollbackFailed, ", "))
+	}
+	return fmt.Sprintf(" (rolled back %d files)", len(result.RolledBack))
+}
+
+// uriToPath converts a file URI to a filesystem path
+func uriToPath(uri protocol.DocumentUri) string {
+	return strings.TrimPrefix(string(uri), "file://")
+}
@@ -0,0 +1,157 @@
+package utilities
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func replaceFirstLine(newText string) []protocol.TextEdit {
+	return []protocol.TextEdit{{
+		Range: protocol.Range{
+			Start: protocol.Position{Line: 0, Character: 0},
+			End:   protocol.Position{Line: 0, Character: 3},
+		},
+		NewText: newText,
+	}}
+}
+
+func textDocumentEdit(uri protocol.DocumentUri, edits []protocol.TextEdit) protocol.DocumentChange {
+	elems := make([]protocol.Or_TextDocumentEdit_edits_Elem, len(edits))
+	for i, edit := range edits {
+		elems[i] = protocol.Or_TextDocumentEdit_edits_Elem{Value: edit}
+	}
+	return protocol.DocumentChange{
+		TextDocumentEdit: &protocol.TextDocumentEdit{
+			TextDocument: protocol.OptionalVersionedTextDocumentIdentifier{
+				TextDocumentIdentifier: protocol.TextDocumentIdentifier{URI: uri},
+			},
+			Edits: elems,
+		},
+	}
+}
+
+func TestApplyWorkspaceEditTransaction(t *testing.T) {
+	t.Run("reports committed files", func(t *testing.T) {
+		mfs := &mockFileSystem{files: map[string][]byte{
+			"/test/a.txt":   []byte("old a\n"),
+			"/test/b.txt":   []byte("old b\n"),
+			"/test/mv1.txt": []byte("moved\n"),
+		}}
+		defer setupMockFileSystem(t, mfs)()
+
+		result, err := ApplyWorkspaceEditTransaction(protocol.WorkspaceEdit{
+			Changes: map[protocol.DocumentUri][]protocol.TextEdit{
+				"file:///test/b.txt": replaceFirstLine("new"),
+				"file:///test/a.txt": replaceFirstLine("new"),
+			},
+			DocumentChanges: []protocol.DocumentChange{
+				{RenameFile: &protocol.RenameFile{OldURI: "file:///test/mv1.txt", NewURI: "file:///test/mv2.txt"}},
+			},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"/test/a.txt", "/test/b.txt", "/test/mv1.txt", "/test/mv2.txt"}, result.Committed)
+		assert.Equal(t, -1, result.FailedChange)
+		assert.Equal(t, "new a\n", string(mfs.files["/test/a.txt"]))
+		assert.Equal(t, "moved\n", string(mfs.files["/test/mv2.txt"]))
+	})
+
+	t.Run("read-only file rejects the edit before anything is written", func(t *testing.T) {
+		mfs := &mockFileSystem{
+			files: map[string][]byte{
+				"/test/a.txt": []byte("old a\n"),
+				"/test/b.txt": []byte("old b\n"),
+			},
+			errors: map[string]error{"/test/b.txt_writable": errors.New("permission denied")},
+		}
+		defer setupMockFileSystem(t, mfs)()
+
+		result, err := ApplyWorkspaceEditTransaction(protocol.WorkspaceEdit{
+			Changes: map[protocol.DocumentUri][]protocol.TextEdit{
+				"file:///test/a.txt": replaceFirstLine("new"),
+				"file:///test/b.txt": replaceFirstLine("new"),
+			},
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "permission denied")
+		assert.Empty(t, result.Committed)
+		assert.Equal(t, "old a\n", string(mfs.files["/test/a.txt"]))
+	})
+
+	t.Run("failure while writing rolls back earlier changes", func(t *testing.T) {
+		mfs := &mockFileSystem{
+			files: map[string][]byte{
+				"/test/a.txt":   []byte("old a\n"),
+				"/test/b.txt":   []byte("old b\n"),
+				"/test/old.txt": []byte("renamed\n"),
+			},
+			errors: map[string]error{"/test/b.txt_write": errors.New("disk full")},
+		}
+		defer setupMockFileSystem(t, mfs)()
+
+		result, err := ApplyWorkspaceEditTransaction(protocol.WorkspaceEdit{
+			DocumentChanges: []protocol.DocumentChange{
+				{RenameFile: &protocol.RenameFile{OldURI: "file:///test/old.txt", NewURI: "file:///test/new.txt"}},
+				{CreateFile: &protocol.CreateFile{URI: "file:///test/created.txt"}},
+				textDocumentEdit("file:///test/a.txt", replaceFirstLine("new")),
+				textDocumentEdit("file:///test/b.txt", replaceFirstLine("new")),
+			},
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "disk full")
+		assert.Contains(t, err.Error(), "rolled back")
+		assert.Equal(t, 3, result.FailedChange)
+		assert.Empty(t, result.Committed)
+		assert.Empty(t, result.RollbackFailed)
+
+		assert.Equal(t, map[string][]byte{
+			"/test/a.txt":   []byte("old a\n"),
+			"/test/b.txt":   []byte("old b\n"),
+			"/test/old.txt": []byte("renamed\n"),
+		}, mfs.files)
+	})
+
+	t.Run("changes are validated in sequence", func(t *testing.T) {
+		mfs := &mockFileSystem{files: map[string][]byte{}}
+		defer setupMockFileSystem(t, mfs)()
+
+		// Editing a file created earlier in the same edit is allowed
+		_, err := ApplyWorkspaceEditTransaction(protocol.WorkspaceEdit{
+			DocumentChanges: []protocol.DocumentChange{
+				{CreateFile: &protocol.CreateFile{URI: "file:///test/new.txt"}},
+				textDocumentEdit("file:///test/new.txt", []protocol.TextEdit{{NewText: "hello"}}),
+			},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "hello", string(mfs.files["/test/new.txt"]))
+
+		// Editing a file deleted earlier in the same edit is not
+		result, err := ApplyWorkspaceEditTransaction(protocol.WorkspaceEdit{
+			DocumentChanges: []protocol.DocumentChange{
+				{DeleteFile: &protocol.DeleteFile{URI: "file:///test/new.txt"}},
+				textDocumentEdit("file:///test/new.txt", []protocol.TextEdit{{NewText: "again"}}),
+			},
+		})
+		require.Error(t, err)
+		assert.Equal(t, 1, result.FailedChange)
+		assert.Equal(t, "hello", string(mfs.files["/test/new.txt"]))
+	})
+
+	t.Run("ignored resource operations are skipped", func(t *testing.T) {
+		mfs := &mockFileSystem{files: map[string][]byte{}}
+		defer setupMockFileSystem(t, mfs)()
+
+		_, err := ApplyWorkspaceEditTransaction(protocol.WorkspaceEdit{
+			DocumentChanges: []protocol.DocumentChange{
+				{DeleteFile: &protocol.DeleteFile{
+					URI:     "file:///test/missing.txt",
+					Options: &protocol.DeleteFileOptions{IgnoreIfNotExists: true},
+				}},
+			},
+		})
+		assert.NoError(t, err)
+	})
+}
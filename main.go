@@ -29,6 +29,7 @@ type config struct {
 	lspArgs      []string
 	transport    string // "stdio" or "http"
 	httpPort     int    // Port for HTTP transport (default: 8080)
+	readOnly     bool   // Skip tools that write to the workspace and refuse server edits
 }
 
 type mcpServer struct {
@@ -80,6 +81,7 @@ func parseConfig() (*config, error) {
 	flag.StringVar(&cfg.lspCommand, "lsp", "", "LSP command to run (args should be passed after --)")
 	flag.StringVar(&cfg.transport, "transport", "stdio", "Transport type: stdio or http")
 	flag.IntVar(&cfg.httpPort, "port", 8080, "Port for HTTP transport")
+	flag.BoolVar(&cfg.readOnly, "read-only", false, "Only register tools that don't change the workspace and refuse workspace/applyEdit from the LSP server")
 	flag.Parse()
 
 	// Get remaining args after -- as LSP arguments
@@ -143,6 +145,7 @@ func (s *mcpServer) initializeLSP() error {
 		return fmt.Errorf("failed to create LSP client: %v", err)
 	}
 	s.lspClient = client
+	client.SetReadOnly(s.config.readOnly)
 	s.workspaceWatcher = watcher.NewWorkspaceWatcher(client)
 
 	initResult, err := client.InitializeLSPClient(s.ctx, s.config.workspaceDir)
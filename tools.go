@@ -2,17 +2,24 @@ package main
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/isaacphi/mcp-language-server/internal/lsp"
 	"github.com/isaacphi/mcp-language-server/internal/protocol"
 	"github.com/isaacphi/mcp-language-server/internal/tools"
 	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
 )
 
 func (s *mcpServer) registerEditFileTool() {
 	applyTextEditTool := mcp.NewTool("edit_file",
-		mcp.WithDescription("Apply multiple text edits to a file."),
+		mcp.WithDescription("Apply multiple text edits to a file. Edits address lines by number, or locate the text to replace with oldText. Use expectedText or expectedHash to reject edits if the file changed since it was read."),
+		mcp.WithOutputSchema[tools.EditResult](),
+		editTool(true, false),
 		mcp.WithArray("edits",
 			mcp.Required(),
 			mcp.Description("List of edits to apply"),
@@ -21,27 +28,37 @@ func (s *mcpServer) registerEditFileTool() {
 				"properties": map[string]any{
 					"startLine": map[string]any{
 						"type":        "number",
-						"description": "Start line to replace, inclusive, one-indexed",
+						"description": "Start line to replace, inclusive, one-indexed. Required unless oldText is set.",
 					},
 					"endLine": map[string]any{
 						"type":        "number",
-						"description": "End line to replace, inclusive, one-indexed",
+						"description": "End line to replace, inclusive, one-indexed. Required unless oldText is set.",
 					},
 					"newText": map[string]any{
 						"type":        "string",
 						"description": "Replacement text. Replace with the new text. Leave blank to remove lines.",
 					},
+					"expectedText": map[string]any{
+						"type":        "string",
+						"description": "Current content of lines startLine-endLine. The edit is rejected with a diff if the file no longer matches. Not allowed with oldText.",
+					},
+					"oldText": map[string]any{
+						"type":        "string",
+						"description": "Exact text to replace, used instead of line numbers. Must occur exactly once in the file.",
+					},
 				},
-				"required": []string{"startLine", "endLine"},
 			}),
 		),
 		mcp.WithString("filePath",
 			mcp.Required(),
 			mcp.Description("Path to the file to edit"),
 		),
+		mcp.WithString("expectedHash",
+			mcp.Description("SHA-256 of the file content the edits were written against (at least 8 hex characters). All edits are rejected if the file has changed."),
+		),
 	)
 
-	s.mcpServer.AddTool(applyTextEditTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.addTool(applyTextEditTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		// Extract arguments
 		filePath, ok := request.GetArguments()["filePath"].(string)
 		if !ok {
@@ -67,6 +84,20 @@ func (s *mcpServer) registerEditFileTool() {
 				return mcp.NewToolResultError("each edit must be an object"), nil
 			}
 
+			newText, _ := editMap["newText"].(string) // newText can be empty
+			expectedText, _ := editMap["expectedText"].(string)
+			oldText, _ := editMap["oldText"].(string)
+
+			// Search-and-replace edits don't need line numbers
+			if oldText != "" {
+				edits = append(edits, tools.TextEdit{
+					NewText:      newText,
+					ExpectedText: expectedText,
+					OldText:      oldText,
+				})
+				continue
+			}
+
 			startLine, ok := editMap["startLine"].(float64)
 			if !ok {
 				return mcp.NewToolResultError("startLine must be a number"), nil
@@ -77,35 +108,210 @@ func (s *mcpServer) registerEditFileTool() {
 				return mcp.NewToolResultError("endLine must be a number"), nil
 			}
 
-			newText, _ := editMap["newText"].(string) // newText can be empty
-
 			edits = append(edits, tools.TextEdit{
-				StartLine: int(startLine),
-				EndLine:   int(endLine),
-				NewText:   newText,
+				StartLine:    int(startLine),
+				EndLine:      int(endLine),
+				NewText:      newText,
+				ExpectedText: expectedText,
 			})
 		}
 
+		expectedHash, _ := request.GetArguments()["expectedHash"].(string)
+
 		coreLogger.Debug("Executing edit_file for file: %s", filePath)
-		response, err := tools.ApplyTextEdits(s.ctx, s.lspClient, filePath, edits)
+		result, err := tools.ApplyTextEdits(s.ctx, s.lspClient, filePath, edits, expectedHash)
 		if err != nil {
 			coreLogger.Error("Failed to apply edits: %v", err)
 			return mcp.NewToolResultError(fmt.Sprintf("failed to apply edits: %v", err)), nil
 		}
-		return mcp.NewToolResultText(response), nil
+		return toolResult(result), nil
+	})
+}
+
+func (s *mcpServer) registerApplyPatchTool() {
+	applyPatchTool := mcp.NewTool("apply_patch",
+		mcp.WithDescription("Apply a unified diff to one or more files. Supports git-style diffs that create, delete or rename files. Hunks are matched by their context, so small line offsets are tolerated; hunks that cannot be placed are reported as rejected while the rest of the patch is applied."),
+		mcp.WithOutputSchema[tools.EditResult](),
+		editTool(true, false),
+		mcp.WithString("patch",
+			mcp.Required(),
+			mcp.Description("The unified diff to apply. Paths are relative to the workspace root; a/ and b/ prefixes are stripped."),
+		),
+		mcp.WithNumber("fuzz",
+			mcp.Description("Maximum number of context lines that may be ignored at each end of a hunk that does not match exactly (default 2)"),
+		),
+	)
+
+	s.addTool(applyPatchTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Extract arguments
+		patch, ok := request.GetArguments()["patch"].(string)
+		if !ok {
+			return mcp.NewToolResultError("patch must be a string"), nil
+		}
+
+		fuzz := 2 // default value
+		switch v := request.GetArguments()["fuzz"].(type) {
+		case float64:
+			fuzz = int(v)
+		case int:
+			fuzz = v
+		}
+		if fuzz < 0 {
+			return mcp.NewToolResultError("fuzz must not be negative"), nil
+		}
+
+		coreLogger.Debug("Executing apply_patch with fuzz: %d", fuzz)
+		result, err := tools.ApplyPatch(s.ctx, s.lspClient, patch, fuzz)
+		if err != nil {
+			coreLogger.Error("Failed to apply patch: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to apply patch: %v", err)), nil
+		}
+		return toolResult(result), nil
+	})
+}
+
+func (s *mcpServer) registerRenameFileTool() {
+	renameFileTool := mcp.NewTool("rename_file",
+		mcp.WithDescription("Rename or move a file and update imports and other references to it. References are updated through the language server's workspace/willRenameFiles support; without it the file is moved and a warning is returned."),
+		mcp.WithOutputSchema[tools.EditResult](),
+		editTool(true, true),
+		mcp.WithString("oldPath",
+			mcp.Required(),
+			mcp.Description("Path of the file to rename"),
+		),
+		mcp.WithString("newPath",
+			mcp.Required(),
+			mcp.Description("New path for the file. Missing directories are created."),
+		),
+	)
+
+	s.addTool(renameFileTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		oldPath, ok := request.GetArguments()["oldPath"].(string)
+		if !ok {
+			return mcp.NewToolResultError("oldPath must be a string"), nil
+		}
+
+		newPath, ok := request.GetArguments()["newPath"].(string)
+		if !ok {
+			return mcp.NewToolResultError("newPath must be a string"), nil
+		}
+
+		coreLogger.Debug("Executing rename_file from: %s to: %s", oldPath, newPath)
+		result, err := tools.RenameFile(s.ctx, s.lspClient, oldPath, newPath)
+		if err != nil {
+			coreLogger.Error("Failed to rename file: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to rename file: %v", err)), nil
+		}
+		return toolResult(result), nil
+	})
+}
+
+func (s *mcpServer) registerCreateDeleteFileTools() {
+	createFileTool := mcp.NewTool("create_file",
+		mcp.WithDescription("Create a new file and open it in the language server. Edits the server contributes through workspace/willCreateFiles, such as package declarations or barrel exports, are applied along with it."),
+		mcp.WithOutputSchema[tools.EditResult](),
+		editTool(false, true),
+		mcp.WithString("filePath",
+			mcp.Required(),
+			mcp.Description("Path of the file to create. Missing directories are created."),
+		),
+		mcp.WithString("content",
+			mcp.Description("Initial content of the file (default: empty)"),
+		),
+	)
+
+	s.addTool(createFileTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		filePath, ok := request.GetArguments()["filePath"].(string)
+		if !ok {
+			return mcp.NewToolResultError("filePath must be a string"), nil
+		}
+
+		content := "" // default value
+		if v, ok := request.GetArguments()["content"].(string); ok {
+			content = v
+		}
+
+		coreLogger.Debug("Executing create_file for file: %s", filePath)
+		result, err := tools.CreateFile(s.ctx, s.lspClient, filePath, content)
+		if err != nil {
+			coreLogger.Error("Failed to create file: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to create file: %v", err)), nil
+		}
+		return toolResult(result), nil
+	})
+
+	deleteFileTool := mcp.NewTool("delete_file",
+		mcp.WithDescription("Delete a file and close it in the language server. Edits the server contributes through workspace/willDeleteFiles are applied along with it."),
+		mcp.WithOutputSchema[tools.EditResult](),
+		editTool(true, true),
+		mcp.WithString("filePath",
+			mcp.Required(),
+			mcp.Description("Path of the file to delete"),
+		),
+	)
+
+	s.addTool(deleteFileTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		filePath, ok := request.GetArguments()["filePath"].(string)
+		if !ok {
+			return mcp.NewToolResultError("filePath must be a string"), nil
+		}
+
+		coreLogger.Debug("Executing delete_file for file: %s", filePath)
+		result, err := tools.DeleteFile(s.ctx, s.lspClient, filePath)
+		if err != nil {
+			coreLogger.Error("Failed to delete file: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to delete file: %v", err)), nil
+		}
+		return toolResult(result), nil
+	})
+}
+
+func (s *mcpServer) registerUndoRedoTools() {
+	undoEditTool := mcp.NewTool("undo_edit",
+		mcp.WithDescription("Undo the most recent edit made by a tool that writes files (edit_file, apply_patch, rename_symbol, code action tools, ...) or by a language server workspace edit. Refuses if any affected file has changed since the edit."),
+		mcp.WithOutputSchema[tools.EditResult](),
+		editTool(true, false),
+	)
+
+	s.addTool(undoEditTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		coreLogger.Debug("Executing undo_edit")
+		result, err := tools.UndoEdit(s.ctx, s.lspClient)
+		if err != nil {
+			coreLogger.Error("Failed to undo edit: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to undo edit: %v", err)), nil
+		}
+		return toolResult(result), nil
+	})
+
+	redoEditTool := mcp.NewTool("redo_edit",
+		mcp.WithDescription("Redo the most recently undone edit. Refuses if any affected file has changed since the undo."),
+		mcp.WithOutputSchema[tools.EditResult](),
+		editTool(true, false),
+	)
+
+	s.addTool(redoEditTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		coreLogger.Debug("Executing redo_edit")
+		result, err := tools.RedoEdit(s.ctx, s.lspClient)
+		if err != nil {
+			coreLogger.Error("Failed to redo edit: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to redo edit: %v", err)), nil
+		}
+		return toolResult(result), nil
 	})
 }
 
 func (s *mcpServer) registerDefinitionTool() {
 	readDefinitionTool := mcp.NewTool("definition",
 		mcp.WithDescription("Read the source code definition of a symbol (function, type, constant, etc.) from the codebase. Returns the complete implementation code where the symbol is defined."),
+		mcp.WithOutputSchema[tools.DefinitionResult](),
+		readOnlyTool(),
 		mcp.WithString("symbolName",
 			mcp.Required(),
 			mcp.Description("The name of the symbol whose definition you want to find (e.g. 'mypackage.MyFunction', 'MyType.MyMethod')"),
 		),
 	)
 
-	s.mcpServer.AddTool(readDefinitionTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.addTool(readDefinitionTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		// Extract arguments
 		symbolName, ok := request.GetArguments()["symbolName"].(string)
 		if !ok {
@@ -113,44 +319,108 @@ func (s *mcpServer) registerDefinitionTool() {
 		}
 
 		coreLogger.Debug("Executing definition for symbol: %s", symbolName)
-		text, err := tools.ReadDefinition(s.ctx, s.lspClient, symbolName)
+		result, err := tools.ReadDefinition(s.ctx, s.lspClient, symbolName)
 		if err != nil {
 			coreLogger.Error("Failed to get definition: %v", err)
 			return mcp.NewToolResultError(fmt.Sprintf("failed to get definition: %v", err)), nil
 		}
-		return mcp.NewToolResultText(text), nil
+		return toolResult(result), nil
 	})
 }
 
 func (s *mcpServer) registerReferencesTool() {
 	findReferencesTool := mcp.NewTool("references",
-		mcp.WithDescription("Find all usages and references of a symbol throughout the codebase. Returns a list of all files and locations where the symbol appears."),
+		mcp.WithDescription("Find all usages and references of a symbol throughout the codebase. Returns the files and locations where the symbol appears, grouped by the function or other symbol each usage sits in."),
+		mcp.WithOutputSchema[tools.ReferencesResult](),
+		readOnlyTool(),
 		mcp.WithString("symbolName",
 			mcp.Required(),
 			mcp.Description("The name of the symbol to search for (e.g. 'mypackage.MyFunction', 'MyType')"),
 		),
+		mcp.WithNumber("contextLines",
+			mcp.Description(fmt.Sprintf("Number of lines to show around each reference, within its enclosing symbol (default: %d)", tools.DefaultReferenceContextLines)),
+		),
+		mcp.WithBoolean("signatures",
+			mcp.Description("Show the full signature of each enclosing symbol and the referencing lines instead of context lines"),
+			mcp.DefaultBool(false),
+		),
+		mcp.WithBoolean("includeDeclaration",
+			mcp.Description("Also list the symbol's declaration"),
+			mcp.DefaultBool(false),
+		),
+		mcp.WithArray("include",
+			mcp.Description("Only list references in files matching one of these globs, relative to the workspace root (e.g. 'src/**/*.ts')"),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
+		mcp.WithArray("exclude",
+			mcp.Description("Leave out references in files matching any of these globs (e.g. '**/*_test.go')"),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
+		withPaging(),
 	)
 
-	s.mcpServer.AddTool(findReferencesTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.addTool(findReferencesTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		// Extract arguments
 		symbolName, ok := request.GetArguments()["symbolName"].(string)
 		if !ok {
 			return mcp.NewToolResultError("symbolName must be a string"), nil
 		}
 
+		opts := tools.ReferencesOptions{ContextLines: tools.DefaultReferenceContextLines}
+		switch v := request.GetArguments()["contextLines"].(type) {
+		case float64:
+			opts.ContextLines = int(v)
+		case int:
+			opts.ContextLines = v
+		}
+		if v, ok := request.GetArguments()["signatures"].(bool); ok {
+			opts.Signatures = v
+		}
+		if v, ok := request.GetArguments()["includeDeclaration"].(bool); ok {
+			opts.IncludeDeclaration = v
+		}
+		if v, ok := request.GetArguments()["include"].([]any); ok {
+			for _, item := range v {
+				pattern, ok := item.(string)
+				if !ok {
+					return mcp.NewToolResultError("include must be an array of strings"), nil
+				}
+				opts.Include = append(opts.Include, pattern)
+			}
+		}
+		if v, ok := request.GetArguments()["exclude"].([]any); ok {
+			for _, item := range v {
+				pattern, ok := item.(string)
+				if !ok {
+					return mcp.NewToolResultError("exclude must be an array of strings"), nil
+				}
+				opts.Exclude = append(opts.Exclude, pattern)
+			}
+		}
+
+		page, err := parsePageOptions(request)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
 		coreLogger.Debug("Executing references for symbol: %s", symbolName)
-		text, err := tools.FindReferences(s.ctx, s.lspClient, symbolName)
+		result, err := tools.FindReferences(s.ctx, s.lspClient, symbolName, opts)
 		if err != nil {
 			coreLogger.Error("Failed to find references: %v", err)
 			return mcp.NewToolResultError(fmt.Sprintf("failed to find references: %v", err)), nil
 		}
-		return mcp.NewToolResultText(text), nil
+		if err := tools.Paginate(result, page); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		return toolResult(result), nil
 	})
 }
 
 func (s *mcpServer) registerDiagnosticsTool() {
 	getDiagnosticsTool := mcp.NewTool("diagnostics",
-		mcp.WithDescription("Get diagnostic information for a specific file from the language server."),
+		mcp.WithDescription("Get diagnostic information for a specific file from the language server. Diagnostics are numbered so they can be passed to fix_diagnostic."),
+		mcp.WithOutputSchema[tools.DiagnosticsResult](),
+		readOnlyTool(),
 		mcp.WithString("filePath",
 			mcp.Required(),
 			mcp.Description("The path to the file to get diagnostics for"),
@@ -165,7 +435,7 @@ func (s *mcpServer) registerDiagnosticsTool() {
 		),
 	)
 
-	s.mcpServer.AddTool(getDiagnosticsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.addTool(getDiagnosticsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		// Extract arguments
 		filePath, ok := request.GetArguments()["filePath"].(string)
 		if !ok {
@@ -183,25 +453,27 @@ func (s *mcpServer) registerDiagnosticsTool() {
 		}
 
 		coreLogger.Debug("Executing diagnostics for file: %s", filePath)
-		text, err := tools.GetDiagnosticsForFile(s.ctx, s.lspClient, filePath, contextLines, showLineNumbers)
+		result, err := tools.GetDiagnosticsForFile(s.ctx, s.lspClient, filePath, contextLines, showLineNumbers)
 		if err != nil {
 			coreLogger.Error("Failed to get diagnostics: %v", err)
 			return mcp.NewToolResultError(fmt.Sprintf("failed to get diagnostics: %v", err)), nil
 		}
-		return mcp.NewToolResultText(text), nil
+		return toolResult(result), nil
 	})
 }
 
 func (s *mcpServer) registerGetCodeLensTool() {
 	getCodeLensTool := mcp.NewTool("get_codelens",
 		mcp.WithDescription("Get code lens hints for a given file from the language server."),
+		mcp.WithOutputSchema[tools.CodeLensResult](),
+		readOnlyTool(),
 		mcp.WithString("filePath",
 			mcp.Required(),
 			mcp.Description("The path to the file to get code lens information for"),
 		),
 	)
 
-	s.mcpServer.AddTool(getCodeLensTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.addTool(getCodeLensTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		// Extract arguments
 		filePath, ok := request.GetArguments()["filePath"].(string)
 		if !ok {
@@ -209,18 +481,20 @@ func (s *mcpServer) registerGetCodeLensTool() {
 		}
 
 		coreLogger.Debug("Executing get_codelens for file: %s", filePath)
-		text, err := tools.GetCodeLens(s.ctx, s.lspClient, filePath)
+		result, err := tools.GetCodeLens(s.ctx, s.lspClient, filePath)
 		if err != nil {
 			coreLogger.Error("Failed to get code lens: %v", err)
 			return mcp.NewToolResultError(fmt.Sprintf("failed to get code lens: %v", err)), nil
 		}
-		return mcp.NewToolResultText(text), nil
+		return toolResult(result), nil
 	})
 }
 
 func (s *mcpServer) registerExecuteCodeLensTool() {
 	executeCodeLensTool := mcp.NewTool("execute_codelens",
 		mcp.WithDescription("Execute a code lens command for a given file and lens index."),
+		mcp.WithOutputSchema[tools.EditResult](),
+		editTool(true, false),
 		mcp.WithString("filePath",
 			mcp.Required(),
 			mcp.Description("The path to the file containing the code lens to execute"),
@@ -231,7 +505,7 @@ func (s *mcpServer) registerExecuteCodeLensTool() {
 		),
 	)
 
-	s.mcpServer.AddTool(executeCodeLensTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.addTool(executeCodeLensTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		// Extract arguments
 		filePath, ok := request.GetArguments()["filePath"].(string)
 		if !ok {
@@ -250,18 +524,20 @@ func (s *mcpServer) registerExecuteCodeLensTool() {
 		}
 
 		coreLogger.Debug("Executing execute_codelens for file: %s index: %d", filePath, index)
-		text, err := tools.ExecuteCodeLens(s.ctx, s.lspClient, filePath, index)
+		result, err := tools.ExecuteCodeLens(s.ctx, s.lspClient, filePath, index)
 		if err != nil {
 			coreLogger.Error("Failed to execute code lens: %v", err)
 			return mcp.NewToolResultError(fmt.Sprintf("failed to execute code lens: %v", err)), nil
 		}
-		return mcp.NewToolResultText(text), nil
+		return toolResult(result), nil
 	})
 }
 
 func (s *mcpServer) registerHoverTool() {
 	hoverTool := mcp.NewTool("hover",
 		mcp.WithDescription("Get hover information (type, documentation) for a symbol at the specified position."),
+		mcp.WithOutputSchema[tools.HoverResult](),
+		readOnlyTool(),
 		mcp.WithString("filePath",
 			mcp.Required(),
 			mcp.Description("The path to the file to get hover information for"),
@@ -276,7 +552,7 @@ func (s *mcpServer) registerHoverTool() {
 		),
 	)
 
-	s.mcpServer.AddTool(hoverTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.addTool(hoverTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		// Extract arguments
 		filePath, ok := request.GetArguments()["filePath"].(string)
 		if !ok {
@@ -304,18 +580,85 @@ func (s *mcpServer) registerHoverTool() {
 		}
 
 		coreLogger.Debug("Executing hover for file: %s line: %d column: %d", filePath, line, column)
-		text, err := tools.GetHoverInfo(s.ctx, s.lspClient, filePath, line, column)
+		result, err := tools.GetHoverInfo(s.ctx, s.lspClient, filePath, line, column)
 		if err != nil {
 			coreLogger.Error("Failed to get hover information: %v", err)
 			return mcp.NewToolResultError(fmt.Sprintf("failed to get hover information: %v", err)), nil
 		}
-		return mcp.NewToolResultText(text), nil
+		return toolResult(result), nil
+	})
+}
+
+func (s *mcpServer) registerInspectTool() {
+	inspectTool := mcp.NewTool("inspect",
+		mcp.WithDescription("Explain the identifier at a position in one call: its type and docs, its definition, its references by file with the first sites, the enclosing symbol and the signature of the surrounding call. Runs hover, definition, references, signature help and document symbols concurrently; use it instead of calling those tools one by one."),
+		mcp.WithOutputSchema[tools.InspectResult](),
+		readOnlyTool(),
+		mcp.WithString("filePath",
+			mcp.Required(),
+			mcp.Description("The path to the file"),
+		),
+		mcp.WithNumber("line",
+			mcp.Required(),
+			mcp.Description("The line number of the identifier (1-indexed)"),
+		),
+		mcp.WithNumber("column",
+			mcp.Required(),
+			mcp.Description("The column number of the identifier (1-indexed)"),
+		),
+		mcp.WithNumber("maxSites",
+			mcp.Description(fmt.Sprintf("Maximum number of reference sites to list (default: %d, 0 for counts only)", tools.DefaultInspectReferenceSites)),
+		),
+	)
+
+	s.addTool(inspectTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		filePath, ok := request.GetArguments()["filePath"].(string)
+		if !ok {
+			return mcp.NewToolResultError("filePath must be a string"), nil
+		}
+
+		var line, column int
+		switch v := request.GetArguments()["line"].(type) {
+		case float64:
+			line = int(v)
+		case int:
+			line = v
+		default:
+			return mcp.NewToolResultError("line must be a number"), nil
+		}
+
+		switch v := request.GetArguments()["column"].(type) {
+		case float64:
+			column = int(v)
+		case int:
+			column = v
+		default:
+			return mcp.NewToolResultError("column must be a number"), nil
+		}
+
+		maxSites := tools.DefaultInspectReferenceSites // default value
+		switch v := request.GetArguments()["maxSites"].(type) {
+		case float64:
+			maxSites = int(v)
+		case int:
+			maxSites = v
+		}
+
+		coreLogger.Debug("Executing inspect for file: %s line: %d column: %d", filePath, line, column)
+		result, err := tools.Inspect(s.ctx, s.lspClient, filePath, line, column, maxSites)
+		if err != nil {
+			coreLogger.Error("Failed to inspect position: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to inspect position: %v", err)), nil
+		}
+		return toolResult(result), nil
 	})
 }
 
 func (s *mcpServer) registerRenameSymbolTool() {
 	renameSymbolTool := mcp.NewTool("rename_symbol",
 		mcp.WithDescription("Rename a symbol (variable, function, class, etc.) at the specified position and update all references throughout the codebase."),
+		mcp.WithOutputSchema[tools.EditResult](),
+		editTool(true, true),
 		mcp.WithString("filePath",
 			mcp.Required(),
 			mcp.Description("The path to the file containing the symbol to rename"),
@@ -335,9 +678,13 @@ func (s *mcpServer) registerRenameSymbolTool() {
 		mcp.WithBoolean("validate",
 			mcp.Description("Whether to validate the rename operation using PrepareRename before executing (default: true)"),
 		),
+		mcp.WithBoolean("dryRun",
+			mcp.Description("Show the rename as unified diffs without writing any files"),
+			mcp.DefaultBool(false),
+		),
 	)
 
-	s.mcpServer.AddTool(renameSymbolTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.addTool(renameSymbolTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		// Extract arguments
 		filePath, ok := request.GetArguments()["filePath"].(string)
 		if !ok {
@@ -360,120 +707,887 @@ func (s *mcpServer) registerRenameSymbolTool() {
 			return mcp.NewToolResultError("line must be a number"), nil
 		}
 
-		switch v := request.GetArguments()["column"].(type) {
-		case float64:
-			column = int(v)
-		case int:
-			column = v
-		default:
-			return mcp.NewToolResultError("column must be a number"), nil
+		switch v := request.GetArguments()["column"].(type) {
+		case float64:
+			column = int(v)
+		case int:
+			column = v
+		default:
+			return mcp.NewToolResultError("column must be a number"), nil
+		}
+
+		// Extract optional validate parameter (default to true)
+		validate := true
+		if v, ok := request.GetArguments()["validate"]; ok {
+			if b, isBool := v.(bool); isBool {
+				validate = b
+			}
+		}
+
+		dryRun := false // default value
+		if v, ok := request.GetArguments()["dryRun"].(bool); ok {
+			dryRun = v
+		}
+
+		coreLogger.Debug("Executing rename_symbol for file: %s line: %d column: %d newName: %s validate: %v dryRun: %v", filePath, line, column, newName, validate, dryRun)
+		result, err := tools.RenameSymbol(s.ctx, s.lspClient, filePath, line, column, newName, validate, dryRun)
+		if err != nil {
+			coreLogger.Error("Failed to rename symbol: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to rename symbol: %v", err)), nil
+		}
+		return toolResult(result), nil
+	})
+}
+
+func (s *mcpServer) registerCodeActionsTool() {
+	codeActionsTool := mcp.NewTool("code_actions",
+		mcp.WithDescription("Get available code actions (quick fixes, refactorings) for a range"),
+		mcp.WithOutputSchema[tools.CodeActionsResult](),
+		readOnlyTool(),
+		mcp.WithString("filePath",
+			mcp.Required(),
+			mcp.Description("Path to the file"),
+		),
+		mcp.WithNumber("startLine",
+			mcp.Required(),
+			mcp.Description("Start line (1-indexed)"),
+		),
+		mcp.WithNumber("startColumn",
+			mcp.Required(),
+			mcp.Description("Start column (1-indexed)"),
+		),
+		mcp.WithNumber("endLine",
+			mcp.Required(),
+			mcp.Description("End line (1-indexed)"),
+		),
+		mcp.WithNumber("endColumn",
+			mcp.Required(),
+			mcp.Description("End column (1-indexed)"),
+		),
+		mcp.WithBoolean("dryRun",
+			mcp.Description("Show each action's edit as a unified diff without applying anything"),
+			mcp.DefaultBool(false),
+		),
+	)
+
+	s.addTool(codeActionsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Extract arguments
+		filePath, ok := request.GetArguments()["filePath"].(string)
+		if !ok {
+			return mcp.NewToolResultError("filePath must be a string"), nil
+		}
+
+		// Handle both float64 and int for all numeric parameters due to JSON parsing
+		var startLine, startColumn, endLine, endColumn int
+
+		switch v := request.GetArguments()["startLine"].(type) {
+		case float64:
+			startLine = int(v)
+		case int:
+			startLine = v
+		default:
+			return mcp.NewToolResultError("startLine must be a number"), nil
+		}
+
+		switch v := request.GetArguments()["startColumn"].(type) {
+		case float64:
+			startColumn = int(v)
+		case int:
+			startColumn = v
+		default:
+			return mcp.NewToolResultError("startColumn must be a number"), nil
+		}
+
+		switch v := request.GetArguments()["endLine"].(type) {
+		case float64:
+			endLine = int(v)
+		case int:
+			endLine = v
+		default:
+			return mcp.NewToolResultError("endLine must be a number"), nil
+		}
+
+		switch v := request.GetArguments()["endColumn"].(type) {
+		case float64:
+			endColumn = int(v)
+		case int:
+			endColumn = v
+		default:
+			return mcp.NewToolResultError("endColumn must be a number"), nil
+		}
+
+		dryRun := false // default value
+		if v, ok := request.GetArguments()["dryRun"].(bool); ok {
+			dryRun = v
+		}
+
+		coreLogger.Debug("Executing code_actions for file: %s range: (%d,%d) to (%d,%d) dryRun: %v", filePath, startLine, startColumn, endLine, endColumn, dryRun)
+		result, err := tools.GetCodeActions(s.ctx, s.lspClient, filePath, startLine, startColumn, endLine, endColumn, dryRun)
+		if err != nil {
+			coreLogger.Error("Failed to get code actions: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to get code actions: %v", err)), nil
+		}
+		return toolResult(result), nil
+	})
+}
+
+func (s *mcpServer) registerFixDiagnosticTool() {
+	fixDiagnosticTool := mcp.NewTool("fix_diagnostic",
+		mcp.WithDescription("Apply the language server's quick fix for a diagnostic, given by its number in the last diagnostics listing for the file. If several fixes are available and none is preferred, they are listed so one can be chosen with actionIndex."),
+		mcp.WithOutputSchema[tools.EditResult](),
+		editTool(true, false),
+		mcp.WithString("filePath",
+			mcp.Required(),
+			mcp.Description("Path to the file"),
+		),
+		mcp.WithNumber("index",
+			mcp.Required(),
+			mcp.Description("Number of the diagnostic in the last diagnostics listing (1-indexed)"),
+		),
+		mcp.WithNumber("actionIndex",
+			mcp.Description("Number of the quick fix to apply when several are listed (1-indexed)"),
+		),
+	)
+
+	s.addTool(fixDiagnosticTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		filePath, ok := request.GetArguments()["filePath"].(string)
+		if !ok {
+			return mcp.NewToolResultError("filePath must be a string"), nil
+		}
+
+		var index int
+		switch v := request.GetArguments()["index"].(type) {
+		case float64:
+			index = int(v)
+		case int:
+			index = v
+		default:
+			return mcp.NewToolResultError("index must be a number"), nil
+		}
+
+		actionIndex := 0 // default value
+		switch v := request.GetArguments()["actionIndex"].(type) {
+		case float64:
+			actionIndex = int(v)
+		case int:
+			actionIndex = v
+		}
+
+		coreLogger.Debug("Executing fix_diagnostic for file: %s index: %d actionIndex: %d", filePath, index, actionIndex)
+		result, err := tools.FixDiagnostic(s.ctx, s.lspClient, filePath, index, actionIndex)
+		if err != nil {
+			coreLogger.Error("Failed to fix diagnostic: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to fix diagnostic: %v", err)), nil
+		}
+		return toolResult(result), nil
+	})
+}
+
+func (s *mcpServer) registerRefactorTool() {
+	refactorTool := mcp.NewTool("refactor",
+		mcp.WithDescription("Apply an extract or inline refactoring to a selection using the language server's refactor code actions, then report where the new symbol was placed. If several matching refactorings are available and none is preferred, they are listed so one can be chosen with actionIndex."),
+		mcp.WithOutputSchema[tools.EditResult](),
+		editTool(true, false),
+		mcp.WithString("filePath",
+			mcp.Required(),
+			mcp.Description("Path to the file"),
+		),
+		mcp.WithNumber("startLine",
+			mcp.Required(),
+			mcp.Description("Start line of the selection (1-indexed)"),
+		),
+		mcp.WithNumber("startColumn",
+			mcp.Required(),
+			mcp.Description("Start column of the selection (1-indexed)"),
+		),
+		mcp.WithNumber("endLine",
+			mcp.Required(),
+			mcp.Description("End line of the selection (1-indexed)"),
+		),
+		mcp.WithNumber("endColumn",
+			mcp.Required(),
+			mcp.Description("End column of the selection (1-indexed)"),
+		),
+		mcp.WithString("kind",
+			mcp.Required(),
+			mcp.Description("Refactoring to apply: "+strings.Join(tools.RefactorKinds, ", ")+", or a refactor.* code action kind such as refactor.rewrite"),
+		),
+		mcp.WithNumber("actionIndex",
+			mcp.Description("Number of the refactoring to apply when several are listed (1-indexed)"),
+		),
+	)
+
+	s.addTool(refactorTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		filePath, ok := request.GetArguments()["filePath"].(string)
+		if !ok {
+			return mcp.NewToolResultError("filePath must be a string"), nil
+		}
+
+		kind, ok := request.GetArguments()["kind"].(string)
+		if !ok {
+			return mcp.NewToolResultError("kind must be a string"), nil
+		}
+
+		// Handle both float64 and int for all numeric parameters due to JSON parsing
+		var startLine, startColumn, endLine, endColumn int
+
+		switch v := request.GetArguments()["startLine"].(type) {
+		case float64:
+			startLine = int(v)
+		case int:
+			startLine = v
+		default:
+			return mcp.NewToolResultError("startLine must be a number"), nil
+		}
+
+		switch v := request.GetArguments()["startColumn"].(type) {
+		case float64:
+			startColumn = int(v)
+		case int:
+			startColumn = v
+		default:
+			return mcp.NewToolResultError("startColumn must be a number"), nil
+		}
+
+		switch v := request.GetArguments()["endLine"].(type) {
+		case float64:
+			endLine = int(v)
+		case int:
+			endLine = v
+		default:
+			return mcp.NewToolResultError("endLine must be a number"), nil
+		}
+
+		switch v := request.GetArguments()["endColumn"].(type) {
+		case float64:
+			endColumn = int(v)
+		case int:
+			endColumn = v
+		default:
+			return mcp.NewToolResultError("endColumn must be a number"), nil
+		}
+
+		actionIndex := 0 // default value
+		switch v := request.GetArguments()["actionIndex"].(type) {
+		case float64:
+			actionIndex = int(v)
+		case int:
+			actionIndex = v
+		}
+
+		coreLogger.Debug("Executing refactor %s for file: %s range: %d:%d-%d:%d", kind, filePath, startLine, startColumn, endLine, endColumn)
+		result, err := tools.Refactor(s.ctx, s.lspClient, filePath, startLine, startColumn, endLine, endColumn, kind, actionIndex)
+		if err != nil {
+			coreLogger.Error("Failed to refactor: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to refactor: %v", err)), nil
+		}
+		return toolResult(result), nil
+	})
+}
+
+func (s *mcpServer) registerFindUnusedTool() {
+	findUnusedTool := mcp.NewTool("find_unused",
+		mcp.WithDescription("Find functions, types, methods, constants and variables that have no references outside their own declaration, to spot dead code. Symbols come from document symbols and are checked with find-references, a few requests at a time. Sends progress notifications when the request has a progress token."),
+		mcp.WithOutputSchema[tools.FindUnusedResult](),
+		readOnlyTool(),
+		mcp.WithArray("filePaths",
+			mcp.Description("Files to check"),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
+		mcp.WithString("glob",
+			mcp.Description("Glob matching workspace files to check, e.g. 'internal/**/*.go'. Excluded and gitignored directories are skipped."),
+		),
+		mcp.WithBoolean("includeTests",
+			mcp.Description("Also check test files and test functions"),
+			mcp.DefaultBool(false),
+		),
+		mcp.WithBoolean("includeMain",
+			mcp.Description("Also check entry points such as main and init"),
+			mcp.DefaultBool(false),
+		),
+		mcp.WithBoolean("includeInterfaceMethods",
+			mcp.Description("Also check methods declared in interfaces"),
+			mcp.DefaultBool(false),
+		),
+		mcp.WithArray("excludeAnnotations",
+			mcp.Description("Skip symbols whose declaration, or the comments and annotations directly above it, contain any of these strings, e.g. '@Override', '//export' or '#[no_mangle]'"),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
+		mcp.WithNumber("concurrency",
+			mcp.Description(fmt.Sprintf("Number of requests sent to the language server at once (default: %d, max: %d)", tools.DefaultFindUnusedConcurrency, tools.MaxFindUnusedConcurrency)),
+		),
+		withPaging(),
+	)
+
+	s.addTool(findUnusedTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var opts tools.FindUnusedOptions
+		if v, ok := request.GetArguments()["filePaths"].([]any); ok {
+			for _, item := range v {
+				path, ok := item.(string)
+				if !ok {
+					return mcp.NewToolResultError("filePaths must be an array of strings"), nil
+				}
+				opts.FilePaths = append(opts.FilePaths, path)
+			}
+		}
+
+		if v, ok := request.GetArguments()["glob"].(string); ok {
+			opts.Glob = v
+		}
+
+		if v, ok := request.GetArguments()["includeTests"].(bool); ok {
+			opts.IncludeTests = v
+		}
+		if v, ok := request.GetArguments()["includeMain"].(bool); ok {
+			opts.IncludeMain = v
+		}
+		if v, ok := request.GetArguments()["includeInterfaceMethods"].(bool); ok {
+			opts.IncludeInterfaceMethods = v
+		}
+
+		if v, ok := request.GetArguments()["excludeAnnotations"].([]any); ok {
+			for _, item := range v {
+				annotation, ok := item.(string)
+				if !ok {
+					return mcp.NewToolResultError("excludeAnnotations must be an array of strings"), nil
+				}
+				opts.ExcludeAnnotations = append(opts.ExcludeAnnotations, annotation)
+			}
+		}
+
+		switch v := request.GetArguments()["concurrency"].(type) {
+		case float64:
+			opts.Concurrency = int(v)
+		case int:
+			opts.Concurrency = v
+		}
+
+		page, err := parsePageOptions(request)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		coreLogger.Debug("Executing find_unused for %d files and glob: %s", len(opts.FilePaths), opts.Glob)
+		result, err := tools.FindUnused(s.ctx, s.lspClient, opts, s.progressNotifier(ctx, request))
+		if err != nil {
+			coreLogger.Error("Failed to find unused symbols: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to find unused symbols: %v", err)), nil
+		}
+		if err := tools.Paginate(result, page); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		return toolResult(result), nil
+	})
+}
+
+func (s *mcpServer) registerImpactOfChangesTool() {
+	impactTool := mcp.NewTool("impact_of_changes",
+		mcp.WithDescription("Analyze the impact of the changes between a git ref and the working tree, including untracked files that aren't ignored. Each changed line is mapped to its enclosing function, method or type; for every changed symbol the transitive callers and the files referencing it are listed, and tests calling or referencing changed code are highlighted."),
+		mcp.WithOutputSchema[tools.ImpactResult](),
+		readOnlyTool(),
+		mcp.WithString("base",
+			mcp.Description("Git ref to diff the working tree against (default: HEAD)"),
+		),
+		mcp.WithNumber("maxDepth",
+			mcp.Description(fmt.Sprintf("Maximum levels of callers to follow (default: %d)", tools.DefaultImpactDepth)),
+		),
+	)
+
+	s.addTool(impactTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		base := "HEAD" // default value
+		if v, ok := request.GetArguments()["base"].(string); ok && v != "" {
+			base = v
+		}
+
+		maxDepth := tools.DefaultImpactDepth // default value
+		switch v := request.GetArguments()["maxDepth"].(type) {
+		case float64:
+			maxDepth = int(v)
+		case int:
+			maxDepth = v
+		}
+
+		coreLogger.Debug("Executing impact_of_changes against %s", base)
+		result, err := tools.ImpactOfChanges(s.ctx, s.lspClient, base, maxDepth)
+		if err != nil {
+			coreLogger.Error("Failed to analyze impact of changes: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to analyze impact of changes: %v", err)), nil
+		}
+		return toolResult(result), nil
+	})
+}
+
+// progressNotifier returns a function sending notifications/progress for a
+// tool call, or nil if the client didn't ask for progress with a token
+func (s *mcpServer) progressNotifier(ctx context.Context, request mcp.CallToolRequest) tools.ProgressFunc {
+	if request.Params.Meta == nil || request.Params.Meta.ProgressToken == nil {
+		return nil
+	}
+	token := request.Params.Meta.ProgressToken
+	return func(done, total int, message string) {
+		err := s.mcpServer.SendNotificationToClient(ctx, "notifications/progress", map[string]any{
+			"progressToken": token,
+			"progress":      done,
+			"total":         total,
+			"message":       message,
+		})
+		if err != nil {
+			coreLogger.Debug("Failed to send progress notification: %v", err)
+		}
+	}
+}
+
+// readOnlyTool annotates a tool that doesn't change the workspace
+func readOnlyTool() mcp.ToolOption {
+	return mcp.WithToolAnnotation(mcp.ToolAnnotation{
+		ReadOnlyHint:    mcp.ToBoolPtr(true),
+		DestructiveHint: mcp.ToBoolPtr(false),
+		IdempotentHint:  mcp.ToBoolPtr(true),
+		OpenWorldHint:   mcp.ToBoolPtr(false),
+	})
+}
+
+// editTool annotates a tool that writes to the workspace. destructive is set
+// when it can change or remove existing content rather than only add to it,
+// idempotent when calling it again with the same arguments has no further
+// effect.
+func editTool(destructive, idempotent bool) mcp.ToolOption {
+	return mcp.WithToolAnnotation(mcp.ToolAnnotation{
+		ReadOnlyHint:    mcp.ToBoolPtr(false),
+		DestructiveHint: mcp.ToBoolPtr(destructive),
+		IdempotentHint:  mcp.ToBoolPtr(idempotent),
+		OpenWorldHint:   mcp.ToBoolPtr(false),
+	})
+}
+
+// isReadOnlyTool reports whether a tool is annotated as read-only
+func isReadOnlyTool(tool mcp.Tool) bool {
+	return tool.Annotations.ReadOnlyHint != nil && *tool.Annotations.ReadOnlyHint
+}
+
+// addTool registers a tool, unless the server is read-only and the tool
+// isn't annotated as read-only
+func (s *mcpServer) addTool(tool mcp.Tool, handler server.ToolHandlerFunc) {
+	if s.config.readOnly && !isReadOnlyTool(tool) {
+		coreLogger.Info("Skipping '%s' tool - the server is read-only", tool.Name)
+		return
+	}
+	s.mcpServer.AddTool(tool, handler)
+}
+
+// withPaging adds the arguments of tools whose output is returned a page at a
+// time: a token budget, a result limit and the cursor of the next page
+func withPaging() mcp.ToolOption {
+	return func(tool *mcp.Tool) {
+		mcp.WithNumber("maxTokens",
+			mcp.Description(fmt.Sprintf("Estimated maximum tokens of output; the rest is summarised and returned with a cursor for the next page (default: %d)", tools.DefaultMaxTokens)),
+		)(tool)
+		mcp.WithNumber("maxResults",
+			mcp.Description("Maximum number of results to return; the rest is summarised and returned with a cursor for the next page"),
+		)(tool)
+		mcp.WithString("cursor",
+			mcp.Description("nextCursor of the previous page, to get the following page. Pass the same other arguments."),
+		)(tool)
+	}
+}
+
+// parsePageOptions reads the paging arguments of a call. The cursor is tied
+// to the tool and its other arguments by a hash of them.
+func parsePageOptions(request mcp.CallToolRequest) (tools.PageOptions, error) {
+	var opts tools.PageOptions
+	args := make(map[string]any)
+	for name, value := range request.GetArguments() {
+		switch name {
+		case "maxTokens", "maxResults":
+			var n int
+			switch v := value.(type) {
+			case float64:
+				n = int(v)
+			case int:
+				n = v
+			default:
+				return opts, fmt.Errorf("%s must be a number", name)
+			}
+			if name == "maxTokens" {
+				opts.MaxTokens = n
+			} else {
+				opts.MaxResults = n
+			}
+		case "cursor":
+			cursor, ok := value.(string)
+			if !ok {
+				return opts, fmt.Errorf("cursor must be a string")
+			}
+			opts.Cursor = cursor
+		default:
+			args[name] = value
+		}
+	}
+
+	data, err := json.Marshal(args)
+	if err != nil {
+		return opts, fmt.Errorf("failed to encode arguments: %v", err)
+	}
+	sum := sha256.Sum256(append([]byte(request.Params.Name+"\x00"), data...))
+	opts.Key = hex.EncodeToString(sum[:8])
+	return opts, nil
+}
+
+func (s *mcpServer) registerSourceActionTool(name, description string, run func(context.Context, *lsp.Client, []string, string) (*tools.EditResult, error)) {
+	sourceActionTool := mcp.NewTool(name,
+		mcp.WithDescription(description),
+		mcp.WithOutputSchema[tools.EditResult](),
+		editTool(true, true),
+		mcp.WithArray("filePaths",
+			mcp.Description("Files to process"),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
+		mcp.WithString("glob",
+			mcp.Description("Glob matching workspace files to process, e.g. 'src/**/*.ts'. Excluded and gitignored directories are skipped."),
+		),
+	)
+
+	s.addTool(sourceActionTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var filePaths []string
+		if v, ok := request.GetArguments()["filePaths"].([]any); ok {
+			for _, item := range v {
+				path, ok := item.(string)
+				if !ok {
+					return mcp.NewToolResultError("filePaths must be an array of strings"), nil
+				}
+				filePaths = append(filePaths, path)
+			}
+		}
+
+		glob := "" // default value
+		if v, ok := request.GetArguments()["glob"].(string); ok {
+			glob = v
+		}
+
+		coreLogger.Debug("Executing %s for files: %v glob: %s", name, filePaths, glob)
+		result, err := run(s.ctx, s.lspClient, filePaths, glob)
+		if err != nil {
+			coreLogger.Error("Failed to run %s: %v", name, err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to run %s: %v", name, err)), nil
+		}
+		return toolResult(result), nil
+	})
+}
+
+func (s *mcpServer) registerSymbolEditTool(mode, description, newTextDescription string) {
+	options := []mcp.ToolOption{
+		mcp.WithDescription(description),
+		mcp.WithOutputSchema[tools.EditResult](),
+		// Inserting only adds text, replacing the same text again changes nothing
+		editTool(mode == tools.ReplaceSymbol, mode == tools.ReplaceSymbol),
+		mcp.WithString("filePath",
+			mcp.Required(),
+			mcp.Description("File declaring the symbol"),
+		),
+		mcp.WithString("symbolPath",
+			mcp.Required(),
+			mcp.Description("Dotted path of the symbol, e.g. 'Server.handleRequest' or 'Server::handle'"),
+		),
+		mcp.WithString("newText",
+			mcp.Required(),
+			mcp.Description(newTextDescription),
+		),
+		mcp.WithBoolean("format",
+			mcp.Description("Format the new text with textDocument/rangeFormatting"),
+			mcp.DefaultBool(false),
+		),
+		mcp.WithBoolean("diagnostics",
+			mcp.Description("Report the file's diagnostics after the edit"),
+			mcp.DefaultBool(true),
+		),
+	}
+	if mode == tools.ReplaceSymbol {
+		options = append(options, mcp.WithBoolean("includeDocs",
+			mcp.Description("Also replace the doc comment, decorators and attributes directly above the symbol"),
+			mcp.DefaultBool(false),
+		))
+	}
+	symbolEditTool := mcp.NewTool(mode, options...)
+
+	s.addTool(symbolEditTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		filePath, ok := request.GetArguments()["filePath"].(string)
+		if !ok {
+			return mcp.NewToolResultError("filePath must be a string"), nil
+		}
+		symbolPath, ok := request.GetArguments()["symbolPath"].(string)
+		if !ok {
+			return mcp.NewToolResultError("symbolPath must be a string"), nil
+		}
+		newText, ok := request.GetArguments()["newText"].(string)
+		if !ok {
+			return mcp.NewToolResultError("newText must be a string"), nil
+		}
+
+		opts := tools.SymbolEditOptions{Diagnostics: true}
+		if v, ok := request.GetArguments()["includeDocs"].(bool); ok {
+			opts.IncludeDocs = v
+		}
+		if v, ok := request.GetArguments()["format"].(bool); ok {
+			opts.Format = v
+		}
+		if v, ok := request.GetArguments()["diagnostics"].(bool); ok {
+			opts.Diagnostics = v
+		}
+
+		coreLogger.Debug("Executing %s for symbol: %s file: %s", mode, symbolPath, filePath)
+		result, err := tools.EditSymbol(s.ctx, s.lspClient, filePath, symbolPath, mode, newText, opts)
+		if err != nil {
+			coreLogger.Error("Failed to run %s: %v", mode, err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to run %s: %v", mode, err)), nil
+		}
+		return toolResult(result), nil
+	})
+}
+
+func (s *mcpServer) registerMoveSymbolTool() {
+	moveSymbolTool := mcp.NewTool("move_symbol",
+		mcp.WithDescription("Move a symbol, given by its dotted path in a file, with its doc comment to another file, which is created if needed. Uses the language server's move refactoring when it can target the file, so references are updated: tsserver's 'Move to file' is given the target, while gopls and rust-analyzer choose their own destination, so with them the text fallback is used. The fallback moves the text and organizes imports in both files; it doesn't update references, and lists them in a warning when the symbol moves to another directory. Returns a diff and can be undone in one step."),
+		mcp.WithOutputSchema[tools.EditResult](),
+		editTool(true, false),
+		mcp.WithString("filePath",
+			mcp.Required(),
+			mcp.Description("File declaring the symbol"),
+		),
+		mcp.WithString("symbolPath",
+			mcp.Required(),
+			mcp.Description("Dotted path of the symbol, e.g. 'Server' or 'Server.handleRequest'"),
+		),
+		mcp.WithString("targetPath",
+			mcp.Required(),
+			mcp.Description("File to move the symbol to"),
+		),
+	)
+
+	s.addTool(moveSymbolTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		filePath, ok := request.GetArguments()["filePath"].(string)
+		if !ok {
+			return mcp.NewToolResultError("filePath must be a string"), nil
+		}
+		symbolPath, ok := request.GetArguments()["symbolPath"].(string)
+		if !ok {
+			return mcp.NewToolResultError("symbolPath must be a string"), nil
+		}
+		targetPath, ok := request.GetArguments()["targetPath"].(string)
+		if !ok {
+			return mcp.NewToolResultError("targetPath must be a string"), nil
+		}
+
+		coreLogger.Debug("Executing move_symbol for symbol: %s from: %s to: %s", symbolPath, filePath, targetPath)
+		result, err := tools.MoveSymbol(s.ctx, s.lspClient, filePath, symbolPath, targetPath)
+		if err != nil {
+			coreLogger.Error("Failed to move symbol: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to move symbol: %v", err)), nil
+		}
+		return toolResult(result), nil
+	})
+}
+
+func (s *mcpServer) registerSignatureHelpTool() {
+	signatureHelpTool := mcp.NewTool("signature_help",
+		mcp.WithDescription("Get function/method signature information at cursor position"),
+		mcp.WithOutputSchema[tools.SignatureHelpResult](),
+		readOnlyTool(),
+		mcp.WithString("filePath",
+			mcp.Required(),
+			mcp.Description("Path to the file"),
+		),
+		mcp.WithNumber("line",
+			mcp.Required(),
+			mcp.Description("Line number (1-indexed)"),
+		),
+		mcp.WithNumber("column",
+			mcp.Required(),
+			mcp.Description("Column number (1-indexed)"),
+		),
+	)
+
+	s.addTool(signatureHelpTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Extract arguments
+		filePath, ok := request.GetArguments()["filePath"].(string)
+		if !ok {
+			return mcp.NewToolResultError("filePath must be a string"), nil
+		}
+
+		// Handle both float64 and int for line and column due to JSON parsing
+		var line, column int
+		switch v := request.GetArguments()["line"].(type) {
+		case float64:
+			line = int(v)
+		case int:
+			line = v
+		default:
+			return mcp.NewToolResultError("line must be a number"), nil
+		}
+
+		switch v := request.GetArguments()["column"].(type) {
+		case float64:
+			column = int(v)
+		case int:
+			column = v
+		default:
+			return mcp.NewToolResultError("column must be a number"), nil
+		}
+
+		coreLogger.Debug("Executing signature_help for file: %s line: %d column: %d", filePath, line, column)
+		result, err := tools.GetSignatureHelp(s.ctx, s.lspClient, filePath, line, column)
+		if err != nil {
+			coreLogger.Error("Failed to get signature help: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to get signature help: %v", err)), nil
+		}
+		return toolResult(result), nil
+	})
+}
+
+func (s *mcpServer) registerDocumentSymbolsTool() {
+	documentSymbolsTool := mcp.NewTool("document_symbols",
+		mcp.WithDescription("Get the hierarchical symbol outline of a file (classes, functions, methods, etc.)"),
+		mcp.WithOutputSchema[tools.DocumentSymbolsResult](),
+		readOnlyTool(),
+		mcp.WithString("filePath",
+			mcp.Required(),
+			mcp.Description("Path to the file to get symbols for"),
+		),
+	)
+
+	s.addTool(documentSymbolsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Extract arguments
+		filePath, ok := request.GetArguments()["filePath"].(string)
+		if !ok {
+			return mcp.NewToolResultError("filePath must be a string"), nil
+		}
+
+		coreLogger.Debug("Executing document_symbols for file: %s", filePath)
+		result, err := tools.GetDocumentSymbols(s.ctx, s.lspClient, filePath)
+		if err != nil {
+			coreLogger.Error("Failed to get document symbols: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to get document symbols: %v", err)), nil
+		}
+		return toolResult(result), nil
+	})
+}
+
+func (s *mcpServer) registerReadSymbolTool() {
+	readSymbolTool := mcp.NewTool("read_symbol",
+		mcp.WithDescription("Read the exact source of a symbol given by its dotted path, such as 'Server.handleRequest', following the file's document symbol tree. Without a file, the symbol is searched across the workspace and may be qualified with its package or module."),
+		mcp.WithOutputSchema[tools.ReadSymbolResult](),
+		readOnlyTool(),
+		mcp.WithString("symbolPath",
+			mcp.Required(),
+			mcp.Description("Dotted path of the symbol, e.g. 'Server.handleRequest', 'Server::handle' or 'models.User.save'"),
+		),
+		mcp.WithString("filePath",
+			mcp.Description("File declaring the symbol. If omitted, the workspace is searched."),
+		),
+		mcp.WithBoolean("includeDocs",
+			mcp.Description("Include the doc comment, decorators and attributes directly above the symbol"),
+			mcp.DefaultBool(true),
+		),
+	)
+
+	s.addTool(readSymbolTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		symbolPath, ok := request.GetArguments()["symbolPath"].(string)
+		if !ok {
+			return mcp.NewToolResultError("symbolPath must be a string"), nil
+		}
+
+		filePath := ""
+		if v, ok := request.GetArguments()["filePath"].(string); ok {
+			filePath = v
 		}
 
-		// Extract optional validate parameter (default to true)
-		validate := true
-		if v, ok := request.GetArguments()["validate"]; ok {
-			if b, isBool := v.(bool); isBool {
-				validate = b
-			}
+		includeDocs := true // default value
+		if v, ok := request.GetArguments()["includeDocs"].(bool); ok {
+			includeDocs = v
 		}
 
-		coreLogger.Debug("Executing rename_symbol for file: %s line: %d column: %d newName: %s validate: %v", filePath, line, column, newName, validate)
-		text, err := tools.RenameSymbol(s.ctx, s.lspClient, filePath, line, column, newName, validate)
+		coreLogger.Debug("Executing read_symbol for symbol: %s file: %s", symbolPath, filePath)
+		result, err := tools.ReadSymbol(s.ctx, s.lspClient, filePath, symbolPath, includeDocs)
 		if err != nil {
-			coreLogger.Error("Failed to rename symbol: %v", err)
-			return mcp.NewToolResultError(fmt.Sprintf("failed to rename symbol: %v", err)), nil
+			coreLogger.Error("Failed to read symbol: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to read symbol: %v", err)), nil
 		}
-		return mcp.NewToolResultText(text), nil
+		return toolResult(result), nil
 	})
 }
 
-func (s *mcpServer) registerCodeActionsTool() {
-	codeActionsTool := mcp.NewTool("code_actions",
-		mcp.WithDescription("Get available code actions (quick fixes, refactorings) for a range"),
-		mcp.WithString("filePath",
+func (s *mcpServer) registerPackageOutlineTool() {
+	packageOutlineTool := mcp.NewTool("package_outline",
+		mcp.WithDescription("Get a compact outline of the types, functions and methods declared in a directory, with their signatures, in one call. Files are filtered by language and .gitignore."),
+		mcp.WithOutputSchema[tools.PackageOutlineResult](),
+		readOnlyTool(),
+		mcp.WithString("directory",
 			mcp.Required(),
-			mcp.Description("Path to the file"),
+			mcp.Description("Path to the directory to outline"),
 		),
-		mcp.WithNumber("startLine",
-			mcp.Required(),
-			mcp.Description("Start line (1-indexed)"),
+		mcp.WithString("language",
+			mcp.Description("Language ID of the files to outline, e.g. 'go', 'python' or 'typescript' (default: the most common language in the directory)"),
 		),
-		mcp.WithNumber("startColumn",
-			mcp.Required(),
-			mcp.Description("Start column (1-indexed)"),
+		mcp.WithNumber("depth",
+			mcp.Description(fmt.Sprintf("Levels of subdirectories to include, 0 for the directory itself only (default: %d)", tools.DefaultOutlineDepth)),
 		),
-		mcp.WithNumber("endLine",
-			mcp.Required(),
-			mcp.Description("End line (1-indexed)"),
+		mcp.WithBoolean("exportedOnly",
+			mcp.Description("Only list symbols visible outside their package"),
+			mcp.DefaultBool(false),
 		),
-		mcp.WithNumber("endColumn",
-			mcp.Required(),
-			mcp.Description("End column (1-indexed)"),
+		mcp.WithNumber("maxBytes",
+			mcp.Description(fmt.Sprintf("Maximum size of the outline; files that don't fit are counted but not shown (default: %d)", tools.DefaultOutlineMaxBytes)),
 		),
 	)
 
-	s.mcpServer.AddTool(codeActionsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		// Extract arguments
-		filePath, ok := request.GetArguments()["filePath"].(string)
+	s.addTool(packageOutlineTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		opts := tools.PackageOutlineOptions{Depth: tools.DefaultOutlineDepth}
+
+		directory, ok := request.GetArguments()["directory"].(string)
 		if !ok {
-			return mcp.NewToolResultError("filePath must be a string"), nil
+			return mcp.NewToolResultError("directory must be a string"), nil
 		}
+		opts.Directory = directory
 
-		// Handle both float64 and int for all numeric parameters due to JSON parsing
-		var startLine, startColumn, endLine, endColumn int
-
-		switch v := request.GetArguments()["startLine"].(type) {
-		case float64:
-			startLine = int(v)
-		case int:
-			startLine = v
-		default:
-			return mcp.NewToolResultError("startLine must be a number"), nil
+		if v, ok := request.GetArguments()["language"].(string); ok {
+			opts.Language = v
 		}
 
-		switch v := request.GetArguments()["startColumn"].(type) {
+		switch v := request.GetArguments()["depth"].(type) {
 		case float64:
-			startColumn = int(v)
+			opts.Depth = int(v)
 		case int:
-			startColumn = v
-		default:
-			return mcp.NewToolResultError("startColumn must be a number"), nil
+			opts.Depth = v
 		}
 
-		switch v := request.GetArguments()["endLine"].(type) {
-		case float64:
-			endLine = int(v)
-		case int:
-			endLine = v
-		default:
-			return mcp.NewToolResultError("endLine must be a number"), nil
+		if v, ok := request.GetArguments()["exportedOnly"].(bool); ok {
+			opts.ExportedOnly = v
 		}
 
-		switch v := request.GetArguments()["endColumn"].(type) {
+		switch v := request.GetArguments()["maxBytes"].(type) {
 		case float64:
-			endColumn = int(v)
+			opts.MaxBytes = int(v)
 		case int:
-			endColumn = v
-		default:
-			return mcp.NewToolResultError("endColumn must be a number"), nil
+			opts.MaxBytes = v
 		}
 
-		coreLogger.Debug("Executing code_actions for file: %s range: (%d,%d) to (%d,%d)", filePath, startLine, startColumn, endLine, endColumn)
-		text, err := tools.GetCodeActions(s.ctx, s.lspClient, filePath, startLine, startColumn, endLine, endColumn)
+		coreLogger.Debug("Executing package_outline for directory: %s", directory)
+		result, err := tools.PackageOutline(s.ctx, s.lspClient, opts)
 		if err != nil {
-			coreLogger.Error("Failed to get code actions: %v", err)
-			return mcp.NewToolResultError(fmt.Sprintf("failed to get code actions: %v", err)), nil
+			coreLogger.Error("Failed to get package outline: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to get package outline: %v", err)), nil
 		}
-		return mcp.NewToolResultText(text), nil
+		return toolResult(result), nil
 	})
 }
 
-func (s *mcpServer) registerSignatureHelpTool() {
-	signatureHelpTool := mcp.NewTool("signature_help",
-		mcp.WithDescription("Get function/method signature information at cursor position"),
+func (s *mcpServer) registerCallGraphTool() {
+	callGraphTool := mcp.NewTool("call_graph",
+		mcp.WithDescription("Build the transitive call graph of a symbol by following callers, callees or both several levels deep. Returns nodes and edges as JSON, or a Graphviz DOT or Mermaid rendering. Each function appears once, so recursion and call cycles are handled."),
+		mcp.WithOutputSchema[tools.CallGraph](),
+		readOnlyTool(),
 		mcp.WithString("filePath",
 			mcp.Required(),
-			mcp.Description("Path to the file"),
+			mcp.Description("Path to the file containing the symbol"),
 		),
 		mcp.WithNumber("line",
 			mcp.Required(),
@@ -483,10 +1597,25 @@ func (s *mcpServer) registerSignatureHelpTool() {
 			mcp.Required(),
 			mcp.Description("Column number (1-indexed)"),
 		),
+		mcp.WithString("direction",
+			mcp.Description("'incoming' for callers, 'outgoing' for callees or 'both' (default: incoming)"),
+		),
+		mcp.WithNumber("maxDepth",
+			mcp.Description(fmt.Sprintf("Number of call levels to follow (default: %d)", tools.DefaultCallGraphDepth)),
+		),
+		mcp.WithNumber("maxNodes",
+			mcp.Description(fmt.Sprintf("Stop once the graph has this many functions (default: %d)", tools.DefaultCallGraphNodes)),
+		),
+		mcp.WithArray("exclude",
+			mcp.Description("Globs relative to the workspace root of files to leave out, e.g. '**/*_test.go' or 'vendor/**'"),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
+		mcp.WithString("format",
+			mcp.Description("'json', 'dot' or 'mermaid' (default: json)"),
+		),
 	)
 
-	s.mcpServer.AddTool(signatureHelpTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		// Extract arguments
+	s.addTool(callGraphTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		filePath, ok := request.GetArguments()["filePath"].(string)
 		if !ok {
 			return mcp.NewToolResultError("filePath must be a string"), nil
@@ -512,45 +1641,53 @@ func (s *mcpServer) registerSignatureHelpTool() {
 			return mcp.NewToolResultError("column must be a number"), nil
 		}
 
-		coreLogger.Debug("Executing signature_help for file: %s line: %d column: %d", filePath, line, column)
-		text, err := tools.GetSignatureHelp(s.ctx, s.lspClient, filePath, line, column)
-		if err != nil {
-			coreLogger.Error("Failed to get signature help: %v", err)
-			return mcp.NewToolResultError(fmt.Sprintf("failed to get signature help: %v", err)), nil
+		var opts tools.CallGraphOptions
+		if v, ok := request.GetArguments()["direction"].(string); ok {
+			opts.Direction = v
+		}
+		if v, ok := request.GetArguments()["format"].(string); ok {
+			opts.Format = v
 		}
-		return mcp.NewToolResultText(text), nil
-	})
-}
 
-func (s *mcpServer) registerDocumentSymbolsTool() {
-	documentSymbolsTool := mcp.NewTool("document_symbols",
-		mcp.WithDescription("Get the hierarchical symbol outline of a file (classes, functions, methods, etc.)"),
-		mcp.WithString("filePath",
-			mcp.Required(),
-			mcp.Description("Path to the file to get symbols for"),
-		),
-	)
+		switch v := request.GetArguments()["maxDepth"].(type) {
+		case float64:
+			opts.MaxDepth = int(v)
+		case int:
+			opts.MaxDepth = v
+		}
 
-	s.mcpServer.AddTool(documentSymbolsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		// Extract arguments
-		filePath, ok := request.GetArguments()["filePath"].(string)
-		if !ok {
-			return mcp.NewToolResultError("filePath must be a string"), nil
+		switch v := request.GetArguments()["maxNodes"].(type) {
+		case float64:
+			opts.MaxNodes = int(v)
+		case int:
+			opts.MaxNodes = v
 		}
 
-		coreLogger.Debug("Executing document_symbols for file: %s", filePath)
-		text, err := tools.GetDocumentSymbols(s.ctx, s.lspClient, filePath)
+		if v, ok := request.GetArguments()["exclude"].([]any); ok {
+			for _, item := range v {
+				pattern, ok := item.(string)
+				if !ok {
+					return mcp.NewToolResultError("exclude must be an array of strings"), nil
+				}
+				opts.Exclude = append(opts.Exclude, pattern)
+			}
+		}
+
+		coreLogger.Debug("Executing call_graph for file: %s line: %d column: %d direction: %s", filePath, line, column, opts.Direction)
+		result, err := tools.GetCallGraph(s.ctx, s.lspClient, filePath, line, column, opts)
 		if err != nil {
-			coreLogger.Error("Failed to get document symbols: %v", err)
-			return mcp.NewToolResultError(fmt.Sprintf("failed to get document symbols: %v", err)), nil
+			coreLogger.Error("Failed to get call graph: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to get call graph: %v", err)), nil
 		}
-		return mcp.NewToolResultText(text), nil
+		return toolResult(result), nil
 	})
 }
 
 func (s *mcpServer) registerCallHierarchyTool() {
 	callHierarchyTool := mcp.NewTool("call_hierarchy",
 		mcp.WithDescription("Find incoming callers or outgoing callees for a symbol at the specified position."),
+		mcp.WithOutputSchema[tools.CallHierarchyResult](),
+		readOnlyTool(),
 		mcp.WithString("filePath",
 			mcp.Required(),
 			mcp.Description("Path to the file containing the symbol"),
@@ -569,7 +1706,7 @@ func (s *mcpServer) registerCallHierarchyTool() {
 		),
 	)
 
-	s.mcpServer.AddTool(callHierarchyTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.addTool(callHierarchyTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		// Extract arguments
 		filePath, ok := request.GetArguments()["filePath"].(string)
 		if !ok {
@@ -607,43 +1744,47 @@ func (s *mcpServer) registerCallHierarchyTool() {
 		}
 
 		coreLogger.Debug("Executing call_hierarchy for file: %s line: %d column: %d direction: %s", filePath, line, column, direction)
-		text, err := tools.GetCallHierarchy(s.ctx, s.lspClient, filePath, line, column, direction)
+		result, err := tools.GetCallHierarchy(s.ctx, s.lspClient, filePath, line, column, direction)
 		if err != nil {
 			coreLogger.Error("Failed to get call hierarchy: %v", err)
 			return mcp.NewToolResultError(fmt.Sprintf("failed to get call hierarchy: %v", err)), nil
 		}
-		return mcp.NewToolResultText(text), nil
+		return toolResult(result), nil
 	})
 }
 
 func (s *mcpServer) registerSemanticTokensTool() {
 	semanticTokensTool := mcp.NewTool("semantic_tokens",
 		mcp.WithDescription("Get semantic token information for syntax highlighting, showing token types and modifiers for code elements."),
+		mcp.WithOutputSchema[tools.SemanticTokensResult](),
+		readOnlyTool(),
 		mcp.WithString("filePath",
 			mcp.Required(),
 			mcp.Description("Path to the file to get semantic tokens for"),
 		),
 	)
 
-	s.mcpServer.AddTool(semanticTokensTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.addTool(semanticTokensTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		filePath, ok := request.GetArguments()["filePath"].(string)
 		if !ok {
 			return mcp.NewToolResultError("filePath must be a string"), nil
 		}
 
 		coreLogger.Debug("Executing semantic_tokens for file: %s", filePath)
-		text, err := tools.GetSemanticTokens(ctx, s.lspClient, filePath)
+		result, err := tools.GetSemanticTokens(ctx, s.lspClient, filePath)
 		if err != nil {
 			coreLogger.Error("Failed to get semantic tokens: %v", err)
 			return mcp.NewToolResultError(fmt.Sprintf("failed to get semantic tokens: %v", err)), nil
 		}
-		return mcp.NewToolResultText(text), nil
+		return toolResult(result), nil
 	})
 }
 
 func (s *mcpServer) registerTypeHierarchyTool() {
 	typeHierarchyTool := mcp.NewTool("type_hierarchy",
 		mcp.WithDescription("Navigate type hierarchies to find supertypes (parent classes/interfaces) or subtypes (derived classes/implementations) of a type."),
+		mcp.WithOutputSchema[tools.TypeHierarchyResult](),
+		readOnlyTool(),
 		mcp.WithString("filePath",
 			mcp.Required(),
 			mcp.Description("Path to the file containing the type"),
@@ -662,7 +1803,7 @@ func (s *mcpServer) registerTypeHierarchyTool() {
 		),
 	)
 
-	s.mcpServer.AddTool(typeHierarchyTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.addTool(typeHierarchyTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		filePath, ok := request.GetArguments()["filePath"].(string)
 		if !ok {
 			return mcp.NewToolResultError("filePath must be a string"), nil
@@ -697,18 +1838,93 @@ func (s *mcpServer) registerTypeHierarchyTool() {
 		}
 
 		coreLogger.Debug("Executing type_hierarchy for file: %s line: %d column: %d direction: %s", filePath, line, column, direction)
-		text, err := tools.GetTypeHierarchy(ctx, s.lspClient, filePath, line, column, direction)
+		result, err := tools.GetTypeHierarchy(ctx, s.lspClient, filePath, line, column, direction)
 		if err != nil {
 			coreLogger.Error("Failed to get type hierarchy: %v", err)
 			return mcp.NewToolResultError(fmt.Sprintf("failed to get type hierarchy: %v", err)), nil
 		}
-		return mcp.NewToolResultText(text), nil
+		return toolResult(result), nil
+	})
+}
+
+func (s *mcpServer) registerTypeHierarchyTreeTool() {
+	typeHierarchyTreeTool := mcp.NewTool("type_hierarchy_tree",
+		mcp.WithDescription("Show the full inheritance or implementation tree of a type, following supertypes and/or subtypes recursively, plus a flat list of the concrete types implementing it with their locations. Falls back to textDocument/implementation where type hierarchy isn't available, e.g. for Go interfaces: interfaces found for a non-interface type are shown as its supertypes, other results as subtypes."),
+		mcp.WithOutputSchema[tools.TypeHierarchyTreeResult](),
+		readOnlyTool(),
+		mcp.WithString("filePath",
+			mcp.Required(),
+			mcp.Description("Path to the file containing the type"),
+		),
+		mcp.WithNumber("line",
+			mcp.Required(),
+			mcp.Description("Line number where the type is located (1-indexed)"),
+		),
+		mcp.WithNumber("column",
+			mcp.Required(),
+			mcp.Description("Column number where the type is located (1-indexed)"),
+		),
+		mcp.WithString("direction",
+			mcp.Description("Direction: 'supertypes' for parent types, 'subtypes' for derived types, or 'both' (default: subtypes)"),
+		),
+		mcp.WithNumber("maxDepth",
+			mcp.Description(fmt.Sprintf("Number of levels to follow (default: %d)", tools.DefaultTypeTreeDepth)),
+		),
+	)
+
+	s.addTool(typeHierarchyTreeTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		filePath, ok := request.GetArguments()["filePath"].(string)
+		if !ok {
+			return mcp.NewToolResultError("filePath must be a string"), nil
+		}
+
+		direction := "subtypes" // default value
+		if v, ok := request.GetArguments()["direction"].(string); ok {
+			direction = v
+		}
+
+		var line, column int
+		switch v := request.GetArguments()["line"].(type) {
+		case float64:
+			line = int(v)
+		case int:
+			line = v
+		default:
+			return mcp.NewToolResultError("line must be a number"), nil
+		}
+
+		switch v := request.GetArguments()["column"].(type) {
+		case float64:
+			column = int(v)
+		case int:
+			column = v
+		default:
+			return mcp.NewToolResultError("column must be a number"), nil
+		}
+
+		maxDepth := tools.DefaultTypeTreeDepth // default value
+		switch v := request.GetArguments()["maxDepth"].(type) {
+		case float64:
+			maxDepth = int(v)
+		case int:
+			maxDepth = v
+		}
+
+		coreLogger.Debug("Executing type_hierarchy_tree for file: %s line: %d column: %d direction: %s", filePath, line, column, direction)
+		result, err := tools.GetTypeHierarchyTree(s.ctx, s.lspClient, filePath, line, column, direction, maxDepth)
+		if err != nil {
+			coreLogger.Error("Failed to get type hierarchy tree: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to get type hierarchy tree: %v", err)), nil
+		}
+		return toolResult(result), nil
 	})
 }
 
 func (s *mcpServer) registerInlayHintsTool() {
 	inlayHintsTool := mcp.NewTool("inlay_hints",
 		mcp.WithDescription("Get inlay hints showing type annotations, parameter names, and other inline information within a line range."),
+		mcp.WithOutputSchema[tools.InlayHintsResult](),
+		readOnlyTool(),
 		mcp.WithString("filePath",
 			mcp.Required(),
 			mcp.Description("Path to the file to get inlay hints for"),
@@ -723,7 +1939,7 @@ func (s *mcpServer) registerInlayHintsTool() {
 		),
 	)
 
-	s.mcpServer.AddTool(inlayHintsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.addTool(inlayHintsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		filePath, ok := request.GetArguments()["filePath"].(string)
 		if !ok {
 			return mcp.NewToolResultError("filePath must be a string"), nil
@@ -749,43 +1965,55 @@ func (s *mcpServer) registerInlayHintsTool() {
 		}
 
 		coreLogger.Debug("Executing inlay_hints for file: %s lines: %d-%d", filePath, startLine, endLine)
-		text, err := tools.GetInlayHints(ctx, s.lspClient, filePath, startLine, endLine)
+		result, err := tools.GetInlayHints(ctx, s.lspClient, filePath, startLine, endLine)
 		if err != nil {
 			coreLogger.Error("Failed to get inlay hints: %v", err)
 			return mcp.NewToolResultError(fmt.Sprintf("failed to get inlay hints: %v", err)), nil
 		}
-		return mcp.NewToolResultText(text), nil
+		return toolResult(result), nil
 	})
 }
 
 func (s *mcpServer) registerWorkspaceSymbolResolveTool() {
 	workspaceSymbolResolveTool := mcp.NewTool("workspace_symbol_resolve",
 		mcp.WithDescription("Search for symbols across the workspace with enhanced details including location and container information."),
+		mcp.WithOutputSchema[tools.WorkspaceSymbolsResult](),
+		readOnlyTool(),
 		mcp.WithString("query",
 			mcp.Required(),
 			mcp.Description("The symbol name or pattern to search for"),
 		),
+		withPaging(),
 	)
 
-	s.mcpServer.AddTool(workspaceSymbolResolveTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.addTool(workspaceSymbolResolveTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		query, ok := request.GetArguments()["query"].(string)
 		if !ok {
 			return mcp.NewToolResultError("query must be a string"), nil
 		}
+		page, err := parsePageOptions(request)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
 
 		coreLogger.Debug("Executing workspace_symbol_resolve for query: %s", query)
-		text, err := tools.GetWorkspaceSymbolResolved(ctx, s.lspClient, query)
+		result, err := tools.GetWorkspaceSymbolResolved(ctx, s.lspClient, query)
 		if err != nil {
 			coreLogger.Error("Failed to resolve workspace symbol: %v", err)
 			return mcp.NewToolResultError(fmt.Sprintf("failed to resolve workspace symbol: %v", err)), nil
 		}
-		return mcp.NewToolResultText(text), nil
+		if err := tools.Paginate(result, page); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		return toolResult(result), nil
 	})
 }
 
 func (s *mcpServer) registerFormatDocumentTool() {
 	formatDocumentTool := mcp.NewTool("format_document",
 		mcp.WithDescription("Format a document or range using the language server's formatting capabilities."),
+		mcp.WithOutputSchema[tools.EditResult](),
+		editTool(true, true),
 		mcp.WithString("filePath",
 			mcp.Required(),
 			mcp.Description("Path to the file to format"),
@@ -808,9 +2036,13 @@ func (s *mcpServer) registerFormatDocumentTool() {
 		mcp.WithString("triggerChar",
 			mcp.Description("The character that triggered formatting (only for 'ontype' mode)"),
 		),
+		mcp.WithBoolean("dryRun",
+			mcp.Description("Show the formatting changes as a unified diff without writing the file"),
+			mcp.DefaultBool(false),
+		),
 	)
 
-	s.mcpServer.AddTool(formatDocumentTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.addTool(formatDocumentTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		filePath, ok := request.GetArguments()["filePath"].(string)
 		if !ok {
 			return mcp.NewToolResultError("filePath must be a string"), nil
@@ -840,44 +2072,95 @@ func (s *mcpServer) registerFormatDocumentTool() {
 			triggerChar = tc
 		}
 
-		coreLogger.Debug("Executing format_document for file: %s mode: %s", filePath, mode)
-		text, err := tools.FormatDocument(ctx, s.lspClient, filePath, mode, startLine, startColumn, endLine, endColumn, triggerChar)
+		dryRun := false // default value
+		if v, ok := request.GetArguments()["dryRun"].(bool); ok {
+			dryRun = v
+		}
+
+		coreLogger.Debug("Executing format_document for file: %s mode: %s dryRun: %v", filePath, mode, dryRun)
+		result, err := tools.FormatDocument(ctx, s.lspClient, filePath, mode, startLine, startColumn, endLine, endColumn, triggerChar, dryRun)
 		if err != nil {
 			coreLogger.Error("Failed to format document: %v", err)
 			return mcp.NewToolResultError(fmt.Sprintf("failed to format document: %v", err)), nil
 		}
-		return mcp.NewToolResultText(text), nil
+		return toolResult(result), nil
 	})
 }
 
 func (s *mcpServer) registerFoldingRangeTool() {
 	foldingRangeTool := mcp.NewTool("folding_range",
 		mcp.WithDescription("Get folding ranges for a document, identifying collapsible regions like functions, classes, and blocks."),
+		mcp.WithOutputSchema[tools.FoldingRangesResult](),
+		readOnlyTool(),
 		mcp.WithString("filePath",
 			mcp.Required(),
 			mcp.Description("Path to the file to get folding ranges for"),
 		),
 	)
 
-	s.mcpServer.AddTool(foldingRangeTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.addTool(foldingRangeTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		filePath, ok := request.GetArguments()["filePath"].(string)
 		if !ok {
 			return mcp.NewToolResultError("filePath must be a string"), nil
 		}
 
 		coreLogger.Debug("Executing folding_range for file: %s", filePath)
-		text, err := tools.GetFoldingRanges(ctx, s.lspClient, filePath)
+		result, err := tools.GetFoldingRanges(ctx, s.lspClient, filePath)
 		if err != nil {
 			coreLogger.Error("Failed to get folding ranges: %v", err)
 			return mcp.NewToolResultError(fmt.Sprintf("failed to get folding ranges: %v", err)), nil
 		}
-		return mcp.NewToolResultText(text), nil
+		return toolResult(result), nil
+	})
+}
+
+func (s *mcpServer) registerFileSkeletonTool() {
+	fileSkeletonTool := mcp.NewTool("file_skeleton",
+		mcp.WithDescription("Get a low-token overview of a file: its source with function and method bodies collapsed to '{ ... }' placeholders and the original line numbers kept. Use it to find your way around a large file before reading parts of it."),
+		mcp.WithOutputSchema[tools.FileSkeletonResult](),
+		readOnlyTool(),
+		mcp.WithString("filePath",
+			mcp.Required(),
+			mcp.Description("Path to the file"),
+		),
+		mcp.WithArray("expand",
+			mcp.Description("Names of symbols whose bodies are shown in full, e.g. 'handleRequest' or 'Server.Start'"),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
+	)
+
+	s.addTool(fileSkeletonTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		filePath, ok := request.GetArguments()["filePath"].(string)
+		if !ok {
+			return mcp.NewToolResultError("filePath must be a string"), nil
+		}
+
+		var expand []string
+		if v, ok := request.GetArguments()["expand"].([]any); ok {
+			for _, item := range v {
+				name, ok := item.(string)
+				if !ok {
+					return mcp.NewToolResultError("expand must be an array of strings"), nil
+				}
+				expand = append(expand, name)
+			}
+		}
+
+		coreLogger.Debug("Executing file_skeleton for file: %s", filePath)
+		result, err := tools.GetFileSkeleton(s.ctx, s.lspClient, filePath, expand)
+		if err != nil {
+			coreLogger.Error("Failed to get file skeleton: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to get file skeleton: %v", err)), nil
+		}
+		return toolResult(result), nil
 	})
 }
 
 func (s *mcpServer) registerSelectionRangeTool() {
 	selectionRangeTool := mcp.NewTool("selection_range",
 		mcp.WithDescription("Get hierarchical selection ranges at a position for smart expand/shrink selection."),
+		mcp.WithOutputSchema[tools.SelectionRangesResult](),
+		readOnlyTool(),
 		mcp.WithString("filePath",
 			mcp.Required(),
 			mcp.Description("Path to the file"),
@@ -892,7 +2175,7 @@ func (s *mcpServer) registerSelectionRangeTool() {
 		),
 	)
 
-	s.mcpServer.AddTool(selectionRangeTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.addTool(selectionRangeTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		filePath, ok := request.GetArguments()["filePath"].(string)
 		if !ok {
 			return mcp.NewToolResultError("filePath must be a string"), nil
@@ -918,12 +2201,99 @@ func (s *mcpServer) registerSelectionRangeTool() {
 		}
 
 		coreLogger.Debug("Executing selection_range for file: %s line: %d column: %d", filePath, line, column)
-		text, err := tools.GetSelectionRanges(ctx, s.lspClient, filePath, line, column)
+		result, err := tools.GetSelectionRanges(ctx, s.lspClient, filePath, line, column)
 		if err != nil {
 			coreLogger.Error("Failed to get selection ranges: %v", err)
 			return mcp.NewToolResultError(fmt.Sprintf("failed to get selection ranges: %v", err)), nil
 		}
-		return mcp.NewToolResultText(text), nil
+		return toolResult(result), nil
+	})
+}
+
+func (s *mcpServer) registerBatchTool() {
+	// Batched calls can only reach the tools that are registered
+	annotation := editTool(true, false)
+	if s.config.readOnly {
+		annotation = readOnlyTool()
+	}
+	batchTool := mcp.NewTool("batch",
+		mcp.WithDescription("Run several tool calls in one request, such as a series of hover, definition or document_symbols lookups. Read-only calls run concurrently against the language server; calls that edit files run one at a time, after the calls before them. Each call gets its own result or error, in the order given."),
+		mcp.WithOutputSchema[BatchResult](),
+		annotation,
+		mcp.WithArray("requests",
+			mcp.Required(),
+			mcp.Description("Tool calls to run"),
+			mcp.Items(map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"tool": map[string]any{
+						"type":        "string",
+						"description": "Name of the tool to call, e.g. 'hover'",
+					},
+					"arguments": map[string]any{
+						"type":        "object",
+						"description": "Arguments of the tool call",
+					},
+				},
+				"required": []string{"tool"},
+			}),
+		),
+		mcp.WithNumber("concurrency",
+			mcp.Description(fmt.Sprintf("Maximum number of calls to run at once (default: %d, max: %d)", defaultBatchConcurrency, maxBatchConcurrency)),
+		),
+		mcp.WithBoolean("stopOnError",
+			mcp.Description("Skip the calls not yet started once one fails"),
+			mcp.DefaultBool(false),
+		),
+	)
+
+	s.addTool(batchTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		calls, err := parseBatchCalls(request.GetArguments()["requests"])
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		for i, call := range calls {
+			if tool := s.mcpServer.GetTool(call.Tool); tool != nil {
+				calls[i].edits = !isReadOnlyTool(tool.Tool)
+			}
+		}
+
+		concurrency := defaultBatchConcurrency // default value
+		switch v := request.GetArguments()["concurrency"].(type) {
+		case float64:
+			concurrency = int(v)
+		case int:
+			concurrency = v
+		}
+		if concurrency > maxBatchConcurrency {
+			concurrency = maxBatchConcurrency
+		}
+
+		stopOnError := false // default value
+		if v, ok := request.GetArguments()["stopOnError"].(bool); ok {
+			stopOnError = v
+		}
+
+		coreLogger.Debug("Executing batch of %d requests with concurrency %d", len(calls), concurrency)
+		results := runBatch(ctx, calls, concurrency, stopOnError, func(ctx context.Context, call batchCall) batchResult {
+			tool := s.mcpServer.GetTool(call.Tool)
+			if tool == nil {
+				return batchResult{text: fmt.Sprintf("unknown tool: %s", call.Tool), failed: true}
+			}
+			var subRequest mcp.CallToolRequest
+			subRequest.Params.Name = call.Tool
+			subRequest.Params.Arguments = call.Arguments
+			result, err := tool.Handler(ctx, subRequest)
+			if err != nil {
+				coreLogger.Error("Failed to run batched %s: %v", call.Tool, err)
+				return batchResult{text: err.Error(), failed: true}
+			}
+			if result == nil {
+				return batchResult{failed: true}
+			}
+			return batchResult{text: toolResultText(result), structured: result.StructuredContent, failed: result.IsError}
+		})
+		return toolResult(newBatchResult(calls, results)), nil
 	})
 }
 
@@ -932,7 +2302,12 @@ func (s *mcpServer) registerTools(caps *protocol.ServerCapabilities) error {
 	if caps == nil {
 		coreLogger.Warn("No server capabilities provided - registering minimal tool set")
 		s.registerEditFileTool()
+		s.registerApplyPatchTool()
+		s.registerRenameFileTool()
+		s.registerCreateDeleteFileTools()
+		s.registerUndoRedoTools()
 		s.registerDiagnosticsTool()
+		s.registerBatchTool()
 		return nil
 	}
 
@@ -960,6 +2335,10 @@ func (s *mcpServer) registerTools(caps *protocol.ServerCapabilities) error {
 	// Always register core tools (capability-independent)
 	coreLogger.Debug("Registering core tools")
 	s.registerEditFileTool()
+	s.registerApplyPatchTool()
+	s.registerRenameFileTool()
+	s.registerCreateDeleteFileTools()
+	s.registerUndoRedoTools()
 	s.registerDiagnosticsTool()
 
 	// Conditionally register capability-dependent tools
@@ -984,6 +2363,13 @@ func (s *mcpServer) registerTools(caps *protocol.ServerCapabilities) error {
 		coreLogger.Info("Skipping 'hover' tool - LSP server doesn't support Hover capability")
 	}
 
+	if lsp.HasHoverSupport(caps) || lsp.HasDefinitionSupport(caps) || lsp.HasReferencesSupport(caps) {
+		coreLogger.Debug("Registering 'inspect' tool")
+		s.registerInspectTool()
+	} else {
+		coreLogger.Info("Skipping 'inspect' tool - LSP server doesn't support Hover, Definition or References capabilities")
+	}
+
 	if lsp.HasRenameSupport(caps) {
 		coreLogger.Debug("Registering 'rename_symbol' tool")
 		s.registerRenameSymbolTool()
@@ -998,6 +2384,38 @@ func (s *mcpServer) registerTools(caps *protocol.ServerCapabilities) error {
 		coreLogger.Info("Skipping 'code_actions' tool - LSP server doesn't support CodeAction capability")
 	}
 
+	if lsp.HasCodeActionKindSupport(caps, protocol.QuickFix) {
+		coreLogger.Debug("Registering 'fix_diagnostic' tool")
+		s.registerFixDiagnosticTool()
+	} else {
+		coreLogger.Info("Skipping 'fix_diagnostic' tool - LSP server doesn't offer quickfix code actions")
+	}
+
+	if lsp.HasCodeActionKindSupport(caps, protocol.Refactor) {
+		coreLogger.Debug("Registering 'refactor' tool")
+		s.registerRefactorTool()
+	} else {
+		coreLogger.Info("Skipping 'refactor' tool - LSP server doesn't offer refactor code actions")
+	}
+
+	if lsp.HasCodeActionKindSupport(caps, protocol.SourceOrganizeImports) {
+		coreLogger.Debug("Registering 'organize_imports' tool")
+		s.registerSourceActionTool("organize_imports",
+			"Organize imports (sort, add missing, remove unused) in whole files using the language server's source.organizeImports code action. Pass filePaths, a glob, or both.",
+			tools.OrganizeImports)
+	} else {
+		coreLogger.Info("Skipping 'organize_imports' tool - LSP server doesn't offer source.organizeImports code actions")
+	}
+
+	if lsp.HasCodeActionKindSupport(caps, protocol.SourceFixAll) {
+		coreLogger.Debug("Registering 'fix_all' tool")
+		s.registerSourceActionTool("fix_all",
+			"Apply all automatic fixes in whole files using the language server's source.fixAll code action. Pass filePaths, a glob, or both.",
+			tools.FixAll)
+	} else {
+		coreLogger.Info("Skipping 'fix_all' tool - LSP server doesn't offer source.fixAll code actions")
+	}
+
 	if lsp.HasSignatureHelpSupport(caps) {
 		coreLogger.Debug("Registering 'signature_help' tool")
 		s.registerSignatureHelpTool()
@@ -1006,17 +2424,50 @@ func (s *mcpServer) registerTools(caps *protocol.ServerCapabilities) error {
 	}
 
 	if lsp.HasDocumentSymbolSupport(caps) {
-		coreLogger.Debug("Registering 'document_symbols' tool")
+		coreLogger.Debug("Registering 'document_symbols', 'package_outline' and 'read_symbol' tools")
 		s.registerDocumentSymbolsTool()
+		s.registerPackageOutlineTool()
+		s.registerReadSymbolTool()
+	} else {
+		coreLogger.Info("Skipping 'document_symbols', 'package_outline' and 'read_symbol' tools - LSP server doesn't support DocumentSymbol capability")
+	}
+
+	if lsp.HasDocumentSymbolSupport(caps) {
+		coreLogger.Debug("Registering symbol edit tools")
+		s.registerSymbolEditTool(tools.ReplaceSymbol,
+			"Replace the whole declaration of a symbol, given by its dotted path in a file, with new text. Safer than line-number edits since the symbol's range comes from the language server.",
+			"The new declaration. If it isn't indented, it is indented like the symbol.")
+		s.registerSymbolEditTool(tools.InsertBeforeSymbol,
+			"Insert text before a symbol, given by its dotted path in a file, above its doc comment and separated by a blank line. Use it to add a function or method next to an existing one.",
+			"The text to insert. If it isn't indented, it is indented like the symbol.")
+		s.registerSymbolEditTool(tools.InsertAfterSymbol,
+			"Insert text after a symbol, given by its dotted path in a file, separated by a blank line. Use it to add a function after another one, or a method after a type.",
+			"The text to insert. If it isn't indented, it is indented like the symbol.")
+		s.registerMoveSymbolTool()
+	} else {
+		coreLogger.Info("Skipping symbol edit tools - LSP server doesn't support DocumentSymbol capability")
+	}
+
+	if lsp.HasDocumentSymbolSupport(caps) && lsp.HasReferencesSupport(caps) {
+		coreLogger.Debug("Registering 'find_unused' tool")
+		s.registerFindUnusedTool()
+	} else {
+		coreLogger.Info("Skipping 'find_unused' tool - LSP server doesn't support both DocumentSymbol and References capabilities")
+	}
+
+	if lsp.HasDocumentSymbolSupport(caps) {
+		coreLogger.Debug("Registering 'impact_of_changes' tool")
+		s.registerImpactOfChangesTool()
 	} else {
-		coreLogger.Info("Skipping 'document_symbols' tool - LSP server doesn't support DocumentSymbol capability")
+		coreLogger.Info("Skipping 'impact_of_changes' tool - LSP server doesn't support DocumentSymbol capability")
 	}
 
 	if lsp.HasCallHierarchySupport(caps) {
-		coreLogger.Debug("Registering 'call_hierarchy' tool")
+		coreLogger.Debug("Registering 'call_hierarchy' and 'call_graph' tools")
 		s.registerCallHierarchyTool()
+		s.registerCallGraphTool()
 	} else {
-		coreLogger.Info("Skipping 'call_hierarchy' tool - LSP server doesn't support CallHierarchy capability (requires LSP 3.16+)")
+		coreLogger.Info("Skipping 'call_hierarchy' and 'call_graph' tools - LSP server doesn't support CallHierarchy capability (requires LSP 3.16+)")
 	}
 
 	if lsp.HasCodeLensSupport(caps) {
@@ -1042,6 +2493,13 @@ func (s *mcpServer) registerTools(caps *protocol.ServerCapabilities) error {
 		coreLogger.Info("Skipping 'type_hierarchy' tool - LSP server doesn't support TypeHierarchy capability")
 	}
 
+	if lsp.HasTypeHierarchySupport(caps) || lsp.HasImplementationSupport(caps) {
+		coreLogger.Debug("Registering 'type_hierarchy_tree' tool")
+		s.registerTypeHierarchyTreeTool()
+	} else {
+		coreLogger.Info("Skipping 'type_hierarchy_tree' tool - LSP server supports neither TypeHierarchy nor Implementation")
+	}
+
 	if lsp.HasInlayHintSupport(caps) {
 		coreLogger.Debug("Registering 'inlay_hints' tool")
 		s.registerInlayHintsTool()
@@ -1070,6 +2528,13 @@ func (s *mcpServer) registerTools(caps *protocol.ServerCapabilities) error {
 		coreLogger.Info("Skipping 'folding_range' tool - LSP server doesn't support FoldingRange capability")
 	}
 
+	if lsp.HasFoldingRangeSupport(caps) && lsp.HasDocumentSymbolSupport(caps) {
+		coreLogger.Debug("Registering 'file_skeleton' tool")
+		s.registerFileSkeletonTool()
+	} else {
+		coreLogger.Info("Skipping 'file_skeleton' tool - LSP server doesn't support both FoldingRange and DocumentSymbol capabilities")
+	}
+
 	if lsp.HasSelectionRangeSupport(caps) {
 		coreLogger.Debug("Registering 'selection_range' tool")
 		s.registerSelectionRangeTool()
@@ -1077,6 +2542,9 @@ func (s *mcpServer) registerTools(caps *protocol.ServerCapabilities) error {
 		coreLogger.Info("Skipping 'selection_range' tool - LSP server doesn't support SelectionRange capability")
 	}
 
+	coreLogger.Debug("Registering 'batch' tool")
+	s.registerBatchTool()
+
 	coreLogger.Info("Successfully registered MCP tools")
 	return nil
 }
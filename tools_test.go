@@ -0,0 +1,128 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fullCapabilities are server capabilities for which every tool is registered
+func fullCapabilities(t *testing.T) *protocol.ServerCapabilities {
+	t.Helper()
+	var caps protocol.ServerCapabilities
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"definitionProvider": true,
+		"referencesProvider": true,
+		"hoverProvider": true,
+		"renameProvider": true,
+		"codeActionProvider": true,
+		"codeLensProvider": {},
+		"signatureHelpProvider": {},
+		"documentSymbolProvider": true,
+		"callHierarchyProvider": true,
+		"workspaceSymbolProvider": {"resolveProvider": true},
+		"semanticTokensProvider": {"legend": {"tokenTypes": [], "tokenModifiers": []}},
+		"typeHierarchyProvider": true,
+		"implementationProvider": true,
+		"inlayHintProvider": true,
+		"documentFormattingProvider": true,
+		"foldingRangeProvider": true,
+		"selectionRangeProvider": true
+	}`), &caps))
+	return &caps
+}
+
+func TestToolsDeclareOutputSchema(t *testing.T) {
+	s := &mcpServer{mcpServer: server.NewMCPServer("test", "v0.0.1")}
+	require.NoError(t, s.registerTools(fullCapabilities(t)))
+
+	registered := s.mcpServer.ListTools()
+	assert.Greater(t, len(registered), 40)
+	for name, tool := range registered {
+		assert.Equal(t, "object", tool.Tool.OutputSchema.Type, name)
+		assert.NotEmpty(t, tool.Tool.OutputSchema.Properties, "%s has no output schema", name)
+	}
+}
+
+func TestParsePageOptions(t *testing.T) {
+	request := func(name string, args map[string]any) mcp.CallToolRequest {
+		var request mcp.CallToolRequest
+		request.Params.Name = name
+		request.Params.Arguments = args
+		return request
+	}
+
+	opts, err := parsePageOptions(request("references", map[string]any{
+		"symbolName": "Run", "maxTokens": float64(500), "maxResults": 20, "cursor": "abc",
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, 500, opts.MaxTokens)
+	assert.Equal(t, 20, opts.MaxResults)
+	assert.Equal(t, "abc", opts.Cursor)
+
+	next, err := parsePageOptions(request("references", map[string]any{"symbolName": "Run", "maxResults": 20}))
+	require.NoError(t, err)
+	assert.Equal(t, opts.Key, next.Key, "paging arguments don't change the key")
+
+	other, err := parsePageOptions(request("references", map[string]any{"symbolName": "Stop"}))
+	require.NoError(t, err)
+	assert.NotEqual(t, opts.Key, other.Key)
+	other, err = parsePageOptions(request("find_unused", map[string]any{"symbolName": "Run"}))
+	require.NoError(t, err)
+	assert.NotEqual(t, opts.Key, other.Key)
+
+	_, err = parsePageOptions(request("references", map[string]any{"maxTokens": "many"}))
+	assert.Error(t, err)
+	_, err = parsePageOptions(request("references", map[string]any{"cursor": 3}))
+	assert.Error(t, err)
+}
+
+func TestToolAnnotations(t *testing.T) {
+	s := &mcpServer{mcpServer: server.NewMCPServer("test", "v0.0.1")}
+	require.NoError(t, s.registerTools(fullCapabilities(t)))
+
+	for name, tool := range s.mcpServer.ListTools() {
+		annotations := tool.Tool.Annotations
+		require.NotNil(t, annotations.ReadOnlyHint, name)
+		assert.False(t, *annotations.OpenWorldHint, name)
+		if *annotations.ReadOnlyHint {
+			assert.False(t, *annotations.DestructiveHint, name)
+		}
+	}
+	for _, name := range []string{"edit_file", "rename_symbol", "format_document", "execute_codelens", "batch"} {
+		assert.False(t, *s.mcpServer.GetTool(name).Tool.Annotations.ReadOnlyHint, name)
+	}
+	assert.True(t, *s.mcpServer.GetTool("hover").Tool.Annotations.ReadOnlyHint)
+	assert.False(t, *s.mcpServer.GetTool("insert_after_symbol").Tool.Annotations.DestructiveHint)
+	assert.True(t, *s.mcpServer.GetTool("replace_symbol").Tool.Annotations.DestructiveHint)
+}
+
+func TestReadOnlyMode(t *testing.T) {
+	s := &mcpServer{config: config{readOnly: true}, mcpServer: server.NewMCPServer("test", "v0.0.1")}
+	require.NoError(t, s.registerTools(fullCapabilities(t)))
+
+	var names []string
+	for name, tool := range s.mcpServer.ListTools() {
+		assert.True(t, *tool.Tool.Annotations.ReadOnlyHint, name)
+		names = append(names, name)
+	}
+	assert.ElementsMatch(t, []string{
+		"batch", "call_graph", "call_hierarchy", "code_actions", "definition", "diagnostics",
+		"document_symbols", "file_skeleton", "find_unused", "folding_range", "get_codelens",
+		"hover", "impact_of_changes", "inlay_hints", "inspect", "package_outline", "read_symbol",
+		"references", "selection_range", "semantic_tokens", "signature_help", "type_hierarchy",
+		"type_hierarchy_tree", "workspace_symbol_resolve",
+	}, names)
+}
+
+func TestRegisterToolsWithoutCapabilities(t *testing.T) {
+	s := &mcpServer{mcpServer: server.NewMCPServer("test", "v0.0.1")}
+	require.NoError(t, s.registerTools(nil))
+	assert.NotNil(t, s.mcpServer.GetTool("edit_file"))
+	assert.NotNil(t, s.mcpServer.GetTool("batch"))
+}